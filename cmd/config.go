@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/JorgeePG/todo-list/internal/config"
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/urfave/cli/v2"
+)
+
+// cfg es la configuración resuelta en el Before de la app; todos los comandos
+// la leen de aquí.
+var cfg config.Config
+
+// configFlags son los flags globales que sobrescriben fichero y entorno.
+func configFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "Fichero de configuración TOML o YAML (por defecto todo.toml|todo.yaml si existe)",
+			EnvVars: []string{"TODO_CONFIG"},
+		},
+		&cli.StringFlag{
+			Name:  "db",
+			Usage: "DSN de la base de datos SQLite [$TODO_DB_DSN]",
+		},
+		&cli.StringFlag{
+			Name:  "addr",
+			Usage: "Dirección en la que escucha el servidor [$TODO_LISTEN_ADDR]",
+		},
+		&cli.StringFlag{
+			Name:  "templates-dir",
+			Usage: "Directorio de plantillas HTML [$TODO_TEMPLATES_DIR]",
+		},
+		&cli.StringFlag{
+			Name:  "static-dir",
+			Usage: "Directorio de archivos estáticos [$TODO_STATIC_DIR]",
+		},
+		&cli.StringFlag{
+			Name:  "session-secret",
+			Usage: "Clave para firmar las cookies de sesión [$TODO_SESSION_SECRET]",
+		},
+		&cli.StringFlag{
+			Name:  "log-level",
+			Usage: "Nivel de log: debug|info|warn|error [$TODO_LOG_LEVEL]",
+		},
+	}
+}
+
+// loadConfig resuelve la configuración con precedencia
+// flags > variables de entorno > fichero > valores por defecto.
+func loadConfig(c *cli.Context) (config.Config, error) {
+	loaded, err := config.Load(c.String("config"))
+	if err != nil {
+		return loaded, err
+	}
+
+	for flag, field := range map[string]*string{
+		"db":             &loaded.DBDSN,
+		"addr":           &loaded.ListenAddr,
+		"templates-dir":  &loaded.TemplatesDir,
+		"static-dir":     &loaded.StaticDir,
+		"session-secret": &loaded.SessionSecret,
+		"log-level":      &loaded.LogLevel,
+	} {
+		if c.IsSet(flag) {
+			*field = c.String(flag)
+		}
+	}
+	// --verbose se mantiene como atajo de --log-level debug
+	if c.Bool("verbose") {
+		loaded.LogLevel = "debug"
+	}
+	return loaded, loaded.Validate()
+}
+
+func setupLogging(c config.Config) {
+	level, _ := c.SlogLevel()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
+
+// credentialsPolicy es la política de nombres de usuario y contraseñas de la
+// configuración.
+func credentialsPolicy(c config.Config) (*credentials.Policy, error) {
+	pattern, err := c.UsernameRegexp()
+	if err != nil {
+		return nil, err
+	}
+	policy := &credentials.Policy{
+		UsernameMinLength: c.UsernameMinLength,
+		UsernameMaxLength: c.UsernameMaxLength,
+		UsernamePattern:   pattern,
+		PasswordMinLength: c.PasswordMinLength,
+		BcryptCost:        c.BcryptCost,
+	}
+	if c.BreachedPasswordsFile != "" {
+		if policy.Breached, err = credentials.OpenBreachedList(c.BreachedPasswordsFile); err != nil {
+			return nil, fmt.Errorf("lista de contraseñas filtradas: %w", err)
+		}
+	}
+	return policy, nil
+}
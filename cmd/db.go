@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	_ "modernc.org/sqlite"
+)
+
+// openDB abre la base de datos SQLite indicada por dsn. Una base de datos
+// nueva se crea con el esquema al día; en una que ya existe, las migraciones
+// pendientes solo se aplican con auto_migrate y si no el comando falla y pide
+// ejecutar migrate up, para no deshacer a escondidas un migrate down.
+func openDB(ctx context.Context, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func checkSchema(ctx context.Context, db *sql.DB) error {
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	initialized, err := m.Initialized(ctx)
+	if err != nil {
+		return err
+	}
+	if !initialized || cfg.AutoMigrate {
+		if _, err := m.Up(ctx); err != nil {
+			return fmt.Errorf("aplicando migraciones: %w", err)
+		}
+		return nil
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("comprobando migraciones: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("el esquema de la base de datos no está al día (%d migraciones pendientes desde %04d_%s): ejecuta todo migrate up",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
@@ -1,119 +1,60 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
-
-	"github.com/JorgeePG/todo-list/internal/handlers"
-	"github.com/JorgeePG/todo-list/internal/midleware"
-	"github.com/gorilla/mux"
-	"github.com/gorilla/sessions"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/JorgeePG/todo-list/internal/taskquery"
 	"github.com/urfave/cli/v2"
-	_ "modernc.org/sqlite"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
 )
 
-var templates *template.Template
-
-func StartServer() {
-	db, err := sql.Open("sqlite", "../todo.db")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Migraciones básicas
-	db.Exec(`CREATE TABLE IF NOT EXISTS tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT,
-		done BOOLEAN,
-		user_id INTEGER
-	)`)
-	db.Exec(`CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		username TEXT NOT NULL UNIQUE,
-		password_hash TEXT NOT NULL
-	)`)
-
-	templates = template.Must(template.ParseGlob("../web_templates/*.html"))
-	templates = template.Must(templates.ParseGlob("../web_templates/fragments/*.html"))
-
-	store := sessions.NewCookieStore([]byte("super-secret-key"))
-	midleware.Store = store
-
-	r := mux.NewRouter()
-	r.Use(midleware.CspControl)
-
-	// Archivos estáticos
-	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("../web_templates/static/"))))
-
-	h := &handlers.WebHandler{
-		Db:        db,
-		Templates: templates,
-		Store:     store,
-	}
-
-	// Web: Rutas públicas
-	r.HandleFunc("/register", h.RegisterHandler)
-	r.HandleFunc("/login", h.LoginHandler)
-	r.HandleFunc("/logout", h.LogoutHandler)
-
-	// Web: Rutas protegidas
-	web := r.PathPrefix("/").Subrouter()
-	web.Use(midleware.RequireLogin)
-
-	web.HandleFunc("/", h.Handler)
-	web.HandleFunc("/addTask", h.AddTask).Methods("GET", "POST")
-	web.HandleFunc("/delete", h.DeleteTask)
-	web.HandleFunc("/update", h.UpdateTask).Methods("GET", "POST")
-
-	// API: Subrouter separado
-	api := r.PathPrefix("/api").Subrouter()
-
-	apiHandler := &handlers.WebHandler{
-		Db:        db,
-		Templates: templates,
-		Store:     store,
-	}
-
-	// Rutas API (JSON)
-	api.HandleFunc("/register", apiHandler.ApiRegisterHandler).Methods("POST")
-	api.HandleFunc("/login", apiHandler.ApiLoginHandler).Methods("POST")
-	api.HandleFunc("/logout", apiHandler.ApiLogoutHandler).Methods("GET")
-	api.HandleFunc("/tasks", apiHandler.ApiListTasks).Methods("GET")
-	api.HandleFunc("/tasks", apiHandler.ApiAddTask).Methods("POST")
-	api.HandleFunc("/tasks/{id:[0-9]+}", apiHandler.ApiUpdateTask).Methods("PUT")
-	api.HandleFunc("/tasks/{id:[0-9]+}", apiHandler.ApiDeleteTask).Methods("DELETE")
-
-	log.Println("Servidor iniciado en :8080")
-	http.ListenAndServe(":8080", r)
-}
-
 func main() {
-	var verbose bool
-
 	app := &cli.App{
 		Name:  "todo",
 		Usage: "Gestor de tareas desde CLI y Web",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
-				Name:        "verbose",
-				Usage:       "Muestra logs detallados",
-				Destination: &verbose,
+				Name:  "verbose",
+				Usage: "Muestra logs detallados (equivale a --log-level debug)",
 			},
+		}, configFlags()...),
+		Before: func(c *cli.Context) error {
+			var err error
+			cfg, err = loadConfig(c)
+			if err != nil {
+				return err
+			}
+			setupLogging(cfg)
+			return nil
 		},
 		Commands: []*cli.Command{
 			{
 				Name:  "serve",
 				Usage: "Inicia el servidor web",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dev",
+						Usage: "Modo desarrollo: permite arrancar con la clave de sesión por defecto",
+					},
+				},
 				Action: func(c *cli.Context) error {
-					if verbose {
-						log.Println("[VERBOSE] Iniciando servidor web...")
+					if err := checkSessionSecret(cfg, c.Bool("dev")); err != nil {
+						return err
 					}
-					StartServer()
+					slog.Debug("Iniciando servidor web...")
+					StartServer(cfg)
 					return nil
 				},
 			},
@@ -135,80 +76,97 @@ func main() {
 						Name:  "pending-only",
 						Usage: "Mostrar solo tareas pendientes",
 					},
+					&cli.BoolFlag{
+						Name:  "overdue",
+						Usage: "Mostrar solo tareas pendientes ya vencidas",
+					},
+					&cli.StringFlag{
+						Name:  "due-before",
+						Usage: "Mostrar solo tareas que vencen antes de la fecha (AAAA-MM-DD[THH:MM])",
+					},
+					&cli.StringSliceFlag{
+						Name:  "tag",
+						Usage: "Mostrar solo tareas con la etiqueta; -etiqueta las excluye (se puede repetir)",
+					},
+					&cli.StringFlag{
+						Name:  "project",
+						Usage: "Mostrar solo tareas del proyecto con ese nombre; \"none\" las que no tienen",
+					},
 					&cli.StringFlag{
 						Name:  "sort",
-						Usage: "Ordenar por: id|title|status",
-						Value: "id",
+						Usage: "Ordenar por: " + strings.Join(taskquery.SortFields, "|"),
+						Value: "priority",
+					},
+					&cli.StringFlag{
+						Name:  "order",
+						Usage: "Sentido del orden: asc|desc",
+						Value: "asc",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "Mostrar como mucho ese número de tareas",
 					},
 				},
 				Action: func(c *cli.Context) error {
-					if verbose {
-						log.Println("[VERBOSE] Conectando a la base de datos para listar tareas...")
+					// Si ambos están activados, esto sería un error lógico
+					if c.Bool("done-only") && c.Bool("pending-only") {
+						return fmt.Errorf("error: no puedes usar --done-only y --pending-only al mismo tiempo")
 					}
 
-					// Construir la consulta SQL con filtros
-					query := "SELECT id, title, done FROM tasks"
-
-					// Aplicar filtros
-					var whereConditions []string
+					// Los mismos filtros que la web y la API, para todos los usuarios
+					var f taskquery.Filter
 					if c.Bool("done-only") {
-						whereConditions = append(whereConditions, "done = 1")
+						f.Done = null.BoolFrom(true)
 					}
 					if c.Bool("pending-only") {
-						whereConditions = append(whereConditions, "done = 0")
+						f.Done = null.BoolFrom(false)
 					}
-
-					// Si ambos están activados, esto sería un error lógico
-					if c.Bool("done-only") && c.Bool("pending-only") {
-						return fmt.Errorf("error: no puedes usar --done-only y --pending-only al mismo tiempo")
+					if c.Bool("overdue") {
+						f.Done = null.BoolFrom(false)
+						f.DueBefore = null.TimeFrom(time.Now())
 					}
-
-					// Agregar las condiciones WHERE si existen
-					if len(whereConditions) > 0 {
-						query += " WHERE " + whereConditions[0]
+					if c.IsSet("due-before") {
+						before, err := due.Parse(c.String("due-before"), time.Local)
+						if err != nil {
+							return err
+						}
+						if !f.DueBefore.Valid || before.Time.Before(f.DueBefore.Time) {
+							f.DueBefore = before
+						}
 					}
-
-					// Aplicar orden
-					sortColumn := c.String("sort")
-					switch sortColumn {
-					case "id":
-						query += " ORDER BY id ASC"
-					case "title":
-						query += " ORDER BY title ASC"
-					case "status":
-						query += " ORDER BY done ASC"
-					default:
-						// Valor predeterminado en caso de valor inválido
-						query += " ORDER BY id ASC"
+					var err error
+					if f.Tags, err = taskquery.ParseTagFilter(c.StringSlice("tag")); err != nil {
+						return err
 					}
-
-					if verbose {
-						log.Printf("[VERBOSE] Ejecutando consulta: %s\n", query)
+					if c.IsSet("project") {
+						if name := c.String("project"); name == project.None {
+							f.Project = taskquery.ProjectFilter{Active: true}
+						} else {
+							f.Extra = append(f.Extra, qm.Where("tasks.project_id IN (SELECT id FROM projects WHERE name = ?)", strings.TrimSpace(name)))
+						}
+					}
+					if f.Sort, err = taskquery.ParseSort(c.String("sort"), c.String("order")); err != nil {
+						return err
+					}
+					if c.Int("limit") < 0 {
+						return fmt.Errorf("--limit no puede ser negativo")
 					}
+					f.Limit = c.Int("limit")
 
-					db, err := sql.Open("sqlite", "../todo.db")
+					slog.Debug("Conectando a la base de datos para listar tareas...")
+					db, err := openDB(c.Context, cfg.DBDSN)
 					if err != nil {
 						return err
 					}
 					defer db.Close()
 
-					rows, err := db.Query(query)
+					dbTasks, err := models.Tasks(append(f.Mods(), taskquery.WithTags(), qm.Load(models.TaskRels.Project), taskquery.WithSubtasks())...).All(c.Context, db)
 					if err != nil {
 						return err
 					}
-					defer rows.Close()
-
-					type Task struct {
-						ID    int    `json:"id"`
-						Title string `json:"title"`
-						Done  bool   `json:"done"`
-					}
-					var tasks []Task
-
-					for rows.Next() {
-						var t Task
-						rows.Scan(&t.ID, &t.Title, &t.Done)
-						tasks = append(tasks, t)
+					var tasks []cliTask
+					for _, t := range dbTasks {
+						tasks = append(tasks, toCLITask(t))
 					}
 
 					output := c.String("output")
@@ -217,80 +175,72 @@ func main() {
 						importjson, _ := json.MarshalIndent(tasks, "", "  ")
 						fmt.Println(string(importjson))
 					default:
-						for _, t := range tasks {
+						describe := func(t cliTask) string {
+							title := t.Title
+							if t.Progress != nil {
+								title += " (" + t.Progress.String() + ")"
+							}
 							status := "Pendiente"
 							if t.Done {
 								status = "Hecha"
 							}
-							fmt.Printf("[%d] %s - %s\n", t.ID, t.Title, status)
+							if t.DueAt != nil {
+								status += " (" + due.Label(null.TimeFromPtr(t.DueAt), time.Now()) + ")"
+							}
+							if t.Priority != priority.Names[priority.None] {
+								status += " [" + t.Priority + "]"
+							}
+							for _, name := range t.Tags {
+								status += " #" + name
+							}
+							if t.Project != "" {
+								status += " @" + t.Project
+							}
+							if t.Recurrence != "" {
+								status += " ↻ " + recurrence.Label(null.StringFrom(t.Recurrence), time.Local)
+							}
+							return fmt.Sprintf("[%d] %s - %s", t.ID, title, status)
+						}
+
+						// Las subtareas se muestran sangradas bajo su tarea si
+						// esta aparece en el listado
+						listed := map[int64]bool{}
+						for _, t := range tasks {
+							listed[t.ID] = true
+						}
+						children := map[int64][]cliTask{}
+						var roots []cliTask
+						for _, t := range tasks {
+							if t.ParentID != 0 && listed[t.ParentID] {
+								children[t.ParentID] = append(children[t.ParentID], t)
+							} else {
+								roots = append(roots, t)
+							}
+						}
+						for _, t := range roots {
+							fmt.Println(describe(t))
+							for _, child := range children[t.ID] {
+								fmt.Println("    └ " + describe(child))
+							}
 						}
 					}
-					if verbose {
-						log.Printf("[VERBOSE] %d tareas listadas.\n", len(tasks))
-					}
+					slog.Debug("Tareas listadas", "count", len(tasks))
 					return nil
 				},
 			},
-			{
-				Name: "add",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:    "title",
-						Aliases: []string{"t"},
-						Usage:   "Título de la tarea",
-					},
-					&cli.StringFlag{
-						Name:  "finish",
-						Usage: "Tarea acabada (true/false)",
-					},
-				},
-				Action: func(c *cli.Context) error {
-					title := c.String("title")
-					finish := c.String("finish")
-					fmt.Println("Título:", title, "Tarea acabada:", finish)
-					return nil
-				},
-			},
-			{
-				Name: "user",
-				Subcommands: []*cli.Command{
-					{
-						Name: "add",
-						Flags: []cli.Flag{
-							&cli.StringFlag{
-								Name:    "nombre",
-								Aliases: []string{"t"},
-								Usage:   "Nombre del usuario",
-							},
-							&cli.StringFlag{
-								Name:  "atareado",
-								Usage: "Tareas pendientes (true/false)",
-							},
-						},
-						Action: func(c *cli.Context) error {
-							nombre := c.String("nombre")
-							atareado := c.String("atareado")
-							fmt.Println("Nombre:", nombre, "Tareas pendientes:", atareado)
-							return nil
-						},
-					},
-					{
-						Name: "delete",
-						Flags: []cli.Flag{
-							&cli.StringFlag{
-								Name:    "nombre",
-								Aliases: []string{"t"},
-								Usage:   "Nombre del usuario",
-							},
-						},
-						Action: func(c *cli.Context) error {
-							nombre := c.String("nombre")
-							fmt.Println("El usuario \"", nombre, "\" ha sido eliminado")
-							return nil
-						},
-					},
-				},
-			},
+			addCommand(),
+			setDoneCommand("done", "Marca tareas como hechas", true),
+			setDoneCommand("undo", "Marca tareas como pendientes", false),
+			editCommand(),
+			rmCommand(),
+			showCommand(),
+			tagCommand(),
+			projectCommand(),
+			repeatCommand(),
+			searchCommand(),
+			migrateCommand(),
+			userCommand(),
+			tokenCommand(),
 		},
 	}
 
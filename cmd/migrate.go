@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/urfave/cli/v2"
+)
+
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Gestiona las migraciones del esquema de la base de datos",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "Aplica todas las migraciones pendientes",
+				Action: func(c *cli.Context) error {
+					return withMigrator(func(m *migrations.Migrator) error {
+						applied, err := m.Up(c.Context)
+						for _, mig := range applied {
+							fmt.Printf("Aplicada %04d_%s\n", mig.Version, mig.Name)
+						}
+						if err != nil {
+							return err
+						}
+						if len(applied) == 0 {
+							fmt.Println("No hay migraciones pendientes")
+						}
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "down",
+				Usage: "Revierte las últimas migraciones aplicadas (siguen revertidas hasta el siguiente migrate up)",
+				Description: "Los demás comandos no vuelven a aplicar las migraciones revertidas: fallan\n" +
+					"hasta que se ejecute todo migrate up, salvo con auto_migrate (TODO_AUTO_MIGRATE),\n" +
+					"que las aplica al abrir la base de datos.",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "steps",
+						Usage: "Número de migraciones a revertir",
+						Value: 1,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return withMigrator(func(m *migrations.Migrator) error {
+						reverted, err := m.Down(c.Context, c.Int("steps"))
+						for _, mig := range reverted {
+							fmt.Printf("Revertida %04d_%s\n", mig.Version, mig.Name)
+						}
+						if err != nil {
+							return err
+						}
+						if len(reverted) == 0 {
+							fmt.Println("No hay migraciones que revertir")
+						}
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "Muestra qué migraciones están aplicadas",
+				Action: func(c *cli.Context) error {
+					return withMigrator(func(m *migrations.Migrator) error {
+						statuses, err := m.Status(c.Context)
+						if err != nil {
+							return err
+						}
+						for _, s := range statuses {
+							state := "pendiente"
+							if s.Applied {
+								state = "aplicada " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+							}
+							if s.Modified {
+								state += " (¡modificada tras aplicarse!)"
+							}
+							fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+						}
+						return nil
+					})
+				},
+			},
+			{
+				Name:      "create",
+				Usage:     "Crea una nueva pareja de ficheros de migración",
+				ArgsUsage: "<nombre>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Usage: "Directorio de las migraciones",
+						Value: "internal/migrations/sql",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("uso: todo migrate create <nombre>")
+					}
+					up, down, err := migrations.Create(c.String("dir"), c.Args().First())
+					if err != nil {
+						return err
+					}
+					fmt.Println("Creada", up)
+					fmt.Println("Creada", down)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func withMigrator(fn func(m *migrations.Migrator) error) error {
+	// Sin openDB: aquí las migraciones se gestionan a mano
+	db, err := sql.Open("sqlite", cfg.DBDSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
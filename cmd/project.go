@@ -0,0 +1,259 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/urfave/cli/v2"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// projectFlag es el flag --project de add.
+var projectFlag = &cli.StringFlag{
+	Name:  "project",
+	Usage: "Nombre del proyecto de la tarea",
+}
+
+// cliProject es la representación de un proyecto en la salida JSON de la CLI.
+type cliProject struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	project.Counts
+}
+
+// resolveProject devuelve el project_id del proyecto name del usuario; vacío o
+// "none" significa sin proyecto.
+func resolveProject(c *cli.Context, exec boil.ContextExecutor, userID int64, name string) (null.Int64, error) {
+	if name == "" || name == project.None {
+		return null.Int64{}, nil
+	}
+	p, err := project.Find(c.Context, exec, userID, name)
+	if err != nil {
+		return null.Int64{}, err
+	}
+	return p.ID, nil
+}
+
+// withUserProject abre la base de datos, resuelve el usuario y el proyecto del
+// primer argumento y ejecuta fn en una transacción.
+func withUserProject(c *cli.Context, fn func(tx *sql.Tx, user *models.User, p *models.Project) error) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("uso: todo project %s %s", c.Command.Name, c.Command.ArgsUsage)
+	}
+
+	db, err := openDB(c.Context, cfg.DBDSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := currentUser(c, db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(c.Context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p, err := project.Find(c.Context, tx, user.ID.Int64, c.Args().First())
+	if err != nil {
+		return err
+	}
+	if err := fn(tx, user, p); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func projectCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "project",
+		Usage: "Gestiona los proyectos del usuario",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Crea un proyecto",
+				ArgsUsage: "<nombre>",
+				Flags:     []cli.Flag{userFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("uso: todo project add <nombre>")
+					}
+					name, err := project.Normalize(c.Args().First())
+					if err != nil {
+						return err
+					}
+
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := currentUser(c, db)
+					if err != nil {
+						return err
+					}
+					if _, err := project.Find(c.Context, db, user.ID.Int64, name); err == nil {
+						return fmt.Errorf("el proyecto %q ya existe", name)
+					}
+					p := &models.Project{UserID: user.ID.Int64, Name: name}
+					if err := p.Insert(c.Context, db, boil.Infer()); err != nil {
+						return fmt.Errorf("error creando proyecto: %w", err)
+					}
+					fmt.Printf("Proyecto %q creado con ID %d\n", p.Name, p.ID.Int64)
+					return nil
+				},
+			},
+			{
+				Name:      "rename",
+				Usage:     "Cambia el nombre de un proyecto",
+				ArgsUsage: "<nombre> <nuevo nombre>",
+				Flags:     []cli.Flag{userFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return errors.New("uso: todo project rename <nombre> <nuevo nombre>")
+					}
+					name, err := project.Normalize(c.Args().Get(1))
+					if err != nil {
+						return err
+					}
+					return withUserProject(c, func(tx *sql.Tx, user *models.User, p *models.Project) error {
+						if _, err := project.Find(c.Context, tx, user.ID.Int64, name); err == nil {
+							return fmt.Errorf("el proyecto %q ya existe", name)
+						}
+						old := p.Name
+						p.Name = name
+						if _, err := p.Update(c.Context, tx, boil.Whitelist(models.ProjectColumns.Name)); err != nil {
+							return fmt.Errorf("error actualizando proyecto: %w", err)
+						}
+						fmt.Printf("Proyecto %q renombrado a %q\n", old, p.Name)
+						return nil
+					})
+				},
+			},
+			{
+				Name:      "rm",
+				Usage:     "Elimina un proyecto; sus tareas quedan sin proyecto",
+				ArgsUsage: "<nombre>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "No pedir confirmación",
+					},
+					userFlag,
+				},
+				Action: func(c *cli.Context) error {
+					return withUserProject(c, func(tx *sql.Tx, user *models.User, p *models.Project) error {
+						count, err := p.Tasks().Count(c.Context, tx)
+						if err != nil {
+							return err
+						}
+						question := fmt.Sprintf("¿Eliminar el proyecto %q? Sus %d tarea(s) quedarán sin proyecto", p.Name, count)
+						if !c.Bool("force") && !confirm(c, question) {
+							return errors.New("operación cancelada")
+						}
+						if _, err := p.Delete(c.Context, tx); err != nil {
+							return fmt.Errorf("error eliminando proyecto: %w", err)
+						}
+						fmt.Printf("Proyecto %q eliminado\n", p.Name)
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "Lista los proyectos del usuario con sus tareas pendientes y totales",
+				Flags: []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := currentUser(c, db)
+					if err != nil {
+						return err
+					}
+					projects, err := models.Projects(
+						models.ProjectWhere.UserID.EQ(user.ID.Int64),
+						qm.OrderBy(models.ProjectColumns.Name),
+					).All(c.Context, db)
+					if err != nil {
+						return err
+					}
+					counts, err := project.TaskCounts(c.Context, db, user.ID.Int64)
+					if err != nil {
+						return err
+					}
+
+					out := make([]cliProject, 0, len(projects))
+					for _, p := range projects {
+						out = append(out, cliProject{ID: p.ID.Int64, Name: p.Name, Counts: counts[p.ID.Int64]})
+					}
+					if c.String("output") == "json" {
+						return printJSON(out)
+					}
+					for _, p := range out {
+						fmt.Printf("[%d] %s - %d pendiente(s) de %d\n", p.ID, p.Name, p.Pending, p.Tasks)
+					}
+					none := counts[0]
+					fmt.Printf("Sin proyecto - %d pendiente(s) de %d\n", none.Pending, none.Tasks)
+					return nil
+				},
+			},
+			{
+				Name:      "move",
+				Usage:     "Mueve tareas a otro proyecto",
+				ArgsUsage: "<id...>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "to",
+						Usage:    "Proyecto de destino; \"none\" las deja sin proyecto",
+						Required: true,
+					},
+					userFlag,
+					outputFlag,
+				},
+				Action: func(c *cli.Context) error {
+					var name string
+					tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+						projectID, err := resolveProject(c, tx, tasks[0].UserID.Int64, c.String("to"))
+						if err != nil {
+							return err
+						}
+						for _, task := range tasks {
+							task.ProjectID = projectID
+							if _, err := task.Update(c.Context, tx, boil.Whitelist(models.TaskColumns.ProjectID)); err != nil {
+								return fmt.Errorf("error actualizando tarea %d: %w", task.ID.Int64, err)
+							}
+							if err := subtask.SyncProject(c.Context, tx, task); err != nil {
+								return fmt.Errorf("error actualizando subtareas de %d: %w", task.ID.Int64, err)
+							}
+						}
+						name = "Sin proyecto"
+						if projectID.Valid {
+							name = "Proyecto: " + c.String("to")
+						}
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+					return printTasks(c, tasks, name)
+				},
+			},
+		},
+	}
+}
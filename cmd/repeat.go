@@ -0,0 +1,68 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/urfave/cli/v2"
+)
+
+func repeatCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "repeat",
+		Usage: "Gestiona las series de tareas que se repiten",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "skip",
+				Usage:     "Salta la repetición actual: la tarea pasa a la fecha de la siguiente",
+				ArgsUsage: "<id...>",
+				Flags:     []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					now := time.Now()
+					tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+						for _, task := range tasks {
+							if err := recurrence.Skip(c.Context, tx, task, now); err != nil {
+								return fmt.Errorf("tarea %d: %w", task.ID.Int64, err)
+							}
+						}
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+					if c.String("output") == "json" {
+						return printTasks(c, tasks, "")
+					}
+					for _, t := range tasks {
+						fmt.Printf("[%d] %s - Saltada, %s\n", t.ID.Int64, t.Title, due.Label(t.DueAt, now))
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "stop",
+				Usage:     "Detiene la serie: la tarea se queda pero no se repetirá",
+				ArgsUsage: "<id...>",
+				Flags:     []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+						for _, task := range tasks {
+							if err := recurrence.Stop(c.Context, tx, task); err != nil {
+								return fmt.Errorf("tarea %d: %w", task.ID.Int64, err)
+							}
+						}
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+					return printTasks(c, tasks, "Ya no se repite")
+				},
+			},
+		},
+	}
+}
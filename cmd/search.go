@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/search"
+	"github.com/urfave/cli/v2"
+)
+
+// cliSearchResult es un resultado de search en la salida JSON de la CLI.
+type cliSearchResult struct {
+	cliTask
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+func searchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Busca tareas por su título y sus notas",
+		ArgsUsage: "<texto...>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: fmt.Sprintf("Número máximo de resultados (1-%d)", search.MaxLimit),
+				Value: search.DefaultLimit,
+			},
+			userFlag,
+			outputFlag,
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() == 0 {
+				return errors.New("uso: todo search [--limit <n>] <texto...>")
+			}
+			if c.Int("limit") < 1 || c.Int("limit") > search.MaxLimit {
+				return fmt.Errorf("--limit debe estar entre 1 y %d", search.MaxLimit)
+			}
+
+			db, err := openDB(c.Context, cfg.DBDSN)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			user, err := currentUser(c, db)
+			if err != nil {
+				return err
+			}
+			results, err := search.Tasks(c.Context, db, user.ID.Int64, strings.Join(c.Args().Slice(), " "), c.Int("limit"))
+			if err != nil {
+				return err
+			}
+
+			if c.String("output") == "json" {
+				out := make([]cliSearchResult, 0, len(results))
+				for _, r := range results {
+					out = append(out, cliSearchResult{cliTask: toCLITask(&r.Task), Snippet: r.Highlight("[", "]"), Rank: r.Rank})
+				}
+				return printJSON(out)
+			}
+			if len(results) == 0 {
+				fmt.Println("No hay tareas que coincidan")
+				return nil
+			}
+			for _, r := range results {
+				status := "Pendiente"
+				if r.Done.Bool {
+					status = "Hecha"
+				}
+				fmt.Printf("[%d] %s - %s\n", r.ID.Int64, r.Title, status)
+				fmt.Printf("    %s\n", strings.ReplaceAll(r.Highlight("[", "]"), "\n", " "))
+			}
+			return nil
+		},
+	}
+}
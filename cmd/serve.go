@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+
+	"github.com/JorgeePG/todo-list/internal/config"
+	"github.com/JorgeePG/todo-list/internal/handlers"
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/JorgeePG/todo-list/internal/midleware"
+	"github.com/JorgeePG/todo-list/internal/sso"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+)
+
+var templates *template.Template
+
+// checkSessionSecret impide arrancar el servidor con la clave de sesión por
+// defecto, que es pública y permite falsificar cookies, salvo en modo
+// desarrollo.
+func checkSessionSecret(cfg config.Config, dev bool) error {
+	if cfg.SessionSecret == config.DefaultSessionSecret && !dev {
+		return errors.New("la clave de sesión es la de por defecto: configura session_secret (TODO_SESSION_SECRET) o usa serve --dev en desarrollo")
+	}
+	return nil
+}
+
+func StartServer(cfg config.Config) {
+	db, err := openDB(context.Background(), cfg.DBDSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	templates = template.Must(template.ParseGlob(filepath.Join(cfg.TemplatesDir, "*.html")))
+	templates = template.Must(templates.ParseGlob(filepath.Join(cfg.TemplatesDir, "fragments", "*.html")))
+
+	if cfg.SessionSecret == config.DefaultSessionSecret {
+		slog.Warn("Modo desarrollo con la clave de sesión por defecto; no lo uses en producción")
+	}
+	store := sessions.NewCookieStore([]byte(cfg.SessionSecret))
+	midleware.Store = store
+
+	r := mux.NewRouter()
+	r.Use(midleware.CspControl)
+
+	// Archivos estáticos
+	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir(cfg.StaticDir))))
+
+	lockoutBase, lockoutMax, err := cfg.LoginLockouts()
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy := &lockout.Policy{
+		MaxFailures:   cfg.LoginMaxFailures,
+		MaxFailuresIP: cfg.LoginMaxFailuresIP,
+		Lockout:       lockoutBase,
+		MaxLockout:    lockoutMax,
+	}
+	creds, err := credentialsPolicy(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	h := &handlers.WebHandler{
+		Db:          db,
+		Templates:   templates,
+		Store:       store,
+		Lockout:     policy,
+		TrustProxy:  cfg.LoginTrustProxy,
+		Credentials: creds,
+	}
+	if cfg.OIDCIssuer != "" {
+		h.SSO, err = sso.New(context.Background(), sso.Config{
+			Issuer:        cfg.OIDCIssuer,
+			ClientID:      cfg.OIDCClientID,
+			ClientSecret:  cfg.OIDCClientSecret,
+			RedirectURL:   cfg.OIDCRedirectURL,
+			Name:          cfg.OIDCName,
+			AutoProvision: cfg.OIDCAutoProvision,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("Login OIDC activado", "issuer", cfg.OIDCIssuer)
+	}
+
+	// Web: Rutas públicas
+	r.HandleFunc("/register", h.RegisterHandler)
+	r.HandleFunc("/login", h.LoginHandler)
+	r.HandleFunc("/logout", h.LogoutHandler)
+	r.HandleFunc("/login/2fa", h.LoginTwoFactor).Methods("GET", "POST")
+	r.HandleFunc("/login/oidc", h.SSOLogin).Methods("GET")
+	r.HandleFunc("/login/oidc/callback", h.SSOCallback).Methods("GET")
+
+	// Web: Rutas protegidas
+	web := r.PathPrefix("/").Subrouter()
+	web.Use(midleware.RequireLogin)
+
+	web.HandleFunc("/", h.Handler)
+	web.HandleFunc("/addTask", h.AddTask).Methods("GET", "POST")
+	web.HandleFunc("/delete", h.DeleteTask)
+	web.HandleFunc("/update", h.UpdateTask).Methods("GET", "POST")
+	web.HandleFunc("/skip", h.SkipTask).Methods("POST")
+	web.HandleFunc("/tasks/{id:[0-9]+}", h.TaskPage).Methods("GET")
+	web.HandleFunc("/tasks/{id:[0-9]+}", h.UpdateTaskNotes).Methods("POST")
+	web.HandleFunc("/projects", h.AddProject).Methods("POST")
+	web.HandleFunc("/search", h.SearchPage).Methods("GET")
+	web.HandleFunc("/settings", h.SettingsPage).Methods("GET")
+	web.HandleFunc("/settings/tokens", h.CreateToken).Methods("POST")
+	web.HandleFunc("/settings/tokens/{id:[0-9]+}/revoke", h.RevokeToken).Methods("POST")
+	web.HandleFunc("/settings/2fa", h.EnrollTwoFactor).Methods("POST")
+	web.HandleFunc("/settings/2fa/qr.png", h.TwoFactorQR).Methods("GET")
+	web.HandleFunc("/settings/2fa/confirm", h.ConfirmTwoFactor).Methods("POST")
+	web.HandleFunc("/settings/2fa/disable", h.DisableTwoFactor).Methods("POST")
+
+	// API: Subrouter separado
+	api := r.PathPrefix("/api").Subrouter()
+
+	apiHandler := &handlers.WebHandler{
+		Db:          db,
+		Templates:   templates,
+		Store:       store,
+		Lockout:     policy,
+		TrustProxy:  cfg.LoginTrustProxy,
+		Credentials: creds,
+	}
+	// Las peticiones con Authorization: Bearer se autentican con un token
+	// personal; las demás, con la sesión.
+	api.Use(apiHandler.TokenAuth)
+
+	// Rutas API (JSON)
+	api.HandleFunc("/register", apiHandler.ApiRegisterHandler).Methods("POST")
+	api.HandleFunc("/login", apiHandler.ApiLoginHandler).Methods("POST")
+	api.HandleFunc("/login/2fa", apiHandler.ApiLoginTwoFactor).Methods("POST")
+	api.HandleFunc("/logout", apiHandler.ApiLogoutHandler).Methods("GET")
+	api.HandleFunc("/tasks", apiHandler.ApiListTasks).Methods("GET")
+	api.HandleFunc("/tasks", apiHandler.ApiAddTask).Methods("POST")
+	api.HandleFunc("/tasks/search", apiHandler.ApiSearchTasks).Methods("GET")
+	api.HandleFunc("/tasks/bulk", apiHandler.ApiBulkTasks).Methods("POST")
+	api.HandleFunc("/tasks/{id:[0-9]+}", apiHandler.ApiGetTask).Methods("GET")
+	api.HandleFunc("/tasks/{id:[0-9]+}", apiHandler.ApiUpdateTask).Methods("PUT")
+	api.HandleFunc("/tasks/{id:[0-9]+}", apiHandler.ApiPatchTask).Methods("PATCH")
+	api.HandleFunc("/tasks/{id:[0-9]+}", apiHandler.ApiDeleteTask).Methods("DELETE")
+	api.HandleFunc("/tasks/{id:[0-9]+}/skip", apiHandler.ApiSkipTask).Methods("POST")
+	api.HandleFunc("/tasks/{id:[0-9]+}/subtasks", apiHandler.ApiListSubtasks).Methods("GET")
+	api.HandleFunc("/tasks/{id:[0-9]+}/subtasks", apiHandler.ApiAddSubtask).Methods("POST")
+	api.HandleFunc("/tasks/{id:[0-9]+}/subtasks/{subtask_id:[0-9]+}", apiHandler.ApiUpdateSubtask).Methods("PUT")
+	api.HandleFunc("/tasks/{id:[0-9]+}/subtasks/{subtask_id:[0-9]+}", apiHandler.ApiDeleteSubtask).Methods("DELETE")
+	api.HandleFunc("/tags", apiHandler.ApiListTags).Methods("GET")
+	api.HandleFunc("/tags", apiHandler.ApiAddTag).Methods("POST")
+	api.HandleFunc("/tags/{id:[0-9]+}", apiHandler.ApiUpdateTag).Methods("PUT")
+	api.HandleFunc("/tags/{id:[0-9]+}", apiHandler.ApiDeleteTag).Methods("DELETE")
+	api.HandleFunc("/projects", apiHandler.ApiListProjects).Methods("GET")
+	api.HandleFunc("/projects", apiHandler.ApiAddProject).Methods("POST")
+	api.HandleFunc("/projects/{id:[0-9]+}", apiHandler.ApiGetProject).Methods("GET")
+	api.HandleFunc("/projects/{id:[0-9]+}", apiHandler.ApiUpdateProject).Methods("PUT")
+	api.HandleFunc("/projects/{id:[0-9]+}", apiHandler.ApiDeleteProject).Methods("DELETE")
+	api.HandleFunc("/projects/{id:[0-9]+}/tasks", apiHandler.ApiListProjectTasks).Methods("GET")
+	api.HandleFunc("/tokens", apiHandler.ApiListTokens).Methods("GET")
+	api.HandleFunc("/tokens", apiHandler.ApiCreateToken).Methods("POST")
+	api.HandleFunc("/tokens/{id:[0-9]+}", apiHandler.ApiRevokeToken).Methods("DELETE")
+
+	slog.Info("Servidor iniciado", "addr", cfg.ListenAddr)
+	log.Fatal(http.ListenAndServe(cfg.ListenAddr, r))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/urfave/cli/v2"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// tagFlag es el flag --tag de add, que puede repetirse.
+var tagFlag = &cli.StringSliceFlag{
+	Name:  "tag",
+	Usage: "Etiqueta de la tarea (se puede repetir)",
+}
+
+// parseTagNames normaliza los nombres de etiqueta de values quitando los
+// repetidos.
+func parseTagNames(values []string) ([]string, error) {
+	return tag.ParseList(strings.Join(values, ","))
+}
+
+// cliTag es la representación de una etiqueta en la salida JSON de la CLI.
+type cliTag struct {
+	Name  string `json:"name"`
+	Tasks int64  `json:"tasks"`
+}
+
+func tagCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tag",
+		Usage: "Gestiona las etiquetas de las tareas",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Añade etiquetas a una tarea",
+				ArgsUsage: "<id> <etiqueta...>",
+				Flags:     []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					return withTaskTags(c, func(tx *sql.Tx, task *models.Task, names []string) error {
+						current := map[string]bool{}
+						for _, name := range tag.Names(task.R.GetTags()) {
+							current[name] = true
+						}
+						var missing []string
+						for _, name := range names {
+							if !current[name] {
+								missing = append(missing, name)
+							}
+						}
+						tags, err := tag.Ensure(c.Context, tx, task.UserID.Int64, missing)
+						if err != nil {
+							return err
+						}
+						return task.AddTags(c.Context, tx, false, tags...)
+					})
+				},
+			},
+			{
+				Name:      "rm",
+				Usage:     "Quita etiquetas de una tarea",
+				ArgsUsage: "<id> <etiqueta...>",
+				Flags:     []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					return withTaskTags(c, func(tx *sql.Tx, task *models.Task, names []string) error {
+						var remove models.TagSlice
+						for _, name := range names {
+							found := false
+							for _, t := range task.R.GetTags() {
+								if t.Name == name {
+									remove = append(remove, t)
+									found = true
+								}
+							}
+							if !found {
+								return fmt.Errorf("la tarea %d no tiene la etiqueta %q", task.ID.Int64, name)
+							}
+						}
+						return task.RemoveTags(c.Context, tx, remove...)
+					})
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "Lista las etiquetas del usuario",
+				Flags: []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := currentUser(c, db)
+					if err != nil {
+						return err
+					}
+					tags, err := models.Tags(
+						models.TagWhere.UserID.EQ(user.ID.Int64),
+						qm.OrderBy(models.TagColumns.Name),
+					).All(c.Context, db)
+					if err != nil {
+						return err
+					}
+
+					out := make([]cliTag, 0, len(tags))
+					for _, t := range tags {
+						count, err := t.Tasks().Count(c.Context, db)
+						if err != nil {
+							return err
+						}
+						out = append(out, cliTag{Name: t.Name, Tasks: count})
+					}
+					if c.String("output") == "json" {
+						return printJSON(out)
+					}
+					for _, t := range out {
+						fmt.Printf("%s (%d)\n", t.Name, t.Tasks)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// withTaskTags resuelve la tarea del primer argumento y las etiquetas del
+// resto, y ejecuta fn en una transacción. Después imprime la tarea con sus
+// etiquetas.
+func withTaskTags(c *cli.Context, fn func(tx *sql.Tx, task *models.Task, names []string) error) error {
+	if c.NArg() < 2 {
+		return fmt.Errorf("uso: todo tag %s <id> <etiqueta...>", c.Command.Name)
+	}
+	id, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("ID inválido %q", c.Args().First())
+	}
+	names, err := parseTagNames(c.Args().Tail())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Context, cfg.DBDSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := currentUser(c, db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(c.Context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	task, err := findUserTask(c.Context, tx, user, id)
+	if err != nil {
+		return err
+	}
+	if err := task.L.LoadTags(c.Context, tx, true, task, qm.OrderBy(models.TagColumns.Name)); err != nil {
+		return err
+	}
+	if err := fn(tx, task, names); err != nil {
+		return err
+	}
+	if err := task.L.LoadTags(c.Context, tx, true, task, qm.OrderBy(models.TagColumns.Name)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return printTasks(c, models.TaskSlice{task}, "Etiquetas: "+strings.Join(tag.Names(task.R.GetTags()), ", "))
+}
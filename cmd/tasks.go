@@ -0,0 +1,632 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/JorgeePG/todo-list/internal/tasktitle"
+	"github.com/urfave/cli/v2"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// outputFlag es el flag -o que comparten los comandos con salida text|json.
+var outputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Usage:   "Formato de salida: text|json",
+	Value:   "text",
+}
+
+// cliTask es la representación de una tarea en la salida JSON de la CLI.
+type cliTask struct {
+	ID          int64             `json:"id"`
+	Title       string            `json:"title"`
+	Done        bool              `json:"done"`
+	UserID      int64             `json:"user_id,omitempty"`
+	DueAt       *time.Time        `json:"due_at,omitempty"`
+	Priority    string            `json:"priority"`
+	Tags        []string          `json:"tags,omitempty"`
+	Project     string            `json:"project,omitempty"`
+	ParentID    int64             `json:"parent_id,omitempty"`
+	Progress    *subtask.Progress `json:"progress,omitempty"`
+	Recurrence  string            `json:"recurrence,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+func toCLITask(t *models.Task) cliTask {
+	out := cliTask{
+		ID:          t.ID.Int64,
+		Title:       t.Title,
+		Done:        t.Done.Bool,
+		UserID:      t.UserID.Int64,
+		DueAt:       t.DueAt.Ptr(),
+		Priority:    priority.Name(t.Priority),
+		Tags:        tag.Names(t.R.GetTags()),
+		ParentID:    t.ParentID.Int64,
+		Recurrence:  t.Recurrence.String,
+		Description: t.Description,
+	}
+	if p := t.R.GetProject(); p != nil {
+		out.Project = p.Name
+	}
+	if p := subtask.Of(t.R.GetParentTasks()); p.Total > 0 {
+		out.Progress = &p
+	}
+	return out
+}
+
+// dueFlag es el flag --due de add.
+var dueFlag = &cli.StringFlag{
+	Name:  "due",
+	Usage: "Fecha de vencimiento (AAAA-MM-DD, AAAA-MM-DDTHH:MM o RFC 3339)",
+}
+
+// repeatFlag es el flag --repeat de add y edit.
+var repeatFlag = &cli.StringFlag{
+	Name:  "repeat",
+	Usage: "Regla de repetición: daily|weekly|monthly o RRULE (FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20251231|COUNT=5); en edit, \"none\" la quita",
+}
+
+// descriptionFlag es el flag --description de add y edit.
+var descriptionFlag = &cli.StringFlag{
+	Name:    "description",
+	Aliases: []string{"notes"},
+	Usage:   "Notas de la tarea en Markdown; \"-\" las lee de la entrada estándar",
+}
+
+// readDescription devuelve las notas de --description, leyéndolas de la
+// entrada estándar si el valor es "-".
+func readDescription(c *cli.Context) (string, error) {
+	text := c.String("description")
+	if text == "-" {
+		in, err := io.ReadAll(c.App.Reader)
+		if err != nil {
+			return "", err
+		}
+		text = string(in)
+	}
+	return notes.Normalize(text)
+}
+
+// priorityFlag es el flag --priority de add y edit.
+var priorityFlag = &cli.StringFlag{
+	Name:    "priority",
+	Aliases: []string{"p"},
+	Usage:   "Prioridad: none|low|medium|high|urgent",
+}
+
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func addCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "add",
+		Usage: "Crea una tarea",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "title",
+				Aliases: []string{"t"},
+				Usage:   "Título de la tarea",
+			},
+			&cli.StringFlag{
+				Name:  "finish",
+				Usage: "Tarea acabada (true/false)",
+				Value: "false",
+			},
+			dueFlag,
+			priorityFlag,
+			tagFlag,
+			projectFlag,
+			&cli.Int64Flag{
+				Name:  "parent",
+				Usage: "Crea la tarea como subtarea de la tarea con ese ID",
+			},
+			repeatFlag,
+			descriptionFlag,
+			userFlag,
+			outputFlag,
+		},
+		Action: func(c *cli.Context) error {
+			title, err := tasktitle.Normalize(c.String("title"))
+			if err != nil {
+				return err
+			}
+			finish, err := strconv.ParseBool(c.String("finish"))
+			if err != nil {
+				return fmt.Errorf("valor inválido para --finish %q: usa true o false", c.String("finish"))
+			}
+			dueAt, err := due.Parse(c.String("due"), time.Local)
+			if err != nil {
+				return err
+			}
+			level, err := priority.Parse(c.String("priority"))
+			if err != nil {
+				return err
+			}
+			tagNames, err := parseTagNames(c.StringSlice("tag"))
+			if err != nil {
+				return err
+			}
+			description, err := readDescription(c)
+			if err != nil {
+				return err
+			}
+			if c.IsSet("parent") && c.IsSet("project") {
+				return errors.New("una subtarea va en el proyecto de su tarea: no uses --project con --parent")
+			}
+
+			db, err := openDB(c.Context, cfg.DBDSN)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			user, err := currentUser(c, db)
+			if err != nil {
+				return err
+			}
+
+			projectID, err := resolveProject(c, db, user.ID.Int64, c.String("project"))
+			if err != nil {
+				return err
+			}
+			var parentID null.Int64
+			if c.IsSet("parent") {
+				parent, err := findParentTask(c.Context, db, user, c.Int64("parent"))
+				if err != nil {
+					return err
+				}
+				parentID, projectID = parent.ID, parent.ProjectID
+			}
+
+			task := &models.Task{
+				Title:       title,
+				Done:        null.BoolFrom(finish),
+				UserID:      user.ID,
+				DueAt:       dueAt,
+				Priority:    level,
+				ProjectID:   projectID,
+				ParentID:    parentID,
+				Description: description,
+			}
+			if err := recurrence.Apply(task, c.String("repeat"), time.Local); err != nil {
+				return err
+			}
+			tx, err := db.BeginTx(c.Context, nil)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+			if err := task.Insert(c.Context, tx, boil.Infer()); err != nil {
+				return fmt.Errorf("error insertando tarea: %w", err)
+			}
+			tags, err := tag.Ensure(c.Context, tx, user.ID.Int64, tagNames)
+			if err != nil {
+				return err
+			}
+			if err := task.AddTags(c.Context, tx, false, tags...); err != nil {
+				return fmt.Errorf("error guardando etiquetas: %w", err)
+			}
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+
+			if c.String("output") == "json" {
+				return printJSON(toCLITask(task))
+			}
+			fmt.Printf("Tarea creada con ID %d\n", task.ID.Int64)
+			return nil
+		},
+	}
+}
+
+// parseTaskIDs convierte los argumentos posicionales en IDs de tarea, sin
+// repetidos y en el orden en que aparecen.
+func parseTaskIDs(c *cli.Context) ([]int64, error) {
+	if c.NArg() == 0 {
+		return nil, fmt.Errorf("uso: todo %s <id...>", c.Command.Name)
+	}
+	ids := make([]int64, 0, c.NArg())
+	seen := make(map[int64]bool, c.NArg())
+	for _, arg := range c.Args().Slice() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ID inválido %q", arg)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// findUserTask busca la tarea id comprobando que pertenece a user.
+func findUserTask(ctx context.Context, exec boil.ContextExecutor, user *models.User, id int64) (*models.Task, error) {
+	task, err := models.FindTask(ctx, exec, null.Int64From(id))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if task == nil || task.UserID != user.ID {
+		return nil, fmt.Errorf("la tarea %d no existe o no pertenece a %s", id, user.Username)
+	}
+	return task, nil
+}
+
+// findParentTask busca la tarea id de user a la que se quiere añadir una
+// subtarea.
+func findParentTask(ctx context.Context, exec boil.ContextExecutor, user *models.User, id int64) (*models.Task, error) {
+	parent, err := subtask.FindParent(ctx, exec, user.ID.Int64, id)
+	if errors.Is(err, subtask.ErrNotFound) {
+		return nil, fmt.Errorf("la tarea %d no existe o no pertenece a %s", id, user.Username)
+	}
+	return parent, err
+}
+
+// parentsToComplete devuelve las tareas pendientes de las que cuelgan tasks
+// cuyas subtareas están ya todas hechas.
+func parentsToComplete(ctx context.Context, exec boil.ContextExecutor, tasks models.TaskSlice) (models.TaskSlice, error) {
+	var parents models.TaskSlice
+	seen := map[int64]bool{}
+	for _, t := range tasks {
+		if !t.ParentID.Valid || seen[t.ParentID.Int64] {
+			continue
+		}
+		seen[t.ParentID.Int64] = true
+		suggest, err := subtask.SuggestComplete(ctx, exec, t.ParentID.Int64)
+		if err != nil {
+			return nil, err
+		}
+		if suggest {
+			parent, err := models.FindTask(ctx, exec, t.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, parent)
+		}
+	}
+	return parents, nil
+}
+
+// withUserTasks abre la base de datos, resuelve el usuario y ejecuta fn en una
+// transacción con las tareas indicadas en los argumentos. Si alguna tarea no
+// existe o no es del usuario no se modifica ninguna. Devuelve las tareas tras
+// confirmar la transacción.
+func withUserTasks(c *cli.Context, fn func(tx *sql.Tx, tasks models.TaskSlice) error) (models.TaskSlice, error) {
+	ids, err := parseTaskIDs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDB(c.Context, cfg.DBDSN)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	user, err := currentUser(c, db)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(c.Context, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tasks := make(models.TaskSlice, 0, len(ids))
+	for _, id := range ids {
+		task, err := findUserTask(c.Context, tx, user, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := fn(tx, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, tx.Commit()
+}
+
+func printTasks(c *cli.Context, tasks models.TaskSlice, message string) error {
+	if c.String("output") == "json" {
+		out := make([]cliTask, 0, len(tasks))
+		for _, t := range tasks {
+			out = append(out, toCLITask(t))
+		}
+		return printJSON(out)
+	}
+	for _, t := range tasks {
+		fmt.Printf("[%d] %s - %s\n", t.ID.Int64, t.Title, message)
+	}
+	return nil
+}
+
+func setDoneCommand(name, usage string, done bool) *cli.Command {
+	message := "Pendiente"
+	if done {
+		message = "Hecha"
+	}
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		ArgsUsage: "<id...>",
+		Flags:     []cli.Flag{userFlag, outputFlag},
+		Action: func(c *cli.Context) error {
+			var parents, next models.TaskSlice
+			tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+				for _, task := range tasks {
+					wasDone := task.Done.Bool
+					task.Done = null.BoolFrom(done)
+					if _, err := task.Update(c.Context, tx, boil.Whitelist(models.TaskColumns.Done)); err != nil {
+						return fmt.Errorf("error actualizando tarea %d: %w", task.ID.Int64, err)
+					}
+					if wasDone || !done {
+						continue
+					}
+					spawn, err := recurrence.Complete(c.Context, tx, task, time.Now())
+					if err != nil {
+						return fmt.Errorf("error creando la siguiente repetición de %d: %w", task.ID.Int64, err)
+					}
+					if spawn != nil {
+						next = append(next, spawn)
+					}
+				}
+				if !done {
+					return nil
+				}
+				var err error
+				parents, err = parentsToComplete(c.Context, tx, tasks)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if err := printTasks(c, tasks, message); err != nil {
+				return err
+			}
+			// Los avisos van a la salida de errores para no romper la salida JSON
+			for _, t := range next {
+				fmt.Fprintf(c.App.ErrWriter, "Siguiente repetición: [%d] %s (%s)\n", t.ID.Int64, t.Title, due.Label(t.DueAt, time.Now()))
+			}
+			for _, p := range parents {
+				fmt.Fprintf(c.App.ErrWriter, "Todas las subtareas de [%d] %s están hechas. Complétala con: todo done %d\n", p.ID.Int64, p.Title, p.ID.Int64)
+			}
+			return nil
+		},
+	}
+}
+
+func editCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "edit",
+		Usage:     "Cambia el título, el vencimiento, la prioridad, la repetición o las notas de una tarea",
+		ArgsUsage: "<id>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "title",
+				Aliases: []string{"t"},
+				Usage:   "Nuevo título de la tarea",
+			},
+			&cli.StringFlag{
+				Name:  "due",
+				Usage: "Nueva fecha de vencimiento; \"none\" la elimina",
+			},
+			priorityFlag,
+			repeatFlag,
+			descriptionFlag,
+			userFlag,
+			outputFlag,
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 || (!c.IsSet("title") && !c.IsSet("due") && !c.IsSet("priority") && !c.IsSet("repeat") && !c.IsSet("description")) {
+				return errors.New("uso: todo edit [--title <título>] [--due <fecha>|none] [--priority <prioridad>] [--repeat <regla>|none] [--description <notas>|-] <id>")
+			}
+
+			var columns []string
+			var title string
+			var dueAt null.Time
+			var level int64
+			if c.IsSet("title") {
+				var err error
+				if title, err = tasktitle.Normalize(c.String("title")); err != nil {
+					return err
+				}
+				columns = append(columns, models.TaskColumns.Title)
+			}
+			if c.IsSet("due") {
+				if c.String("due") != "none" {
+					var err error
+					if dueAt, err = due.Parse(c.String("due"), time.Local); err != nil {
+						return err
+					}
+				}
+				columns = append(columns, models.TaskColumns.DueAt)
+			}
+			if c.IsSet("priority") {
+				var err error
+				if level, err = priority.Parse(c.String("priority")); err != nil {
+					return err
+				}
+				columns = append(columns, models.TaskColumns.Priority)
+			}
+			var description string
+			if c.IsSet("description") {
+				var err error
+				if description, err = readDescription(c); err != nil {
+					return err
+				}
+				columns = append(columns, models.TaskColumns.Description)
+			}
+
+			tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+				task := tasks[0]
+				if c.IsSet("title") {
+					task.Title = title
+				}
+				if c.IsSet("due") {
+					task.DueAt = dueAt
+				}
+				if c.IsSet("priority") {
+					task.Priority = level
+				}
+				if c.IsSet("description") {
+					task.Description = description
+				}
+				if c.IsSet("repeat") {
+					if err := recurrence.Apply(task, c.String("repeat"), time.Local); err != nil {
+						return err
+					}
+					columns = append(columns, models.TaskColumns.Recurrence)
+				}
+				if _, err := task.Update(c.Context, tx, boil.Whitelist(columns...)); err != nil {
+					return fmt.Errorf("error actualizando tarea %d: %w", task.ID.Int64, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			return printTasks(c, tasks, "Actualizada")
+		},
+	}
+}
+
+func rmCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Elimina tareas",
+		ArgsUsage: "<id...>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "No pedir confirmación",
+			},
+			userFlag,
+			outputFlag,
+		},
+		Action: func(c *cli.Context) error {
+			tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+				if !c.Bool("force") && !confirm(c, fmt.Sprintf("¿Eliminar %d tarea(s)?", len(tasks))) {
+					return errors.New("operación cancelada")
+				}
+				if _, err := tasks.DeleteAll(c.Context, tx); err != nil {
+					return fmt.Errorf("error eliminando tareas: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			return printTasks(c, tasks, "Eliminada")
+		},
+	}
+}
+
+// confirm pregunta por la entrada estándar y devuelve true solo si la
+// respuesta es afirmativa.
+func confirm(c *cli.Context, question string) bool {
+	fmt.Fprintf(c.App.ErrWriter, "%s [s/N] ", question)
+	answer, _ := bufio.NewReader(c.App.Reader).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "s", "si", "sí", "y", "yes":
+		return true
+	}
+	return false
+}
+
+func showCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Muestra el detalle de una tarea",
+		ArgsUsage: "<id>",
+		Flags:     []cli.Flag{userFlag, outputFlag},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("uso: todo show <id>")
+			}
+			tasks, err := withUserTasks(c, func(tx *sql.Tx, tasks models.TaskSlice) error {
+				if err := tasks[0].L.LoadProject(c.Context, tx, true, tasks[0], nil); err != nil {
+					return err
+				}
+				if err := subtask.Load(c.Context, tx, tasks[0]); err != nil {
+					return err
+				}
+				return tasks[0].L.LoadTags(c.Context, tx, true, tasks[0], qm.OrderBy(models.TagColumns.Name))
+			})
+			if err != nil {
+				return err
+			}
+			task := tasks[0]
+			if c.String("output") == "json" {
+				return printJSON(toCLITask(task))
+			}
+			status := "Pendiente"
+			if task.Done.Bool {
+				status = "Hecha"
+			}
+			fmt.Printf("ID:        %d\n", task.ID.Int64)
+			fmt.Printf("Título:    %s\n", task.Title)
+			fmt.Printf("Estado:    %s\n", status)
+			fmt.Printf("Prioridad: %s\n", priority.Label(task.Priority))
+			if p := task.R.GetProject(); p != nil {
+				fmt.Printf("Proyecto:  %s\n", p.Name)
+			}
+			if names := tag.Names(task.R.GetTags()); len(names) > 0 {
+				fmt.Printf("Etiquetas: %s\n", strings.Join(names, ", "))
+			}
+			if task.DueAt.Valid {
+				fmt.Printf("Vence:     %s (%s)\n", task.DueAt.Time.Local().Format("2006-01-02 15:04"), due.Label(task.DueAt, time.Now()))
+			}
+			if task.Recurrence.Valid {
+				fmt.Printf("Se repite: %s\n", recurrence.Label(task.Recurrence, time.Local))
+			}
+			if task.ParentID.Valid {
+				fmt.Printf("Principal: %d\n", task.ParentID.Int64)
+			}
+			if children := task.R.GetParentTasks(); len(children) > 0 {
+				fmt.Printf("Subtareas: %s\n", subtask.Of(children))
+				for _, child := range children {
+					mark := " "
+					if child.Done.Bool {
+						mark = "x"
+					}
+					fmt.Printf("  [%s] %d %s\n", mark, child.ID.Int64, child.Title)
+				}
+			}
+			if task.Description != "" {
+				fmt.Println("Notas:")
+				for _, line := range strings.Split(task.Description, "\n") {
+					fmt.Printf("  %s\n", line)
+				}
+			}
+			return nil
+		},
+	}
+}
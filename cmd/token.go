@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/apitoken"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// cliToken es la representación de un token personal en la salida JSON de la
+// CLI. Secret solo se incluye al crearlo.
+type cliToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Secret     string     `json:"secret,omitempty"`
+}
+
+func toCLIToken(t *models.APIToken) cliToken {
+	return cliToken{
+		ID:         t.ID.Int64,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     apitoken.ScopeList(t),
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt.Ptr(),
+		LastUsedAt: t.LastUsedAt.Ptr(),
+	}
+}
+
+func tokenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "Gestiona los tokens personales de la API",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Crea un token y muestra su secreto, que no se vuelve a mostrar",
+				Flags: []cli.Flag{
+					userFlag,
+					&cli.StringFlag{
+						Name:     "name",
+						Usage:    "Nombre del token",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:  "scope",
+						Usage: "Alcance del token: " + strings.Join(apitoken.Scopes, " o ") + " (se puede repetir)",
+						Value: cli.NewStringSlice(apitoken.ScopeTasksRead),
+					},
+					&cli.StringFlag{
+						Name:  "expires",
+						Usage: "Caducidad: número de días, como 30d, o fecha AAAA-MM-DD (por defecto no caduca)",
+					},
+					outputFlag,
+				},
+				Action: func(c *cli.Context) error {
+					name, err := apitoken.NormalizeName(c.String("name"))
+					if err != nil {
+						return err
+					}
+					scopes, err := apitoken.ParseScopes(strings.Join(c.StringSlice("scope"), ","))
+					if err != nil {
+						return err
+					}
+					expiresAt, err := apitoken.ParseExpiry(c.String("expires"), time.Local, time.Now())
+					if err != nil {
+						return err
+					}
+
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := currentUser(c, db)
+					if err != nil {
+						return err
+					}
+					t, secret, err := apitoken.Create(c.Context, db, user.ID.Int64, name, scopes, expiresAt)
+					if errors.Is(err, apitoken.ErrNameTaken) {
+						return fmt.Errorf("ya existe un token llamado %q", name)
+					}
+					if err != nil {
+						return fmt.Errorf("error creando token: %w", err)
+					}
+					if c.String("output") == "json" {
+						out := toCLIToken(t)
+						out.Secret = secret
+						return printJSON(out)
+					}
+					fmt.Printf("Token %q creado con ID %d. Cópialo ahora, no se volverá a mostrar:\n", t.Name, t.ID.Int64)
+					fmt.Println(secret)
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "Lista los tokens del usuario",
+				Flags: []cli.Flag{userFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := currentUser(c, db)
+					if err != nil {
+						return err
+					}
+					tokens, err := apitoken.List(c.Context, db, user.ID.Int64)
+					if err != nil {
+						return err
+					}
+
+					out := make([]cliToken, 0, len(tokens))
+					for _, t := range tokens {
+						out = append(out, toCLIToken(t))
+					}
+					if c.String("output") == "json" {
+						return printJSON(out)
+					}
+					if len(out) == 0 {
+						fmt.Println("No hay tokens.")
+						return nil
+					}
+					for _, t := range out {
+						expires := "no caduca"
+						if t.ExpiresAt != nil {
+							expires = "caduca el " + t.ExpiresAt.Local().Format("2006-01-02")
+						}
+						used := "sin usar"
+						if t.LastUsedAt != nil {
+							used = "usado el " + t.LastUsedAt.Local().Format("2006-01-02 15:04")
+						}
+						fmt.Printf("%d. %s (%s…) [%s] %s, %s\n", t.ID, t.Name, t.Prefix, strings.Join(t.Scopes, " "), expires, used)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "revoke",
+				Usage:     "Revoca un token",
+				ArgsUsage: "<id|nombre>",
+				Flags:     []cli.Flag{userFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("uso: todo token revoke <id|nombre>")
+					}
+
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := currentUser(c, db)
+					if err != nil {
+						return err
+					}
+					t, err := apitoken.Revoke(c.Context, db, user.ID.Int64, c.Args().First())
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Token %q revocado\n", t.Name)
+					return nil
+				},
+			},
+		},
+	}
+}
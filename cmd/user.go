@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/urfave/cli/v2"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// userFlag es el flag --user que comparten los comandos que operan sobre las
+// tareas de un usuario.
+var userFlag = &cli.StringFlag{
+	Name:    "user",
+	Aliases: []string{"u"},
+	Usage:   "Usuario propietario de las tareas (por defecto default_user de la configuración)",
+}
+
+// currentUser resuelve el usuario del flag --user o, si no se indica, el
+// default_user de la configuración.
+func currentUser(c *cli.Context, db *sql.DB) (*models.User, error) {
+	username := c.String("user")
+	if username == "" {
+		username = cfg.DefaultUser
+	}
+	if username == "" {
+		return nil, errors.New("indica el usuario con --user o configura default_user")
+	}
+	return findUser(c.Context, db, username)
+}
+
+// findUser busca el usuario sin distinguir mayúsculas, como el login.
+func findUser(ctx context.Context, exec boil.ContextExecutor, username string) (*models.User, error) {
+	user, err := credentials.FindUser(ctx, exec, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("el usuario %q no existe", username)
+	}
+	return user, err
+}
+
+// cliUser es la representación de un usuario en la salida de la CLI.
+type cliUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Tasks    int64  `json:"tasks"`
+	Done     int64  `json:"done"`
+	Pending  int64  `json:"pending"`
+}
+
+func toCLIUser(ctx context.Context, exec boil.ContextExecutor, u *models.User) (cliUser, error) {
+	out := cliUser{ID: u.ID.Int64, Username: u.Username}
+	var err error
+	if out.Tasks, err = u.Tasks().Count(ctx, exec); err != nil {
+		return out, err
+	}
+	if out.Done, err = u.Tasks(models.TaskWhere.Done.EQ(null.BoolFrom(true))).Count(ctx, exec); err != nil {
+		return out, err
+	}
+	out.Pending = out.Tasks - out.Done
+	return out, nil
+}
+
+// cliLoginFailure es un login fallido en la salida JSON de la CLI.
+type cliLoginFailure struct {
+	Username  string    `json:"username"`
+	IP        string    `json:"ip"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// usernameFlag identifica al usuario sobre el que actúa un subcomando.
+var usernameFlag = &cli.StringFlag{
+	Name:     "username",
+	Aliases:  []string{"nombre"},
+	Usage:    "Nombre del usuario",
+	Required: true,
+}
+
+var passwordStdinFlag = &cli.BoolFlag{
+	Name:  "password-stdin",
+	Usage: "Lee la contraseña de la primera línea de la entrada estándar",
+}
+
+// readPassword lee la contraseña de la primera línea de la entrada estándar.
+func readPassword(c *cli.Context) (string, error) {
+	if !c.Bool("password-stdin") {
+		return "", errors.New("indica la contraseña por la entrada estándar con --password-stdin")
+	}
+	line, err := bufio.NewReader(c.App.Reader).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// hashPassword comprueba la contraseña del usuario username con la política
+// de la configuración, igual que el registro, y la hashea.
+func hashPassword(policy *credentials.Policy, password, username string) (string, error) {
+	if err := policy.CheckPassword(password, username); err != nil {
+		return "", err
+	}
+	if err := policy.CheckBreached(password); err != nil {
+		return "", err
+	}
+	return policy.Hash(password)
+}
+
+func userCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "user",
+		Usage: "Administra los usuarios",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "add",
+				Usage: "Crea un usuario",
+				Flags: []cli.Flag{usernameFlag, passwordStdinFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					policy, err := credentialsPolicy(cfg)
+					if err != nil {
+						return err
+					}
+					username := credentials.NormalizeUsername(c.String("username"))
+					if err := policy.CheckUsername(username); err != nil {
+						return err
+					}
+					password, err := readPassword(c)
+					if err != nil {
+						return err
+					}
+					hash, err := hashPassword(policy, password, username)
+					if err != nil {
+						return err
+					}
+
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					if taken, err := credentials.UsernameTaken(c.Context, db, username); err != nil {
+						return err
+					} else if taken {
+						return fmt.Errorf("el usuario %q ya existe", username)
+					}
+
+					user := &models.User{Username: username, PasswordHash: hash}
+					if err := user.Insert(c.Context, db, boil.Infer()); err != nil {
+						return fmt.Errorf("error creando usuario: %w", err)
+					}
+					if c.String("output") == "json" {
+						return printJSON(cliUser{ID: user.ID.Int64, Username: user.Username})
+					}
+					fmt.Printf("Usuario %q creado con ID %d\n", user.Username, user.ID.Int64)
+					return nil
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Elimina un usuario y sus tareas, o se las pasa a otro usuario",
+				Flags: []cli.Flag{
+					usernameFlag,
+					&cli.StringFlag{
+						Name:  "reassign-to",
+						Usage: "Usuario que recibe las tareas en lugar de borrarlas",
+					},
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "No pedir confirmación",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					tx, err := db.BeginTx(c.Context, nil)
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+
+					user, err := findUser(c.Context, tx, c.String("username"))
+					if err != nil {
+						return err
+					}
+					count, err := user.Tasks().Count(c.Context, tx)
+					if err != nil {
+						return err
+					}
+
+					var target *models.User
+					if name := c.String("reassign-to"); name != "" {
+						if target, err = findUser(c.Context, tx, name); err != nil {
+							return err
+						}
+						if target.ID == user.ID {
+							return errors.New("no puedes reasignar las tareas al mismo usuario")
+						}
+					}
+
+					question := fmt.Sprintf("¿Eliminar el usuario %q y sus %d tarea(s)?", user.Username, count)
+					if target != nil {
+						question = fmt.Sprintf("¿Eliminar el usuario %q y pasar sus %d tarea(s) a %q?", user.Username, count, target.Username)
+					}
+					if !c.Bool("force") && !confirm(c, question) {
+						return errors.New("operación cancelada")
+					}
+
+					if target != nil {
+						if err := tag.Reassign(c.Context, tx, user.ID.Int64, target.ID.Int64); err != nil {
+							return fmt.Errorf("error reasignando etiquetas: %w", err)
+						}
+						if err := project.Reassign(c.Context, tx, user.ID.Int64, target.ID.Int64); err != nil {
+							return fmt.Errorf("error reasignando proyectos: %w", err)
+						}
+						_, err = user.Tasks().UpdateAll(c.Context, tx, models.M{models.TaskColumns.UserID: target.ID})
+					} else {
+						_, err = user.Tasks().DeleteAll(c.Context, tx)
+					}
+					if err != nil {
+						return fmt.Errorf("error con las tareas del usuario: %w", err)
+					}
+					if _, err := user.Delete(c.Context, tx); err != nil {
+						return fmt.Errorf("error eliminando usuario: %w", err)
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+
+					if target != nil {
+						fmt.Printf("El usuario %q ha sido eliminado; %d tarea(s) pasan a %q\n", user.Username, count, target.Username)
+					} else {
+						fmt.Printf("El usuario %q ha sido eliminado junto con %d tarea(s)\n", user.Username, count)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "Lista los usuarios",
+				Flags: []cli.Flag{outputFlag},
+				Action: func(c *cli.Context) error {
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					users, err := models.Users(qm.OrderBy(models.UserColumns.Username)).All(c.Context, db)
+					if err != nil {
+						return err
+					}
+					out := make([]cliUser, 0, len(users))
+					for _, u := range users {
+						cu, err := toCLIUser(c.Context, db, u)
+						if err != nil {
+							return err
+						}
+						out = append(out, cu)
+					}
+
+					if c.String("output") == "json" {
+						return printJSON(out)
+					}
+					for _, u := range out {
+						fmt.Printf("[%d] %s - %d tarea(s), %d pendiente(s)\n", u.ID, u.Username, u.Tasks, u.Pending)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Muestra un usuario y el recuento de sus tareas",
+				Flags: []cli.Flag{usernameFlag, outputFlag},
+				Action: func(c *cli.Context) error {
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := findUser(c.Context, db, c.String("username"))
+					if err != nil {
+						return err
+					}
+					out, err := toCLIUser(c.Context, db, user)
+					if err != nil {
+						return err
+					}
+
+					if c.String("output") == "json" {
+						return printJSON(out)
+					}
+					fmt.Printf("ID:         %d\n", out.ID)
+					fmt.Printf("Usuario:    %s\n", out.Username)
+					fmt.Printf("Tareas:     %d\n", out.Tasks)
+					fmt.Printf("Hechas:     %d\n", out.Done)
+					fmt.Printf("Pendientes: %d\n", out.Pending)
+					return nil
+				},
+			},
+			{
+				Name:  "passwd",
+				Usage: "Cambia la contraseña de un usuario",
+				Flags: []cli.Flag{usernameFlag, passwordStdinFlag},
+				Action: func(c *cli.Context) error {
+					policy, err := credentialsPolicy(cfg)
+					if err != nil {
+						return err
+					}
+					password, err := readPassword(c)
+					if err != nil {
+						return err
+					}
+
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					user, err := findUser(c.Context, db, c.String("username"))
+					if err != nil {
+						return err
+					}
+					if user.PasswordHash, err = hashPassword(policy, password, user.Username); err != nil {
+						return err
+					}
+					if _, err := user.Update(c.Context, db, boil.Whitelist(models.UserColumns.PasswordHash)); err != nil {
+						return fmt.Errorf("error actualizando contraseña: %w", err)
+					}
+					fmt.Printf("Contraseña de %q actualizada\n", user.Username)
+					return nil
+				},
+			},
+			{
+				Name:  "2fa",
+				Usage: "Administra la verificación en dos pasos de los usuarios",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "reset",
+						Usage: "Desactiva la verificación en dos pasos de un usuario que ha perdido el dispositivo y los códigos de recuperación",
+						Flags: []cli.Flag{
+							usernameFlag,
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "No pedir confirmación",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							db, err := openDB(c.Context, cfg.DBDSN)
+							if err != nil {
+								return err
+							}
+							defer db.Close()
+
+							tx, err := db.BeginTx(c.Context, nil)
+							if err != nil {
+								return err
+							}
+							defer tx.Rollback()
+
+							user, err := findUser(c.Context, tx, c.String("username"))
+							if err != nil {
+								return err
+							}
+							if !twofactor.Enabled(user) && !user.TotpSecret.Valid {
+								return fmt.Errorf("el usuario %q no tiene activada la verificación en dos pasos", user.Username)
+							}
+							question := fmt.Sprintf("¿Desactivar la verificación en dos pasos de %q? Podrá entrar solo con la contraseña", user.Username)
+							if !c.Bool("force") && !confirm(c, question) {
+								return errors.New("operación cancelada")
+							}
+							if err := twofactor.Disable(c.Context, tx, user); err != nil {
+								return fmt.Errorf("error desactivando la verificación en dos pasos: %w", err)
+							}
+							if err := tx.Commit(); err != nil {
+								return err
+							}
+							fmt.Printf("Verificación en dos pasos de %q desactivada\n", user.Username)
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "unlock",
+				Usage: "Desbloquea el login de un usuario o de una IP tras demasiados intentos fallidos",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "username", Aliases: []string{"nombre"}, Usage: "Nombre del usuario"},
+					&cli.StringFlag{Name: "ip", Usage: "Dirección IP"},
+				},
+				Action: func(c *cli.Context) error {
+					scope, subject := lockout.ScopeUsername, c.String("username")
+					if c.IsSet("ip") {
+						scope, subject = lockout.ScopeIP, c.String("ip")
+					}
+					if strings.TrimSpace(subject) == "" || c.IsSet("username") == c.IsSet("ip") {
+						return errors.New("uso: todo user unlock --username <nombre> | --ip <ip>")
+					}
+
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					locked, err := lockout.Unlock(c.Context, db, scope, subject, time.Now())
+					if err != nil {
+						return fmt.Errorf("error desbloqueando: %w", err)
+					}
+					if !locked {
+						fmt.Printf("%q no estaba bloqueado; sus intentos fallidos se han puesto a cero\n", subject)
+						return nil
+					}
+					fmt.Printf("%q desbloqueado\n", subject)
+					return nil
+				},
+			},
+			{
+				Name:  "failed-logins",
+				Usage: "Muestra los últimos logins fallidos",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "username", Aliases: []string{"nombre"}, Usage: "Solo los de este usuario"},
+					&cli.IntFlag{Name: "limit", Usage: "Número máximo de resultados", Value: 20},
+					outputFlag,
+				},
+				Action: func(c *cli.Context) error {
+					if c.Int("limit") < 1 {
+						return errors.New("--limit debe ser al menos 1")
+					}
+					db, err := openDB(c.Context, cfg.DBDSN)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					failures, err := lockout.Failures(c.Context, db, c.String("username"), c.Int("limit"))
+					if err != nil {
+						return err
+					}
+					out := make([]cliLoginFailure, 0, len(failures))
+					for _, f := range failures {
+						out = append(out, cliLoginFailure{Username: f.Username, IP: f.IP, Reason: f.Reason, CreatedAt: f.CreatedAt})
+					}
+
+					if c.String("output") == "json" {
+						return printJSON(out)
+					}
+					for _, f := range out {
+						fmt.Printf("%s %s desde %s (%s)\n", f.CreatedAt.Local().Format("2006-01-02 15:04:05"), f.Username, f.IP, f.Reason)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
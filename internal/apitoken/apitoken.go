@@ -0,0 +1,228 @@
+// Package apitoken gestiona los tokens personales de la API, con los que los
+// scripts y la integración continua se autentican sin sesión. De cada token
+// solo se guarda su hash: el secreto se muestra una vez, al crearlo.
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Prefix es el comienzo de todos los tokens, para reconocerlos, p. ej. en los
+// escáneres de secretos.
+const Prefix = "todo_"
+
+// MaxNameLength es la longitud máxima del nombre de un token.
+const MaxNameLength = 64
+
+// Alcances de los tokens. Cubren las tareas y lo que las organiza (subtareas,
+// etiquetas y proyectos); tasks:write incluye tasks:read.
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+)
+
+// Scopes son los alcances válidos.
+var Scopes = []string{ScopeTasksRead, ScopeTasksWrite}
+
+var (
+	// ErrInvalid indica un token que no existe, ha caducado o se ha revocado.
+	ErrInvalid = errors.New("token inválido o caducado")
+	// ErrNotFound indica que el usuario no tiene ese token.
+	ErrNotFound = errors.New("el token no existe")
+	// ErrNameTaken indica que el usuario ya tiene un token con ese nombre.
+	ErrNameTaken = errors.New("ya existe un token con ese nombre")
+)
+
+// NormalizeName quita los espacios alrededor de name y comprueba que es un
+// nombre de token válido.
+func NormalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", errors.New("el nombre del token no puede estar vacío")
+	case utf8.RuneCountInString(name) > MaxNameLength:
+		return "", fmt.Errorf("el nombre del token no puede superar los %d caracteres", MaxNameLength)
+	}
+	return name, nil
+}
+
+// ParseScopes interpreta una lista de alcances separados por comas o
+// espacios, quitando los repetidos. Tiene que haber al menos uno.
+func ParseScopes(s string) ([]string, error) {
+	var scopes []string
+	seen := map[string]bool{}
+	for _, scope := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if !valid(scope) {
+			return nil, fmt.Errorf("alcance inválido %q: usa %s", scope, strings.Join(Scopes, " o "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("indica al menos un alcance: " + strings.Join(Scopes, ", "))
+	}
+	return scopes, nil
+}
+
+func valid(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseExpiry interpreta la caducidad de un token: un número de días, como
+// "30d", o una fecha como las de vencimiento de las tareas. Una cadena vacía
+// es un token que no caduca. La fecha tiene que ser posterior a now.
+func ParseExpiry(s string, loc *time.Location, now time.Time) (null.Time, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return null.Time{}, fmt.Errorf("caducidad inválida %q: usa un número de días, como 30d, o una fecha", s)
+		}
+		return null.TimeFrom(now.AddDate(0, 0, n).UTC().Truncate(time.Second)), nil
+	}
+	expires, err := due.Parse(s, loc)
+	if err != nil {
+		return null.Time{}, fmt.Errorf("caducidad inválida %q: usa un número de días, como 30d, o una fecha AAAA-MM-DD", s)
+	}
+	if expires.Valid && !expires.Time.After(now) {
+		return null.Time{}, errors.New("la caducidad tiene que ser posterior a ahora")
+	}
+	return expires, nil
+}
+
+// ScopeList devuelve los alcances de t.
+func ScopeList(t *models.APIToken) []string {
+	return strings.Fields(t.Scopes)
+}
+
+// Allows indica si t tiene el alcance scope.
+func Allows(t *models.APIToken, scope string) bool {
+	for _, s := range ScopeList(t) {
+		if s == scope || (s == ScopeTasksWrite && scope == ScopeTasksRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// hash es lo que se guarda de un token.
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create crea un token del usuario y devuelve también su secreto, que no se
+// puede volver a obtener. Si el usuario ya tiene un token con ese nombre
+// devuelve ErrNameTaken.
+func Create(ctx context.Context, exec boil.ContextExecutor, userID int64, name string, scopes []string, expiresAt null.Time) (*models.APIToken, string, error) {
+	exists, err := models.APITokens(
+		models.APITokenWhere.UserID.EQ(userID),
+		models.APITokenWhere.Name.EQ(name),
+	).Exists(ctx, exec)
+	if err != nil {
+		return nil, "", err
+	}
+	if exists {
+		return nil, "", ErrNameTaken
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := Prefix + hex.EncodeToString(random)
+	t := &models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hash(secret),
+		Prefix:    secret[:len(Prefix)+6],
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: expiresAt,
+	}
+	if err := t.Insert(ctx, exec, boil.Infer()); err != nil {
+		return nil, "", err
+	}
+	return t, secret, nil
+}
+
+// Authenticate busca el token con ese secreto y apunta que se ha usado en
+// now. Si no existe o ha caducado devuelve ErrInvalid.
+func Authenticate(ctx context.Context, exec boil.ContextExecutor, secret string, now time.Time) (*models.APIToken, error) {
+	if !strings.HasPrefix(secret, Prefix) {
+		return nil, ErrInvalid
+	}
+	t, err := models.APITokens(models.APITokenWhere.TokenHash.EQ(hash(secret))).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.ExpiresAt.Valid && !now.Before(t.ExpiresAt.Time) {
+		return nil, ErrInvalid
+	}
+	t.LastUsedAt = null.TimeFrom(now.UTC().Truncate(time.Second))
+	if _, err := t.Update(ctx, exec, boil.Whitelist(models.APITokenColumns.LastUsedAt)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// List devuelve los tokens del usuario por orden de creación.
+func List(ctx context.Context, exec boil.ContextExecutor, userID int64) (models.APITokenSlice, error) {
+	return models.APITokens(
+		models.APITokenWhere.UserID.EQ(userID),
+		qm.OrderBy(models.APITokenColumns.ID),
+	).All(ctx, exec)
+}
+
+// Find busca el token del usuario con ese ID o, si ref no es un número, con
+// ese nombre.
+func Find(ctx context.Context, exec boil.ContextExecutor, userID int64, ref string) (*models.APIToken, error) {
+	ref = strings.TrimSpace(ref)
+	where := models.APITokenWhere.Name.EQ(ref)
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		where = models.APITokenWhere.ID.EQ(null.Int64From(id))
+	}
+	t, err := models.APITokens(models.APITokenWhere.UserID.EQ(userID), where).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+// Revoke borra el token del usuario con ese ID o nombre; deja de valer en el
+// acto.
+func Revoke(ctx context.Context, exec boil.ContextExecutor, userID int64, ref string) (*models.APIToken, error) {
+	t, err := Find(ctx, exec, userID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.Delete(ctx, exec); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,288 @@
+// Package config resuelve la configuración de la aplicación a partir de
+// valores por defecto, un fichero TOML/YAML opcional y variables de entorno
+// TODO_*. Los flags de la CLI se aplican encima desde cmd.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSessionSecret es la clave de sesión usada si no se configura otra.
+// Solo sirve para desarrollo.
+const DefaultSessionSecret = "super-secret-key"
+
+// DefaultFiles son los ficheros de configuración que se buscan en el
+// directorio actual cuando no se indica ninguno.
+var DefaultFiles = []string{"todo.toml", "todo.yaml", "todo.yml"}
+
+// Config agrupa los ajustes compartidos por el servidor y la CLI.
+type Config struct {
+	DBDSN         string `toml:"db_dsn" yaml:"db_dsn"`
+	ListenAddr    string `toml:"listen_addr" yaml:"listen_addr"`
+	TemplatesDir  string `toml:"templates_dir" yaml:"templates_dir"`
+	StaticDir     string `toml:"static_dir" yaml:"static_dir"`
+	SessionSecret string `toml:"session_secret" yaml:"session_secret"`
+	LogLevel      string `toml:"log_level" yaml:"log_level"`
+	// DefaultUser es el usuario que usan los comandos de la CLI cuando no se
+	// indica --user.
+	DefaultUser string `toml:"default_user" yaml:"default_user"`
+	// AutoMigrate hace que los comandos apliquen las migraciones pendientes
+	// al abrir la base de datos en vez de pedir que se ejecute migrate up.
+	AutoMigrate bool `toml:"auto_migrate" yaml:"auto_migrate"`
+
+	// OIDC* configuran el login con un proveedor OpenID Connect, que está
+	// desactivado si no hay OIDCIssuer. OIDCName es el nombre del proveedor en
+	// el botón de login y, con OIDCAutoProvision, las cuentas externas que no
+	// están vinculadas crean un usuario al entrar.
+	OIDCIssuer        string `toml:"oidc_issuer" yaml:"oidc_issuer"`
+	OIDCClientID      string `toml:"oidc_client_id" yaml:"oidc_client_id"`
+	OIDCClientSecret  string `toml:"oidc_client_secret" yaml:"oidc_client_secret"`
+	OIDCRedirectURL   string `toml:"oidc_redirect_url" yaml:"oidc_redirect_url"`
+	OIDCName          string `toml:"oidc_name" yaml:"oidc_name"`
+	OIDCAutoProvision bool   `toml:"oidc_auto_provision" yaml:"oidc_auto_provision"`
+
+	// Login* limitan los intentos de login fallidos: LoginMaxFailures por
+	// usuario y LoginMaxFailuresIP por IP (0 desactiva el límite). Al pasar
+	// del límite el login se bloquea LoginLockout, que se dobla con cada fallo
+	// hasta LoginLockoutMax. Con LoginTrustProxy la IP es la última de
+	// X-Forwarded-For, para cuando el servidor está detrás de un proxy.
+	LoginMaxFailures   int    `toml:"login_max_failures" yaml:"login_max_failures"`
+	LoginMaxFailuresIP int    `toml:"login_max_failures_ip" yaml:"login_max_failures_ip"`
+	LoginLockout       string `toml:"login_lockout" yaml:"login_lockout"`
+	LoginLockoutMax    string `toml:"login_lockout_max" yaml:"login_lockout_max"`
+	LoginTrustProxy    bool   `toml:"login_trust_proxy" yaml:"login_trust_proxy"`
+
+	// Username* y Password* son las reglas del registro y de los cambios de
+	// contraseña. Las longitudes se cuentan en caracteres y 0 desactiva el
+	// límite, igual que un UsernamePattern vacío. BreachedPasswordsFile es
+	// una lista local de contraseñas filtradas con el formato de Pwned
+	// Passwords: un fichero HASH:CUENTA ordenado o un directorio de rangos.
+	// BcryptCost es el coste de los hashes nuevos; al subirlo, los que tienen
+	// menos se rehacen cuando el usuario inicia sesión.
+	UsernameMinLength     int    `toml:"username_min_length" yaml:"username_min_length"`
+	UsernameMaxLength     int    `toml:"username_max_length" yaml:"username_max_length"`
+	UsernamePattern       string `toml:"username_pattern" yaml:"username_pattern"`
+	PasswordMinLength     int    `toml:"password_min_length" yaml:"password_min_length"`
+	BreachedPasswordsFile string `toml:"breached_passwords_file" yaml:"breached_passwords_file"`
+	BcryptCost            int    `toml:"bcrypt_cost" yaml:"bcrypt_cost"`
+}
+
+// Default devuelve la configuración por defecto, con rutas relativas a la
+// raíz del repositorio.
+func Default() Config {
+	return Config{
+		DBDSN:         "todo.db",
+		ListenAddr:    ":8080",
+		TemplatesDir:  "web_templates",
+		StaticDir:     filepath.Join("web_templates", "static"),
+		SessionSecret: DefaultSessionSecret,
+		LogLevel:      "info",
+		OIDCName:      "SSO",
+
+		LoginMaxFailures:   5,
+		LoginMaxFailuresIP: 20,
+		LoginLockout:       "1m",
+		LoginLockoutMax:    "1h",
+
+		UsernameMinLength: 3,
+		UsernameMaxLength: 32,
+		UsernamePattern:   credentials.DefaultUsernamePattern,
+		PasswordMinLength: 8,
+		BcryptCost:        bcrypt.DefaultCost,
+	}
+}
+
+// Load parte de Default, aplica el fichero path (o el primero de DefaultFiles
+// que exista si path está vacío) y después las variables de entorno.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		for _, f := range DefaultFiles {
+			if _, err := os.Stat(f); err == nil {
+				path = f
+				break
+			}
+		}
+	}
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.LoadEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// LoadFile sobrescribe los campos presentes en el fichero. El formato se
+// deduce de la extensión (.toml, .yaml o .yml).
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("leyendo configuración: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("formato de configuración no soportado: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parseando %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadEnv sobrescribe los campos cuya variable TODO_* esté definida. Devuelve
+// juntos los errores de las variables con valores inválidos, que no se
+// aplican.
+func (c *Config) LoadEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for env, field := range map[string]*string{
+		"TODO_DB_DSN":         &c.DBDSN,
+		"TODO_LISTEN_ADDR":    &c.ListenAddr,
+		"TODO_TEMPLATES_DIR":  &c.TemplatesDir,
+		"TODO_STATIC_DIR":     &c.StaticDir,
+		"TODO_SESSION_SECRET": &c.SessionSecret,
+		"TODO_LOG_LEVEL":      &c.LogLevel,
+		"TODO_DEFAULT_USER":   &c.DefaultUser,
+
+		"TODO_OIDC_ISSUER":        &c.OIDCIssuer,
+		"TODO_OIDC_CLIENT_ID":     &c.OIDCClientID,
+		"TODO_OIDC_CLIENT_SECRET": &c.OIDCClientSecret,
+		"TODO_OIDC_REDIRECT_URL":  &c.OIDCRedirectURL,
+		"TODO_OIDC_NAME":          &c.OIDCName,
+
+		"TODO_LOGIN_LOCKOUT":     &c.LoginLockout,
+		"TODO_LOGIN_LOCKOUT_MAX": &c.LoginLockoutMax,
+
+		"TODO_USERNAME_PATTERN":        &c.UsernamePattern,
+		"TODO_BREACHED_PASSWORDS_FILE": &c.BreachedPasswordsFile,
+	} {
+		if v, ok := lookup(env); ok {
+			*field = v
+		}
+	}
+	for env, field := range map[string]*bool{
+		"TODO_OIDC_AUTO_PROVISION": &c.OIDCAutoProvision,
+		"TODO_LOGIN_TRUST_PROXY":   &c.LoginTrustProxy,
+		"TODO_AUTO_MIGRATE":        &c.AutoMigrate,
+	} {
+		if v, ok := lookup(env); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("valor inválido en %s %q: usa true o false", env, v))
+			} else {
+				*field = b
+			}
+		}
+	}
+	for env, field := range map[string]*int{
+		"TODO_LOGIN_MAX_FAILURES":    &c.LoginMaxFailures,
+		"TODO_LOGIN_MAX_FAILURES_IP": &c.LoginMaxFailuresIP,
+		"TODO_USERNAME_MIN_LENGTH":   &c.UsernameMinLength,
+		"TODO_USERNAME_MAX_LENGTH":   &c.UsernameMaxLength,
+		"TODO_PASSWORD_MIN_LENGTH":   &c.PasswordMinLength,
+		"TODO_BCRYPT_COST":           &c.BcryptCost,
+	} {
+		if v, ok := lookup(env); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("valor inválido en %s %q: tiene que ser un número entero", env, v))
+			} else {
+				*field = n
+			}
+		}
+	}
+	// Los mapas no tienen orden: así el mensaje es siempre el mismo
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// Validate comprueba que la configuración es utilizable.
+func (c Config) Validate() error {
+	if c.DBDSN == "" {
+		return errors.New("la base de datos (db_dsn) no puede estar vacía")
+	}
+	if c.SessionSecret == "" {
+		return errors.New("la clave de sesión (session_secret) no puede estar vacía")
+	}
+	if c.OIDCIssuer != "" && (c.OIDCClientID == "" || c.OIDCRedirectURL == "") {
+		return errors.New("el login OIDC necesita oidc_client_id y oidc_redirect_url además de oidc_issuer")
+	}
+	if c.LoginMaxFailures < 0 || c.LoginMaxFailuresIP < 0 {
+		return errors.New("login_max_failures y login_max_failures_ip no pueden ser negativos")
+	}
+	if _, _, err := c.LoginLockouts(); err != nil {
+		return err
+	}
+	if c.UsernameMinLength < 0 || c.UsernameMaxLength < 0 || c.PasswordMinLength < 0 {
+		return errors.New("username_min_length, username_max_length y password_min_length no pueden ser negativos")
+	}
+	if c.UsernameMaxLength > 0 && c.UsernameMaxLength < c.UsernameMinLength {
+		return errors.New("username_max_length no puede ser menor que username_min_length")
+	}
+	if _, err := c.UsernameRegexp(); err != nil {
+		return err
+	}
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt_cost tiene que estar entre %d y %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	_, err := c.SlogLevel()
+	return err
+}
+
+// LoginLockouts traduce LoginLockout y LoginLockoutMax, como "1m" o "1h", a
+// duraciones.
+func (c Config) LoginLockouts() (lockout, maxLockout time.Duration, err error) {
+	lockout, err = time.ParseDuration(c.LoginLockout)
+	if err != nil || lockout <= 0 {
+		return 0, 0, fmt.Errorf("duración inválida en login_lockout %q: usa p. ej. 1m", c.LoginLockout)
+	}
+	maxLockout, err = time.ParseDuration(c.LoginLockoutMax)
+	if err != nil || maxLockout < lockout {
+		return 0, 0, fmt.Errorf("duración inválida en login_lockout_max %q: tiene que ser al menos login_lockout", c.LoginLockoutMax)
+	}
+	return lockout, maxLockout, nil
+}
+
+// UsernameRegexp compila UsernamePattern, o devuelve nil si está vacío.
+func (c Config) UsernameRegexp() (*regexp.Regexp, error) {
+	if c.UsernamePattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(c.UsernamePattern)
+	if err != nil {
+		return nil, fmt.Errorf("expresión inválida en username_pattern %q: %w", c.UsernamePattern, err)
+	}
+	return re, nil
+}
+
+// SlogLevel traduce LogLevel (debug|info|warn|error) a un slog.Level.
+func (c Config) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return level, fmt.Errorf("nivel de log inválido %q: usa debug|info|warn|error", c.LogLevel)
+	}
+	return level, nil
+}
@@ -0,0 +1,139 @@
+package credentials
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashLen es la longitud de un SHA-1 en hexadecimal y prefixLen, la del
+// prefijo de los rangos de k-anonimato.
+const (
+	hashLen   = 40
+	prefixLen = 5
+)
+
+// BreachedList es una lista local de contraseñas filtradas con el formato de
+// Pwned Passwords, que identifica cada contraseña por su SHA-1 en hexadecimal
+// y nunca por la contraseña. Puede ser un fichero con una línea HASH:CUENTA
+// por contraseña, ordenado por hash, o un directorio de rangos de
+// k-anonimato: un fichero PREFIJO.txt por cada prefijo de 5 caracteres, con
+// líneas SUFIJO:CUENTA, como los que devuelve la API de rangos.
+type BreachedList struct {
+	path string
+	dir  bool
+}
+
+// OpenBreachedList comprueba que la lista path existe.
+func OpenBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &BreachedList{path: path, dir: info.IsDir()}, nil
+}
+
+// Contains indica si la contraseña está en la lista.
+func (l *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if l.dir {
+		return l.containsRange(hash)
+	}
+	return l.containsSorted(hash)
+}
+
+// containsRange busca el sufijo del hash en el fichero de su prefijo. Los
+// rangos son pequeños y se leen enteros.
+func (l *BreachedList) containsRange(hash string) (bool, error) {
+	f, err := os.Open(filepath.Join(l.path, hash[:prefixLen]+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if lineHash(scanner.Text(), hashLen-prefixLen) == hash[prefixLen:] {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// containsSorted hace una búsqueda binaria del hash en el fichero ordenado,
+// que puede ocupar decenas de GB y no se carga en memoria.
+func (l *BreachedList) containsSorted(hash string) (bool, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	// Las líneas que empiezan antes de lo tienen un hash menor y las que
+	// empiezan en hi o después, mayor.
+	lo, hi := int64(0), info.Size()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, start, next, err := lineAt(f, mid, info.Size())
+		if err != nil {
+			return false, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+		switch h := lineHash(line, hashLen); {
+		case h == hash:
+			return true, nil
+		case h < hash:
+			lo = next
+		default:
+			hi = start
+		}
+	}
+	return false, nil
+}
+
+// lineAt lee la primera línea que empieza en off o después. Devuelve también
+// dónde empieza y dónde empieza la siguiente; si no hay ninguna, start es
+// size.
+func lineAt(r io.ReaderAt, off, size int64) (line string, start, next int64, err error) {
+	start = off
+	base := max(off-1, 0)
+	br := bufio.NewReader(io.NewSectionReader(r, base, size-base))
+	if off > 0 {
+		// Salta el resto de la línea en la que cae off
+		skipped, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", 0, 0, err
+		}
+		start = base + int64(len(skipped))
+	}
+	line, err = br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, 0, err
+	}
+	next = start + int64(len(line))
+	return line, start, next, nil
+}
+
+// lineHash es el hash de una línea HASH:CUENTA en mayúsculas, o "" si no
+// tiene n caracteres de hash.
+func lineHash(line string, n int) string {
+	line = strings.TrimSpace(line)
+	if len(line) < n || (len(line) > n && line[n] != ':') {
+		return ""
+	}
+	return strings.ToUpper(line[:n])
+}
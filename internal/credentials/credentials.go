@@ -0,0 +1,151 @@
+// Package credentials reúne las reglas de los nombres de usuario y las
+// contraseñas: el formato de los nombres, la política de contraseñas, la
+// lista local de contraseñas filtradas y el coste de bcrypt con el que se
+// guardan.
+package credentials
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxPasswordBytes es lo que bcrypt tiene en cuenta de una contraseña; el
+// resto lo ignora.
+const maxPasswordBytes = 72
+
+// DefaultUsernamePattern son las letras, los números y . _ - sin empezar por
+// un signo.
+const DefaultUsernamePattern = `^[\p{L}\p{N}][\p{L}\p{N}._-]*$`
+
+// ErrBreached es el error de una contraseña que aparece en la lista de
+// contraseñas filtradas.
+var ErrBreached = errors.New("la contraseña aparece en filtraciones conocidas; elige otra")
+
+// Policy son las reglas de los nombres de usuario y las contraseñas. Las
+// longitudes se cuentan en caracteres y 0 desactiva el límite, igual que un
+// UsernamePattern o un Breached nil. BcryptCost es el coste de los hashes
+// nuevos; los que tienen menos se rehacen al iniciar sesión.
+type Policy struct {
+	UsernameMinLength int
+	UsernameMaxLength int
+	UsernamePattern   *regexp.Regexp
+	PasswordMinLength int
+	Breached          *BreachedList
+	BcryptCost        int
+}
+
+// DefaultPolicy es la política por defecto.
+var DefaultPolicy = Policy{
+	UsernameMinLength: 3,
+	UsernameMaxLength: 32,
+	UsernamePattern:   regexp.MustCompile(DefaultUsernamePattern),
+	PasswordMinLength: 8,
+	BcryptCost:        bcrypt.DefaultCost,
+}
+
+// NormalizeUsername quita los espacios de alrededor y pasa el nombre a NFC,
+// para que la misma letra escrita de dos formas sea el mismo nombre.
+func NormalizeUsername(username string) string {
+	return norm.NFC.String(strings.TrimSpace(username))
+}
+
+// CheckUsername comprueba un nombre de usuario ya normalizado.
+func (p Policy) CheckUsername(username string) error {
+	n := utf8.RuneCountInString(username)
+	switch {
+	case n == 0:
+		return errors.New("el nombre de usuario no puede estar vacío")
+	case p.UsernameMinLength > 0 && n < p.UsernameMinLength:
+		return fmt.Errorf("el nombre de usuario tiene que tener al menos %d caracteres", p.UsernameMinLength)
+	case p.UsernameMaxLength > 0 && n > p.UsernameMaxLength:
+		return fmt.Errorf("el nombre de usuario no puede tener más de %d caracteres", p.UsernameMaxLength)
+	case p.UsernamePattern != nil && !p.UsernamePattern.MatchString(username):
+		return errors.New("el nombre de usuario solo puede tener letras, números, puntos, guiones y guiones bajos, y empezar por letra o número")
+	}
+	return nil
+}
+
+// CheckPassword comprueba la contraseña del usuario username. No mira la
+// lista de contraseñas filtradas, que se consulta aparte con CheckBreached.
+func (p Policy) CheckPassword(password, username string) error {
+	switch {
+	case password == "":
+		return errors.New("la contraseña no puede estar vacía")
+	case p.PasswordMinLength > 0 && utf8.RuneCountInString(password) < p.PasswordMinLength:
+		return fmt.Errorf("la contraseña tiene que tener al menos %d caracteres", p.PasswordMinLength)
+	case len(password) > maxPasswordBytes:
+		return fmt.Errorf("la contraseña no puede ocupar más de %d bytes", maxPasswordBytes)
+	case strings.EqualFold(password, username):
+		return errors.New("la contraseña no puede ser igual al nombre de usuario")
+	}
+	return nil
+}
+
+// CheckBreached devuelve ErrBreached si la contraseña está en la lista de
+// contraseñas filtradas, o el error de leer la lista. Sin lista no comprueba
+// nada.
+func (p Policy) CheckBreached(password string) error {
+	if p.Breached == nil {
+		return nil
+	}
+	breached, err := p.Breached.Contains(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		return ErrBreached
+	}
+	return nil
+}
+
+func (p Policy) cost() int {
+	if p.BcryptCost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return p.BcryptCost
+}
+
+// Hash es el hash bcrypt de la contraseña con el coste de la política.
+func (p Policy) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cost())
+	return string(hash), err
+}
+
+// NeedsRehash indica si el hash se hizo con un coste de bcrypt menor que el de
+// la política.
+func (p Policy) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost < p.cost()
+}
+
+// FindUser busca el usuario por su nombre, sin distinguir mayúsculas. Si hay
+// varios, de antes de que fuera así, devuelve el que coincide exactamente.
+// Devuelve sql.ErrNoRows si no existe.
+func FindUser(ctx context.Context, exec boil.ContextExecutor, username string) (*models.User, error) {
+	username = NormalizeUsername(username)
+	return models.Users(
+		qm.Where(models.UserColumns.Username+" = ? COLLATE NOCASE", username),
+		qm.OrderBy(models.UserColumns.Username+" = ? DESC, "+models.UserColumns.ID, username),
+	).One(ctx, exec)
+}
+
+// UsernameTaken indica si ya hay un usuario con ese nombre, sin distinguir
+// mayúsculas.
+func UsernameTaken(ctx context.Context, exec boil.ContextExecutor, username string) (bool, error) {
+	_, err := FindUser(ctx, exec, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,97 @@
+// Package due interpreta y presenta las fechas de vencimiento de las tareas.
+// Las fechas se guardan siempre en UTC; la entrada sin zona horaria se
+// interpreta en la zona indicada (normalmente time.Local).
+package due
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/volatiletech/null/v8"
+)
+
+// InputLayout es el formato de los <input type="datetime-local">.
+const InputLayout = "2006-01-02T15:04"
+
+var localLayouts = []string{
+	InputLayout,
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parse convierte s en una fecha de vencimiento. Acepta RFC 3339, el formato
+// de datetime-local, "2006-01-02 15:04" y "2006-01-02" (se entiende el final
+// de ese día). Una cadena vacía devuelve una fecha nula.
+func Parse(s string, loc *time.Location) (null.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return null.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return normalize(t), nil
+	}
+	for _, layout := range localLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			t = t.Add(24*time.Hour - time.Minute)
+		}
+		return normalize(t), nil
+	}
+	return null.Time{}, fmt.Errorf("fecha de vencimiento inválida %q: usa AAAA-MM-DD, AAAA-MM-DDTHH:MM o RFC 3339", s)
+}
+
+func normalize(t time.Time) null.Time {
+	return null.TimeFrom(t.UTC().Truncate(time.Second))
+}
+
+// Overdue indica si una tarea pendiente con vencimiento dueAt está vencida en
+// el instante now.
+func Overdue(dueAt null.Time, done bool, now time.Time) bool {
+	return dueAt.Valid && !done && dueAt.Time.Before(now)
+}
+
+// Input formatea dueAt para un <input type="datetime-local"> en loc.
+func Input(dueAt null.Time, loc *time.Location) string {
+	if !dueAt.Valid {
+		return ""
+	}
+	return dueAt.Time.In(loc).Format(InputLayout)
+}
+
+// Label describe dueAt respecto a now en días naturales de la zona de now,
+// por ejemplo "vence mañana" o "venció hace 3 días".
+func Label(dueAt null.Time, now time.Time) string {
+	if !dueAt.Valid {
+		return ""
+	}
+	local := dueAt.Time.In(now.Location())
+	days := calendarDays(now, local)
+
+	switch {
+	case days == 0 && local.Before(now):
+		return "venció hoy a las " + local.Format("15:04")
+	case days == 0:
+		return "vence hoy a las " + local.Format("15:04")
+	case days == 1:
+		return "vence mañana"
+	case days == -1:
+		return "venció ayer"
+	case days > 1:
+		return fmt.Sprintf("vence en %d días", days)
+	default:
+		return fmt.Sprintf("venció hace %d días", -days)
+	}
+}
+
+// calendarDays cuenta los cambios de día entre from y to.
+func calendarDays(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
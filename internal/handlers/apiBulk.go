@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// maxBulkOperations es el número máximo de operaciones de una petición a
+// /api/tasks/bulk.
+const maxBulkOperations = 200
+
+// Operaciones de /api/tasks/bulk.
+const (
+	bulkComplete = "complete"
+	bulkReopen   = "reopen"
+	bulkDelete   = "delete"
+	bulkRetitle  = "retitle"
+	bulkSet      = "set"
+)
+
+// bulkRequest es el cuerpo de POST /api/tasks/bulk: las operaciones, que se
+// aplican en orden, y si tienen que aplicarse todas o ninguna.
+type bulkRequest struct {
+	Atomic     bool              `json:"atomic"`
+	Operations []json.RawMessage `json:"operations"`
+	ops        []bulkOperation
+}
+
+// validate lee también cada operación. Sus errores van con el prefijo
+// operations[i]., p. ej. operations[2].fields.priority.
+func (req *bulkRequest) validate(errs fieldErrors) {
+	switch {
+	case len(req.Operations) == 0:
+		errs.add("operations", errRequired)
+		return
+	case len(req.Operations) > maxBulkOperations:
+		errs.add("operations", fmt.Sprintf("debe tener como mucho %d elementos", maxBulkOperations))
+		return
+	}
+	req.ops = make([]bulkOperation, len(req.Operations))
+	for i, raw := range req.Operations {
+		prefix := fmt.Sprintf("operations[%d]", i)
+		opErrs := fieldErrors{}
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &body); err != nil || body == nil {
+			errs.add(prefix, "debe ser un objeto")
+			continue
+		}
+		decodeFields(body, requestFields(&req.ops[i]), opErrs)
+		if len(opErrs) == 0 {
+			req.ops[i].validate(opErrs)
+		}
+		for field, msg := range opErrs {
+			errs.add(prefix+"."+field, msg)
+		}
+	}
+}
+
+// bulkOperation es una operación de /api/tasks/bulk sobre la tarea ID. Solo se
+// aplica si la tarea sigue teniendo el ETag IfMatch, que es obligatorio como
+// If-Match en PUT, PATCH y DELETE. Title es el de retitle y Fields, los campos
+// que cambia set, como en PATCH.
+type bulkOperation struct {
+	Op      string          `json:"op"`
+	ID      int64           `json:"id"`
+	IfMatch string          `json:"if_match"`
+	Title   string          `json:"title"`
+	Fields  json.RawMessage `json:"fields"`
+	patch   taskPatch
+}
+
+func (op *bulkOperation) validate(errs fieldErrors) {
+	if op.ID <= 0 {
+		errs.add("id", errRequired)
+	}
+	switch op.Op {
+	case bulkComplete, bulkReopen:
+		op.patch.Done = optionalBool{Set: true, Value: op.Op == bulkComplete}
+	case bulkDelete:
+	case bulkRetitle:
+		op.Title = errs.title("title", op.Title)
+		op.patch.Title = optionalText{Set: true, Value: op.Title}
+	case bulkSet:
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(op.Fields, &body); err != nil || len(body) == 0 {
+			errs.add("fields", "debe ser un objeto con los campos que se cambian")
+			return
+		}
+		fieldErrs := fieldErrors{}
+		decodeFields(body, requestFields(&op.patch), fieldErrs)
+		op.patch.validate(fieldErrs)
+		for field, msg := range fieldErrs {
+			errs.add("fields."+field, msg)
+		}
+	case "":
+		errs.add("op", errRequired)
+	default:
+		errs.add("op", "debe ser complete, reopen, delete, retitle o set")
+	}
+}
+
+// bulkResult es el resultado de una operación de /api/tasks/bulk: su estado
+// HTTP y la tarea como ha quedado, o el error. Task es null si la tarea se ha
+// borrado o la operación ha fallado; Next es la siguiente repetición, si al
+// completarla se ha creado.
+type bulkResult struct {
+	ID     int64       `json:"id"`
+	Status int         `json:"status"`
+	Task   *apiTask    `json:"task"`
+	Next   *apiTask    `json:"next"`
+	Error  *apiError   `json:"error"`
+	Errors fieldErrors `json:"errors"`
+}
+
+// bulkError es el resultado de una operación que ha fallado.
+func bulkError(id int64, status int, code, message string) bulkResult {
+	if code == "" {
+		code = statusCodes[status]
+	}
+	return bulkResult{ID: id, Status: status, Error: &apiError{Code: code, Message: message}}
+}
+
+// ApiBulkTasks aplica varias operaciones sobre tareas en una sola transacción
+// y responde con el resultado de cada una, en el mismo orden. Una operación que
+// falla se deshace sin tocar las demás; con atomic se deshacen todas y
+// responde 409.
+func (h *WebHandler) ApiBulkTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+	var req bulkRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	db, ok := h.Db.(boil.ContextBeginner)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "La base de datos no admite transacciones")
+		return
+	}
+	tx, err := db.BeginTx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	defer tx.Rollback()
+
+	results := make([]bulkResult, len(req.ops))
+	failed := false
+	for i := range req.ops {
+		results[i] = h.runBulkOperation(r.Context(), tx, int64(userID), &req.ops[i])
+		failed = failed || results[i].Error != nil
+	}
+
+	if failed && req.Atomic {
+		tx.Rollback()
+		for i, result := range results {
+			if result.Error == nil {
+				results[i] = bulkError(result.ID, http.StatusFailedDependency, codeRolledBack, "No se ha aplicado porque ha fallado otra operación")
+			}
+		}
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":   apiError{Code: codeBulkFailed, Message: "Ha fallado alguna operación y no se ha aplicado ninguna"},
+			"results": results,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error guardando los cambios: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// runBulkOperation aplica op dentro de un savepoint, para poder deshacerla
+// sola si falla.
+func (h *WebHandler) runBulkOperation(ctx context.Context, tx *sql.Tx, userID int64, op *bulkOperation) bulkResult {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_operation"); err != nil {
+		return bulkError(op.ID, http.StatusInternalServerError, "", "Error de base de datos")
+	}
+	result := h.applyBulkOperation(ctx, tx, userID, op)
+	if result.Error != nil {
+		if _, err := tx.ExecContext(ctx, "ROLLBACK TO bulk_operation"); err != nil {
+			return bulkError(op.ID, http.StatusInternalServerError, "", "Error deshaciendo la operación")
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE bulk_operation"); err != nil {
+		return bulkError(op.ID, http.StatusInternalServerError, "", "Error de base de datos")
+	}
+	return result
+}
+
+// applyBulkOperation aplica op a su tarea, que tiene que ser de userID.
+func (h *WebHandler) applyBulkOperation(ctx context.Context, tx *sql.Tx, userID int64, op *bulkOperation) bulkResult {
+	task, err := models.FindTask(ctx, tx, null.Int64From(op.ID))
+	if err != nil || !task.UserID.Valid || task.UserID.Int64 != userID {
+		return bulkError(op.ID, http.StatusForbidden, "", "No autorizado")
+	}
+	if op.IfMatch == "" {
+		return bulkError(op.ID, http.StatusPreconditionRequired, "", "Falta if_match con el ETag de la tarea")
+	}
+	if !matchETag(op.IfMatch, taskETag(task)) {
+		return bulkError(op.ID, http.StatusPreconditionFailed, "", errVersionConflict.Error())
+	}
+
+	if op.Op == bulkDelete {
+		if _, err := task.Delete(ctx, tx); err != nil {
+			return bulkError(op.ID, http.StatusInternalServerError, "", "Error eliminando tarea: "+err.Error())
+		}
+		return bulkResult{ID: op.ID, Status: http.StatusOK}
+	}
+
+	changes, errs := op.patch.apply(ctx, tx, task)
+	if len(errs) > 0 {
+		result := bulkError(op.ID, http.StatusBadRequest, codeValidation, "La operación tiene campos inválidos")
+		result.Errors = errs
+		return result
+	}
+	next, err := changes.save(ctx, tx, h.now())
+	if errors.Is(err, errVersionConflict) {
+		return bulkError(op.ID, http.StatusPreconditionFailed, "", err.Error())
+	}
+	if err != nil {
+		return bulkError(op.ID, http.StatusInternalServerError, "", "Error "+err.Error())
+	}
+
+	result := bulkResult{ID: op.ID, Status: http.StatusOK}
+	for _, t := range []*models.Task{task, next} {
+		if t == nil {
+			continue
+		}
+		if err := t.L.LoadTags(ctx, tx, true, t, qm.OrderBy(models.TagColumns.Name)); err != nil {
+			return bulkError(op.ID, http.StatusInternalServerError, "", "Error obteniendo etiquetas")
+		}
+		out := newAPITask(t)
+		if t == task {
+			result.Task = &out
+		} else {
+			result.Next = &out
+		}
+	}
+	return result
+}
@@ -1,79 +1,149 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/due"
 	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/JorgeePG/todo-list/internal/taskquery"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/gorilla/mux"
 	"github.com/volatiletech/null/v8"
 	"github.com/volatiletech/sqlboiler/v4/boil"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
 )
 
+// credentialsRequest es el cuerpo del registro.
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (req *credentialsRequest) validate(errs fieldErrors) {
+	errs.required("username", req.Username)
+	errs.required("password", req.Password)
+}
+
 func (h *WebHandler) ApiRegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSON(w, http.StatusMethodNotAllowed, `{"error":"Método no permitido"}`)
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
 		return
 	}
-	username := r.FormValue("username")
-	password := r.FormValue("password")
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	//fmt.Printf("Registro - Password: %s, Hash: %s\n", password, string(hash))
-	result, err := h.Db.Exec("INSERT INTO users (username, password_hash) VALUES (?, ?)", username, hash)
-	if err != nil {
-		writeJSON(w, http.StatusBadRequest, `{"error":"Usuario ya existe"}`)
+	var req credentialsRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	username := credentials.NormalizeUsername(req.Username)
+	if errs := h.checkCredentials(username, req.Password); len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+	user, err := h.createUser(r.Context(), username, req.Password)
+	if errors.Is(err, errUsernameTaken) {
+		writeErrorCode(w, http.StatusBadRequest, codeUsernameTaken, err.Error())
 		return
 	}
-	userID, err := result.LastInsertId()
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, `{"error":"Error al obtener el ID del usuario"}`)
+		writeError(w, http.StatusInternalServerError, "Error creando el usuario")
 		return
 	}
 	session, _ := h.Store.Get(r, "session")
-	session.Values["user_id"] = int(userID)
+	session.Values["user_id"] = int(user.ID.Int64)
 	session.Save(r, w)
-	writeJSON(w, http.StatusCreated, `{"message":"Usuario registrado correctamente"}`)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "Usuario registrado correctamente",
+		"user":    apiUser{ID: user.ID.Int64, Username: user.Username},
+	})
+}
+
+// loginRequest es el cuerpo del login. OTP es el código de verificación en
+// dos pasos, si el usuario la tiene activada; sin él, el login queda
+// pendiente de POST /api/login/2fa.
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	OTP      string `json:"otp"`
+}
+
+func (req *loginRequest) validate(errs fieldErrors) {
+	errs.required("username", req.Username)
+	errs.required("password", req.Password)
 }
 
 func (h *WebHandler) ApiLoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Método no permitido"})
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
 		return
 	}
-	username := strings.TrimSpace(r.FormValue("username"))
-	password := strings.TrimSpace(r.FormValue("password"))
-
-	var id int
-	var storedHash string // Variable separada para el hash almacenado
-
-	err := h.Db.QueryRow("SELECT id, password_hash FROM users WHERE username = ?", username).Scan(&id, &storedHash)
-	//fmt.Printf("Login - Password: %s, Stored Hash: %s\n", password, storedHash)
+	var req loginRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	// La contraseña no se recorta: se compara tal cual se registró
+	username := credentials.NormalizeUsername(req.Username)
+	password := req.Password
 
+	wait, err := h.loginLocked(r, username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Usuario o contraseña incorrectos"})
-		} else {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error de base de datos"})
-		}
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if wait > 0 {
+		writeLocked(w, wait)
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(password)); err != nil {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Usuario o contraseña incorrectos"})
+	user, reason, err := h.authenticate(r, username, password)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if user == nil {
+		h.writeLoginFailed(w, r, username, reason, codeInvalidCredentials, "Usuario o contraseña incorrectos")
 		return
 	}
 
+	id := int(user.ID.Int64)
 	session, _ := h.Store.Get(r, "session")
+	if twofactor.Enabled(user) {
+		if req.OTP != "" {
+			h.completeAPILogin(w, r, session, user, req.OTP)
+			return
+		}
+		h.startSecondStep(session, id)
+		if err := session.Save(r, w); err != nil {
+			writeError(w, http.StatusInternalServerError, "Error guardando sesión")
+			return
+		}
+		writeErrorCode(w, http.StatusUnauthorized, codeOTPRequired, "Falta el código de verificación en dos pasos: envíalo en otp o a POST /api/login/2fa")
+		return
+	}
+	if err := h.loginSucceeded(r, username); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
 	session.Values["user_id"] = id
 	if err := session.Save(r, w); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error guardando sesión"})
+		writeError(w, http.StatusInternalServerError, "Error guardando sesión")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{"message": "Login correcto"})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Login correcto",
+		"user":    apiUser{ID: int64(id), Username: user.Username},
+	})
 }
 
 func (h *WebHandler) ApiLogoutHandler(w http.ResponseWriter, r *http.Request) {
@@ -88,130 +158,413 @@ func (h *WebHandler) ApiLogoutHandler(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"message": "Logout correcto"})
 }
 
+// taskRequest es el cuerpo del alta de tareas.
+type taskRequest struct {
+	Title       string       `json:"title"`
+	Done        bool         `json:"done"`
+	DueAt       optionalText `json:"due_at"`
+	Priority    optionalText `json:"priority"`
+	Tags        optionalList `json:"tags"`
+	ProjectID   optionalText `json:"project_id"`
+	Description optionalText `json:"description"`
+	Recurrence  optionalText `json:"recurrence"`
+}
+
+func (req *taskRequest) validate(errs fieldErrors) {
+	req.Title = errs.title("title", req.Title)
+}
+
+// taskFields son los campos de un taskRequest ya interpretados.
+type taskFields struct {
+	DueAt       null.Time
+	Priority    int64
+	Tags        []string
+	ProjectID   null.Int64
+	Description string
+}
+
+// parse interpreta los campos de req; el proyecto tiene que ser de userID.
+func (req *taskRequest) parse(ctx context.Context, exec boil.ContextExecutor, userID int64) (taskFields, fieldErrors) {
+	var f taskFields
+	var err error
+	errs := fieldErrors{}
+	f.DueAt, err = due.Parse(req.DueAt.Value, time.Local)
+	errs.check("due_at", err)
+	f.Priority, err = priority.Parse(req.Priority.Value)
+	errs.check("priority", err)
+	f.Tags, err = tag.ParseList(req.Tags.Value)
+	errs.check("tags", err)
+	f.ProjectID, err = project.ResolveID(ctx, exec, userID, req.ProjectID.Value)
+	errs.check("project_id", err)
+	f.Description, err = notes.Normalize(req.Description.Value)
+	errs.check("description", err)
+	return f, errs
+}
+
+// idRequest es el cuerpo de las peticiones que solo llevan el ID de una
+// tarea, si no va en la ruta.
+type idRequest struct {
+	ID int64 `json:"id"`
+}
+
+func (req *idRequest) validate(errs fieldErrors) {}
+
+// taskID devuelve el ID de la ruta /api/tasks/{id} o, si no va en la ruta, el
+// del cuerpo. Si no hay ninguno lo anota en errs.
+func taskID(r *http.Request, body int64, errs fieldErrors) int64 {
+	if s, ok := mux.Vars(r)["id"]; ok {
+		id, _ := strconv.ParseInt(s, 10, 64)
+		return id
+	}
+	if body <= 0 {
+		errs.add("id", errRequired)
+	}
+	return body
+}
+
 func (h *WebHandler) ApiAddTask(w http.ResponseWriter, r *http.Request) {
-	session, _ := h.Store.Get(r, "session")
-	userID, ok := session.Values["user_id"].(int)
+	userID, ok := h.userID(r)
 	if !ok {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "No autorizado"})
+		writeError(w, http.StatusUnauthorized, "No autorizado")
 		return
 	}
 	if r.Method != http.MethodPost {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Método no permitido"})
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
 		return
 	}
-	title := r.FormValue("title")
-	done := r.FormValue("done")
+	var req taskRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	fields, errs := req.parse(r.Context(), h.Db, int64(userID))
 	task := &models.Task{
-		Title:  title,
-		Done:   null.Bool{Bool: done == "on" || done == "true", Valid: true},
-		ID:     generateUniqueID(),
-		UserID: null.Int64From(int64(userID)),
+		Title:       req.Title,
+		Done:        null.BoolFrom(req.Done),
+		UserID:      null.Int64From(int64(userID)),
+		DueAt:       fields.DueAt,
+		Priority:    fields.Priority,
+		ProjectID:   fields.ProjectID,
+		Description: fields.Description,
+	}
+	errs.check("recurrence", recurrence.Apply(task, req.Recurrence.Value, time.Local))
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
 	}
 	err := task.Insert(r.Context(), h.Db, boil.Infer())
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error insertando tarea: " + err.Error()})
+		writeError(w, http.StatusInternalServerError, "Error insertando tarea: "+err.Error())
 		return
 	}
-	writeJSON(w, http.StatusCreated, map[string]interface{}{"message": "Tarea creada", "task": task})
-}
-
-func (h *WebHandler) ApiDeleteTask(w http.ResponseWriter, r *http.Request) {
-	// 1. Parsear el formulario primero (esto lee r.Body)
-	if err := r.ParseForm(); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Formulario inválido"})
+	if err := setTaskTags(r.Context(), h.Db, task, fields.Tags); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error guardando etiquetas: "+err.Error())
 		return
 	}
+	if err := loadVersion(r.Context(), h.Db, task); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error leyendo la versión de la tarea")
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"message": "Tarea creada", "task": newAPITask(task)})
+}
 
-	// 2. Ahora podemos diagnosticar y usar FormValue
-	fmt.Printf("Delete Task - Form values: %v\n", r.Form)
-	id := r.FormValue("id")
-	fmt.Printf("Delete Task - ID: %s\n", id)
-
-	// 3. Verificación de sesión (después del parseo)
-	session, _ := h.Store.Get(r, "session")
-	userID, ok := session.Values["user_id"].(int)
+func (h *WebHandler) ApiDeleteTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
 	if !ok {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "No autorizado"})
+		writeError(w, http.StatusUnauthorized, "No autorizado")
 		return
 	}
-
-	// 4. Verificación del método HTTP
 	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Método no permitido"})
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
 		return
 	}
-
-	// Resto del handler...
-	intID, err := strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	var req idRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	errs := fieldErrors{}
+	id := taskID(r, req.ID, errs)
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
 		return
 	}
 
-	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(intID))
+	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(id))
 	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
-		writeJSON(w, http.StatusForbidden, map[string]string{"error": "No autorizado"})
+		writeError(w, http.StatusForbidden, "No autorizado")
+		return
+	}
+	if !checkIfMatch(w, r, task) {
 		return
 	}
 
-	_, err = task.Delete(r.Context(), h.Db)
+	// Solo se borra si nadie la ha cambiado desde que se leyó
+	n, err := models.Tasks(models.TaskWhere.ID.EQ(task.ID), models.TaskWhere.Version.EQ(task.Version)).DeleteAll(r.Context(), h.Db)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error eliminando tarea: " + err.Error()})
+		writeError(w, http.StatusInternalServerError, "Error eliminando tarea: "+err.Error())
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusPreconditionFailed, errVersionConflict.Error())
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]string{"message": "Tarea eliminada"})
 }
 
+// ApiGetTask devuelve una tarea con su ETag y la fecha de su último cambio en
+// Last-Modified. Con If-None-Match o If-Modified-Since responde 304 si no ha
+// cambiado.
+func (h *WebHandler) ApiGetTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+	intID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	task, err := models.Tasks(
+		models.TaskWhere.ID.EQ(null.Int64From(intID)),
+		taskquery.WithTags(),
+		taskquery.WithSubtasks(),
+	).One(r.Context(), h.Db)
+	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
+		writeError(w, http.StatusForbidden, "No autorizado")
+		return
+	}
+	writeConditional(w, r, taskETag(task), task.UpdatedAt, map[string]interface{}{"task": newAPITask(task)})
+}
+
+// ApiUpdateTask sustituye una tarea por la del cuerpo, que tiene que llevar
+// todos los campos. Para cambiar solo algunos está ApiPatchTask. Como PATCH y
+// DELETE, exige If-Match con el ETag de la tarea.
 func (h *WebHandler) ApiUpdateTask(w http.ResponseWriter, r *http.Request) {
-	session, _ := h.Store.Get(r, "session")
-	userID, ok := session.Values["user_id"].(int)
+	userID, ok := h.userID(r)
 	if !ok {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "No autorizado"})
+		writeError(w, http.StatusUnauthorized, "No autorizado")
 		return
 	}
 	if r.Method != http.MethodPost && r.Method != http.MethodPut {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Método no permitido"})
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+	var req replaceTaskRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	errs := fieldErrors{}
+	id := taskID(r, req.ID, errs)
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(id))
+	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
+		writeError(w, http.StatusForbidden, "No autorizado")
+		return
+	}
+	if !checkIfMatch(w, r, task) {
+		return
+	}
+	h.updateTask(w, r, task, &req.taskPatch)
+}
+
+// ApiPatchTask cambia solo los campos de la tarea que incluye el cuerpo:
+// un JSON Merge Patch (RFC 7396) o un objeto JSON, un JSON Patch (RFC 6902)
+// sobre la tarea tal y como la devuelve la API, o un formulario.
+func (h *WebHandler) ApiPatchTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	if r.Method != http.MethodPatch {
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
 		return
 	}
-	id := r.FormValue("id")
-	title := r.FormValue("title")
-	done := r.FormValue("done")
-	intID, err := strconv.ParseInt(id, 10, 64)
+	intID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+		writeError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
 	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(intID))
 	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
-		writeJSON(w, http.StatusForbidden, map[string]string{"error": "No autorizado"})
+		writeError(w, http.StatusForbidden, "No autorizado")
+		return
+	}
+	if !checkIfMatch(w, r, task) {
+		return
+	}
+	var patch taskPatch
+	if !h.decodeTaskPatch(w, r, task, &patch) {
+		return
+	}
+	h.updateTask(w, r, task, &patch)
+}
+
+// updateTask guarda los cambios de patch en task y responde con la tarea y,
+// si se ha completado una tarea que se repite, con la siguiente repetición en
+// next.
+func (h *WebHandler) updateTask(w http.ResponseWriter, r *http.Request, task *models.Task, patch *taskPatch) {
+	changes, errs := patch.apply(r.Context(), h.Db, task)
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+	// Completar una tarea que se repite hace varias escrituras: o todas o
+	// ninguna, para no perder la serie
+	var next *models.Task
+	err := h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		var err error
+		next, err = changes.save(r.Context(), exec, h.now())
+		return err
+	})
+	if errors.Is(err, errVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err.Error())
 		return
 	}
-	task.Title = title
-	task.Done = null.Bool{Bool: done == "on" || done == "true", Valid: true}
-	_, err = task.Update(r.Context(), h.Db, boil.Infer())
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error actualizando tarea: " + err.Error()})
+		writeError(w, http.StatusInternalServerError, "Error "+err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Tarea actualizada", "task": task})
+	if !patch.Tags.Set {
+		if err := task.L.LoadTags(r.Context(), h.Db, true, task, qm.OrderBy(models.TagColumns.Name)); err != nil {
+			writeError(w, http.StatusInternalServerError, "Error obteniendo etiquetas")
+			return
+		}
+	}
+	response := map[string]interface{}{"message": "Tarea actualizada", "task": newAPITask(task)}
+	if next != nil {
+		if err := next.L.LoadTags(r.Context(), h.Db, true, next, qm.OrderBy(models.TagColumns.Name)); err != nil {
+			writeError(w, http.StatusInternalServerError, "Error obteniendo etiquetas")
+			return
+		}
+		response["next"] = newAPITask(next)
+	}
+	w.Header().Set("ETag", taskETag(task))
+	writeJSON(w, http.StatusOK, response)
 }
 
+// ApiSkipTask salta la repetición actual de una tarea que se repite: pasa a
+// la fecha de la siguiente sin completarse.
+func (h *WebHandler) ApiSkipTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	intID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(intID))
+	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
+		writeError(w, http.StatusForbidden, "No autorizado")
+		return
+	}
+	err = h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		return recurrence.Skip(r.Context(), exec, task, h.now())
+	})
+	switch {
+	case errors.Is(err, recurrence.ErrNotRecurring):
+		writeErrorCode(w, http.StatusConflict, codeNotRecurring, err.Error())
+		return
+	case errors.Is(err, recurrence.ErrSeriesEnded):
+		writeErrorCode(w, http.StatusConflict, codeSeriesEnded, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "Error saltando la repetición: "+err.Error())
+		return
+	}
+	if err := task.L.LoadTags(r.Context(), h.Db, true, task, qm.OrderBy(models.TagColumns.Name)); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo etiquetas")
+		return
+	}
+	if err := loadVersion(r.Context(), h.Db, task); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error leyendo la versión de la tarea")
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Repetición saltada", "task": newAPITask(task)})
+}
+
+// ApiListTasks lista las tareas del usuario con los filtros, el orden y la
+// paginación de taskquery.ParseFilter.
 func (h *WebHandler) ApiListTasks(w http.ResponseWriter, r *http.Request) {
-	session, _ := h.Store.Get(r, "session")
-	userID, ok := session.Values["user_id"].(int)
+	userID, ok := h.userID(r)
 	if !ok {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "No autorizado"})
+		writeError(w, http.StatusUnauthorized, "No autorizado")
 		return
 	}
 	if r.Method != http.MethodGet {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Método no permitido"})
+		writeError(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+	f, err := taskquery.ParseFilter(r.URL.Query())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+	f.UserID = int64(userID)
+	h.writeTaskPage(w, r, f)
+}
+
+// writeFilterError responde 400 con el error de los parámetros de un listado.
+func writeFilterError(w http.ResponseWriter, err error) {
+	code := codeBadRequest
+	if errors.Is(err, taskquery.ErrInvalidCursor) {
+		code = codeInvalidCursor
+	}
+	writeErrorCode(w, http.StatusBadRequest, code, err.Error())
+}
+
+// writeTaskPage responde con una página de las tareas de f (sin subtareas
+// sueltas): las tareas, next_cursor para pedir la siguiente (null si es la
+// última) y el total de tareas en la cabecera X-Total-Count. Admite GET
+// condicionales: el ETag sale del contenido y Last-Modified es el último
+// cambio en cualquier tarea del usuario.
+func (h *WebHandler) writeTaskPage(w http.ResponseWriter, r *http.Request, f taskquery.Filter) {
+	f.TopLevel = true
+	if f.Limit == 0 {
+		f.Limit = taskquery.DefaultLimit
+	}
+	page, err := f.Page(r.Context(), h.Db, taskquery.WithTags(), taskquery.WithSubtasks())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo tareas")
+		return
+	}
+	tasks := make([]apiTask, 0, len(page.Tasks))
+	for _, t := range page.Tasks {
+		tasks = append(tasks, newAPITask(t))
+	}
+	var next interface{}
+	if page.Next != "" {
+		next = page.Next
+	}
+	var modified null.Time
+	user, err := models.FindUser(r.Context(), h.Db, null.Int64From(f.UserID), models.UserColumns.TasksUpdatedAt)
+	switch {
+	case err == nil:
+		modified = user.TasksUpdatedAt
+	case !errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusInternalServerError, "Error obteniendo tareas")
 		return
 	}
-	dbTasks, err := models.Tasks(models.TaskWhere.UserID.EQ(null.Int64From(int64(userID)))).All(r.Context(), h.Db)
+	payload := map[string]interface{}{"tasks": tasks, "next_cursor": next}
+	body, err := json.Marshal(payload)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error obteniendo tareas"})
+		writeError(w, http.StatusInternalServerError, "Error obteniendo tareas")
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": dbTasks})
+	total := strconv.FormatInt(page.Total, 10)
+	w.Header().Set("X-Total-Count", total)
+	writeConditional(w, r, contentETag(body, []byte(total)), modified, payload)
 }
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/taskquery"
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// apiProject es un proyecto tal y como lo devuelve la API, con sus totales.
+type apiProject struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	project.Counts
+}
+
+func (h *WebHandler) ApiListProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	dbProjects, err := models.Projects(
+		models.ProjectWhere.UserID.EQ(int64(userID)),
+		qm.OrderBy(models.ProjectColumns.Name),
+	).All(r.Context(), h.Db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo proyectos")
+		return
+	}
+	counts, err := project.TaskCounts(r.Context(), h.Db, int64(userID))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo proyectos")
+		return
+	}
+	projects := make([]apiProject, 0, len(dbProjects))
+	for _, p := range dbProjects {
+		projects = append(projects, apiProject{ID: p.ID.Int64, Name: p.Name, Counts: counts[p.ID.Int64]})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"projects": projects, "unassigned": counts[0]})
+}
+
+func (h *WebHandler) ApiGetProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	p, status, err := h.findUserProject(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	counts, err := project.TaskCounts(r.Context(), h.Db, int64(userID))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"project": apiProject{ID: p.ID.Int64, Name: p.Name, Counts: counts[p.ID.Int64]}})
+}
+
+func (h *WebHandler) ApiAddProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	var req nameRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	name, err := project.Normalize(req.Name)
+	if err != nil {
+		writeFieldErrors(w, fieldErrors{"name": err.Error()})
+		return
+	}
+	exists, err := models.Projects(
+		models.ProjectWhere.UserID.EQ(int64(userID)),
+		models.ProjectWhere.Name.EQ(name),
+	).Exists(r.Context(), h.Db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if exists {
+		writeError(w, http.StatusConflict, "El proyecto ya existe")
+		return
+	}
+	p := &models.Project{UserID: int64(userID), Name: name}
+	if err := p.Insert(r.Context(), h.Db, boil.Infer()); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error creando proyecto: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"message": "Proyecto creado", "project": apiProject{ID: p.ID.Int64, Name: p.Name}})
+}
+
+func (h *WebHandler) ApiUpdateProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	p, status, err := h.findUserProject(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	var req nameRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	name, err := project.Normalize(req.Name)
+	if err != nil {
+		writeFieldErrors(w, fieldErrors{"name": err.Error()})
+		return
+	}
+	exists, err := models.Projects(
+		models.ProjectWhere.UserID.EQ(int64(userID)),
+		models.ProjectWhere.Name.EQ(name),
+		models.ProjectWhere.ID.NEQ(p.ID),
+	).Exists(r.Context(), h.Db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if exists {
+		writeError(w, http.StatusConflict, "El proyecto ya existe")
+		return
+	}
+	p.Name = name
+	if _, err := p.Update(r.Context(), h.Db, boil.Whitelist(models.ProjectColumns.Name)); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error actualizando proyecto: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Proyecto actualizado", "project": apiProject{ID: p.ID.Int64, Name: p.Name}})
+}
+
+func (h *WebHandler) ApiDeleteProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	p, status, err := h.findUserProject(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	// Un trigger deja sin proyecto las tareas que tenía
+	if _, err := p.Delete(r.Context(), h.Db); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error eliminando proyecto: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Proyecto eliminado"})
+}
+
+func (h *WebHandler) ApiListProjectTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	p, status, err := h.findUserProject(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	f, err := taskquery.ParseFilter(r.URL.Query())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+	f.UserID = int64(userID)
+	f.Project = taskquery.ProjectFilter{Active: true, ID: p.ID.Int64}
+	h.writeTaskPage(w, r, f)
+}
+
+// findUserProject busca el proyecto del {id} de la ruta comprobando que
+// pertenece al usuario. Si falla devuelve también el código HTTP de la
+// respuesta.
+func (h *WebHandler) findUserProject(r *http.Request, userID int) (*models.Project, int, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("ID inválido")
+	}
+	p, err := models.FindProject(r.Context(), h.Db, null.Int64From(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && p.UserID != int64(userID)) {
+		return nil, http.StatusNotFound, errors.New("Proyecto no encontrado")
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("Error de base de datos")
+	}
+	return p, 0, nil
+}
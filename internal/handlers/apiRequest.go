@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/tasktitle"
+)
+
+// maxRequestBody es el tamaño máximo del cuerpo de una petición a la API.
+const maxRequestBody = 1 << 20
+
+// Mensajes de los errores de validación comunes a todos los campos.
+const (
+	errRequired     = "obligatorio"
+	errUnknownField = "campo desconocido"
+)
+
+// fieldErrors son los errores de validación de una petición, por campo. La API
+// los devuelve como {"errors":{"title":"obligatorio"}}.
+type fieldErrors map[string]string
+
+// add guarda el primer error de field.
+func (e fieldErrors) add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// String une los errores en un texto, para las respuestas que no son JSON.
+func (e fieldErrors) String() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for i, field := range fields {
+		fields[i] = field + ": " + e[field]
+	}
+	return strings.Join(fields, "; ")
+}
+
+// check guarda err, si lo hay, como error de field.
+func (e fieldErrors) check(field string, err error) {
+	if err != nil {
+		e.add(field, err.Error())
+	}
+}
+
+// required guarda errRequired en field si value está vacío.
+func (e fieldErrors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.add(field, errRequired)
+	}
+}
+
+// title guarda el error del título de una tarea en field, errRequired si está
+// vacío, y devuelve el título normalizado.
+func (e fieldErrors) title(field, value string) string {
+	e.required(field, value)
+	title, err := tasktitle.Normalize(value)
+	e.check(field, err)
+	return title
+}
+
+// apiRequest es el cuerpo de una petición a la API. Los campos se leen por su
+// nombre JSON, tanto del JSON como del formulario; validate comprueba los que
+// no dependen de la base de datos.
+type apiRequest interface {
+	validate(errs fieldErrors)
+}
+
+// nameRequest es el cuerpo del alta y la edición de etiquetas y proyectos.
+type nameRequest struct {
+	Name string `json:"name"`
+}
+
+func (req *nameRequest) validate(errs fieldErrors) {
+	errs.required("name", req.Name)
+}
+
+// decodeRequest lee el cuerpo de la petición en req, un puntero a struct, y
+// lo valida. Acepta application/json, formularios y peticiones sin cuerpo. Si
+// no es válido responde con el error y devuelve false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req apiRequest) bool {
+	errs := fieldErrors{}
+	status, err := readRequest(w, r, req, errs)
+	return checkRequest(w, req, errs, status, err)
+}
+
+// checkRequest valida req, ya leído, y responde con el error si lo hay. status
+// y err son los de la lectura del cuerpo.
+func checkRequest(w http.ResponseWriter, req apiRequest, errs fieldErrors, status int, err error) bool {
+	if err != nil {
+		writeError(w, status, err.Error())
+		return false
+	}
+	if len(errs) == 0 {
+		req.validate(errs)
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return false
+	}
+	return true
+}
+
+// readRequest lee el cuerpo según su Content-Type. Los errores de los campos
+// van a errs; los del cuerpo entero se devuelven con su estado.
+func readRequest(w http.ResponseWriter, r *http.Request, req apiRequest, errs fieldErrors) (int, error) {
+	fields := requestFields(req)
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if contentType == "" {
+		// Sin cuerpo, los campos pueden venir en la URL, como en DELETE ?id=
+		if r.ContentLength != 0 {
+			return http.StatusUnsupportedMediaType, errors.New("falta la cabecera Content-Type")
+		}
+		mediaType, err = "application/x-www-form-urlencoded", nil
+	}
+	switch {
+	case err != nil:
+		return http.StatusUnsupportedMediaType, fmt.Errorf("Content-Type inválido %q", contentType)
+	case mediaType == "application/json":
+		return readJSON(r, fields, errs)
+	case mediaType == "application/x-www-form-urlencoded", mediaType == "multipart/form-data":
+		return readForm(r, fields, errs)
+	}
+	return http.StatusUnsupportedMediaType, fmt.Errorf("Content-Type no soportado %q: usa application/json o un formulario", mediaType)
+}
+
+// readJSON lee un objeto JSON. Cada campo se decodifica por separado para
+// informar de todos los errores, incluidos los campos desconocidos.
+func readJSON(r *http.Request, fields map[string]reflect.Value, errs fieldErrors) (int, error) {
+	var body map[string]json.RawMessage
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&body); err != nil {
+		return bodyError(err)
+	}
+	if body == nil {
+		return http.StatusBadRequest, errors.New("el cuerpo debe ser un objeto JSON")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return http.StatusBadRequest, errors.New("el cuerpo debe tener un solo objeto JSON")
+	}
+	decodeFields(body, fields, errs)
+	return 0, nil
+}
+
+// decodeFields guarda en fields los campos de un objeto JSON.
+func decodeFields(body map[string]json.RawMessage, fields map[string]reflect.Value, errs fieldErrors) {
+	for name, raw := range body {
+		field, ok := fields[name]
+		if !ok {
+			errs.add(name, errUnknownField)
+			continue
+		}
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			errs.add(name, "debe ser "+expected(field))
+		}
+	}
+}
+
+// readForm lee un formulario. Los campos pueden venir en el cuerpo o en la
+// URL, pero en el cuerpo no se admiten campos desconocidos.
+func readForm(r *http.Request, fields map[string]reflect.Value, errs fieldErrors) (int, error) {
+	if err := r.ParseMultipartForm(maxRequestBody); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return bodyError(err)
+	}
+	for name := range r.PostForm {
+		if _, ok := fields[name]; !ok {
+			errs.add(name, errUnknownField)
+		}
+	}
+	for name, field := range fields {
+		values, ok := r.Form[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if err := setFormField(field, values); err != nil {
+			errs.add(name, "debe ser "+expected(field))
+		}
+	}
+	return 0, nil
+}
+
+// bodyError traduce un error leyendo el cuerpo a su estado.
+func bodyError(err error) (int, error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("el cuerpo no puede superar %d bytes", tooLarge.Limit)
+	}
+	if errors.Is(err, io.EOF) {
+		return http.StatusBadRequest, errors.New("el cuerpo está vacío")
+	}
+	return http.StatusBadRequest, fmt.Errorf("cuerpo inválido: %v", err)
+}
+
+// requestFields devuelve los campos de req por su nombre JSON.
+func requestFields(req apiRequest) map[string]reflect.Value {
+	fields := map[string]reflect.Value{}
+	addFields(fields, reflect.ValueOf(req).Elem())
+	return fields
+}
+
+// addFields añade los campos de v, incluidos los de los structs embebidos.
+func addFields(fields map[string]reflect.Value, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			addFields(fields, v.Field(i))
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = v.Field(i)
+		}
+	}
+}
+
+// formField es un campo que sabe leerse de un formulario.
+type formField interface {
+	setForm(values []string) error
+}
+
+// setFormField guarda en field los valores del formulario.
+func setFormField(field reflect.Value, values []string) error {
+	if f, ok := field.Addr().Interface().(formField); ok {
+		return f.setForm(values)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(values[0])
+	case reflect.Bool:
+		b, err := parseFormBool(values[0])
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(values[0]), 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	default:
+		return fmt.Errorf("campo de tipo %s", field.Type())
+	}
+	return nil
+}
+
+// parseFormBool interpreta un checkbox: "on" si está marcado y vacío o
+// ausente si no.
+func parseFormBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "on", "true", "1":
+		return true, nil
+	case "", "off", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("booleano inválido %q", s)
+}
+
+// expected describe el tipo JSON de field para los errores de tipo.
+func expected(field reflect.Value) string {
+	if f, ok := field.Addr().Interface().(interface{ jsonType() string }); ok {
+		return f.jsonType()
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		return "un booleano"
+	case reflect.Int64:
+		return "un entero"
+	case reflect.Slice:
+		return "una lista"
+	}
+	return "un texto"
+}
+
+// optionalText es un campo de texto que solo se cambia si la petición lo
+// incluye. En JSON admite también números, como el ID de un proyecto o el
+// nivel de prioridad, y null, que equivale a vacío.
+type optionalText struct {
+	Set   bool
+	Value string
+}
+
+func (t *optionalText) UnmarshalJSON(data []byte) error {
+	switch {
+	case string(data) == "null":
+		t.Value = ""
+	case len(data) > 0 && data[0] == '"':
+		if err := json.Unmarshal(data, &t.Value); err != nil {
+			return err
+		}
+	default:
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		t.Value = n.String()
+	}
+	t.Set = true
+	return nil
+}
+
+func (t *optionalText) setForm(values []string) error {
+	t.Set, t.Value = true, values[0]
+	return nil
+}
+
+func (t *optionalText) jsonType() string { return "un texto o un número" }
+
+// optionalBool es un campo booleano que solo se cambia si la petición lo
+// incluye.
+type optionalBool struct {
+	Set   bool
+	Value bool
+}
+
+func (b *optionalBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.Value); err != nil {
+		return err
+	}
+	b.Set = true
+	return nil
+}
+
+func (b *optionalBool) setForm(values []string) error {
+	v, err := parseFormBool(values[0])
+	if err != nil {
+		return err
+	}
+	b.Set, b.Value = true, v
+	return nil
+}
+
+func (b *optionalBool) jsonType() string { return "un booleano" }
+
+// optionalList es una lista, como las etiquetas de una tarea, que solo se
+// cambia si la petición la incluye. Admite un array, un texto separado por
+// comas como en los formularios y null, que equivale a la lista vacía.
+type optionalList struct {
+	Set   bool
+	Value string
+}
+
+func (l *optionalList) UnmarshalJSON(data []byte) error {
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		items = []string{text}
+	}
+	l.Set, l.Value = true, strings.Join(items, ",")
+	return nil
+}
+
+func (l *optionalList) setForm(values []string) error {
+	l.Set, l.Value = true, strings.Join(values, ",")
+	return nil
+}
+
+func (l *optionalList) jsonType() string { return "una lista de textos" }
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/volatiletech/null/v8"
+)
+
+// apiTask es una tarea tal y como la devuelve la API. Tiene siempre los mismos
+// campos, aunque cambie el modelo: los que no tienen valor van a null, los
+// booleanos siempre están y las fechas van en RFC 3339 y UTC. Progress solo
+// tiene valor si la tarea tiene subtareas. ETag es el valor para If-Match.
+type apiTask struct {
+	ID          int64             `json:"id"`
+	ETag        string            `json:"etag"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Done        bool              `json:"done"`
+	DueAt       *string           `json:"due_at"`
+	Priority    int64             `json:"priority"`
+	ProjectID   *int64            `json:"project_id"`
+	ParentID    *int64            `json:"parent_id"`
+	Recurrence  *string           `json:"recurrence"`
+	Tags        []string          `json:"tags"`
+	Progress    *subtask.Progress `json:"progress"`
+}
+
+func newAPITask(t *models.Task) apiTask {
+	out := apiTask{
+		ID:          t.ID.Int64,
+		ETag:        taskETag(t),
+		Title:       t.Title,
+		Description: t.Description,
+		Done:        t.Done.Bool,
+		DueAt:       timestamp(t.DueAt),
+		Priority:    t.Priority,
+		ProjectID:   t.ProjectID.Ptr(),
+		ParentID:    t.ParentID.Ptr(),
+		Recurrence:  t.Recurrence.Ptr(),
+		Tags:        tag.Names(t.R.GetTags()),
+	}
+	if p := subtask.Of(t.R.GetParentTasks()); p.Total > 0 {
+		out.Progress = &p
+	}
+	return out
+}
+
+// apiUser es un usuario tal y como lo devuelve la API.
+type apiUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// timestamp da formato RFC 3339 en UTC a t, o devuelve nil si no tiene valor.
+func timestamp(t null.Time) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.UTC().Format(time.RFC3339)
+	return &s
+}
+
+// apiError es el error de las respuestas de la API: {"error":{"code":...,
+// "message":...}}. Code es estable para los programas; Message, el texto para
+// las personas.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Códigos de error de la API.
+const (
+	codeBadRequest           = "bad_request"
+	codeValidation           = "validation_failed"
+	codeUnauthorized         = "unauthorized"
+	codeInvalidCredentials   = "invalid_credentials"
+	codeInvalidToken         = "invalid_token"
+	codeInsufficientScope    = "insufficient_scope"
+	codeOTPRequired          = "otp_required"
+	codeInvalidOTP           = "invalid_otp"
+	codeTooManyAttempts      = "too_many_attempts"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeConflict             = "conflict"
+	codePreconditionFailed   = "precondition_failed"
+	codePreconditionRequired = "precondition_required"
+	codeUsernameTaken        = "username_taken"
+	codeNotRecurring         = "not_recurring"
+	codeSeriesEnded          = "series_ended"
+	codeInvalidCursor        = "invalid_cursor"
+	codeBulkFailed           = "bulk_failed"
+	codeRolledBack           = "rolled_back"
+	codeTooLarge             = "payload_too_large"
+	codeUnsupportedMedia     = "unsupported_media_type"
+	codeInternal             = "internal_error"
+)
+
+// statusCodes es el código por defecto de cada estado HTTP de error.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            codeBadRequest,
+	http.StatusUnauthorized:          codeUnauthorized,
+	http.StatusForbidden:             codeForbidden,
+	http.StatusNotFound:              codeNotFound,
+	http.StatusMethodNotAllowed:      codeMethodNotAllowed,
+	http.StatusConflict:              codeConflict,
+	http.StatusPreconditionFailed:    codePreconditionFailed,
+	http.StatusPreconditionRequired:  codePreconditionRequired,
+	http.StatusRequestEntityTooLarge: codeTooLarge,
+	http.StatusUnsupportedMediaType:  codeUnsupportedMedia,
+	http.StatusTooManyRequests:       codeTooManyAttempts,
+	http.StatusInternalServerError:   codeInternal,
+}
+
+// writeError responde con un error con el código por defecto de status.
+func writeError(w http.ResponseWriter, status int, message string) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = codeBadRequest
+	}
+	writeErrorCode(w, status, code, message)
+}
+
+// writeErrorCode responde con un error con un código propio.
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": apiError{Code: code, Message: message}})
+}
+
+// writeFieldErrors responde 400 con los errores de validación, que van por
+// campo en errors.
+func writeFieldErrors(w http.ResponseWriter, errs fieldErrors) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":  apiError{Code: codeValidation, Message: "La petición tiene campos inválidos"},
+		"errors": errs,
+	})
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+// subtaskRequest es el cuerpo del alta de subtareas.
+type subtaskRequest struct {
+	Title string `json:"title"`
+}
+
+func (req *subtaskRequest) validate(errs fieldErrors) {
+	req.Title = errs.title("title", req.Title)
+}
+
+// updateSubtaskRequest es el cuerpo de la edición de subtareas: solo cambia
+// lo que incluye.
+type updateSubtaskRequest struct {
+	Title optionalText `json:"title"`
+	Done  optionalBool `json:"done"`
+}
+
+func (req *updateSubtaskRequest) validate(errs fieldErrors) {
+	if req.Title.Set {
+		req.Title.Value = errs.title("title", req.Title.Value)
+	}
+}
+
+func (h *WebHandler) ApiListSubtasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	parent, status, err := h.findSubtaskParent(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if err := subtask.Load(r.Context(), h.Db, parent); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo subtareas")
+		return
+	}
+	children := parent.R.GetParentTasks()
+	subtasks := make([]apiTask, 0, len(children))
+	for _, t := range children {
+		subtasks = append(subtasks, newAPITask(t))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"subtasks": subtasks, "progress": subtask.Of(children)})
+}
+
+func (h *WebHandler) ApiAddSubtask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	parent, status, err := h.findSubtaskParent(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	var req subtaskRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	child, err := subtask.Add(r.Context(), h.Db, parent, strings.TrimSpace(req.Title))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error creando subtarea: "+err.Error())
+		return
+	}
+	if err := subtask.Load(r.Context(), h.Db, parent); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo subtareas")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":  "Subtarea creada",
+		"subtask":  newAPITask(child),
+		"progress": subtask.Of(parent.R.GetParentTasks()),
+	})
+}
+
+// ApiUpdateSubtask cambia el estado (done) o el título de una subtarea. Si
+// con el cambio quedan hechas todas las subtareas de una tarea pendiente, la
+// respuesta lo indica en suggest_complete_parent para que el cliente proponga
+// completarla.
+func (h *WebHandler) ApiUpdateSubtask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	parent, child, status, err := h.findSubtask(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	var req updateSubtaskRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	var columns []string
+	if req.Title.Set {
+		child.Title = strings.TrimSpace(req.Title.Value)
+		columns = append(columns, models.TaskColumns.Title)
+	}
+	if req.Done.Set {
+		child.Done = null.BoolFrom(req.Done.Value)
+		columns = append(columns, models.TaskColumns.Done)
+	}
+	if len(columns) == 0 {
+		writeError(w, http.StatusBadRequest, "Indica done o title")
+		return
+	}
+	if _, err := child.Update(r.Context(), h.Db, boil.Whitelist(columns...)); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error actualizando subtarea: "+err.Error())
+		return
+	}
+	if err := subtask.Load(r.Context(), h.Db, parent); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo subtareas")
+		return
+	}
+	progress := subtask.Of(parent.R.GetParentTasks())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":                 "Subtarea actualizada",
+		"subtask":                 newAPITask(child),
+		"progress":                progress,
+		"suggest_complete_parent": !parent.Done.Bool && progress.Complete(),
+	})
+}
+
+func (h *WebHandler) ApiDeleteSubtask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	parent, child, status, err := h.findSubtask(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if _, err := child.Delete(r.Context(), h.Db); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error eliminando subtarea: "+err.Error())
+		return
+	}
+	if err := subtask.Load(r.Context(), h.Db, parent); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo subtareas")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Subtarea eliminada", "progress": subtask.Of(parent.R.GetParentTasks())})
+}
+
+// findSubtaskParent busca la tarea del {id} de la ruta comprobando que
+// pertenece al usuario y que no es a su vez una subtarea. Si falla devuelve
+// también el código HTTP de la respuesta.
+func (h *WebHandler) findSubtaskParent(r *http.Request, userID int) (*models.Task, int, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("ID inválido")
+	}
+	parent, err := subtask.FindParent(r.Context(), h.Db, int64(userID), id)
+	switch {
+	case errors.Is(err, subtask.ErrNotFound):
+		return nil, http.StatusNotFound, errors.New("Tarea no encontrada")
+	case errors.Is(err, subtask.ErrNested):
+		return nil, http.StatusBadRequest, errors.New("Una subtarea no puede tener subtareas")
+	case err != nil:
+		return nil, http.StatusInternalServerError, errors.New("Error de base de datos")
+	}
+	return parent, 0, nil
+}
+
+// findSubtask busca la tarea del {id} de la ruta y su subtarea {subtask_id}.
+func (h *WebHandler) findSubtask(r *http.Request, userID int) (*models.Task, *models.Task, int, error) {
+	parent, status, err := h.findSubtaskParent(r, userID)
+	if err != nil {
+		return nil, nil, status, err
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["subtask_id"], 10, 64)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, errors.New("ID inválido")
+	}
+	child, err := models.FindTask(r.Context(), h.Db, null.Int64From(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && child.ParentID != parent.ID) {
+		return nil, nil, http.StatusNotFound, errors.New("Subtarea no encontrada")
+	}
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.New("Error de base de datos")
+	}
+	return parent, child, 0, nil
+}
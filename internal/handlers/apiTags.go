@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// apiTag es una etiqueta tal y como la devuelve la API, con el número de
+// tareas que la llevan.
+type apiTag struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Tasks int64  `json:"tasks"`
+}
+
+func (h *WebHandler) ApiListTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	dbTags, err := models.Tags(
+		models.TagWhere.UserID.EQ(int64(userID)),
+		qm.OrderBy(models.TagColumns.Name),
+	).All(r.Context(), h.Db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo etiquetas")
+		return
+	}
+	tags := make([]apiTag, 0, len(dbTags))
+	for _, t := range dbTags {
+		count, err := t.Tasks().Count(r.Context(), h.Db)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error obteniendo etiquetas")
+			return
+		}
+		tags = append(tags, apiTag{ID: t.ID.Int64, Name: t.Name, Tasks: count})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tags": tags})
+}
+
+func (h *WebHandler) ApiAddTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	var req nameRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	name, err := tag.Normalize(req.Name)
+	if err != nil {
+		writeFieldErrors(w, fieldErrors{"name": err.Error()})
+		return
+	}
+	exists, err := models.Tags(
+		models.TagWhere.UserID.EQ(int64(userID)),
+		models.TagWhere.Name.EQ(name),
+	).Exists(r.Context(), h.Db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if exists {
+		writeError(w, http.StatusConflict, "La etiqueta ya existe")
+		return
+	}
+	t := &models.Tag{UserID: int64(userID), Name: name}
+	if err := t.Insert(r.Context(), h.Db, boil.Infer()); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error creando etiqueta: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"message": "Etiqueta creada", "tag": apiTag{ID: t.ID.Int64, Name: t.Name}})
+}
+
+func (h *WebHandler) ApiUpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	t, status, err := h.findUserTag(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	var req nameRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	name, err := tag.Normalize(req.Name)
+	if err != nil {
+		writeFieldErrors(w, fieldErrors{"name": err.Error()})
+		return
+	}
+	exists, err := models.Tags(
+		models.TagWhere.UserID.EQ(int64(userID)),
+		models.TagWhere.Name.EQ(name),
+		models.TagWhere.ID.NEQ(t.ID),
+	).Exists(r.Context(), h.Db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if exists {
+		writeError(w, http.StatusConflict, "La etiqueta ya existe")
+		return
+	}
+	t.Name = name
+	if _, err := t.Update(r.Context(), h.Db, boil.Whitelist(models.TagColumns.Name)); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error actualizando etiqueta: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Etiqueta actualizada", "tag": apiTag{ID: t.ID.Int64, Name: t.Name}})
+}
+
+func (h *WebHandler) ApiDeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	t, status, err := h.findUserTag(r, userID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	// Un trigger quita la etiqueta de las tareas que la llevaban
+	if _, err := t.Delete(r.Context(), h.Db); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error eliminando etiqueta: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Etiqueta eliminada"})
+}
+
+// findUserTag busca la etiqueta del {id} de la ruta comprobando que pertenece
+// al usuario. Si falla devuelve también el código HTTP de la respuesta.
+func (h *WebHandler) findUserTag(r *http.Request, userID int) (*models.Tag, int, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("ID inválido")
+	}
+	t, err := models.FindTag(r.Context(), h.Db, null.Int64From(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && t.UserID != int64(userID)) {
+		return nil, http.StatusNotFound, errors.New("Etiqueta no encontrada")
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("Error de base de datos")
+	}
+	return t, 0, nil
+}
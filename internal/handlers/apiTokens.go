@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/apitoken"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/null/v8"
+)
+
+// contextKey son las claves de los valores que los middlewares guardan en el
+// contexto de la petición.
+type contextKey int
+
+// tokenKey guarda el token personal con el que se ha autenticado la petición.
+const tokenKey contextKey = iota
+
+// requestToken devuelve el token personal con el que se ha autenticado r, o
+// nil si usa la sesión.
+func requestToken(r *http.Request) *models.APIToken {
+	t, _ := r.Context().Value(tokenKey).(*models.APIToken)
+	return t
+}
+
+// userID devuelve el usuario de una petición a la API: el del token personal
+// con el que la ha autenticado TokenAuth o, si no lleva token, el de la
+// sesión.
+func (h *WebHandler) userID(r *http.Request) (int, bool) {
+	if t := requestToken(r); t != nil {
+		return int(t.UserID), true
+	}
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	return userID, ok
+}
+
+// TokenAuth autentica las peticiones a la API que llevan un token personal en
+// Authorization: Bearer. El token tiene que tener tasks:read para los GET y
+// tasks:write para el resto. Las peticiones sin Authorization siguen con la
+// sesión.
+func (h *WebHandler) TokenAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		scheme, secret, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			writeTokenError(w, http.StatusUnauthorized, codeInvalidToken, "", "La API solo acepta tokens Bearer")
+			return
+		}
+		t, err := apitoken.Authenticate(r.Context(), h.Db, strings.TrimSpace(secret), h.now())
+		if errors.Is(err, apitoken.ErrInvalid) {
+			writeTokenError(w, http.StatusUnauthorized, codeInvalidToken, "", "Token inválido o caducado")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error comprobando el token")
+			return
+		}
+		scope := apitoken.ScopeTasksWrite
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			scope = apitoken.ScopeTasksRead
+		}
+		if !apitoken.Allows(t, scope) {
+			writeTokenError(w, http.StatusForbidden, codeInsufficientScope, scope, "El token no tiene el alcance "+scope)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey, t)))
+	})
+}
+
+// writeTokenError responde con un error de token y la cabecera
+// WWW-Authenticate de RFC 6750.
+func writeTokenError(w http.ResponseWriter, status int, code, scope, message string) {
+	challenge := `Bearer error="` + code + `"`
+	if scope != "" {
+		challenge += `, scope="` + scope + `"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	writeErrorCode(w, status, code, message)
+}
+
+// apiToken es un token personal tal y como lo devuelve la API, sin el
+// secreto.
+type apiToken struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  *string  `json:"created_at"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+}
+
+func newAPIToken(t *models.APIToken) apiToken {
+	return apiToken{
+		ID:         t.ID.Int64,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     apitoken.ScopeList(t),
+		CreatedAt:  timestamp(null.TimeFrom(t.CreatedAt)),
+		ExpiresAt:  timestamp(t.ExpiresAt),
+		LastUsedAt: timestamp(t.LastUsedAt),
+	}
+}
+
+// tokenRequest es el cuerpo de POST /api/tokens. ExpiresAt es una fecha o un
+// número de días, como "30d"; vacío, el token no caduca.
+type tokenRequest struct {
+	Name      string       `json:"name"`
+	Scopes    optionalList `json:"scopes"`
+	ExpiresAt string       `json:"expires_at"`
+}
+
+func (req *tokenRequest) validate(errs fieldErrors) {
+	errs.required("name", req.Name)
+	if !req.Scopes.Set {
+		errs.add("scopes", errRequired)
+	}
+}
+
+// tokenUser devuelve el usuario de una petición que gestiona tokens, que solo
+// se puede hacer con la sesión: un token no puede crear ni revocar tokens. Si
+// no hay sesión responde con el error y devuelve false.
+func (h *WebHandler) tokenUser(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if requestToken(r) != nil {
+		writeError(w, http.StatusForbidden, "Los tokens solo se gestionan con la sesión iniciada")
+		return 0, false
+	}
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return 0, false
+	}
+	return int64(userID), true
+}
+
+// ApiListTokens lista los tokens personales del usuario.
+func (h *WebHandler) ApiListTokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.tokenUser(w, r)
+	if !ok {
+		return
+	}
+	tokens, err := apitoken.List(r.Context(), h.Db, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error obteniendo tokens")
+		return
+	}
+	out := make([]apiToken, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, newAPIToken(t))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tokens": out})
+}
+
+// ApiCreateToken crea un token personal. La respuesta es la única vez que se
+// devuelve su secreto.
+func (h *WebHandler) ApiCreateToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.tokenUser(w, r)
+	if !ok {
+		return
+	}
+	var req tokenRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	name, scopes, expiresAt, errs := h.parseToken(req.Name, req.Scopes.Value, req.ExpiresAt)
+	if len(errs) > 0 {
+		writeFieldErrors(w, errs)
+		return
+	}
+	t, secret, err := apitoken.Create(r.Context(), h.Db, userID, name, scopes, expiresAt)
+	if errors.Is(err, apitoken.ErrNameTaken) {
+		writeError(w, http.StatusConflict, "Ya existe un token con ese nombre")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error creando el token: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"message": "Token creado", "token": newAPIToken(t), "secret": secret})
+}
+
+// parseToken valida los campos de un token nuevo.
+func (h *WebHandler) parseToken(name, scopes, expires string) (string, []string, null.Time, fieldErrors) {
+	errs := fieldErrors{}
+	name, err := apitoken.NormalizeName(name)
+	errs.check("name", err)
+	list, err := apitoken.ParseScopes(scopes)
+	errs.check("scopes", err)
+	expiresAt, err := apitoken.ParseExpiry(expires, time.Local, h.now())
+	errs.check("expires_at", err)
+	return name, list, expiresAt, errs
+}
+
+// ApiRevokeToken revoca un token personal del usuario.
+func (h *WebHandler) ApiRevokeToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.tokenUser(w, r)
+	if !ok {
+		return
+	}
+	_, err := apitoken.Revoke(r.Context(), h.Db, userID, mux.Vars(r)["id"])
+	if errors.Is(err, apitoken.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error revocando el token")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Token revocado"})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// errUsernameTaken es el error del registro con un nombre que ya existe.
+var errUsernameTaken = errors.New("Usuario ya existe")
+
+func (h *WebHandler) credentialsPolicy() credentials.Policy {
+	if h.Credentials != nil {
+		return *h.Credentials
+	}
+	return credentials.DefaultPolicy
+}
+
+// checkCredentials comprueba el nombre, ya normalizado, y la contraseña de un
+// registro. Si no se puede leer la lista de contraseñas filtradas se deja
+// pasar la contraseña, para que un fallo del disco no impida registrarse.
+func (h *WebHandler) checkCredentials(username, password string) fieldErrors {
+	policy := h.credentialsPolicy()
+	errs := fieldErrors{}
+	errs.check("username", policy.CheckUsername(username))
+	errs.check("password", policy.CheckPassword(password, username))
+	if _, ok := errs["password"]; !ok {
+		err := policy.CheckBreached(password)
+		if errors.Is(err, credentials.ErrBreached) {
+			errs.add("password", err.Error())
+		} else if err != nil {
+			slog.Error("Error consultando la lista de contraseñas filtradas", "error", err)
+		}
+	}
+	return errs
+}
+
+// createUser da de alta el usuario con la contraseña hasheada según la
+// política. Devuelve errUsernameTaken si el nombre ya existe, sin distinguir
+// mayúsculas.
+func (h *WebHandler) createUser(ctx context.Context, username, password string) (*models.User, error) {
+	taken, err := credentials.UsernameTaken(ctx, h.Db, username)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errUsernameTaken
+	}
+	hash, err := h.credentialsPolicy().Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{Username: username, PasswordHash: hash}
+	if err := user.Insert(ctx, h.Db, boil.Infer()); err != nil {
+		// Otro registro con el mismo nombre se ha adelantado
+		if taken, _ := credentials.UsernameTaken(ctx, h.Db, username); taken {
+			return nil, errUsernameTaken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// authenticate comprueba el nombre y la contraseña de un login. Si no son
+// correctos devuelve un usuario nil y el motivo para el registro de fallos.
+func (h *WebHandler) authenticate(r *http.Request, username, password string) (*models.User, string, error) {
+	user, err := credentials.FindUser(r.Context(), h.Db, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, lockout.ReasonUnknownUser, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, lockout.ReasonPassword, nil
+	}
+	h.rehash(r.Context(), user, password)
+	return user, "", nil
+}
+
+// rehash vuelve a hashear la contraseña si se guardó con un coste de bcrypt
+// menor que el actual. Es el único momento en que se tiene la contraseña; si
+// falla, el login sigue adelante y se intentará en el siguiente.
+func (h *WebHandler) rehash(ctx context.Context, user *models.User, password string) {
+	policy := h.credentialsPolicy()
+	if !policy.NeedsRehash(user.PasswordHash) {
+		return
+	}
+	hash, err := policy.Hash(password)
+	if err == nil {
+		user.PasswordHash = hash
+		_, err = user.Update(ctx, h.Db, boil.Whitelist(models.UserColumns.PasswordHash))
+	}
+	if err != nil {
+		slog.Error("Error rehaciendo el hash de la contraseña", "user_id", user.ID.Int64, "error", err)
+		return
+	}
+	slog.Info("Hash de la contraseña actualizado al nuevo coste de bcrypt", "user_id", user.ID.Int64)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// errVersionConflict indica que la tarea ha cambiado desde que se leyó.
+var errVersionConflict = errors.New("La tarea ha cambiado desde que se leyó")
+
+// taskETag es el ETag de una tarea: su versión, que los triggers de la base de
+// datos suben con cada cambio de la tarea, de sus etiquetas o de sus
+// subtareas.
+func taskETag(t *models.Task) string {
+	return `"` + strconv.FormatInt(t.Version, 10) + `"`
+}
+
+// ifMatch indica si la cabecera If-Match de r incluye etag o es *. Los ETag
+// débiles (W/"...") nunca coinciden, porque If-Match usa la comparación
+// fuerte.
+func ifMatch(r *http.Request, etag string) bool {
+	for _, header := range r.Header.Values("If-Match") {
+		if matchETag(header, etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchETag indica si la lista de ETags de una cabecera If-Match incluye etag
+// o es *, con la comparación fuerte.
+func matchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch comprueba que una petición de la API que cambia task lleva
+// If-Match con su ETag actual. Si no lo lleva responde 428 y, si es otro, 412.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, task *models.Task) bool {
+	if r.Header.Get("If-Match") == "" {
+		writeError(w, http.StatusPreconditionRequired, "Falta la cabecera If-Match con el ETag de la tarea")
+		return false
+	}
+	if !ifMatch(r, taskETag(task)) {
+		writeError(w, http.StatusPreconditionFailed, errVersionConflict.Error())
+		return false
+	}
+	return true
+}
+
+// claimVersion sube la versión de task solo si sigue siendo la que se leyó,
+// en la misma sentencia, para que dos ediciones a la vez no se pisen. Si otra
+// la ha cambiado entretanto devuelve errVersionConflict.
+func claimVersion(ctx context.Context, exec boil.ContextExecutor, task *models.Task) error {
+	n, err := models.Tasks(
+		models.TaskWhere.ID.EQ(task.ID),
+		models.TaskWhere.Version.EQ(task.Version),
+	).UpdateAll(ctx, exec, models.M{models.TaskColumns.Version: task.Version + 1})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errVersionConflict
+	}
+	task.Version++
+	return nil
+}
+
+// loadVersion lee la versión actual de task y la fecha de su último cambio,
+// que los triggers cambian al guardarla.
+func loadVersion(ctx context.Context, exec boil.ContextExecutor, task *models.Task) error {
+	current, err := models.Tasks(
+		qm.Select(models.TaskColumns.Version, models.TaskColumns.UpdatedAt),
+		models.TaskWhere.ID.EQ(task.ID),
+	).One(ctx, exec)
+	if err != nil {
+		return err
+	}
+	task.Version = current.Version
+	task.UpdatedAt = current.UpdatedAt
+	return nil
+}
+
+// contentETag es un ETag calculado a partir del contenido de una respuesta,
+// para las que no tienen versión, como los listados.
+func contentETag(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// notModified indica si el cliente ya tiene la versión de un recurso con etag
+// y fecha de último cambio modified. If-None-Match usa la comparación débil y,
+// si está, manda sobre If-Modified-Since, que solo tiene precisión de segundos.
+func notModified(r *http.Request, etag string, modified null.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || !modified.Valid {
+		return false
+	}
+	return !modified.Time.Truncate(time.Second).After(since)
+}
+
+// writeConditional responde a un GET con payload, su ETag y, si se conoce, la
+// fecha de su último cambio. Si el cliente ya tiene esa versión responde 304
+// sin cuerpo.
+func writeConditional(w http.ResponseWriter, r *http.Request, etag string, modified null.Time, payload interface{}) {
+	// Las respuestas son de cada usuario y hay que revalidarlas siempre
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+	if modified.Valid {
+		w.Header().Set("Last-Modified", modified.Time.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
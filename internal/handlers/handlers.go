@@ -1,18 +1,33 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"html/template"
-	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/JorgeePG/todo-list/internal/lockout"
 	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/JorgeePG/todo-list/internal/sso"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/JorgeePG/todo-list/internal/taskquery"
+	"github.com/JorgeePG/todo-list/internal/tasktitle"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
 	"github.com/gorilla/sessions"
 	"github.com/volatiletech/null/v8"
 	"github.com/volatiletech/sqlboiler/v4/boil"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
 )
 
 type Datos struct {
@@ -21,16 +36,99 @@ type Datos struct {
 }
 
 type PageData struct {
-	Título string
-	Texto  string
-	Tasks  []*models.Task
-	Error  string
+	Título    string
+	Texto     string
+	Tasks     []TaskView
+	Error     string
+	UserTags  models.TagSlice
+	TagFilter []string
+
+	Projects      []ProjectView
+	AllCounts     project.Counts
+	NoneCounts    project.Counts
+	ProjectFilter string
+}
+
+// ProjectView es un proyecto de la barra lateral con sus totales.
+type ProjectView struct {
+	*models.Project
+	project.Counts
+	Active bool
+}
+
+// TaskFormData son los datos de addTask.html.
+type TaskFormData struct {
+	Error     string
+	Projects  models.ProjectSlice
+	ProjectID int64
+}
+
+// TaskView añade a una tarea los datos de presentación que usa index.html.
+type TaskView struct {
+	*models.Task
+	DueLabel      string
+	DueInput      string
+	Overdue       bool
+	PriorityName  string
+	PriorityLabel string
+	Tags          []string
+	TagsInput     string
+	Subtasks      models.TaskSlice
+	Progress      subtask.Progress
+	ETag          string
+
+	RecurrenceLabel string
+}
+
+func newTaskViews(tasks models.TaskSlice, now time.Time) []TaskView {
+	views := make([]TaskView, 0, len(tasks))
+	for _, t := range tasks {
+		names := tag.Names(t.R.GetTags())
+		views = append(views, TaskView{
+			Task:     t,
+			DueLabel: due.Label(t.DueAt, now),
+			DueInput: due.Input(t.DueAt, now.Location()),
+			Overdue:  due.Overdue(t.DueAt, t.Done.Bool, now),
+
+			PriorityName:  priority.Name(t.Priority),
+			PriorityLabel: priority.Label(t.Priority),
+			Tags:          names,
+			TagsInput:     strings.Join(names, ", "),
+			Subtasks:      t.R.GetParentTasks(),
+			Progress:      subtask.Of(t.R.GetParentTasks()),
+			ETag:          taskETag(t),
+
+			RecurrenceLabel: recurrence.Label(t.Recurrence, now.Location()),
+		})
+	}
+	return views
 }
 
 type WebHandler struct {
 	Db        boil.ContextExecutor
 	Templates *template.Template
 	Store     *sessions.CookieStore
+	// Now es el reloj de los vencimientos y las repeticiones; si es nil se
+	// usa time.Now. Los tests lo fijan.
+	Now func() time.Time
+	// SSO es el proveedor OpenID Connect con el que se puede iniciar sesión;
+	// nil si no está configurado.
+	SSO *sso.Provider
+	// Lockout limita los logins fallidos; si es nil se usa
+	// lockout.DefaultPolicy. Con TrustProxy la IP del cliente es la última de
+	// X-Forwarded-For.
+	Lockout    *lockout.Policy
+	TrustProxy bool
+	// Credentials son las reglas de los nombres de usuario y las
+	// contraseñas; si es nil se usa credentials.DefaultPolicy.
+	Credentials *credentials.Policy
+}
+
+func (h *WebHandler) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+	return time.Now()
 }
 
 func (h *WebHandler) Handler(w http.ResponseWriter, r *http.Request) {
@@ -41,16 +139,63 @@ func (h *WebHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbTasks, err := models.Tasks(models.TaskWhere.UserID.EQ(null.Int64From(int64(userID)))).All(r.Context(), h.Db)
+	// Los mismos filtros y orden que /api/tasks, pero sin paginar
+	f, err := taskquery.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.UserID = int64(userID)
+	f.TopLevel = true
+	f.Limit, f.After = 0, nil
+	mods := append(f.Mods(), taskquery.WithTags(), taskquery.WithSubtasks())
+	dbTasks, err := models.Tasks(mods...).All(r.Context(), h.Db)
 	if err != nil {
 		http.Error(w, "Error obteniendo tareas: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	userTags, err := models.Tags(
+		models.TagWhere.UserID.EQ(int64(userID)),
+		qm.OrderBy(models.TagColumns.Name),
+	).All(r.Context(), h.Db)
+	if err != nil {
+		http.Error(w, "Error obteniendo etiquetas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	projects, err := models.Projects(
+		models.ProjectWhere.UserID.EQ(int64(userID)),
+		qm.OrderBy(models.ProjectColumns.Name),
+	).All(r.Context(), h.Db)
+	if err != nil {
+		http.Error(w, "Error obteniendo proyectos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	counts, err := project.TaskCounts(r.Context(), h.Db, int64(userID))
+	if err != nil {
+		http.Error(w, "Error obteniendo proyectos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	data := PageData{
-		Título: "Mi To-Do List",
-		Texto:  "Bienvenido a tu lista de tareas",
-		Tasks:  dbTasks,
+		Título:        "Mi To-Do List",
+		Texto:         "Bienvenido a tu lista de tareas",
+		Tasks:         newTaskViews(dbTasks, h.now()),
+		UserTags:      userTags,
+		TagFilter:     f.Tags.Values(),
+		NoneCounts:    counts[0],
+		ProjectFilter: f.Project.Value(),
+	}
+	for _, c := range counts {
+		data.AllCounts.Tasks += c.Tasks
+		data.AllCounts.Pending += c.Pending
+	}
+	for _, p := range projects {
+		active := f.Project.Active && f.Project.ID == p.ID.Int64
+		data.Projects = append(data.Projects, ProjectView{Project: p, Counts: counts[p.ID.Int64], Active: active})
+		if active {
+			data.Texto = p.Name
+		}
 	}
 
 	err = h.Templates.ExecuteTemplate(w, "index.html", data)
@@ -59,10 +204,6 @@ func (h *WebHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-type ErrorData struct {
-	Error string
-}
-
 func (h *WebHandler) AddTask(w http.ResponseWriter, r *http.Request) {
 	session, _ := h.Store.Get(r, "session")
 	userID, ok := session.Values["user_id"].(int)
@@ -71,37 +212,172 @@ func (h *WebHandler) AddTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == http.MethodPost && r.FormValue("parent_id") != "" {
+		h.addSubtask(w, r, userID)
+		return
+	}
+
 	if r.Method == http.MethodPost {
-		title := r.FormValue("title")
+		title, err := tasktitle.Normalize(r.FormValue("title"))
+		if err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
 		done := r.FormValue("done")
+		dueAt, err := due.Parse(r.FormValue("due_at"), time.Local)
+		if err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
+		level, err := priority.Parse(r.FormValue("priority"))
+		if err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
+		tagNames, err := tag.ParseList(r.FormValue("tags"))
+		if err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
+		projectID, err := project.ResolveID(r.Context(), h.Db, int64(userID), r.FormValue("project_id"))
+		if err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
+		description, err := notes.Normalize(r.FormValue("description"))
+		if err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
 
 		task := &models.Task{
-			Title:  title,
-			Done:   null.Bool{Bool: done == "on", Valid: true},
-			ID:     generateUniqueID(),
-			UserID: null.Int64From(int64(userID)),
+			Title:       title,
+			Done:        null.Bool{Bool: done == "on", Valid: true},
+			UserID:      null.Int64From(int64(userID)),
+			DueAt:       dueAt,
+			Priority:    level,
+			ProjectID:   projectID,
+			Description: description,
 		}
-		err := task.Insert(r.Context(), h.Db, boil.Infer())
+		if err := recurrence.Apply(task, r.FormValue("recurrence"), time.Local); err != nil {
+			h.renderTaskForm(w, r, userID, err.Error())
+			return
+		}
+		err = task.Insert(r.Context(), h.Db, boil.Infer())
 		if err != nil {
-			data := ErrorData{Error: "Error insertando tarea: " + err.Error()}
-			h.Templates.ExecuteTemplate(w, "addTask.html", data)
+			h.renderTaskForm(w, r, userID, "Error insertando tarea: "+err.Error())
+			return
+		}
+		if err := setTaskTags(r.Context(), h.Db, task, tagNames); err != nil {
+			h.renderTaskForm(w, r, userID, "Error guardando etiquetas: "+err.Error())
 			return
 		}
 
+		if projectID.Valid {
+			http.Redirect(w, r, "/?project="+strconv.FormatInt(projectID.Int64, 10), http.StatusSeeOther)
+			return
+		}
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
-	err := h.Templates.ExecuteTemplate(w, "addTask.html", nil)
+	h.renderTaskForm(w, r, userID, "")
+}
+
+// addSubtask crea la subtarea del formulario de una tarea de index.html.
+func (h *WebHandler) addSubtask(w http.ResponseWriter, r *http.Request, userID int) {
+	parentID, err := strconv.ParseInt(r.FormValue("parent_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	title, err := tasktitle.Normalize(r.FormValue("title"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	parent, err := subtask.FindParent(r.Context(), h.Db, int64(userID), parentID)
+	switch {
+	case errors.Is(err, subtask.ErrNotFound):
+		http.Error(w, "No autorizado", http.StatusForbidden)
+		return
+	case errors.Is(err, subtask.ErrNested):
+		http.Error(w, "Una subtarea no puede tener subtareas", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := subtask.Add(r.Context(), h.Db, parent, title); err != nil {
+		http.Error(w, "Error creando subtarea: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if parent.ProjectID.Valid {
+		http.Redirect(w, r, "/?project="+strconv.FormatInt(parent.ProjectID.Int64, 10), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// renderTaskForm muestra addTask.html con los proyectos del usuario. El
+// proyecto preseleccionado es el de project_id o, si no, el de ?project.
+func (h *WebHandler) renderTaskForm(w http.ResponseWriter, r *http.Request, userID int, errMsg string) {
+	projects, err := models.Projects(
+		models.ProjectWhere.UserID.EQ(int64(userID)),
+		qm.OrderBy(models.ProjectColumns.Name),
+	).All(r.Context(), h.Db)
+	if err != nil {
+		http.Error(w, "Error obteniendo proyectos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := TaskFormData{Error: errMsg, Projects: projects}
+	selected := r.FormValue("project_id")
+	if selected == "" {
+		selected = r.URL.Query().Get("project")
+	}
+	data.ProjectID, _ = strconv.ParseInt(selected, 10, 64)
+
+	err = h.Templates.ExecuteTemplate(w, "addTask.html", data)
 	if err != nil {
 		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
 	}
 }
 
-func generateUniqueID() null.Int64 {
-	// Combinamos tiempo en nanosegundos + un número aleatorio para evitar colisiones.
-	uniqueID := time.Now().UnixNano() + rand.Int63n(1000) // rand.Int63n añade entropía
-	return null.Int64From(uniqueID)
+// AddProject crea un proyecto desde la barra lateral y muestra sus tareas.
+func (h *WebHandler) AddProject(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	name, err := project.Normalize(r.FormValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := project.Find(r.Context(), h.Db, int64(userID), name); err == nil {
+		http.Error(w, "El proyecto ya existe", http.StatusConflict)
+		return
+	}
+	p := &models.Project{UserID: int64(userID), Name: name}
+	if err := p.Insert(r.Context(), h.Db, boil.Infer()); err != nil {
+		http.Error(w, "Error creando proyecto: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/?project="+strconv.FormatInt(p.ID.Int64, 10), http.StatusSeeOther)
+}
+
+// setTaskTags sustituye las etiquetas de task por las de nombre names, creando
+// las que el usuario aún no tenga.
+func setTaskTags(ctx context.Context, exec boil.ContextExecutor, task *models.Task, names []string) error {
+	tags, err := tag.Ensure(ctx, exec, task.UserID.Int64, names)
+	if err != nil {
+		return err
+	}
+	return task.SetTags(ctx, exec, false, tags...)
 }
 
 func (h *WebHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
@@ -143,6 +419,42 @@ func (h *WebHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 }
 
+// SkipTask salta la repetición actual de una tarea que se repite.
+func (h *WebHandler) SkipTask(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	intID, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(intID))
+	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
+		http.Error(w, "No autorizado", http.StatusForbidden)
+		return
+	}
+	err = h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		return recurrence.Skip(r.Context(), exec, task, h.now())
+	})
+	switch {
+	case errors.Is(err, recurrence.ErrNotRecurring), errors.Is(err, recurrence.ErrSeriesEnded):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Error saltando la repetición: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// UpdateTask cambia solo los campos de la tarea que incluye el formulario,
+// así que marcar una tarea como hecha no borra su título.
 func (h *WebHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	session, _ := h.Store.Get(r, "session")
 	userID, ok := session.Values["user_id"].(int)
@@ -156,67 +468,100 @@ func (h *WebHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.FormValue("id")
-	title := r.FormValue("title")
-	done := r.FormValue("done")
-
-	intID, err := strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		http.Error(w, "ID inválido: "+err.Error(), http.StatusBadRequest)
+	var req updateTaskForm
+	errs := fieldErrors{}
+	if status, err := readForm(r, requestFields(&req), errs); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if len(errs) == 0 {
+		req.validate(errs)
+	}
+	if len(errs) > 0 {
+		http.Error(w, errs.String(), http.StatusBadRequest)
 		return
 	}
 
-	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(intID))
+	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(req.ID))
 	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
 		http.Error(w, "No autorizado", http.StatusForbidden)
 		return
 	}
+	// main.js envía el ETag de la tarea que muestra; si ha cambiado en otra
+	// pestaña, propone recargar en vez de pisar los cambios
+	if r.Header.Get("If-Match") != "" && !ifMatch(r, taskETag(task)) {
+		http.Error(w, errVersionConflict.Error(), http.StatusPreconditionFailed)
+		return
+	}
 
-	task.Title = title
-	task.Done = null.Bool{Bool: done == "on", Valid: true}
-	_, err = task.Update(r.Context(), h.Db, boil.Infer())
+	changes, errs := req.apply(r.Context(), h.Db, task)
+	if len(errs) > 0 {
+		http.Error(w, errs.String(), http.StatusBadRequest)
+		return
+	}
+	err = h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		_, err := changes.save(r.Context(), exec, h.now())
+		return err
+	})
+	if errors.Is(err, errVersionConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
-		data := ErrorData{Error: "Error actualizando tarea: " + err.Error()}
-		h.Templates.ExecuteTemplate(w, "index.html", data)
+		http.Error(w, "Error "+err.Error(), http.StatusInternalServerError)
 		return
-
 	}
 
+	w.Header().Set("ETag", taskETag(task))
 	w.WriteHeader(http.StatusOK)
 }
 
+// RegisterPageData son los datos de register.html. Errors son los errores de
+// validación por campo y Username, el nombre que se ha escrito, para no
+// tener que volver a escribirlo.
+type RegisterPageData struct {
+	Error    string
+	Username string
+	Errors   map[string]string
+}
+
+func (h *WebHandler) renderRegister(w http.ResponseWriter, status int, data RegisterPageData) {
+	w.WriteHeader(status)
+	err := h.Templates.ExecuteTemplate(w, "register.html", data)
+	if err != nil {
+		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h *WebHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
-		err := h.Templates.ExecuteTemplate(w, "register.html", nil)
-
-		if err != nil {
-			http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+		h.renderRegister(w, http.StatusOK, RegisterPageData{})
 		return
 	}
 
-	username := r.FormValue("username")
+	username := credentials.NormalizeUsername(r.FormValue("username"))
 	password := r.FormValue("password")
-
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-
-	result, err := h.Db.Exec("INSERT INTO users (username, password_hash) VALUES (?, ?)", username, hash)
-	if err != nil {
-		data := ErrorData{Error: "Usuario ya existe"}
-		h.Templates.ExecuteTemplate(w, "register.html", data)
+	if errs := h.checkCredentials(username, password); len(errs) > 0 {
+		h.renderRegister(w, http.StatusBadRequest, RegisterPageData{Username: username, Errors: errs})
 		return
 	}
 
-	userID, err := result.LastInsertId()
+	user, err := h.createUser(r.Context(), username, password)
+	if errors.Is(err, errUsernameTaken) {
+		h.renderRegister(w, http.StatusBadRequest, RegisterPageData{
+			Username: username,
+			Errors:   map[string]string{"username": "Ya existe un usuario con ese nombre"},
+		})
+		return
+	}
 	if err != nil {
-		http.Error(w, "Error al obtener el ID del usuario", http.StatusInternalServerError)
+		http.Error(w, "Error creando el usuario: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Crear sesión automáticamente
 	session, _ := h.Store.Get(r, "session")
-	session.Values["user_id"] = int(userID)
+	session.Values["user_id"] = int(user.ID.Int64)
 	err = session.Save(r, w)
 	if err != nil {
 		http.Error(w, "Error guardando sesión: "+err.Error(), http.StatusInternalServerError)
@@ -228,25 +573,54 @@ func (h *WebHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h *WebHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
-		err := h.Templates.ExecuteTemplate(w, "login.html", nil)
+		h.renderLogin(w, http.StatusOK, "")
+		return
+	}
+	username := credentials.NormalizeUsername(r.FormValue("username"))
+	password := r.FormValue("password")
+	wait, err := h.loginLocked(r, username)
+	if err != nil {
+		http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		setRetryAfter(w, wait)
+		h.renderLogin(w, http.StatusTooManyRequests, lockedMessage(wait))
+		return
+	}
+	user, reason, err := h.authenticate(r, username, password)
+	if err != nil {
+		http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		wait, err := h.loginFailed(r, username, reason)
 		if err != nil {
-			http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+			http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if wait > 0 {
+			setRetryAfter(w, wait)
+			h.renderLogin(w, http.StatusTooManyRequests, lockedMessage(wait))
 			return
 		}
+		h.renderLogin(w, http.StatusOK, "Usuario o contraseña incorrectos")
 		return
 	}
-	username := r.FormValue("username")
-	password := r.FormValue("password")
-	var id int
-	var hash string
-	err := h.Db.QueryRow("SELECT id, password_hash FROM users WHERE username = ?", username).Scan(&id, &hash)
-	if err != nil || bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
-		data := ErrorData{Error: "Usuario o contraseña incorrectos"}
-		h.Templates.ExecuteTemplate(w, "login.html", data)
+	id := int(user.ID.Int64)
+	session, _ := h.Store.Get(r, "session")
+	// Con la verificación en dos pasos falta el código
+	if twofactor.Enabled(user) {
+		h.startSecondStep(session, id)
+		session.Save(r, w)
+		http.Redirect(w, r, "/login/2fa", http.StatusSeeOther)
+		return
+	}
+	if err := h.loginSucceeded(r, username); err != nil {
+		http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 	// Guardar el user_id en la cookie de sesión
-	session, _ := h.Store.Get(r, "session")
 	session.Values["user_id"] = id
 	session.Save(r, w)
 
@@ -260,17 +634,10 @@ func (h *WebHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
+// writeJSON responde con payload codificado en JSON. Los errores se escriben
+// con writeError para que todos tengan la misma forma.
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-
-	// Manejar diferentes tipos de payload
-	switch v := payload.(type) {
-	case string:
-		// Si es un string, asumimos que ya es JSON válido
-		w.Write([]byte(v))
-	default:
-		// Para otros tipos, usar el encoder directamente
-		json.NewEncoder(w).Encode(payload)
-	}
+	json.NewEncoder(w).Encode(payload)
 }
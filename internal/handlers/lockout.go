@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+func (h *WebHandler) lockoutPolicy() lockout.Policy {
+	if h.Lockout != nil {
+		return *h.Lockout
+	}
+	return lockout.DefaultPolicy
+}
+
+// clientIP es la IP desde la que se hace la petición. Detrás de un proxy de
+// confianza es la última de X-Forwarded-For, la que añade el proxy: las
+// anteriores las puede poner el cliente.
+func (h *WebHandler) clientIP(r *http.Request) string {
+	if h.TrustProxy {
+		if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+			last := fwd[len(fwd)-1]
+			if i := strings.LastIndex(last, ","); i >= 0 {
+				last = last[i+1:]
+			}
+			if ip := strings.TrimSpace(last); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// loginLocked devuelve cuánto falta para que termine el bloqueo del login de
+// username desde la IP de r, o 0. Los intentos durante el bloqueo quedan en
+// el registro.
+func (h *WebHandler) loginLocked(r *http.Request, username string) (time.Duration, error) {
+	ip := h.clientIP(r)
+	wait, err := h.lockoutPolicy().Locked(r.Context(), h.Db, username, ip, h.now())
+	if err != nil || wait <= 0 {
+		return 0, err
+	}
+	return wait, lockout.Record(r.Context(), h.Db, username, ip, lockout.ReasonLocked, h.now())
+}
+
+// loginFailed apunta un login fallido y devuelve el bloqueo que empieza con
+// él, o 0.
+func (h *WebHandler) loginFailed(r *http.Request, username, reason string) (time.Duration, error) {
+	ip := h.clientIP(r)
+	slog.Warn("Login fallido", "username", username, "ip", ip, "reason", reason)
+	var wait time.Duration
+	err := h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		var err error
+		wait, err = h.lockoutPolicy().Fail(r.Context(), exec, username, ip, reason, h.now())
+		return err
+	})
+	if wait > 0 {
+		slog.Warn("Login bloqueado", "username", username, "ip", ip, "for", wait)
+	}
+	return wait, err
+}
+
+// loginSucceeded pone a cero los fallos de username tras un login correcto.
+func (h *WebHandler) loginSucceeded(r *http.Request, username string) error {
+	return lockout.Succeed(r.Context(), h.Db, username)
+}
+
+// setRetryAfter pone la cabecera Retry-After, en segundos redondeados hacia
+// arriba.
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+// lockedMessage es el mensaje de un login bloqueado durante wait.
+func lockedMessage(wait time.Duration) string {
+	if wait <= time.Minute {
+		return "Demasiados intentos fallidos; vuelve a intentarlo en un minuto"
+	}
+	return fmt.Sprintf("Demasiados intentos fallidos; vuelve a intentarlo en %d minutos", int(math.Ceil(wait.Minutes())))
+}
+
+// writeLocked responde 429 a un login bloqueado durante wait.
+func writeLocked(w http.ResponseWriter, wait time.Duration) {
+	setRetryAfter(w, wait)
+	writeErrorCode(w, http.StatusTooManyRequests, codeTooManyAttempts, lockedMessage(wait))
+}
+
+// writeLoginFailed apunta un login fallido de la API y responde 401 con code
+// y message o, si empieza un bloqueo, 429.
+func (h *WebHandler) writeLoginFailed(w http.ResponseWriter, r *http.Request, username, reason, code, message string) {
+	wait, err := h.loginFailed(r, username, reason)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if wait > 0 {
+		writeLocked(w, wait)
+		return
+	}
+	writeErrorCode(w, http.StatusUnauthorized, code, message)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/search"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// SearchPageData son los datos de search.html.
+type SearchPageData struct {
+	Query   string
+	Results []*search.Result
+	Error   string
+}
+
+// SearchPage muestra los resultados de la búsqueda ?q= en search.html.
+func (h *WebHandler) SearchPage(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	data := SearchPageData{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+	if data.Query != "" {
+		results, err := search.Tasks(r.Context(), h.Db, int64(userID), data.Query, search.MaxLimit)
+		switch {
+		case errors.Is(err, search.ErrEmptyQuery):
+			data.Error = err.Error()
+		case err != nil:
+			http.Error(w, "Error buscando tareas: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data.Results = results
+	}
+
+	err := h.Templates.ExecuteTemplate(w, "search.html", data)
+	if err != nil {
+		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// apiSearchResult es un resultado de /api/tasks/search. Snippet es HTML: el
+// texto va escapado y los términos encontrados, en <mark>.
+type apiSearchResult struct {
+	Task    apiTask       `json:"task"`
+	Snippet template.HTML `json:"snippet"`
+	Rank    float64       `json:"rank"`
+}
+
+// ApiSearchTasks busca ?q= en el título y las notas de las tareas del
+// usuario; ?limit= limita el número de resultados.
+func (h *WebHandler) ApiSearchTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No autorizado")
+		return
+	}
+	limit := search.DefaultLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > search.MaxLimit {
+			writeError(w, http.StatusBadRequest, "limit debe estar entre 1 y "+strconv.Itoa(search.MaxLimit))
+			return
+		}
+		limit = n
+	}
+	results, err := search.Tasks(r.Context(), h.Db, int64(userID), r.URL.Query().Get("q"), limit)
+	switch {
+	case errors.Is(err, search.ErrEmptyQuery):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "Error buscando tareas")
+		return
+	}
+
+	tasks := make([]*models.Task, 0, len(results))
+	for _, res := range results {
+		tasks = append(tasks, &res.Task)
+	}
+	if len(tasks) > 0 {
+		if err := tasks[0].L.LoadTags(r.Context(), h.Db, false, &tasks, qm.OrderBy(models.TagColumns.Name)); err != nil {
+			writeError(w, http.StatusInternalServerError, "Error obteniendo etiquetas")
+			return
+		}
+	}
+	out := make([]apiSearchResult, 0, len(results))
+	for _, res := range results {
+		out = append(out, apiSearchResult{Task: newAPITask(&res.Task), Snippet: res.HTML(), Rank: res.Rank})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"query": r.URL.Query().Get("q"), "results": out})
+}
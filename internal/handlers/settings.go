@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/apitoken"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/sso"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/null/v8"
+)
+
+// SettingsPageData son los datos de settings.html.
+type SettingsPageData struct {
+	Tokens models.APITokenSlice
+	Scopes []string
+	// Created es el token que se acaba de crear y Secret, su secreto, que
+	// solo se muestra esta vez.
+	Created *models.APIToken
+	Secret  string
+	Error   string
+	// SSOName es el nombre del proveedor OpenID Connect, si está configurado,
+	// e Identities, las cuentas del usuario vinculadas con él.
+	SSOName    string
+	Identities models.UserIdentitySlice
+	// TwoFactor es el estado de la verificación en dos pasos del usuario.
+	TwoFactor TwoFactorData
+	// RecoveryCodes son los códigos de recuperación que se acaban de generar,
+	// que solo se muestran esta vez.
+	RecoveryCodes []string
+}
+
+// TwoFactorData es el estado de la verificación en dos pasos en los ajustes.
+// Con un alta pendiente, Secret y URI son los datos para la aplicación de
+// autenticación.
+type TwoFactorData struct {
+	Enabled           bool
+	Pending           bool
+	Secret            string
+	URI               string
+	RecoveryCodesLeft int64
+}
+
+// SettingsPage muestra los ajustes del usuario: sus tokens personales, sus
+// cuentas externas y la verificación en dos pasos.
+func (h *WebHandler) SettingsPage(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	h.renderSettingsPage(w, r, int64(userID), SettingsPageData{})
+}
+
+// CreateToken crea un token personal con el formulario de settings.html y
+// vuelve a mostrar la página con su secreto.
+func (h *WebHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Formulario inválido", http.StatusBadRequest)
+		return
+	}
+	name, scopes, expiresAt, errs := h.parseToken(r.FormValue("name"), strings.Join(r.Form["scopes"], ","), r.FormValue("expires_at"))
+	if len(errs) > 0 {
+		h.renderSettingsPage(w, r, int64(userID), SettingsPageData{Error: errs.String()})
+		return
+	}
+	t, secret, err := apitoken.Create(r.Context(), h.Db, int64(userID), name, scopes, expiresAt)
+	if errors.Is(err, apitoken.ErrNameTaken) {
+		h.renderSettingsPage(w, r, int64(userID), SettingsPageData{Error: "Ya existe un token con ese nombre"})
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error creando el token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.renderSettingsPage(w, r, int64(userID), SettingsPageData{Created: t, Secret: secret})
+}
+
+// RevokeToken revoca el token de la ruta /settings/tokens/{id}/revoke.
+func (h *WebHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	_, err := apitoken.Revoke(r.Context(), h.Db, int64(userID), mux.Vars(r)["id"])
+	if errors.Is(err, apitoken.ErrNotFound) {
+		http.Error(w, "No autorizado", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error revocando el token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// renderSettingsPage muestra settings.html con los tokens del usuario. Si
+// data tiene Error, con estado 400.
+func (h *WebHandler) renderSettingsPage(w http.ResponseWriter, r *http.Request, userID int64, data SettingsPageData) {
+	tokens, err := apitoken.List(r.Context(), h.Db, userID)
+	if err != nil {
+		http.Error(w, "Error obteniendo tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data.Tokens = tokens
+	data.Scopes = apitoken.Scopes
+	if h.SSO != nil {
+		data.SSOName = h.SSO.Name
+		data.Identities, err = sso.Identities(r.Context(), h.Db, userID)
+		if err != nil {
+			http.Error(w, "Error obteniendo cuentas vinculadas: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	user, err := models.FindUser(r.Context(), h.Db, null.Int64From(userID))
+	if err != nil {
+		http.Error(w, "Error obteniendo el usuario: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data.TwoFactor.Enabled = twofactor.Enabled(user); data.TwoFactor.Enabled {
+		data.TwoFactor.RecoveryCodesLeft, err = twofactor.RemainingRecoveryCodes(r.Context(), h.Db, userID)
+		if err != nil {
+			http.Error(w, "Error obteniendo códigos de recuperación: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else if key, err := twofactor.PendingKey(user); err == nil {
+		data.TwoFactor.Pending = true
+		data.TwoFactor.Secret = key.Secret()
+		data.TwoFactor.URI = key.URL()
+	}
+	if data.Error != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	err = h.Templates.ExecuteTemplate(w, "settings.html", data)
+	if err != nil {
+		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/sso"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+// LoginPageData son los datos de login.html. SSOName es el nombre del
+// proveedor OpenID Connect, si está configurado.
+type LoginPageData struct {
+	Error   string
+	SSOName string
+}
+
+// renderLogin muestra login.html con el error errMsg y el estado status.
+func (h *WebHandler) renderLogin(w http.ResponseWriter, status int, errMsg string) {
+	data := LoginPageData{Error: errMsg}
+	if h.SSO != nil {
+		data.SSOName = h.SSO.Name
+	}
+	w.WriteHeader(status)
+	err := h.Templates.ExecuteTemplate(w, "login.html", data)
+	if err != nil {
+		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SSOLogin redirige al proveedor OpenID Connect para iniciar sesión o, si ya
+// hay sesión, para vincular la cuenta externa con el usuario.
+func (h *WebHandler) SSOLogin(w http.ResponseWriter, r *http.Request) {
+	if h.SSO == nil {
+		http.NotFound(w, r)
+		return
+	}
+	req, err := sso.NewRequest()
+	if err != nil {
+		http.Error(w, "Error iniciando el login: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	session, _ := h.Store.Get(r, "session")
+	session.Values["oidc_state"] = req.State
+	session.Values["oidc_nonce"] = req.Nonce
+	session.Values["oidc_verifier"] = req.Verifier
+	if err := session.Save(r, w); err != nil {
+		http.Error(w, "Error guardando sesión: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, h.SSO.AuthCodeURL(req), http.StatusFound)
+}
+
+// SSOCallback es la vuelta del proveedor. Con sesión vincula la cuenta
+// externa al usuario; sin ella, inicia sesión con el usuario vinculado o, si
+// está activado AutoProvision, con uno nuevo.
+func (h *WebHandler) SSOCallback(w http.ResponseWriter, r *http.Request) {
+	if h.SSO == nil {
+		http.NotFound(w, r)
+		return
+	}
+	session, _ := h.Store.Get(r, "session")
+	state, _ := session.Values["oidc_state"].(string)
+	nonce, _ := session.Values["oidc_nonce"].(string)
+	verifier, _ := session.Values["oidc_verifier"].(string)
+	delete(session.Values, "oidc_state")
+	delete(session.Values, "oidc_nonce")
+	delete(session.Values, "oidc_verifier")
+
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		slog.Info("Login OIDC cancelado", "error", e, "description", query.Get("error_description"))
+		session.Save(r, w)
+		h.renderLogin(w, http.StatusUnauthorized, "No se ha podido iniciar sesión con "+h.SSO.Name)
+		return
+	}
+	if state == "" || query.Get("state") != state {
+		session.Save(r, w)
+		h.renderLogin(w, http.StatusBadRequest, "El inicio de sesión ha caducado; vuelve a intentarlo")
+		return
+	}
+	id, err := h.SSO.Exchange(r.Context(), query.Get("code"), sso.Request{State: state, Nonce: nonce, Verifier: verifier})
+	if err != nil {
+		slog.Warn("Login OIDC fallido", "error", err)
+		session.Save(r, w)
+		h.renderLogin(w, http.StatusUnauthorized, "No se ha podido iniciar sesión con "+h.SSO.Name)
+		return
+	}
+
+	// Con sesión iniciada, el usuario está vinculando su cuenta
+	if userID, ok := session.Values["user_id"].(int); ok {
+		_, err := sso.Link(r.Context(), h.Db, int64(userID), h.SSO.Issuer, id)
+		if errors.Is(err, sso.ErrLinkedElsewhere) {
+			session.Save(r, w)
+			http.Error(w, "Esa cuenta de "+h.SSO.Name+" ya está vinculada a otro usuario", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Error vinculando la cuenta: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		session.Save(r, w)
+		http.Redirect(w, r, "/settings", http.StatusSeeOther)
+		return
+	}
+
+	user, err := sso.FindUser(r.Context(), h.Db, h.SSO.Issuer, id)
+	if errors.Is(err, sso.ErrNotLinked) && h.SSO.AutoProvision {
+		user, err = h.provisionUser(r, id)
+	}
+	if errors.Is(err, sso.ErrNotLinked) {
+		session.Save(r, w)
+		h.renderLogin(w, http.StatusForbidden, "Tu cuenta de "+h.SSO.Name+" no está vinculada a ningún usuario: entra con tu contraseña y vincúlala en Ajustes")
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error iniciando sesión: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// El proveedor sustituye a la contraseña, no al segundo paso
+	if twofactor.Enabled(user) {
+		h.startSecondStep(session, int(user.ID.Int64))
+		session.Save(r, w)
+		http.Redirect(w, r, "/login/2fa", http.StatusSeeOther)
+		return
+	}
+	session.Values["user_id"] = int(user.ID.Int64)
+	if err := session.Save(r, w); err != nil {
+		http.Error(w, "Error guardando sesión: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// provisionUser crea el usuario de la cuenta externa id en una transacción,
+// para no dejar usuarios sin vincular.
+func (h *WebHandler) provisionUser(r *http.Request, id *sso.Identity) (*models.User, error) {
+	db, ok := h.Db.(boil.ContextBeginner)
+	if !ok {
+		return sso.Provision(r.Context(), h.Db, h.SSO.Issuer, id, h.credentialsPolicy())
+	}
+	tx, err := db.BeginTx(r.Context(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	user, err := sso.Provision(r.Context(), tx, h.SSO.Issuer, id, h.credentialsPolicy())
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Usuario creado desde OIDC", "username", user.Username, "subject", id.Subject)
+	return user, tx.Commit()
+}
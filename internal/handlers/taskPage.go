@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// TaskPageData son los datos de task.html.
+type TaskPageData struct {
+	Task   TaskView
+	Notes  template.HTML
+	Parent *models.Task
+	// Description es el texto del formulario de notas, que se conserva si
+	// no se ha podido guardar.
+	Description string
+	Error       string
+}
+
+// TaskPage muestra el detalle de una tarea con sus notas en Markdown.
+func (h *WebHandler) TaskPage(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	task, ok := h.findPageTask(w, r, userID)
+	if !ok {
+		return
+	}
+	h.renderTaskPage(w, r, task, task.Description, "")
+}
+
+// notesConflict es el error de task.html cuando las notas han cambiado en otra
+// pestaña.
+const notesConflict = "La tarea ha cambiado desde que abriste la página: revisa las notas y vuelve a guardar"
+
+// UpdateTaskNotes guarda las notas del formulario de task.html.
+func (h *WebHandler) UpdateTaskNotes(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	task, ok := h.findPageTask(w, r, userID)
+	if !ok {
+		return
+	}
+	// Si la tarea ha cambiado desde que se abrió la página, se vuelve a mostrar
+	// con las notas actuales, sin perder el texto del formulario
+	if v := r.FormValue("version"); v != "" && v != strconv.FormatInt(task.Version, 10) {
+		h.renderTaskPage(w, r, task, r.FormValue("description"), notesConflict)
+		return
+	}
+	description, err := notes.Normalize(r.FormValue("description"))
+	if err != nil {
+		h.renderTaskPage(w, r, task, r.FormValue("description"), err.Error())
+		return
+	}
+	err = claimVersion(r.Context(), h.Db, task)
+	if errors.Is(err, errVersionConflict) {
+		// Otra petición la ha cambiado entre la lectura y la escritura
+		if task, ok = h.findPageTask(w, r, userID); ok {
+			h.renderTaskPage(w, r, task, r.FormValue("description"), notesConflict)
+		}
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error guardando notas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	task.Description = description
+	if _, err := task.Update(r.Context(), h.Db, boil.Whitelist(models.TaskColumns.Description)); err != nil {
+		http.Error(w, "Error guardando notas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/tasks/"+strconv.FormatInt(task.ID.Int64, 10), http.StatusSeeOther)
+}
+
+// findPageTask busca la tarea de la ruta /tasks/{id} y comprueba que sea del
+// usuario. Si no, responde con el error y devuelve false.
+func (h *WebHandler) findPageTask(w http.ResponseWriter, r *http.Request, userID int) (*models.Task, bool) {
+	intID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	task, err := models.FindTask(r.Context(), h.Db, null.Int64From(intID))
+	if err != nil || !task.UserID.Valid || task.UserID.Int64 != int64(userID) {
+		http.Error(w, "No autorizado", http.StatusForbidden)
+		return nil, false
+	}
+	return task, true
+}
+
+// renderTaskPage muestra task.html. Si hay errMsg, con estado 400.
+func (h *WebHandler) renderTaskPage(w http.ResponseWriter, r *http.Request, task *models.Task, description, errMsg string) {
+	if err := task.L.LoadTags(r.Context(), h.Db, true, task, qm.OrderBy(models.TagColumns.Name)); err != nil {
+		http.Error(w, "Error obteniendo etiquetas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := task.L.LoadProject(r.Context(), h.Db, true, task, nil); err != nil {
+		http.Error(w, "Error obteniendo el proyecto: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := subtask.Load(r.Context(), h.Db, task); err != nil {
+		http.Error(w, "Error obteniendo subtareas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := TaskPageData{
+		Task:        newTaskViews(models.TaskSlice{task}, h.now())[0],
+		Notes:       notes.Render(task.Description),
+		Description: description,
+		Error:       errMsg,
+	}
+	if task.ParentID.Valid {
+		parent, err := models.FindTask(r.Context(), h.Db, task.ParentID)
+		if err != nil {
+			http.Error(w, "Error obteniendo la tarea principal: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data.Parent = parent
+	}
+	w.Header().Set("ETag", taskETag(task))
+	if errMsg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	err := h.Templates.ExecuteTemplate(w, "task.html", data)
+	if err != nil {
+		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+	}
+}
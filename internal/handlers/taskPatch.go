@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/JorgeePG/todo-list/internal/jsonpatch"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Tipos de cuerpo propios de PATCH /api/tasks/{id}.
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// taskPatch son los cambios de una tarea: solo se cambian los campos que
+// incluye la petición. En JSON, null vacía el campo, como en JSON Merge Patch
+// (RFC 7396).
+type taskPatch struct {
+	Title       optionalText `json:"title"`
+	Done        optionalBool `json:"done"`
+	DueAt       optionalText `json:"due_at"`
+	Priority    optionalText `json:"priority"`
+	Tags        optionalList `json:"tags"`
+	ProjectID   optionalText `json:"project_id"`
+	Description optionalText `json:"description"`
+	Recurrence  optionalText `json:"recurrence"`
+}
+
+func (p *taskPatch) validate(errs fieldErrors) {
+	if p.Title.Set {
+		p.Title.Value = errs.title("title", p.Title.Value)
+	}
+}
+
+// replaceTaskRequest es el cuerpo de PUT /api/tasks/{id}: la tarea entera,
+// así que todos los campos son obligatorios. El ID solo hace falta si no va en
+// la ruta.
+type replaceTaskRequest struct {
+	ID int64 `json:"id"`
+	taskPatch
+}
+
+func (req *replaceTaskRequest) validate(errs fieldErrors) {
+	for field, set := range map[string]bool{
+		"title":       req.Title.Set,
+		"done":        req.Done.Set,
+		"due_at":      req.DueAt.Set,
+		"priority":    req.Priority.Set,
+		"tags":        req.Tags.Set,
+		"project_id":  req.ProjectID.Set,
+		"description": req.Description.Set,
+		"recurrence":  req.Recurrence.Set,
+	} {
+		if !set {
+			errs.add(field, errRequired)
+		}
+	}
+	req.taskPatch.validate(errs)
+}
+
+// updateTaskForm es el formulario de /update en la web: el ID de la tarea y
+// los campos que cambian.
+type updateTaskForm struct {
+	ID int64 `json:"id"`
+	taskPatch
+}
+
+func (req *updateTaskForm) validate(errs fieldErrors) {
+	if req.ID <= 0 {
+		errs.add("id", errRequired)
+	}
+	req.taskPatch.validate(errs)
+}
+
+// taskChanges son los cambios de un taskPatch ya copiados en la tarea y
+// pendientes de guardar.
+type taskChanges struct {
+	task    *models.Task
+	columns []string
+	tags    []string
+	setTags bool
+	wasDone bool
+}
+
+// apply interpreta los campos de p y los copia en task. El proyecto tiene que
+// ser del dueño de la tarea.
+func (p *taskPatch) apply(ctx context.Context, exec boil.ContextExecutor, task *models.Task) (*taskChanges, fieldErrors) {
+	c := &taskChanges{task: task, wasDone: task.Done.Bool}
+	errs := fieldErrors{}
+	var err error
+	if p.Title.Set {
+		task.Title = p.Title.Value
+		c.columns = append(c.columns, models.TaskColumns.Title)
+	}
+	if p.Done.Set {
+		task.Done = null.BoolFrom(p.Done.Value)
+		c.columns = append(c.columns, models.TaskColumns.Done)
+	}
+	if p.DueAt.Set {
+		task.DueAt, err = due.Parse(p.DueAt.Value, time.Local)
+		errs.check("due_at", err)
+		c.columns = append(c.columns, models.TaskColumns.DueAt)
+	}
+	if p.Priority.Set {
+		task.Priority, err = priority.Parse(p.Priority.Value)
+		errs.check("priority", err)
+		c.columns = append(c.columns, models.TaskColumns.Priority)
+	}
+	if p.Tags.Set {
+		c.tags, err = tag.ParseList(p.Tags.Value)
+		errs.check("tags", err)
+		c.setTags = true
+	}
+	if p.ProjectID.Set {
+		task.ProjectID, err = project.ResolveID(ctx, exec, task.UserID.Int64, p.ProjectID.Value)
+		errs.check("project_id", err)
+		c.columns = append(c.columns, models.TaskColumns.ProjectID)
+	}
+	if p.Description.Set {
+		task.Description, err = notes.Normalize(p.Description.Value)
+		errs.check("description", err)
+		c.columns = append(c.columns, models.TaskColumns.Description)
+	}
+	// Una regla vacía detiene la serie
+	if p.Recurrence.Set {
+		errs.check("recurrence", recurrence.Apply(task, p.Recurrence.Value, time.Local))
+		c.columns = append(c.columns, models.TaskColumns.Recurrence)
+	}
+	return c, errs
+}
+
+// save guarda solo las columnas que han cambiado, si la tarea sigue en la
+// versión que se leyó; si no, devuelve errVersionConflict. Si la tarea pasa a
+// estar hecha y se repite, crea la siguiente repetición y la devuelve. Hace
+// varias escrituras, así que hay que llamarla en una transacción.
+func (c *taskChanges) save(ctx context.Context, exec boil.ContextExecutor, now time.Time) (*models.Task, error) {
+	if len(c.columns) > 0 || c.setTags {
+		if err := claimVersion(ctx, exec, c.task); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.columns) > 0 {
+		if _, err := c.task.Update(ctx, exec, boil.Whitelist(c.columns...)); err != nil {
+			return nil, fmt.Errorf("actualizando tarea: %w", err)
+		}
+	}
+	for _, column := range c.columns {
+		if column == models.TaskColumns.ProjectID {
+			if err := subtask.SyncProject(ctx, exec, c.task); err != nil {
+				return nil, fmt.Errorf("actualizando subtareas: %w", err)
+			}
+		}
+	}
+	if c.setTags {
+		if err := setTaskTags(ctx, exec, c.task, c.tags); err != nil {
+			return nil, fmt.Errorf("guardando etiquetas: %w", err)
+		}
+	}
+	// Al completar una tarea que se repite se crea la siguiente repetición,
+	// y la completada deja de repetirse
+	var next *models.Task
+	if !c.wasDone && c.task.Done.Bool {
+		var err error
+		if next, err = recurrence.Complete(ctx, exec, c.task, now); err != nil {
+			return nil, fmt.Errorf("creando la siguiente repetición: %w", err)
+		}
+		if next != nil {
+			if err := loadVersion(ctx, exec, next); err != nil {
+				return nil, fmt.Errorf("leyendo la versión: %w", err)
+			}
+		}
+	}
+	if err := loadVersion(ctx, exec, c.task); err != nil {
+		return nil, fmt.Errorf("leyendo la versión: %w", err)
+	}
+	return next, nil
+}
+
+// decodeTaskPatch lee el cuerpo de un PATCH de task y lo valida. Además de lo
+// que admite decodeRequest, acepta JSON Merge Patch y JSON Patch. Si no es
+// válido responde con el error y devuelve false.
+func (h *WebHandler) decodeTaskPatch(w http.ResponseWriter, r *http.Request, task *models.Task, patch *taskPatch) bool {
+	errs := fieldErrors{}
+	var status int
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case mergePatchType:
+		// Las tareas son objetos planos: el merge patch es un objeto con los
+		// campos que cambian
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+		status, err = readJSON(r, requestFields(patch), errs)
+	case jsonPatchType:
+		status, err = h.readJSONPatch(w, r, task, patch, errs)
+	default:
+		status, err = readRequest(w, r, patch, errs)
+	}
+	return checkRequest(w, patch, errs, status, err)
+}
+
+// readJSONPatch aplica un JSON Patch a los campos editables de task, tal y
+// como los devuelve la API, y guarda en patch los que cambian. Un campo
+// quitado con remove se vacía.
+func (h *WebHandler) readJSONPatch(w http.ResponseWriter, r *http.Request, task *models.Task, patch *taskPatch, errs fieldErrors) (int, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		return bodyError(err)
+	}
+	ops, err := jsonpatch.Decode(body)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := task.L.LoadTags(r.Context(), h.Db, true, task, qm.OrderBy(models.TagColumns.Name)); err != nil {
+		return http.StatusInternalServerError, errors.New("Error obteniendo etiquetas")
+	}
+	doc, err := taskDocument(task, requestFields(patch))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	result, err := ops.Apply(doc)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+	patched, ok := result.(map[string]interface{})
+	if !ok {
+		return http.StatusConflict, fmt.Errorf("%w: el resultado debe ser un objeto", jsonpatch.ErrFailed)
+	}
+
+	changed := map[string]json.RawMessage{}
+	for name, value := range patched {
+		if old, ok := doc[name]; !ok || !reflect.DeepEqual(old, value) {
+			changed[name], _ = json.Marshal(value)
+		}
+	}
+	for name := range doc {
+		if _, ok := patched[name]; !ok {
+			changed[name] = json.RawMessage("null")
+		}
+	}
+	decodeFields(changed, requestFields(patch), errs)
+	return 0, nil
+}
+
+// taskDocument devuelve los campos de task que están en fields, con sus
+// valores tal y como los devuelve la API.
+func taskDocument(task *models.Task, fields map[string]reflect.Value) (map[string]interface{}, error) {
+	data, err := json.Marshal(newAPITask(task))
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for name := range doc {
+		if _, ok := fields[name]; !ok {
+			delete(doc, name)
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/gorilla/sessions"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+// pendingLoginTTL es el tiempo que tiene el usuario para escribir el código
+// de verificación después de dar la contraseña.
+const pendingLoginTTL = 5 * time.Minute
+
+// startSecondStep deja el login del usuario pendiente del código de
+// verificación en dos pasos. Hasta entonces la sesión no tiene user_id.
+func (h *WebHandler) startSecondStep(session *sessions.Session, userID int) {
+	delete(session.Values, "user_id")
+	session.Values["pending_user_id"] = userID
+	session.Values["pending_login_at"] = h.now().Unix()
+}
+
+// pendingUser devuelve el usuario con el login pendiente del segundo paso, si
+// no ha caducado.
+func (h *WebHandler) pendingUser(ctx context.Context, session *sessions.Session) (*models.User, bool) {
+	userID, ok := session.Values["pending_user_id"].(int)
+	if !ok {
+		return nil, false
+	}
+	at, _ := session.Values["pending_login_at"].(int64)
+	if h.now().Sub(time.Unix(at, 0)) > pendingLoginTTL {
+		return nil, false
+	}
+	user, err := models.FindUser(ctx, h.Db, null.Int64From(int64(userID)))
+	if err != nil {
+		return nil, false
+	}
+	return user, true
+}
+
+// finishLogin inicia la sesión del usuario y olvida el login pendiente.
+func finishLogin(session *sessions.Session, userID int) {
+	delete(session.Values, "pending_user_id")
+	delete(session.Values, "pending_login_at")
+	session.Values["user_id"] = userID
+}
+
+// LoginTwoFactor es el segundo paso del login web: pide el código de la
+// aplicación de autenticación o un código de recuperación.
+func (h *WebHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.Store.Get(r, "session")
+	user, ok := h.pendingUser(r.Context(), session)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if r.Method == "GET" {
+		h.renderLoginTwoFactor(w, http.StatusOK, "")
+		return
+	}
+	wait, err := h.loginLocked(r, user.Username)
+	if err != nil {
+		http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		setRetryAfter(w, wait)
+		h.renderLoginTwoFactor(w, http.StatusTooManyRequests, lockedMessage(wait))
+		return
+	}
+	err = twofactor.Verify(r.Context(), h.Db, user, r.FormValue("code"), h.now())
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		wait, err := h.loginFailed(r, user.Username, lockout.ReasonOTP)
+		if err != nil {
+			http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if wait > 0 {
+			setRetryAfter(w, wait)
+			h.renderLoginTwoFactor(w, http.StatusTooManyRequests, lockedMessage(wait))
+			return
+		}
+		h.renderLoginTwoFactor(w, http.StatusOK, "Código incorrecto")
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error comprobando el código: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.loginSucceeded(r, user.Username); err != nil {
+		http.Error(w, "Error de base de datos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	finishLogin(session, int(user.ID.Int64))
+	if err := session.Save(r, w); err != nil {
+		http.Error(w, "Error guardando sesión: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (h *WebHandler) renderLoginTwoFactor(w http.ResponseWriter, status int, errMsg string) {
+	w.WriteHeader(status)
+	err := h.Templates.ExecuteTemplate(w, "login2fa.html", LoginPageData{Error: errMsg})
+	if err != nil {
+		http.Error(w, "Error ejecutando plantilla: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// twoFactorRequest es el cuerpo de POST /api/login/2fa.
+type twoFactorRequest struct {
+	Code string `json:"code"`
+}
+
+func (req *twoFactorRequest) validate(errs fieldErrors) {
+	errs.required("code", req.Code)
+}
+
+// ApiLoginTwoFactor completa un login de la API que ha respondido
+// otp_required.
+func (h *WebHandler) ApiLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req twoFactorRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	session, _ := h.Store.Get(r, "session")
+	user, ok := h.pendingUser(r.Context(), session)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "No hay ningún login pendiente o ha caducado")
+		return
+	}
+	h.completeAPILogin(w, r, session, user, req.Code)
+}
+
+// completeAPILogin comprueba el código del segundo paso y, si es correcto,
+// inicia la sesión del usuario.
+func (h *WebHandler) completeAPILogin(w http.ResponseWriter, r *http.Request, session *sessions.Session, user *models.User, code string) {
+	wait, err := h.loginLocked(r, user.Username)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if wait > 0 {
+		writeLocked(w, wait)
+		return
+	}
+	err = twofactor.Verify(r.Context(), h.Db, user, code, h.now())
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		h.writeLoginFailed(w, r, user.Username, lockout.ReasonOTP, codeInvalidOTP, "Código de verificación incorrecto")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	if err := h.loginSucceeded(r, user.Username); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error de base de datos")
+		return
+	}
+	finishLogin(session, int(user.ID.Int64))
+	if err := session.Save(r, w); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error guardando sesión")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Login correcto",
+		"user":    apiUser{ID: user.ID.Int64, Username: user.Username},
+	})
+}
+
+// settingsUser devuelve el usuario de la sesión, o redirige al login.
+func (h *WebHandler) settingsUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	session, _ := h.Store.Get(r, "session")
+	userID, ok := session.Values["user_id"].(int)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+	user, err := models.FindUser(r.Context(), h.Db, null.Int64From(int64(userID)))
+	if err != nil {
+		http.Error(w, "Error obteniendo el usuario: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return user, true
+}
+
+// EnrollTwoFactor empieza el alta de la verificación en dos pasos. Los
+// ajustes muestran después el secreto y el código QR.
+func (h *WebHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.settingsUser(w, r)
+	if !ok {
+		return
+	}
+	_, err := twofactor.Enroll(r.Context(), h.Db, user)
+	if errors.Is(err, twofactor.ErrAlreadyEnabled) {
+		h.renderSettingsPage(w, r, user.ID.Int64, SettingsPageData{Error: "La verificación en dos pasos ya está activada"})
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error activando la verificación en dos pasos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// TwoFactorQR es el código QR con la URI otpauth:// del alta pendiente. Se
+// sirve aparte porque la CSP no deja usar imágenes data:.
+func (h *WebHandler) TwoFactorQR(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.settingsUser(w, r)
+	if !ok {
+		return
+	}
+	key, err := twofactor.PendingKey(user)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	img, err := key.Image(200, 200)
+	if err != nil {
+		http.Error(w, "Error generando el código QR: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		http.Error(w, "Error generando el código QR: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// El QR contiene el secreto: que no se guarde en ninguna caché
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// ConfirmTwoFactor termina el alta con el primer código de la aplicación y
+// muestra los códigos de recuperación.
+func (h *WebHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.settingsUser(w, r)
+	if !ok {
+		return
+	}
+	var codes []string
+	err := h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		var err error
+		codes, err = twofactor.Confirm(r.Context(), exec, user, r.FormValue("code"), h.now())
+		return err
+	})
+	switch {
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		h.renderSettingsPage(w, r, user.ID.Int64, SettingsPageData{Error: "Código incorrecto: comprueba la hora del dispositivo y vuelve a intentarlo"})
+	case errors.Is(err, twofactor.ErrNotEnrolling), errors.Is(err, twofactor.ErrAlreadyEnabled):
+		h.renderSettingsPage(w, r, user.ID.Int64, SettingsPageData{Error: err.Error()})
+	case err != nil:
+		http.Error(w, "Error activando la verificación en dos pasos: "+err.Error(), http.StatusInternalServerError)
+	default:
+		h.renderSettingsPage(w, r, user.ID.Int64, SettingsPageData{RecoveryCodes: codes})
+	}
+}
+
+// DisableTwoFactor desactiva la verificación en dos pasos, o cancela el alta
+// pendiente. Si está activada pide un código, para que no baste con una
+// sesión abierta.
+func (h *WebHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.settingsUser(w, r)
+	if !ok {
+		return
+	}
+	err := h.inTx(r.Context(), func(exec boil.ContextExecutor) error {
+		if twofactor.Enabled(user) {
+			if err := twofactor.Verify(r.Context(), exec, user, r.FormValue("code"), h.now()); err != nil {
+				return err
+			}
+		}
+		return twofactor.Disable(r.Context(), exec, user)
+	})
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		h.renderSettingsPage(w, r, user.ID.Int64, SettingsPageData{Error: "Código incorrecto"})
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error desactivando la verificación en dos pasos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// inTx ejecuta fn en una transacción, si la base de datos las admite.
+func (h *WebHandler) inTx(ctx context.Context, fn func(exec boil.ContextExecutor) error) error {
+	db, ok := h.Db.(boil.ContextBeginner)
+	if !ok {
+		return fn(h.Db)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
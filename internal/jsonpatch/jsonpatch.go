@@ -0,0 +1,289 @@
+// Package jsonpatch aplica documentos JSON Patch (RFC 6902) a documentos JSON
+// ya decodificados con encoding/json: objetos map[string]interface{}, arrays
+// []interface{}, números float64, textos, booleanos y nil.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid indica que el patch no es un documento JSON Patch válido.
+var ErrInvalid = errors.New("JSON Patch inválido")
+
+// ErrFailed indica que el patch no se puede aplicar al documento: una ruta no
+// existe o falla una operación test.
+var ErrFailed = errors.New("no se puede aplicar el JSON Patch")
+
+// Operation es una operación de un patch. From solo se usa en move y copy, y
+// Value en add, replace y test.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+
+	path, from []string
+	value      interface{}
+}
+
+// Patch es una lista de operaciones, que se aplican en orden.
+type Patch []Operation
+
+// Decode lee un patch y comprueba sus operaciones y rutas.
+func Decode(data []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if patch == nil {
+		return nil, fmt.Errorf("%w: debe ser un array de operaciones", ErrInvalid)
+	}
+	for i := range patch {
+		if err := patch[i].parse(); err != nil {
+			return nil, fmt.Errorf("%w: operación %d: %v", ErrInvalid, i, err)
+		}
+	}
+	return patch, nil
+}
+
+// parse interpreta las rutas y el valor de op.
+func (op *Operation) parse() error {
+	var err error
+	if op.path, err = parsePointer(op.Path); err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("%s necesita value", op.Op)
+		}
+		return json.Unmarshal(op.Value, &op.value)
+	case "move", "copy":
+		if op.from, err = parsePointer(op.From); err != nil {
+			return err
+		}
+		if op.Op == "move" && len(op.from) < len(op.path) && isPrefix(op.from, op.path) {
+			return fmt.Errorf("no se puede mover %q dentro de sí mismo", op.From)
+		}
+		return nil
+	case "remove":
+		return nil
+	}
+	return fmt.Errorf("operación desconocida %q", op.Op)
+}
+
+// parsePointer divide un JSON Pointer (RFC 6901) en sus partes, ya sin
+// escapar. "" es el documento entero.
+func parsePointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("ruta inválida %q: debe empezar por /", s)
+	}
+	parts := strings.Split(s[1:], "/")
+	for i, p := range parts {
+		parts[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(p)
+	}
+	return parts, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply aplica el patch a una copia de doc y devuelve el resultado. Si una
+// operación falla devuelve un error con ErrFailed.
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+	var err error
+	doc = copyValue(doc)
+	for i, op := range p {
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("%w: operación %d (%s %s): %v", ErrFailed, i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func (op *Operation) apply(doc interface{}) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		return add(doc, op.path, copyValue(op.value))
+	case "remove":
+		doc, _, err := remove(doc, op.path)
+		return doc, err
+	case "replace":
+		doc, _, err := remove(doc, op.path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.path, copyValue(op.value))
+	case "move":
+		doc, value, err := remove(doc, op.from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.path, value)
+	case "copy":
+		value, err := get(doc, op.from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.path, copyValue(value))
+	case "test":
+		value, err := get(doc, op.path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.value) {
+			return nil, errors.New("el valor no coincide")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("operación desconocida %q", op.Op)
+}
+
+// get devuelve el valor de path en doc.
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, key := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("no existe %q", key)
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("no existe %q", key)
+		}
+	}
+	return doc, nil
+}
+
+// add añade value en path. En un objeto crea o cambia la clave; en un array
+// lo inserta en la posición, o al final si es "-".
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[key] = value
+		return doc, nil
+	case []interface{}:
+		i := len(node)
+		if key != "-" {
+			if i, err = index(key, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return set(doc, path[:len(path)-1], node)
+	}
+	return nil, fmt.Errorf("no existe %q", key)
+}
+
+// remove quita el valor de path y lo devuelve.
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, ok := node[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("no existe %q", key)
+		}
+		delete(node, key)
+		return doc, value, nil
+	case []interface{}:
+		i, err := index(key, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = set(doc, path[:len(path)-1], node)
+		return doc, value, err
+	}
+	return nil, nil, fmt.Errorf("no existe %q", key)
+}
+
+// set cambia el valor de path, que ya existe. Hace falta para los arrays, que
+// cambian de longitud.
+func set(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[key] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(key)
+		node[i] = value
+	}
+	return doc, nil
+}
+
+// index interpreta key como posición de un array, entre 0 y max.
+func index(key string, max int) (int, error) {
+	// Solo dígitos y sin ceros a la izquierda
+	i, err := strconv.Atoi(key)
+	if err != nil || strings.Trim(key, "0123456789") != "" || i > max || (key != "0" && strings.HasPrefix(key, "0")) {
+		return 0, fmt.Errorf("posición inválida %q", key)
+	}
+	return i, nil
+}
+
+// copyValue copia en profundidad un valor, para que dos rutas no compartan
+// el mismo objeto o array.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = copyValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = copyValue(item)
+		}
+		return out
+	}
+	return value
+}
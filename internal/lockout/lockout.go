@@ -0,0 +1,201 @@
+// Package lockout protege el login frente a la adivinación de contraseñas:
+// cuenta los fallos seguidos por nombre de usuario y por IP y, al pasar del
+// límite, bloquea el login durante un tiempo que se dobla con cada fallo. Los
+// contadores y el registro de fallos se guardan en la base de datos, así que
+// sobreviven a los reinicios del servidor.
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Ámbitos de los contadores.
+const (
+	ScopeUsername = "username"
+	ScopeIP       = "ip"
+)
+
+// Motivos de los fallos del registro.
+const (
+	ReasonUnknownUser = "unknown_user"
+	ReasonPassword    = "password"
+	ReasonOTP         = "otp"
+	// ReasonLocked es un intento durante un bloqueo, que no llega a
+	// comprobarse.
+	ReasonLocked = "locked"
+)
+
+// Policy son los límites del login. MaxFailures son los fallos seguidos que
+// se permiten por nombre de usuario y MaxFailuresIP, por IP; 0 desactiva el
+// límite. Al llegar al límite el login se bloquea durante Lockout, y cada
+// fallo más dobla el bloqueo hasta MaxLockout. Los fallos se olvidan cuando
+// pasa MaxLockout sin ninguno.
+type Policy struct {
+	MaxFailures   int
+	MaxFailuresIP int
+	Lockout       time.Duration
+	MaxLockout    time.Duration
+}
+
+// DefaultPolicy es la política por defecto.
+var DefaultPolicy = Policy{
+	MaxFailures:   5,
+	MaxFailuresIP: 20,
+	Lockout:       time.Minute,
+	MaxLockout:    time.Hour,
+}
+
+// NormalizeUsername es el nombre de usuario con el que se cuentan los fallos,
+// para que no se pueda esquivar el límite cambiando mayúsculas o espacios.
+// Normaliza igual que el login y solo pasa a minúsculas las letras ASCII, como
+// COLLATE NOCASE: dos nombres comparten contador si son el mismo usuario.
+func NormalizeUsername(username string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, credentials.NormalizeUsername(username))
+}
+
+// Locked devuelve cuánto falta para que termine el bloqueo del usuario o de la
+// IP, el más largo de los dos, o 0 si no hay ninguno.
+func (p Policy) Locked(ctx context.Context, exec boil.ContextExecutor, username, ip string, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for _, key := range [][2]string{{ScopeUsername, NormalizeUsername(username)}, {ScopeIP, ip}} {
+		t, err := models.LoginThrottles(
+			models.LoginThrottleWhere.Scope.EQ(key[0]),
+			models.LoginThrottleWhere.Subject.EQ(key[1]),
+		).One(ctx, exec)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if t.LockedUntil.Valid {
+			wait = max(wait, t.LockedUntil.Time.Sub(now))
+		}
+	}
+	return wait, nil
+}
+
+// Fail apunta un login fallido en el registro y en los contadores del usuario
+// y de la IP. Devuelve el bloqueo que empieza con este fallo, o 0.
+func (p Policy) Fail(ctx context.Context, exec boil.ContextExecutor, username, ip, reason string, now time.Time) (time.Duration, error) {
+	if err := Record(ctx, exec, username, ip, reason, now); err != nil {
+		return 0, err
+	}
+	wait, err := p.fail(ctx, exec, ScopeUsername, NormalizeUsername(username), p.MaxFailures, now)
+	if err != nil {
+		return 0, err
+	}
+	waitIP, err := p.fail(ctx, exec, ScopeIP, ip, p.MaxFailuresIP, now)
+	if err != nil {
+		return 0, err
+	}
+	return max(wait, waitIP), nil
+}
+
+func (p Policy) fail(ctx context.Context, exec boil.ContextExecutor, scope, subject string, limit int, now time.Time) (time.Duration, error) {
+	if limit <= 0 || subject == "" {
+		return 0, nil
+	}
+	t, err := models.LoginThrottles(
+		models.LoginThrottleWhere.Scope.EQ(scope),
+		models.LoginThrottleWhere.Subject.EQ(subject),
+	).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		t = &models.LoginThrottle{Scope: scope, Subject: subject}
+	} else if err != nil {
+		return 0, err
+	}
+	if now.Sub(t.LastFailureAt) > p.MaxLockout {
+		t.Failures = 0
+	}
+	t.Failures++
+	t.LastFailureAt = now.UTC()
+
+	var wait time.Duration
+	if t.Failures >= int64(limit) {
+		wait = p.Lockout
+		for i := int64(limit); i < t.Failures && wait < p.MaxLockout; i++ {
+			wait *= 2
+		}
+		wait = min(wait, p.MaxLockout)
+		t.LockedUntil = null.TimeFrom(now.Add(wait).UTC())
+	}
+	if t.ID.Valid {
+		_, err = t.Update(ctx, exec, boil.Infer())
+	} else {
+		err = t.Insert(ctx, exec, boil.Infer())
+	}
+	return wait, err
+}
+
+// Record apunta un login fallido en el registro sin tocar los contadores. El
+// nombre se guarda normalizado.
+func Record(ctx context.Context, exec boil.ContextExecutor, username, ip, reason string, now time.Time) error {
+	f := &models.LoginFailure{
+		Username:  NormalizeUsername(username),
+		IP:        ip,
+		Reason:    reason,
+		CreatedAt: now.UTC(),
+	}
+	return f.Insert(ctx, exec, boil.Infer())
+}
+
+// Succeed pone a cero los fallos del usuario tras un login correcto. Los de
+// la IP no, para que una cuenta propia no sirva para seguir probando otras.
+func Succeed(ctx context.Context, exec boil.ContextExecutor, username string) error {
+	_, err := models.LoginThrottles(
+		models.LoginThrottleWhere.Scope.EQ(ScopeUsername),
+		models.LoginThrottleWhere.Subject.EQ(NormalizeUsername(username)),
+	).DeleteAll(ctx, exec)
+	return err
+}
+
+// Unlock borra el contador del usuario o de la IP, según scope, y con él su
+// bloqueo. Devuelve si estaba bloqueado en now.
+func Unlock(ctx context.Context, exec boil.ContextExecutor, scope, subject string, now time.Time) (bool, error) {
+	if scope == ScopeUsername {
+		subject = NormalizeUsername(subject)
+	}
+	t, err := models.LoginThrottles(
+		models.LoginThrottleWhere.Scope.EQ(scope),
+		models.LoginThrottleWhere.Subject.EQ(subject),
+	).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := t.Delete(ctx, exec); err != nil {
+		return false, err
+	}
+	return t.LockedUntil.Valid && t.LockedUntil.Time.After(now), nil
+}
+
+// Failures devuelve los últimos limit logins fallidos, del más reciente al más
+// antiguo. Si username no está vacío, solo los de ese usuario.
+func Failures(ctx context.Context, exec boil.ContextExecutor, username string, limit int) (models.LoginFailureSlice, error) {
+	mods := []qm.QueryMod{
+		qm.OrderBy(models.LoginFailureColumns.ID + " DESC"),
+		qm.Limit(limit),
+	}
+	if username = NormalizeUsername(username); username != "" {
+		mods = append(mods, models.LoginFailureWhere.Username.EQ(username))
+	}
+	return models.LoginFailures(mods...).All(ctx, exec)
+}
@@ -0,0 +1,347 @@
+// Package migrations aplica cambios de esquema versionados sobre la base de
+// datos. Cada migración son dos ficheros SQL (NNNN_nombre.up.sql y
+// NNNN_nombre.down.sql) embebidos en el binario; las aplicadas se registran en
+// la tabla schema_migrations junto con el checksum de su SQL.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// ErrChecksumMismatch indica que el SQL de una migración ya aplicada ha
+// cambiado desde que se ejecutó.
+var ErrChecksumMismatch = errors.New("el checksum de la migración no coincide con el aplicado")
+
+var fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration es un paso del esquema con su SQL de subida y bajada.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status describe el estado de una migración en una base de datos concreta.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Modified es true si el checksum registrado difiere del SQL actual.
+	Modified bool
+}
+
+type appliedRow struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator aplica y revierte migraciones sobre una base de datos.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New crea un Migrator con las migraciones embebidas en el binario.
+func New(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return NewFromFS(db, sub)
+}
+
+// NewFromFS crea un Migrator leyendo las migraciones de la raíz de fsys.
+func NewFromFS(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Apply aplica todas las migraciones embebidas pendientes.
+func Apply(ctx context.Context, db *sql.DB) error {
+	m, err := New(db)
+	if err != nil {
+		return err
+	}
+	_, err = m.Up(ctx)
+	return err
+}
+
+// Load lee las migraciones de la raíz de fsys ordenadas por versión.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := fileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migración %04d con nombres distintos: %q y %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migración %04d_%s sin fichero .up.sql", m.Version, m.Name)
+		}
+		m.Checksum = checksum(m.Up)
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Migrations devuelve las migraciones conocidas ordenadas por versión.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedRow, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedRow{}
+	for rows.Next() {
+		var version int
+		var sum, at string
+		if err := rows.Scan(&version, &sum, &at); err != nil {
+			return nil, err
+		}
+		appliedAt, _ := time.Parse(time.RFC3339, at)
+		applied[version] = appliedRow{checksum: sum, appliedAt: appliedAt}
+	}
+	return applied, rows.Err()
+}
+
+// Initialized indica si la base de datos ya tiene la tabla schema_migrations,
+// es decir, si se ha migrado alguna vez. A diferencia de Status, no la crea.
+func (m *Migrator) Initialized(ctx context.Context) (bool, error) {
+	var n int
+	err := m.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&n)
+	return n > 0, err
+}
+
+// Pending devuelve en orden las migraciones que faltan por aplicar. Igual que
+// Up, falla si alguna migración ya aplicada ha sido modificada.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		row, ok := applied[mig.Version]
+		if !ok {
+			pending = append(pending, mig)
+		} else if row.checksum != mig.Checksum {
+			return nil, fmt.Errorf("%04d_%s: %w", mig.Version, mig.Name, ErrChecksumMismatch)
+		}
+	}
+	return pending, nil
+}
+
+// Up aplica en orden las migraciones pendientes, cada una en su propia
+// transacción, y devuelve las que se han aplicado. Falla antes de tocar nada
+// si alguna migración ya aplicada ha sido modificada.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, mig := range m.migrations {
+		if row, ok := applied[mig.Version]; ok && row.checksum != mig.Checksum {
+			return nil, fmt.Errorf("%04d_%s: %w", mig.Version, mig.Name, ErrChecksumMismatch)
+		}
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		err := m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+				mig.Version, mig.Name, mig.Checksum, time.Now().UTC().Format(time.RFC3339))
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("aplicando %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down revierte las últimas steps migraciones aplicadas, de la más reciente a
+// la más antigua, y devuelve las que se han revertido.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]Migration{}
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	var done []Migration
+	for i := 0; i < steps && i < len(versions); i++ {
+		mig, ok := byVersion[versions[i]]
+		if !ok {
+			return done, fmt.Errorf("la migración %04d está aplicada pero no existe en este binario", versions[i])
+		}
+		err := m.inTx(ctx, func(tx *sql.Tx) error {
+			if strings.TrimSpace(mig.Down) != "" {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("revirtiendo %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Status devuelve el estado de cada migración conocida.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if row, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = row.appliedAt
+			s.Modified = row.checksum != mig.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+var nameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create escribe en dir una nueva pareja de ficheros vacíos con la siguiente
+// versión disponible y devuelve sus rutas.
+func Create(dir, name string) (upPath, downPath string, err error) {
+	name = strings.Trim(nameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("el nombre de la migración no puede estar vacío")
+	}
+
+	existing, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	next := 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	upPath = filepath.Join(dir, base+".up.sql")
+	downPath = filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(upPath, []byte("-- "+base+": cambios a aplicar\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- "+base+": cómo deshacer los cambios\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
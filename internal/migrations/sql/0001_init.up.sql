@@ -0,0 +1,14 @@
+-- Esquema inicial. Usa IF NOT EXISTS para poder adoptar bases de datos
+-- creadas antes de que existieran las migraciones.
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    done BOOLEAN DEFAULT FALSE,
+    user_id INTEGER REFERENCES users(id)
+);
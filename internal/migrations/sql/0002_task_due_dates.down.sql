@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_user_due_at;
+
+ALTER TABLE tasks DROP COLUMN due_at;
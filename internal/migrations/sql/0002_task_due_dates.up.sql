@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN due_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS tasks_user_due_at ON tasks (user_id, due_at);
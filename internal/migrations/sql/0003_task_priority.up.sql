@@ -0,0 +1,2 @@
+-- 0 ninguna, 1 baja, 2 media, 3 alta, 4 urgente
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
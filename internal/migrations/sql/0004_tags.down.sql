@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS users_delete_tags;
+DROP TRIGGER IF EXISTS tags_delete_task_tags;
+DROP TRIGGER IF EXISTS tasks_delete_task_tags;
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
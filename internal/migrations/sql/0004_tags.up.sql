@@ -0,0 +1,29 @@
+-- Etiquetas por usuario y su relación muchos a muchos con las tareas.
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE task_tags (
+    task_id INTEGER NOT NULL REFERENCES tasks(id),
+    tag_id INTEGER NOT NULL REFERENCES tags(id),
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX task_tags_tag_id ON task_tags (tag_id);
+
+-- SQLite no aplica las claves foráneas salvo con PRAGMA foreign_keys, así que
+-- el borrado en cascada se hace con triggers.
+CREATE TRIGGER tasks_delete_task_tags AFTER DELETE ON tasks BEGIN
+    DELETE FROM task_tags WHERE task_id = OLD.id;
+END;
+
+CREATE TRIGGER tags_delete_task_tags AFTER DELETE ON tags BEGIN
+    DELETE FROM task_tags WHERE tag_id = OLD.id;
+END;
+
+CREATE TRIGGER users_delete_tags AFTER DELETE ON users BEGIN
+    DELETE FROM tags WHERE user_id = OLD.id;
+END;
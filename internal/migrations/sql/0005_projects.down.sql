@@ -0,0 +1,24 @@
+DROP TRIGGER IF EXISTS users_delete_projects;
+DROP TRIGGER IF EXISTS projects_delete_unassign_tasks;
+DROP INDEX IF EXISTS tasks_project_id;
+
+-- SQLite no permite DROP COLUMN de una clave foránea: se reconstruye la tabla.
+CREATE TABLE tasks_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    done BOOLEAN DEFAULT FALSE,
+    user_id INTEGER REFERENCES users(id),
+    due_at DATETIME,
+    priority INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO tasks_old (id, title, done, user_id, due_at, priority)
+    SELECT id, title, done, user_id, due_at, priority FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_old RENAME TO tasks;
+
+CREATE INDEX tasks_user_due_at ON tasks (user_id, due_at);
+CREATE TRIGGER tasks_delete_task_tags AFTER DELETE ON tasks BEGIN
+    DELETE FROM task_tags WHERE task_id = OLD.id;
+END;
+
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,20 @@
+-- Proyectos (listas) de cada usuario. Una tarea pertenece como mucho a uno.
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects(id);
+
+CREATE INDEX tasks_project_id ON tasks (project_id);
+
+-- Al borrar un proyecto sus tareas quedan sin proyecto.
+CREATE TRIGGER projects_delete_unassign_tasks AFTER DELETE ON projects BEGIN
+    UPDATE tasks SET project_id = NULL WHERE project_id = OLD.id;
+END;
+
+CREATE TRIGGER users_delete_projects AFTER DELETE ON users BEGIN
+    DELETE FROM projects WHERE user_id = OLD.id;
+END;
@@ -0,0 +1,29 @@
+DROP TRIGGER IF EXISTS tasks_delete_subtasks;
+DROP INDEX IF EXISTS tasks_parent_id;
+DELETE FROM tasks WHERE parent_id IS NOT NULL;
+
+-- SQLite no permite DROP COLUMN de una clave foránea: se reconstruye la tabla.
+-- El trigger de proyectos hace referencia a tasks y se recrea al final.
+DROP TRIGGER IF EXISTS projects_delete_unassign_tasks;
+CREATE TABLE tasks_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    done BOOLEAN DEFAULT FALSE,
+    user_id INTEGER REFERENCES users(id),
+    due_at DATETIME,
+    priority INTEGER NOT NULL DEFAULT 0,
+    project_id INTEGER REFERENCES projects(id)
+);
+INSERT INTO tasks_old (id, title, done, user_id, due_at, priority, project_id)
+    SELECT id, title, done, user_id, due_at, priority, project_id FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_old RENAME TO tasks;
+
+CREATE INDEX tasks_user_due_at ON tasks (user_id, due_at);
+CREATE INDEX tasks_project_id ON tasks (project_id);
+CREATE TRIGGER tasks_delete_task_tags AFTER DELETE ON tasks BEGIN
+    DELETE FROM task_tags WHERE task_id = OLD.id;
+END;
+CREATE TRIGGER projects_delete_unassign_tasks AFTER DELETE ON projects BEGIN
+    UPDATE tasks SET project_id = NULL WHERE project_id = OLD.id;
+END;
@@ -0,0 +1,10 @@
+-- Subtareas: una tarea puede colgar de otra (un solo nivel, como una lista de
+-- comprobación).
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER REFERENCES tasks(id);
+
+CREATE INDEX tasks_parent_id ON tasks (parent_id);
+
+-- Al borrar una tarea se borran sus subtareas.
+CREATE TRIGGER tasks_delete_subtasks AFTER DELETE ON tasks BEGIN
+    DELETE FROM tasks WHERE parent_id = OLD.id;
+END;
@@ -0,0 +1,3 @@
+-- Regla de repetición (subconjunto de RRULE de RFC 5545) de las tareas que
+-- se repiten. Solo la lleva la repetición pendiente de cada serie.
+ALTER TABLE tasks ADD COLUMN recurrence TEXT;
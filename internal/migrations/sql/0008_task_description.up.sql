@@ -0,0 +1,3 @@
+-- Notas de las tareas en Markdown. Se guardan tal y como las escribe el
+-- usuario; se convierten a HTML (saneado) al mostrarlas.
+ALTER TABLE tasks ADD COLUMN description TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,25 @@
+-- Búsqueda de texto completo en el título y las notas de las tareas. El
+-- índice FTS5 no guarda el texto (content='tasks'); los triggers lo mantienen
+-- al día con la tabla tasks.
+CREATE VIRTUAL TABLE tasks_fts USING fts5(
+    title,
+    description,
+    content = 'tasks',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO tasks_fts (tasks_fts) VALUES ('rebuild');
+
+CREATE TRIGGER tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+END;
+
+CREATE TRIGGER tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+END;
+
+CREATE TRIGGER tasks_fts_update AFTER UPDATE OF title, description ON tasks BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', OLD.id, OLD.title, OLD.description);
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (NEW.id, NEW.title, NEW.description);
+END;
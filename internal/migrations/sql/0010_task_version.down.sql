@@ -0,0 +1,4 @@
+DROP TRIGGER task_tags_version_delete;
+DROP TRIGGER task_tags_version_insert;
+DROP TRIGGER tasks_version_update;
+ALTER TABLE tasks DROP COLUMN version;
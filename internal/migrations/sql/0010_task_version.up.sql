@@ -0,0 +1,17 @@
+-- Versión de cada tarea para el control de concurrencia optimista: la API la
+-- devuelve como ETag y la exige en If-Match para cambiar la tarea.
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Cualquier UPDATE de la tarea sube la versión, salvo que ya la cambie él.
+CREATE TRIGGER tasks_version_update AFTER UPDATE ON tasks WHEN NEW.version = OLD.version BEGIN
+    UPDATE tasks SET version = OLD.version + 1 WHERE id = NEW.id;
+END;
+
+-- Las etiquetas también son parte de la tarea.
+CREATE TRIGGER task_tags_version_insert AFTER INSERT ON task_tags BEGIN
+    UPDATE tasks SET version = version + 1 WHERE id = NEW.task_id;
+END;
+
+CREATE TRIGGER task_tags_version_delete AFTER DELETE ON task_tags BEGIN
+    UPDATE tasks SET version = version + 1 WHERE id = OLD.task_id;
+END;
@@ -0,0 +1,26 @@
+DROP TRIGGER users_tasks_delete;
+DROP TRIGGER users_tasks_update;
+DROP TRIGGER users_tasks_insert;
+DROP TRIGGER subtasks_version_delete;
+DROP TRIGGER subtasks_version_update;
+DROP TRIGGER subtasks_version_insert;
+DROP TRIGGER tags_version_rename;
+DROP TRIGGER task_tags_version_delete;
+DROP TRIGGER task_tags_version_insert;
+DROP TRIGGER tasks_version_update;
+DROP TRIGGER tasks_updated_insert;
+
+ALTER TABLE users DROP COLUMN tasks_updated_at;
+ALTER TABLE tasks DROP COLUMN updated_at;
+
+CREATE TRIGGER tasks_version_update AFTER UPDATE ON tasks WHEN NEW.version = OLD.version BEGIN
+    UPDATE tasks SET version = OLD.version + 1 WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER task_tags_version_insert AFTER INSERT ON task_tags BEGIN
+    UPDATE tasks SET version = version + 1 WHERE id = NEW.task_id;
+END;
+
+CREATE TRIGGER task_tags_version_delete AFTER DELETE ON task_tags BEGIN
+    UPDATE tasks SET version = version + 1 WHERE id = OLD.task_id;
+END;
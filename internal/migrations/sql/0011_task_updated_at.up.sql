@@ -0,0 +1,65 @@
+-- Fecha del último cambio de cada tarea y de las tareas de cada usuario, para
+-- los GET condicionales (Last-Modified e If-Modified-Since). Las mantienen los
+-- triggers, igual que la versión, que además pasa a cubrir todo lo que la API
+-- devuelve de una tarea: también el progreso de sus subtareas y el nombre de
+-- sus etiquetas.
+DROP TRIGGER tasks_version_update;
+DROP TRIGGER task_tags_version_insert;
+DROP TRIGGER task_tags_version_delete;
+
+ALTER TABLE tasks ADD COLUMN updated_at DATETIME;
+ALTER TABLE users ADD COLUMN tasks_updated_at DATETIME;
+
+UPDATE tasks SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now');
+UPDATE users SET tasks_updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now');
+
+-- Las tareas que se insertan sin fecha toman la actual.
+CREATE TRIGGER tasks_updated_insert AFTER INSERT ON tasks WHEN NEW.updated_at IS NULL BEGIN
+    UPDATE tasks SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+-- Cualquier UPDATE de la tarea sube la versión y la fecha, salvo que ya las
+-- cambie él.
+CREATE TRIGGER tasks_version_update AFTER UPDATE ON tasks
+WHEN NEW.version = OLD.version AND NEW.updated_at IS OLD.updated_at BEGIN
+    UPDATE tasks SET version = OLD.version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER task_tags_version_insert AFTER INSERT ON task_tags BEGIN
+    UPDATE tasks SET version = version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.task_id;
+END;
+
+CREATE TRIGGER task_tags_version_delete AFTER DELETE ON task_tags BEGIN
+    UPDATE tasks SET version = version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = OLD.task_id;
+END;
+
+CREATE TRIGGER tags_version_rename AFTER UPDATE OF name ON tags BEGIN
+    UPDATE tasks SET version = version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = NEW.id);
+END;
+
+-- El progreso de las subtareas es parte de la tarea principal.
+CREATE TRIGGER subtasks_version_insert AFTER INSERT ON tasks WHEN NEW.parent_id IS NOT NULL BEGIN
+    UPDATE tasks SET version = version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.parent_id;
+END;
+
+CREATE TRIGGER subtasks_version_update AFTER UPDATE OF done ON tasks WHEN NEW.parent_id IS NOT NULL BEGIN
+    UPDATE tasks SET version = version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.parent_id;
+END;
+
+CREATE TRIGGER subtasks_version_delete AFTER DELETE ON tasks WHEN OLD.parent_id IS NOT NULL BEGIN
+    UPDATE tasks SET version = version + 1, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = OLD.parent_id;
+END;
+
+-- Cualquier cambio en las tareas de un usuario cambia sus listados.
+CREATE TRIGGER users_tasks_insert AFTER INSERT ON tasks BEGIN
+    UPDATE users SET tasks_updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.user_id;
+END;
+
+CREATE TRIGGER users_tasks_update AFTER UPDATE ON tasks BEGIN
+    UPDATE users SET tasks_updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id IN (OLD.user_id, NEW.user_id);
+END;
+
+CREATE TRIGGER users_tasks_delete AFTER DELETE ON tasks BEGIN
+    UPDATE users SET tasks_updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = OLD.user_id;
+END;
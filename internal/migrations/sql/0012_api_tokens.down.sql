@@ -0,0 +1,2 @@
+DROP TRIGGER users_delete_api_tokens;
+DROP TABLE api_tokens;
@@ -0,0 +1,18 @@
+-- Tokens personales de la API. Del token solo se guarda el hash SHA-256; el
+-- prefijo sirve para reconocerlo en los listados.
+CREATE TABLE api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER users_delete_api_tokens AFTER DELETE ON users BEGIN
+    DELETE FROM api_tokens WHERE user_id = OLD.id;
+END;
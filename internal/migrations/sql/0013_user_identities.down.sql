@@ -0,0 +1,2 @@
+DROP TRIGGER users_delete_user_identities;
+DROP TABLE user_identities;
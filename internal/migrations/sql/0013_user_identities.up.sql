@@ -0,0 +1,16 @@
+-- Identidades de un proveedor OpenID Connect vinculadas a usuarios. Un
+-- usuario creado desde el proveedor tiene password_hash vacío y no puede
+-- entrar con contraseña.
+CREATE TABLE user_identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT,
+    created_at DATETIME NOT NULL,
+    UNIQUE (issuer, subject)
+);
+
+CREATE TRIGGER users_delete_user_identities AFTER DELETE ON users BEGIN
+    DELETE FROM user_identities WHERE user_id = OLD.id;
+END;
@@ -0,0 +1,6 @@
+DROP TRIGGER users_delete_user_recovery_codes;
+DROP TABLE user_recovery_codes;
+
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_secret;
@@ -0,0 +1,19 @@
+-- Verificación en dos pasos con TOTP (RFC 6238). totp_secret es el secreto
+-- base32 del usuario, que se guarda al empezar el alta y solo cuenta cuando
+-- totp_enabled_at tiene fecha. totp_last_step es el último paso de 30 s
+-- aceptado, para que un código no se pueda usar dos veces.
+ALTER TABLE users ADD COLUMN totp_secret TEXT;
+ALTER TABLE users ADD COLUMN totp_enabled_at DATETIME;
+ALTER TABLE users ADD COLUMN totp_last_step INTEGER;
+
+-- Códigos de recuperación de un solo uso. Solo se guarda su hash SHA-256.
+CREATE TABLE user_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    code_hash TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TRIGGER users_delete_user_recovery_codes AFTER DELETE ON users BEGIN
+    DELETE FROM user_recovery_codes WHERE user_id = OLD.id;
+END;
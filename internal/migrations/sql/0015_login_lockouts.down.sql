@@ -0,0 +1,3 @@
+DROP INDEX login_failures_username;
+DROP TABLE login_failures;
+DROP TABLE login_throttles;
@@ -0,0 +1,24 @@
+-- Intentos fallidos de login, por nombre de usuario y por IP, para bloquear
+-- temporalmente el login tras demasiados fallos. failures cuenta los fallos
+-- seguidos y locked_until es el final del bloqueo.
+CREATE TABLE login_throttles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scope TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at DATETIME NOT NULL,
+    locked_until DATETIME,
+    UNIQUE (scope, subject)
+);
+
+-- Registro de los logins fallidos. Se guarda el nombre normalizado, exista o
+-- no el usuario, así que no depende de la tabla users.
+CREATE TABLE login_failures (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX login_failures_username ON login_failures (username, created_at);
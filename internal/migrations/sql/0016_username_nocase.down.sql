@@ -0,0 +1,3 @@
+DROP TRIGGER users_username_nocase_update;
+DROP TRIGGER users_username_nocase_insert;
+DROP INDEX users_username_nocase;
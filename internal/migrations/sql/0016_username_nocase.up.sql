@@ -0,0 +1,17 @@
+-- Los nombres de usuario son únicos sin distinguir mayúsculas. No se cambia
+-- la restricción UNIQUE de la tabla para no romper las bases de datos que ya
+-- tengan nombres que solo difieren en mayúsculas; los triggers impiden crear
+-- más. NOCASE solo pliega las letras ASCII.
+CREATE INDEX users_username_nocase ON users (username COLLATE NOCASE);
+
+CREATE TRIGGER users_username_nocase_insert BEFORE INSERT ON users
+WHEN EXISTS (SELECT 1 FROM users WHERE username = NEW.username COLLATE NOCASE)
+BEGIN
+    SELECT RAISE(ABORT, 'UNIQUE constraint failed: users.username');
+END;
+
+CREATE TRIGGER users_username_nocase_update BEFORE UPDATE OF username ON users
+WHEN EXISTS (SELECT 1 FROM users WHERE username = NEW.username COLLATE NOCASE AND id <> NEW.id)
+BEGIN
+    SELECT RAISE(ABORT, 'UNIQUE constraint failed: users.username');
+END;
@@ -4,9 +4,25 @@
 package models
 
 var TableNames = struct {
-	Tasks string
-	Users string
+	APITokens         string
+	LoginFailures     string
+	LoginThrottles    string
+	Projects          string
+	Tags              string
+	TaskTags          string
+	Tasks             string
+	UserIdentities    string
+	UserRecoveryCodes string
+	Users             string
 }{
-	Tasks: "tasks",
-	Users: "users",
+	APITokens:         "api_tokens",
+	LoginFailures:     "login_failures",
+	LoginThrottles:    "login_throttles",
+	Projects:          "projects",
+	Tags:              "tags",
+	TaskTags:          "task_tags",
+	Tasks:             "tasks",
+	UserIdentities:    "user_identities",
+	UserRecoveryCodes: "user_recovery_codes",
+	Users:             "users",
 }
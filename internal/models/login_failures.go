@@ -0,0 +1,961 @@
+// Code generated by SQLBoiler 4.19.1 (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friendsofgo/errors"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"github.com/volatiletech/sqlboiler/v4/queries/qmhelper"
+	"github.com/volatiletech/strmangle"
+)
+
+// LoginFailure is an object representing the database table.
+type LoginFailure struct {
+	ID        null.Int64 `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
+	Username  string     `boil:"username" json:"username" toml:"username" yaml:"username"`
+	IP        string     `boil:"ip" json:"ip" toml:"ip" yaml:"ip"`
+	Reason    string     `boil:"reason" json:"reason" toml:"reason" yaml:"reason"`
+	CreatedAt time.Time  `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+
+	R *loginFailureR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L loginFailureL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var LoginFailureColumns = struct {
+	ID        string
+	Username  string
+	IP        string
+	Reason    string
+	CreatedAt string
+}{
+	ID:        "id",
+	Username:  "username",
+	IP:        "ip",
+	Reason:    "reason",
+	CreatedAt: "created_at",
+}
+
+var LoginFailureTableColumns = struct {
+	ID        string
+	Username  string
+	IP        string
+	Reason    string
+	CreatedAt string
+}{
+	ID:        "login_failures.id",
+	Username:  "login_failures.username",
+	IP:        "login_failures.ip",
+	Reason:    "login_failures.reason",
+	CreatedAt: "login_failures.created_at",
+}
+
+// Generated where
+
+var LoginFailureWhere = struct {
+	ID        whereHelpernull_Int64
+	Username  whereHelperstring
+	IP        whereHelperstring
+	Reason    whereHelperstring
+	CreatedAt whereHelpertime_Time
+}{
+	ID:        whereHelpernull_Int64{field: "\"login_failures\".\"id\""},
+	Username:  whereHelperstring{field: "\"login_failures\".\"username\""},
+	IP:        whereHelperstring{field: "\"login_failures\".\"ip\""},
+	Reason:    whereHelperstring{field: "\"login_failures\".\"reason\""},
+	CreatedAt: whereHelpertime_Time{field: "\"login_failures\".\"created_at\""},
+}
+
+// LoginFailureRels is where relationship names are stored.
+var LoginFailureRels = struct {
+}{}
+
+// loginFailureR is where relationships are stored.
+type loginFailureR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*loginFailureR) NewStruct() *loginFailureR {
+	return &loginFailureR{}
+}
+
+// loginFailureL is where Load methods for each relationship are stored.
+type loginFailureL struct{}
+
+var (
+	loginFailureAllColumns            = []string{"id", "username", "ip", "reason", "created_at"}
+	loginFailureColumnsWithoutDefault = []string{"username", "ip", "reason", "created_at"}
+	loginFailureColumnsWithDefault    = []string{"id"}
+	loginFailurePrimaryKeyColumns     = []string{"id"}
+	loginFailureGeneratedColumns      = []string{"id"}
+)
+
+type (
+	// LoginFailureSlice is an alias for a slice of pointers to LoginFailure.
+	// This should almost always be used instead of []LoginFailure.
+	LoginFailureSlice []*LoginFailure
+	// LoginFailureHook is the signature for custom LoginFailure hook methods
+	LoginFailureHook func(context.Context, boil.ContextExecutor, *LoginFailure) error
+
+	loginFailureQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	loginFailureType                 = reflect.TypeOf(&LoginFailure{})
+	loginFailureMapping              = queries.MakeStructMapping(loginFailureType)
+	loginFailurePrimaryKeyMapping, _ = queries.BindMapping(loginFailureType, loginFailureMapping, loginFailurePrimaryKeyColumns)
+	loginFailureInsertCacheMut       sync.RWMutex
+	loginFailureInsertCache          = make(map[string]insertCache)
+	loginFailureUpdateCacheMut       sync.RWMutex
+	loginFailureUpdateCache          = make(map[string]updateCache)
+	loginFailureUpsertCacheMut       sync.RWMutex
+	loginFailureUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var loginFailureAfterSelectMu sync.Mutex
+var loginFailureAfterSelectHooks []LoginFailureHook
+
+var loginFailureBeforeInsertMu sync.Mutex
+var loginFailureBeforeInsertHooks []LoginFailureHook
+var loginFailureAfterInsertMu sync.Mutex
+var loginFailureAfterInsertHooks []LoginFailureHook
+
+var loginFailureBeforeUpdateMu sync.Mutex
+var loginFailureBeforeUpdateHooks []LoginFailureHook
+var loginFailureAfterUpdateMu sync.Mutex
+var loginFailureAfterUpdateHooks []LoginFailureHook
+
+var loginFailureBeforeDeleteMu sync.Mutex
+var loginFailureBeforeDeleteHooks []LoginFailureHook
+var loginFailureAfterDeleteMu sync.Mutex
+var loginFailureAfterDeleteHooks []LoginFailureHook
+
+var loginFailureBeforeUpsertMu sync.Mutex
+var loginFailureBeforeUpsertHooks []LoginFailureHook
+var loginFailureAfterUpsertMu sync.Mutex
+var loginFailureAfterUpsertHooks []LoginFailureHook
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *LoginFailure) doAfterSelectHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureAfterSelectHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *LoginFailure) doBeforeInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureBeforeInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *LoginFailure) doAfterInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureAfterInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *LoginFailure) doBeforeUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureBeforeUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *LoginFailure) doAfterUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureAfterUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *LoginFailure) doBeforeDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureBeforeDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *LoginFailure) doAfterDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureAfterDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *LoginFailure) doBeforeUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureBeforeUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *LoginFailure) doAfterUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginFailureAfterUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddLoginFailureHook registers your hook function for all future operations.
+func AddLoginFailureHook(hookPoint boil.HookPoint, loginFailureHook LoginFailureHook) {
+	switch hookPoint {
+	case boil.AfterSelectHook:
+		loginFailureAfterSelectMu.Lock()
+		loginFailureAfterSelectHooks = append(loginFailureAfterSelectHooks, loginFailureHook)
+		loginFailureAfterSelectMu.Unlock()
+	case boil.BeforeInsertHook:
+		loginFailureBeforeInsertMu.Lock()
+		loginFailureBeforeInsertHooks = append(loginFailureBeforeInsertHooks, loginFailureHook)
+		loginFailureBeforeInsertMu.Unlock()
+	case boil.AfterInsertHook:
+		loginFailureAfterInsertMu.Lock()
+		loginFailureAfterInsertHooks = append(loginFailureAfterInsertHooks, loginFailureHook)
+		loginFailureAfterInsertMu.Unlock()
+	case boil.BeforeUpdateHook:
+		loginFailureBeforeUpdateMu.Lock()
+		loginFailureBeforeUpdateHooks = append(loginFailureBeforeUpdateHooks, loginFailureHook)
+		loginFailureBeforeUpdateMu.Unlock()
+	case boil.AfterUpdateHook:
+		loginFailureAfterUpdateMu.Lock()
+		loginFailureAfterUpdateHooks = append(loginFailureAfterUpdateHooks, loginFailureHook)
+		loginFailureAfterUpdateMu.Unlock()
+	case boil.BeforeDeleteHook:
+		loginFailureBeforeDeleteMu.Lock()
+		loginFailureBeforeDeleteHooks = append(loginFailureBeforeDeleteHooks, loginFailureHook)
+		loginFailureBeforeDeleteMu.Unlock()
+	case boil.AfterDeleteHook:
+		loginFailureAfterDeleteMu.Lock()
+		loginFailureAfterDeleteHooks = append(loginFailureAfterDeleteHooks, loginFailureHook)
+		loginFailureAfterDeleteMu.Unlock()
+	case boil.BeforeUpsertHook:
+		loginFailureBeforeUpsertMu.Lock()
+		loginFailureBeforeUpsertHooks = append(loginFailureBeforeUpsertHooks, loginFailureHook)
+		loginFailureBeforeUpsertMu.Unlock()
+	case boil.AfterUpsertHook:
+		loginFailureAfterUpsertMu.Lock()
+		loginFailureAfterUpsertHooks = append(loginFailureAfterUpsertHooks, loginFailureHook)
+		loginFailureAfterUpsertMu.Unlock()
+	}
+}
+
+// One returns a single loginFailure record from the query.
+func (q loginFailureQuery) One(ctx context.Context, exec boil.ContextExecutor) (*LoginFailure, error) {
+	o := &LoginFailure{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for login_failures")
+	}
+
+	if err := o.doAfterSelectHooks(ctx, exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// All returns all LoginFailure records from the query.
+func (q loginFailureQuery) All(ctx context.Context, exec boil.ContextExecutor) (LoginFailureSlice, error) {
+	var o []*LoginFailure
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to LoginFailure slice")
+	}
+
+	if len(loginFailureAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(ctx, exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all LoginFailure records in the query.
+func (q loginFailureQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count login_failures rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q loginFailureQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if login_failures exists")
+	}
+
+	return count > 0, nil
+}
+
+// LoginFailures retrieves all the records using an executor.
+func LoginFailures(mods ...qm.QueryMod) loginFailureQuery {
+	mods = append(mods, qm.From("\"login_failures\""))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"\"login_failures\".*"})
+	}
+
+	return loginFailureQuery{q}
+}
+
+// FindLoginFailure retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindLoginFailure(ctx context.Context, exec boil.ContextExecutor, iD null.Int64, selectCols ...string) (*LoginFailure, error) {
+	loginFailureObj := &LoginFailure{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"login_failures\" where \"id\"=?", sel,
+	)
+
+	q := queries.Raw(query, iD)
+
+	err := q.Bind(ctx, exec, loginFailureObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from login_failures")
+	}
+
+	if err = loginFailureObj.doAfterSelectHooks(ctx, exec); err != nil {
+		return loginFailureObj, err
+	}
+
+	return loginFailureObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *LoginFailure) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no login_failures provided for insertion")
+	}
+
+	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
+
+	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(loginFailureColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	loginFailureInsertCacheMut.RLock()
+	cache, cached := loginFailureInsertCache[key]
+	loginFailureInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			loginFailureAllColumns,
+			loginFailureColumnsWithDefault,
+			loginFailureColumnsWithoutDefault,
+			nzDefaults,
+		)
+		wl = strmangle.SetComplement(wl, loginFailureGeneratedColumns)
+
+		cache.valueMapping, err = queries.BindMapping(loginFailureType, loginFailureMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(loginFailureType, loginFailureMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"login_failures\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"login_failures\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into login_failures")
+	}
+
+	if !cached {
+		loginFailureInsertCacheMut.Lock()
+		loginFailureInsertCache[key] = cache
+		loginFailureInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(ctx, exec)
+}
+
+// Update uses an executor to update the LoginFailure.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *LoginFailure) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	loginFailureUpdateCacheMut.RLock()
+	cache, cached := loginFailureUpdateCache[key]
+	loginFailureUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			loginFailureAllColumns,
+			loginFailurePrimaryKeyColumns,
+		)
+		wl = strmangle.SetComplement(wl, loginFailureGeneratedColumns)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update login_failures, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"login_failures\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 0, wl),
+			strmangle.WhereClause("\"", "\"", 0, loginFailurePrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(loginFailureType, loginFailureMapping, append(wl, loginFailurePrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update login_failures row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for login_failures")
+	}
+
+	if !cached {
+		loginFailureUpdateCacheMut.Lock()
+		loginFailureUpdateCache[key] = cache
+		loginFailureUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(ctx, exec)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q loginFailureQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for login_failures")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for login_failures")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o LoginFailureSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginFailurePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"login_failures\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginFailurePrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in loginFailure slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all loginFailure")
+	}
+	return rowsAff, nil
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *LoginFailure) Upsert(ctx context.Context, exec boil.ContextExecutor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no login_failures provided for upsert")
+	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
+
+	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(loginFailureColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	loginFailureUpsertCacheMut.RLock()
+	cache, cached := loginFailureUpsertCache[key]
+	loginFailureUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			loginFailureAllColumns,
+			loginFailureColumnsWithDefault,
+			loginFailureColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			loginFailureAllColumns,
+			loginFailurePrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert login_failures, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(loginFailureAllColumns, strmangle.SetIntersect(insert, update))
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(loginFailurePrimaryKeyColumns))
+			copy(conflict, loginFailurePrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQuerySQLite(dialect, "\"login_failures\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(loginFailureType, loginFailureMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(loginFailureType, loginFailureMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(returns...)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert login_failures")
+	}
+
+	if !cached {
+		loginFailureUpsertCacheMut.Lock()
+		loginFailureUpsertCache[key] = cache
+		loginFailureUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(ctx, exec)
+}
+
+// Delete deletes a single LoginFailure record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *LoginFailure) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no LoginFailure provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), loginFailurePrimaryKeyMapping)
+	sql := "DELETE FROM \"login_failures\" WHERE \"id\"=?"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from login_failures")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for login_failures")
+	}
+
+	if err := o.doAfterDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q loginFailureQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no loginFailureQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from login_failures")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for login_failures")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o LoginFailureSlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(loginFailureBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginFailurePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"login_failures\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginFailurePrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from loginFailure slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for login_failures")
+	}
+
+	if len(loginFailureAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *LoginFailure) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindLoginFailure(ctx, exec, o.ID)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *LoginFailureSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := LoginFailureSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginFailurePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"login_failures\".* FROM \"login_failures\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginFailurePrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in LoginFailureSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// LoginFailureExists checks if the LoginFailure row exists.
+func LoginFailureExists(ctx context.Context, exec boil.ContextExecutor, iD null.Int64) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"login_failures\" where \"id\"=? limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, iD)
+	}
+	row := exec.QueryRowContext(ctx, sql, iD)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if login_failures exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the LoginFailure row exists.
+func (o *LoginFailure) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return LoginFailureExists(ctx, exec, o.ID)
+}
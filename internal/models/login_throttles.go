@@ -0,0 +1,954 @@
+// Code generated by SQLBoiler 4.19.1 (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friendsofgo/errors"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"github.com/volatiletech/sqlboiler/v4/queries/qmhelper"
+	"github.com/volatiletech/strmangle"
+)
+
+// LoginThrottle is an object representing the database table.
+type LoginThrottle struct {
+	ID            null.Int64 `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
+	Scope         string     `boil:"scope" json:"scope" toml:"scope" yaml:"scope"`
+	Subject       string     `boil:"subject" json:"subject" toml:"subject" yaml:"subject"`
+	Failures      int64      `boil:"failures" json:"failures" toml:"failures" yaml:"failures"`
+	LastFailureAt time.Time  `boil:"last_failure_at" json:"last_failure_at" toml:"last_failure_at" yaml:"last_failure_at"`
+	LockedUntil   null.Time  `boil:"locked_until" json:"locked_until,omitempty" toml:"locked_until" yaml:"locked_until,omitempty"`
+
+	R *loginThrottleR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L loginThrottleL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var LoginThrottleColumns = struct {
+	ID            string
+	Scope         string
+	Subject       string
+	Failures      string
+	LastFailureAt string
+	LockedUntil   string
+}{
+	ID:            "id",
+	Scope:         "scope",
+	Subject:       "subject",
+	Failures:      "failures",
+	LastFailureAt: "last_failure_at",
+	LockedUntil:   "locked_until",
+}
+
+var LoginThrottleTableColumns = struct {
+	ID            string
+	Scope         string
+	Subject       string
+	Failures      string
+	LastFailureAt string
+	LockedUntil   string
+}{
+	ID:            "login_throttles.id",
+	Scope:         "login_throttles.scope",
+	Subject:       "login_throttles.subject",
+	Failures:      "login_throttles.failures",
+	LastFailureAt: "login_throttles.last_failure_at",
+	LockedUntil:   "login_throttles.locked_until",
+}
+
+// Generated where
+
+var LoginThrottleWhere = struct {
+	ID            whereHelpernull_Int64
+	Scope         whereHelperstring
+	Subject       whereHelperstring
+	Failures      whereHelperint64
+	LastFailureAt whereHelpertime_Time
+	LockedUntil   whereHelpernull_Time
+}{
+	ID:            whereHelpernull_Int64{field: "\"login_throttles\".\"id\""},
+	Scope:         whereHelperstring{field: "\"login_throttles\".\"scope\""},
+	Subject:       whereHelperstring{field: "\"login_throttles\".\"subject\""},
+	Failures:      whereHelperint64{field: "\"login_throttles\".\"failures\""},
+	LastFailureAt: whereHelpertime_Time{field: "\"login_throttles\".\"last_failure_at\""},
+	LockedUntil:   whereHelpernull_Time{field: "\"login_throttles\".\"locked_until\""},
+}
+
+// LoginThrottleRels is where relationship names are stored.
+var LoginThrottleRels = struct {
+}{}
+
+// loginThrottleR is where relationships are stored.
+type loginThrottleR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*loginThrottleR) NewStruct() *loginThrottleR {
+	return &loginThrottleR{}
+}
+
+// loginThrottleL is where Load methods for each relationship are stored.
+type loginThrottleL struct{}
+
+var (
+	loginThrottleAllColumns            = []string{"id", "scope", "subject", "failures", "last_failure_at", "locked_until"}
+	loginThrottleColumnsWithoutDefault = []string{"scope", "subject", "last_failure_at"}
+	loginThrottleColumnsWithDefault    = []string{"id", "failures", "locked_until"}
+	loginThrottlePrimaryKeyColumns     = []string{"id"}
+	loginThrottleGeneratedColumns      = []string{"id"}
+)
+
+type (
+	// LoginThrottleSlice is an alias for a slice of pointers to LoginThrottle.
+	// This should almost always be used instead of []LoginThrottle.
+	LoginThrottleSlice []*LoginThrottle
+	// LoginThrottleHook is the signature for custom LoginThrottle hook methods
+	LoginThrottleHook func(context.Context, boil.ContextExecutor, *LoginThrottle) error
+
+	loginThrottleQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	loginThrottleType                 = reflect.TypeOf(&LoginThrottle{})
+	loginThrottleMapping              = queries.MakeStructMapping(loginThrottleType)
+	loginThrottlePrimaryKeyMapping, _ = queries.BindMapping(loginThrottleType, loginThrottleMapping, loginThrottlePrimaryKeyColumns)
+	loginThrottleInsertCacheMut       sync.RWMutex
+	loginThrottleInsertCache          = make(map[string]insertCache)
+	loginThrottleUpdateCacheMut       sync.RWMutex
+	loginThrottleUpdateCache          = make(map[string]updateCache)
+	loginThrottleUpsertCacheMut       sync.RWMutex
+	loginThrottleUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var loginThrottleAfterSelectMu sync.Mutex
+var loginThrottleAfterSelectHooks []LoginThrottleHook
+
+var loginThrottleBeforeInsertMu sync.Mutex
+var loginThrottleBeforeInsertHooks []LoginThrottleHook
+var loginThrottleAfterInsertMu sync.Mutex
+var loginThrottleAfterInsertHooks []LoginThrottleHook
+
+var loginThrottleBeforeUpdateMu sync.Mutex
+var loginThrottleBeforeUpdateHooks []LoginThrottleHook
+var loginThrottleAfterUpdateMu sync.Mutex
+var loginThrottleAfterUpdateHooks []LoginThrottleHook
+
+var loginThrottleBeforeDeleteMu sync.Mutex
+var loginThrottleBeforeDeleteHooks []LoginThrottleHook
+var loginThrottleAfterDeleteMu sync.Mutex
+var loginThrottleAfterDeleteHooks []LoginThrottleHook
+
+var loginThrottleBeforeUpsertMu sync.Mutex
+var loginThrottleBeforeUpsertHooks []LoginThrottleHook
+var loginThrottleAfterUpsertMu sync.Mutex
+var loginThrottleAfterUpsertHooks []LoginThrottleHook
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *LoginThrottle) doAfterSelectHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleAfterSelectHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *LoginThrottle) doBeforeInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleBeforeInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *LoginThrottle) doAfterInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleAfterInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *LoginThrottle) doBeforeUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleBeforeUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *LoginThrottle) doAfterUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleAfterUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *LoginThrottle) doBeforeDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleBeforeDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *LoginThrottle) doAfterDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleAfterDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *LoginThrottle) doBeforeUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleBeforeUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *LoginThrottle) doAfterUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginThrottleAfterUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddLoginThrottleHook registers your hook function for all future operations.
+func AddLoginThrottleHook(hookPoint boil.HookPoint, loginThrottleHook LoginThrottleHook) {
+	switch hookPoint {
+	case boil.AfterSelectHook:
+		loginThrottleAfterSelectMu.Lock()
+		loginThrottleAfterSelectHooks = append(loginThrottleAfterSelectHooks, loginThrottleHook)
+		loginThrottleAfterSelectMu.Unlock()
+	case boil.BeforeInsertHook:
+		loginThrottleBeforeInsertMu.Lock()
+		loginThrottleBeforeInsertHooks = append(loginThrottleBeforeInsertHooks, loginThrottleHook)
+		loginThrottleBeforeInsertMu.Unlock()
+	case boil.AfterInsertHook:
+		loginThrottleAfterInsertMu.Lock()
+		loginThrottleAfterInsertHooks = append(loginThrottleAfterInsertHooks, loginThrottleHook)
+		loginThrottleAfterInsertMu.Unlock()
+	case boil.BeforeUpdateHook:
+		loginThrottleBeforeUpdateMu.Lock()
+		loginThrottleBeforeUpdateHooks = append(loginThrottleBeforeUpdateHooks, loginThrottleHook)
+		loginThrottleBeforeUpdateMu.Unlock()
+	case boil.AfterUpdateHook:
+		loginThrottleAfterUpdateMu.Lock()
+		loginThrottleAfterUpdateHooks = append(loginThrottleAfterUpdateHooks, loginThrottleHook)
+		loginThrottleAfterUpdateMu.Unlock()
+	case boil.BeforeDeleteHook:
+		loginThrottleBeforeDeleteMu.Lock()
+		loginThrottleBeforeDeleteHooks = append(loginThrottleBeforeDeleteHooks, loginThrottleHook)
+		loginThrottleBeforeDeleteMu.Unlock()
+	case boil.AfterDeleteHook:
+		loginThrottleAfterDeleteMu.Lock()
+		loginThrottleAfterDeleteHooks = append(loginThrottleAfterDeleteHooks, loginThrottleHook)
+		loginThrottleAfterDeleteMu.Unlock()
+	case boil.BeforeUpsertHook:
+		loginThrottleBeforeUpsertMu.Lock()
+		loginThrottleBeforeUpsertHooks = append(loginThrottleBeforeUpsertHooks, loginThrottleHook)
+		loginThrottleBeforeUpsertMu.Unlock()
+	case boil.AfterUpsertHook:
+		loginThrottleAfterUpsertMu.Lock()
+		loginThrottleAfterUpsertHooks = append(loginThrottleAfterUpsertHooks, loginThrottleHook)
+		loginThrottleAfterUpsertMu.Unlock()
+	}
+}
+
+// One returns a single loginThrottle record from the query.
+func (q loginThrottleQuery) One(ctx context.Context, exec boil.ContextExecutor) (*LoginThrottle, error) {
+	o := &LoginThrottle{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for login_throttles")
+	}
+
+	if err := o.doAfterSelectHooks(ctx, exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// All returns all LoginThrottle records from the query.
+func (q loginThrottleQuery) All(ctx context.Context, exec boil.ContextExecutor) (LoginThrottleSlice, error) {
+	var o []*LoginThrottle
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to LoginThrottle slice")
+	}
+
+	if len(loginThrottleAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(ctx, exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all LoginThrottle records in the query.
+func (q loginThrottleQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count login_throttles rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q loginThrottleQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if login_throttles exists")
+	}
+
+	return count > 0, nil
+}
+
+// LoginThrottles retrieves all the records using an executor.
+func LoginThrottles(mods ...qm.QueryMod) loginThrottleQuery {
+	mods = append(mods, qm.From("\"login_throttles\""))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"\"login_throttles\".*"})
+	}
+
+	return loginThrottleQuery{q}
+}
+
+// FindLoginThrottle retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindLoginThrottle(ctx context.Context, exec boil.ContextExecutor, iD null.Int64, selectCols ...string) (*LoginThrottle, error) {
+	loginThrottleObj := &LoginThrottle{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"login_throttles\" where \"id\"=?", sel,
+	)
+
+	q := queries.Raw(query, iD)
+
+	err := q.Bind(ctx, exec, loginThrottleObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from login_throttles")
+	}
+
+	if err = loginThrottleObj.doAfterSelectHooks(ctx, exec); err != nil {
+		return loginThrottleObj, err
+	}
+
+	return loginThrottleObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *LoginThrottle) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no login_throttles provided for insertion")
+	}
+
+	var err error
+
+	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(loginThrottleColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	loginThrottleInsertCacheMut.RLock()
+	cache, cached := loginThrottleInsertCache[key]
+	loginThrottleInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			loginThrottleAllColumns,
+			loginThrottleColumnsWithDefault,
+			loginThrottleColumnsWithoutDefault,
+			nzDefaults,
+		)
+		wl = strmangle.SetComplement(wl, loginThrottleGeneratedColumns)
+
+		cache.valueMapping, err = queries.BindMapping(loginThrottleType, loginThrottleMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(loginThrottleType, loginThrottleMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"login_throttles\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"login_throttles\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into login_throttles")
+	}
+
+	if !cached {
+		loginThrottleInsertCacheMut.Lock()
+		loginThrottleInsertCache[key] = cache
+		loginThrottleInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(ctx, exec)
+}
+
+// Update uses an executor to update the LoginThrottle.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *LoginThrottle) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	loginThrottleUpdateCacheMut.RLock()
+	cache, cached := loginThrottleUpdateCache[key]
+	loginThrottleUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			loginThrottleAllColumns,
+			loginThrottlePrimaryKeyColumns,
+		)
+		wl = strmangle.SetComplement(wl, loginThrottleGeneratedColumns)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update login_throttles, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"login_throttles\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 0, wl),
+			strmangle.WhereClause("\"", "\"", 0, loginThrottlePrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(loginThrottleType, loginThrottleMapping, append(wl, loginThrottlePrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update login_throttles row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for login_throttles")
+	}
+
+	if !cached {
+		loginThrottleUpdateCacheMut.Lock()
+		loginThrottleUpdateCache[key] = cache
+		loginThrottleUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(ctx, exec)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q loginThrottleQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for login_throttles")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for login_throttles")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o LoginThrottleSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginThrottlePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"login_throttles\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginThrottlePrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in loginThrottle slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all loginThrottle")
+	}
+	return rowsAff, nil
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *LoginThrottle) Upsert(ctx context.Context, exec boil.ContextExecutor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no login_throttles provided for upsert")
+	}
+
+	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(loginThrottleColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	loginThrottleUpsertCacheMut.RLock()
+	cache, cached := loginThrottleUpsertCache[key]
+	loginThrottleUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			loginThrottleAllColumns,
+			loginThrottleColumnsWithDefault,
+			loginThrottleColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			loginThrottleAllColumns,
+			loginThrottlePrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert login_throttles, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(loginThrottleAllColumns, strmangle.SetIntersect(insert, update))
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(loginThrottlePrimaryKeyColumns))
+			copy(conflict, loginThrottlePrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQuerySQLite(dialect, "\"login_throttles\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(loginThrottleType, loginThrottleMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(loginThrottleType, loginThrottleMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(returns...)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert login_throttles")
+	}
+
+	if !cached {
+		loginThrottleUpsertCacheMut.Lock()
+		loginThrottleUpsertCache[key] = cache
+		loginThrottleUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(ctx, exec)
+}
+
+// Delete deletes a single LoginThrottle record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *LoginThrottle) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no LoginThrottle provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), loginThrottlePrimaryKeyMapping)
+	sql := "DELETE FROM \"login_throttles\" WHERE \"id\"=?"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from login_throttles")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for login_throttles")
+	}
+
+	if err := o.doAfterDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q loginThrottleQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no loginThrottleQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from login_throttles")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for login_throttles")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o LoginThrottleSlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(loginThrottleBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginThrottlePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"login_throttles\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginThrottlePrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from loginThrottle slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for login_throttles")
+	}
+
+	if len(loginThrottleAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *LoginThrottle) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindLoginThrottle(ctx, exec, o.ID)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *LoginThrottleSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := LoginThrottleSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginThrottlePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"login_throttles\".* FROM \"login_throttles\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginThrottlePrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in LoginThrottleSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// LoginThrottleExists checks if the LoginThrottle row exists.
+func LoginThrottleExists(ctx context.Context, exec boil.ContextExecutor, iD null.Int64) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"login_throttles\" where \"id\"=? limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, iD)
+	}
+	row := exec.QueryRowContext(ctx, sql, iD)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if login_throttles exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the LoginThrottle row exists.
+func (o *LoginThrottle) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return LoginThrottleExists(ctx, exec, o.ID)
+}
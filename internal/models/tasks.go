@@ -24,150 +24,199 @@ import (
 
 // Task is an object representing the database table.
 type Task struct {
-	ID     null.Int64 `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
-	Title  string     `boil:"title" json:"title" toml:"title" yaml:"title"`
-	Done   null.Bool  `boil:"done" json:"done,omitempty" toml:"done" yaml:"done,omitempty"`
-	UserID null.Int64 `boil:"user_id" json:"user_id,omitempty" toml:"user_id" yaml:"user_id,omitempty"`
+	ID          null.Int64  `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
+	Title       string      `boil:"title" json:"title" toml:"title" yaml:"title"`
+	Done        null.Bool   `boil:"done" json:"done,omitempty" toml:"done" yaml:"done,omitempty"`
+	UserID      null.Int64  `boil:"user_id" json:"user_id,omitempty" toml:"user_id" yaml:"user_id,omitempty"`
+	DueAt       null.Time   `boil:"due_at" json:"due_at,omitempty" toml:"due_at" yaml:"due_at,omitempty"`
+	Priority    int64       `boil:"priority" json:"priority" toml:"priority" yaml:"priority"`
+	ProjectID   null.Int64  `boil:"project_id" json:"project_id,omitempty" toml:"project_id" yaml:"project_id,omitempty"`
+	ParentID    null.Int64  `boil:"parent_id" json:"parent_id,omitempty" toml:"parent_id" yaml:"parent_id,omitempty"`
+	Recurrence  null.String `boil:"recurrence" json:"recurrence,omitempty" toml:"recurrence" yaml:"recurrence,omitempty"`
+	Description string      `boil:"description" json:"description" toml:"description" yaml:"description"`
+	Version     int64       `boil:"version" json:"version" toml:"version" yaml:"version"`
+	UpdatedAt   null.Time   `boil:"updated_at" json:"updated_at,omitempty" toml:"updated_at" yaml:"updated_at,omitempty"`
 
 	R *taskR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L taskL  `boil:"-" json:"-" toml:"-" yaml:"-"`
 }
 
 var TaskColumns = struct {
-	ID     string
-	Title  string
-	Done   string
-	UserID string
+	ID          string
+	Title       string
+	Done        string
+	UserID      string
+	DueAt       string
+	Priority    string
+	ProjectID   string
+	ParentID    string
+	Recurrence  string
+	Description string
+	Version     string
+	UpdatedAt   string
 }{
-	ID:     "id",
-	Title:  "title",
-	Done:   "done",
-	UserID: "user_id",
+	ID:          "id",
+	Title:       "title",
+	Done:        "done",
+	UserID:      "user_id",
+	DueAt:       "due_at",
+	Priority:    "priority",
+	ProjectID:   "project_id",
+	ParentID:    "parent_id",
+	Recurrence:  "recurrence",
+	Description: "description",
+	Version:     "version",
+	UpdatedAt:   "updated_at",
 }
 
 var TaskTableColumns = struct {
-	ID     string
-	Title  string
-	Done   string
-	UserID string
+	ID          string
+	Title       string
+	Done        string
+	UserID      string
+	DueAt       string
+	Priority    string
+	ProjectID   string
+	ParentID    string
+	Recurrence  string
+	Description string
+	Version     string
+	UpdatedAt   string
 }{
-	ID:     "tasks.id",
-	Title:  "tasks.title",
-	Done:   "tasks.done",
-	UserID: "tasks.user_id",
+	ID:          "tasks.id",
+	Title:       "tasks.title",
+	Done:        "tasks.done",
+	UserID:      "tasks.user_id",
+	DueAt:       "tasks.due_at",
+	Priority:    "tasks.priority",
+	ProjectID:   "tasks.project_id",
+	ParentID:    "tasks.parent_id",
+	Recurrence:  "tasks.recurrence",
+	Description: "tasks.description",
+	Version:     "tasks.version",
+	UpdatedAt:   "tasks.updated_at",
 }
 
 // Generated where
 
-type whereHelpernull_Int64 struct{ field string }
+type whereHelpernull_Bool struct{ field string }
 
-func (w whereHelpernull_Int64) EQ(x null.Int64) qm.QueryMod {
+func (w whereHelpernull_Bool) EQ(x null.Bool) qm.QueryMod {
 	return qmhelper.WhereNullEQ(w.field, false, x)
 }
-func (w whereHelpernull_Int64) NEQ(x null.Int64) qm.QueryMod {
+func (w whereHelpernull_Bool) NEQ(x null.Bool) qm.QueryMod {
 	return qmhelper.WhereNullEQ(w.field, true, x)
 }
-func (w whereHelpernull_Int64) LT(x null.Int64) qm.QueryMod {
+func (w whereHelpernull_Bool) LT(x null.Bool) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.LT, x)
 }
-func (w whereHelpernull_Int64) LTE(x null.Int64) qm.QueryMod {
+func (w whereHelpernull_Bool) LTE(x null.Bool) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.LTE, x)
 }
-func (w whereHelpernull_Int64) GT(x null.Int64) qm.QueryMod {
+func (w whereHelpernull_Bool) GT(x null.Bool) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.GT, x)
 }
-func (w whereHelpernull_Int64) GTE(x null.Int64) qm.QueryMod {
+func (w whereHelpernull_Bool) GTE(x null.Bool) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.GTE, x)
 }
-func (w whereHelpernull_Int64) IN(slice []int64) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
-}
-func (w whereHelpernull_Int64) NIN(slice []int64) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
-}
-
-func (w whereHelpernull_Int64) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
-func (w whereHelpernull_Int64) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
-type whereHelperstring struct{ field string }
-
-func (w whereHelperstring) EQ(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.EQ, x) }
-func (w whereHelperstring) NEQ(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
-func (w whereHelperstring) LT(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.LT, x) }
-func (w whereHelperstring) LTE(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.LTE, x) }
-func (w whereHelperstring) GT(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.GT, x) }
-func (w whereHelperstring) GTE(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.GTE, x) }
-func (w whereHelperstring) LIKE(x string) qm.QueryMod  { return qm.Where(w.field+" LIKE ?", x) }
-func (w whereHelperstring) NLIKE(x string) qm.QueryMod { return qm.Where(w.field+" NOT LIKE ?", x) }
-func (w whereHelperstring) IN(slice []string) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
-}
-func (w whereHelperstring) NIN(slice []string) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
-}
+func (w whereHelpernull_Bool) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_Bool) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
-type whereHelpernull_Bool struct{ field string }
+type whereHelpernull_String struct{ field string }
 
-func (w whereHelpernull_Bool) EQ(x null.Bool) qm.QueryMod {
+func (w whereHelpernull_String) EQ(x null.String) qm.QueryMod {
 	return qmhelper.WhereNullEQ(w.field, false, x)
 }
-func (w whereHelpernull_Bool) NEQ(x null.Bool) qm.QueryMod {
+func (w whereHelpernull_String) NEQ(x null.String) qm.QueryMod {
 	return qmhelper.WhereNullEQ(w.field, true, x)
 }
-func (w whereHelpernull_Bool) LT(x null.Bool) qm.QueryMod {
+func (w whereHelpernull_String) LT(x null.String) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.LT, x)
 }
-func (w whereHelpernull_Bool) LTE(x null.Bool) qm.QueryMod {
+func (w whereHelpernull_String) LTE(x null.String) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.LTE, x)
 }
-func (w whereHelpernull_Bool) GT(x null.Bool) qm.QueryMod {
+func (w whereHelpernull_String) GT(x null.String) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.GT, x)
 }
-func (w whereHelpernull_Bool) GTE(x null.Bool) qm.QueryMod {
+func (w whereHelpernull_String) GTE(x null.String) qm.QueryMod {
 	return qmhelper.Where(w.field, qmhelper.GTE, x)
 }
+func (w whereHelpernull_String) LIKE(x null.String) qm.QueryMod {
+	return qm.Where(w.field+" LIKE ?", x)
+}
+func (w whereHelpernull_String) NLIKE(x null.String) qm.QueryMod {
+	return qm.Where(w.field+" NOT LIKE ?", x)
+}
+func (w whereHelpernull_String) IN(slice []string) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelpernull_String) NIN(slice []string) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
 
-func (w whereHelpernull_Bool) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
-func (w whereHelpernull_Bool) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
+func (w whereHelpernull_String) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_String) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
 var TaskWhere = struct {
-	ID     whereHelpernull_Int64
-	Title  whereHelperstring
-	Done   whereHelpernull_Bool
-	UserID whereHelpernull_Int64
+	ID          whereHelpernull_Int64
+	Title       whereHelperstring
+	Done        whereHelpernull_Bool
+	UserID      whereHelpernull_Int64
+	DueAt       whereHelpernull_Time
+	Priority    whereHelperint64
+	ProjectID   whereHelpernull_Int64
+	ParentID    whereHelpernull_Int64
+	Recurrence  whereHelpernull_String
+	Description whereHelperstring
+	Version     whereHelperint64
+	UpdatedAt   whereHelpernull_Time
 }{
-	ID:     whereHelpernull_Int64{field: "\"tasks\".\"id\""},
-	Title:  whereHelperstring{field: "\"tasks\".\"title\""},
-	Done:   whereHelpernull_Bool{field: "\"tasks\".\"done\""},
-	UserID: whereHelpernull_Int64{field: "\"tasks\".\"user_id\""},
+	ID:          whereHelpernull_Int64{field: "\"tasks\".\"id\""},
+	Title:       whereHelperstring{field: "\"tasks\".\"title\""},
+	Done:        whereHelpernull_Bool{field: "\"tasks\".\"done\""},
+	UserID:      whereHelpernull_Int64{field: "\"tasks\".\"user_id\""},
+	DueAt:       whereHelpernull_Time{field: "\"tasks\".\"due_at\""},
+	Priority:    whereHelperint64{field: "\"tasks\".\"priority\""},
+	ProjectID:   whereHelpernull_Int64{field: "\"tasks\".\"project_id\""},
+	ParentID:    whereHelpernull_Int64{field: "\"tasks\".\"parent_id\""},
+	Recurrence:  whereHelpernull_String{field: "\"tasks\".\"recurrence\""},
+	Description: whereHelperstring{field: "\"tasks\".\"description\""},
+	Version:     whereHelperint64{field: "\"tasks\".\"version\""},
+	UpdatedAt:   whereHelpernull_Time{field: "\"tasks\".\"updated_at\""},
 }
 
 // TaskRels is where relationship names are stored.
 var TaskRels = struct {
-	User string
+	Parent      string
+	Project     string
+	User        string
+	Tags        string
+	ParentTasks string
 }{
-	User: "User",
+	Parent:      "Parent",
+	Project:     "Project",
+	User:        "User",
+	Tags:        "Tags",
+	ParentTasks: "ParentTasks",
 }
 
 // taskR is where relationships are stored.
 type taskR struct {
-	User *User `boil:"User" json:"User" toml:"User" yaml:"User"`
+	Parent      *Task     `boil:"Parent" json:"Parent" toml:"Parent" yaml:"Parent"`
+	Project     *Project  `boil:"Project" json:"Project" toml:"Project" yaml:"Project"`
+	User        *User     `boil:"User" json:"User" toml:"User" yaml:"User"`
+	Tags        TagSlice  `boil:"Tags" json:"Tags" toml:"Tags" yaml:"Tags"`
+	ParentTasks TaskSlice `boil:"ParentTasks" json:"ParentTasks" toml:"ParentTasks" yaml:"ParentTasks"`
 }
 
 // NewStruct creates a new relationship struct
@@ -175,6 +224,38 @@ func (*taskR) NewStruct() *taskR {
 	return &taskR{}
 }
 
+func (o *Task) GetParent() *Task {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetParent()
+}
+
+func (r *taskR) GetParent() *Task {
+	if r == nil {
+		return nil
+	}
+
+	return r.Parent
+}
+
+func (o *Task) GetProject() *Project {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetProject()
+}
+
+func (r *taskR) GetProject() *Project {
+	if r == nil {
+		return nil
+	}
+
+	return r.Project
+}
+
 func (o *Task) GetUser() *User {
 	if o == nil {
 		return nil
@@ -191,13 +272,45 @@ func (r *taskR) GetUser() *User {
 	return r.User
 }
 
+func (o *Task) GetTags() TagSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetTags()
+}
+
+func (r *taskR) GetTags() TagSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.Tags
+}
+
+func (o *Task) GetParentTasks() TaskSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetParentTasks()
+}
+
+func (r *taskR) GetParentTasks() TaskSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.ParentTasks
+}
+
 // taskL is where Load methods for each relationship are stored.
 type taskL struct{}
 
 var (
-	taskAllColumns            = []string{"id", "title", "done", "user_id"}
+	taskAllColumns            = []string{"id", "title", "done", "user_id", "due_at", "priority", "project_id", "parent_id", "recurrence", "description", "version", "updated_at"}
 	taskColumnsWithoutDefault = []string{"title"}
-	taskColumnsWithDefault    = []string{"id", "done", "user_id"}
+	taskColumnsWithDefault    = []string{"id", "done", "user_id", "due_at", "priority", "project_id", "parent_id", "recurrence", "description", "version", "updated_at"}
 	taskPrimaryKeyColumns     = []string{"id"}
 	taskGeneratedColumns      = []string{"id"}
 )
@@ -507,6 +620,28 @@ func (q taskQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool,
 	return count > 0, nil
 }
 
+// Parent pointed to by the foreign key.
+func (o *Task) Parent(mods ...qm.QueryMod) taskQuery {
+	queryMods := []qm.QueryMod{
+		qm.Where("\"id\" = ?", o.ParentID),
+	}
+
+	queryMods = append(queryMods, mods...)
+
+	return Tasks(queryMods...)
+}
+
+// Project pointed to by the foreign key.
+func (o *Task) Project(mods ...qm.QueryMod) projectQuery {
+	queryMods := []qm.QueryMod{
+		qm.Where("\"id\" = ?", o.ProjectID),
+	}
+
+	queryMods = append(queryMods, mods...)
+
+	return Projects(queryMods...)
+}
+
 // User pointed to by the foreign key.
 func (o *Task) User(mods ...qm.QueryMod) userQuery {
 	queryMods := []qm.QueryMod{
@@ -518,9 +653,38 @@ func (o *Task) User(mods ...qm.QueryMod) userQuery {
 	return Users(queryMods...)
 }
 
-// LoadUser allows an eager lookup of values, cached into the
+// Tags retrieves all the tag's Tags with an executor.
+func (o *Task) Tags(mods ...qm.QueryMod) tagQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.InnerJoin("\"task_tags\" on \"tags\".\"id\" = \"task_tags\".\"tag_id\""),
+		qm.Where("\"task_tags\".\"task_id\"=?", o.ID),
+	)
+
+	return Tags(queryMods...)
+}
+
+// ParentTasks retrieves all the task's Tasks with an executor via parent_id column.
+func (o *Task) ParentTasks(mods ...qm.QueryMod) taskQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"tasks\".\"parent_id\"=?", o.ID),
+	)
+
+	return Tasks(queryMods...)
+}
+
+// LoadParent allows an eager lookup of values, cached into the
 // loaded structs of the objects. This is for an N-1 relationship.
-func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool, maybeTask interface{}, mods queries.Applicator) error {
+func (taskL) LoadParent(ctx context.Context, e boil.ContextExecutor, singular bool, maybeTask interface{}, mods queries.Applicator) error {
 	var slice []*Task
 	var object *Task
 
@@ -551,8 +715,8 @@ func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool
 		if object.R == nil {
 			object.R = &taskR{}
 		}
-		if !queries.IsNil(object.UserID) {
-			args[object.UserID] = struct{}{}
+		if !queries.IsNil(object.ParentID) {
+			args[object.ParentID] = struct{}{}
 		}
 
 	} else {
@@ -561,8 +725,8 @@ func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool
 				obj.R = &taskR{}
 			}
 
-			if !queries.IsNil(obj.UserID) {
-				args[obj.UserID] = struct{}{}
+			if !queries.IsNil(obj.ParentID) {
+				args[obj.ParentID] = struct{}{}
 			}
 
 		}
@@ -580,8 +744,8 @@ func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool
 	}
 
 	query := NewQuery(
-		qm.From(`users`),
-		qm.WhereIn(`users.id in ?`, argsSlice...),
+		qm.From(`tasks`),
+		qm.WhereIn(`tasks.id in ?`, argsSlice...),
 	)
 	if mods != nil {
 		mods.Apply(query)
@@ -589,22 +753,22 @@ func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool
 
 	results, err := query.QueryContext(ctx, e)
 	if err != nil {
-		return errors.Wrap(err, "failed to eager load User")
+		return errors.Wrap(err, "failed to eager load Task")
 	}
 
-	var resultSlice []*User
+	var resultSlice []*Task
 	if err = queries.Bind(results, &resultSlice); err != nil {
-		return errors.Wrap(err, "failed to bind eager loaded slice User")
+		return errors.Wrap(err, "failed to bind eager loaded slice Task")
 	}
 
 	if err = results.Close(); err != nil {
-		return errors.Wrap(err, "failed to close results of eager load for users")
+		return errors.Wrap(err, "failed to close results of eager load for tasks")
 	}
 	if err = results.Err(); err != nil {
-		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for users")
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for tasks")
 	}
 
-	if len(userAfterSelectHooks) != 0 {
+	if len(taskAfterSelectHooks) != 0 {
 		for _, obj := range resultSlice {
 			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
 				return err
@@ -618,22 +782,22 @@ func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool
 
 	if singular {
 		foreign := resultSlice[0]
-		object.R.User = foreign
+		object.R.Parent = foreign
 		if foreign.R == nil {
-			foreign.R = &userR{}
+			foreign.R = &taskR{}
 		}
-		foreign.R.Tasks = append(foreign.R.Tasks, object)
+		foreign.R.ParentTasks = append(foreign.R.ParentTasks, object)
 		return nil
 	}
 
 	for _, local := range slice {
 		for _, foreign := range resultSlice {
-			if queries.Equal(local.UserID, foreign.ID) {
-				local.R.User = foreign
+			if queries.Equal(local.ParentID, foreign.ID) {
+				local.R.Parent = foreign
 				if foreign.R == nil {
-					foreign.R = &userR{}
+					foreign.R = &taskR{}
 				}
-				foreign.R.Tasks = append(foreign.R.Tasks, local)
+				foreign.R.ParentTasks = append(foreign.R.ParentTasks, local)
 				break
 			}
 		}
@@ -642,72 +806,723 @@ func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool
 	return nil
 }
 
-// SetUser of the task to the related item.
-// Sets o.R.User to related.
-// Adds o to related.R.Tasks.
-func (o *Task) SetUser(ctx context.Context, exec boil.ContextExecutor, insert bool, related *User) error {
-	var err error
-	if insert {
-		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
-			return errors.Wrap(err, "failed to insert into foreign table")
-		}
-	}
-
-	updateQuery := fmt.Sprintf(
-		"UPDATE \"tasks\" SET %s WHERE %s",
-		strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
-		strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
-	)
-	values := []interface{}{related.ID, o.ID}
-
-	if boil.IsDebug(ctx) {
-		writer := boil.DebugWriterFrom(ctx)
-		fmt.Fprintln(writer, updateQuery)
-		fmt.Fprintln(writer, values)
-	}
-	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
-		return errors.Wrap(err, "failed to update local table")
-	}
+// LoadProject allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for an N-1 relationship.
+func (taskL) LoadProject(ctx context.Context, e boil.ContextExecutor, singular bool, maybeTask interface{}, mods queries.Applicator) error {
+	var slice []*Task
+	var object *Task
 
-	queries.Assign(&o.UserID, related.ID)
-	if o.R == nil {
-		o.R = &taskR{
-			User: related,
+	if singular {
+		var ok bool
+		object, ok = maybeTask.(*Task)
+		if !ok {
+			object = new(Task)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeTask))
+			}
 		}
 	} else {
-		o.R.User = related
+		s, ok := maybeTask.(*[]*Task)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeTask))
+			}
+		}
 	}
 
-	if related.R == nil {
-		related.R = &userR{
-			Tasks: TaskSlice{o},
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &taskR{}
+		}
+		if !queries.IsNil(object.ProjectID) {
+			args[object.ProjectID] = struct{}{}
 		}
+
 	} else {
-		related.R.Tasks = append(related.R.Tasks, o)
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &taskR{}
+			}
+
+			if !queries.IsNil(obj.ProjectID) {
+				args[obj.ProjectID] = struct{}{}
+			}
+
+		}
 	}
 
-	return nil
-}
+	if len(args) == 0 {
+		return nil
+	}
 
-// RemoveUser relationship.
-// Sets o.R.User to nil.
-// Removes o from all passed in related items' relationships struct.
-func (o *Task) RemoveUser(ctx context.Context, exec boil.ContextExecutor, related *User) error {
-	var err error
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
 
-	queries.SetScanner(&o.UserID, nil)
-	if _, err = o.Update(ctx, exec, boil.Whitelist("user_id")); err != nil {
-		return errors.Wrap(err, "failed to update local table")
+	query := NewQuery(
+		qm.From(`projects`),
+		qm.WhereIn(`projects.id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
 	}
 
-	if o.R != nil {
-		o.R.User = nil
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load Project")
 	}
-	if related == nil || related.R == nil {
-		return nil
+
+	var resultSlice []*Project
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice Project")
 	}
 
-	for i, ri := range related.R.Tasks {
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results of eager load for projects")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for projects")
+	}
+
+	if len(projectAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(resultSlice) == 0 {
+		return nil
+	}
+
+	if singular {
+		foreign := resultSlice[0]
+		object.R.Project = foreign
+		if foreign.R == nil {
+			foreign.R = &projectR{}
+		}
+		foreign.R.Tasks = append(foreign.R.Tasks, object)
+		return nil
+	}
+
+	for _, local := range slice {
+		for _, foreign := range resultSlice {
+			if queries.Equal(local.ProjectID, foreign.ID) {
+				local.R.Project = foreign
+				if foreign.R == nil {
+					foreign.R = &projectR{}
+				}
+				foreign.R.Tasks = append(foreign.R.Tasks, local)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadUser allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for an N-1 relationship.
+func (taskL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool, maybeTask interface{}, mods queries.Applicator) error {
+	var slice []*Task
+	var object *Task
+
+	if singular {
+		var ok bool
+		object, ok = maybeTask.(*Task)
+		if !ok {
+			object = new(Task)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeTask))
+			}
+		}
+	} else {
+		s, ok := maybeTask.(*[]*Task)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeTask))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &taskR{}
+		}
+		if !queries.IsNil(object.UserID) {
+			args[object.UserID] = struct{}{}
+		}
+
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &taskR{}
+			}
+
+			if !queries.IsNil(obj.UserID) {
+				args[obj.UserID] = struct{}{}
+			}
+
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`users`),
+		qm.WhereIn(`users.id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load User")
+	}
+
+	var resultSlice []*User
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice User")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results of eager load for users")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for users")
+	}
+
+	if len(userAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(resultSlice) == 0 {
+		return nil
+	}
+
+	if singular {
+		foreign := resultSlice[0]
+		object.R.User = foreign
+		if foreign.R == nil {
+			foreign.R = &userR{}
+		}
+		foreign.R.Tasks = append(foreign.R.Tasks, object)
+		return nil
+	}
+
+	for _, local := range slice {
+		for _, foreign := range resultSlice {
+			if queries.Equal(local.UserID, foreign.ID) {
+				local.R.User = foreign
+				if foreign.R == nil {
+					foreign.R = &userR{}
+				}
+				foreign.R.Tasks = append(foreign.R.Tasks, local)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadTags allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (taskL) LoadTags(ctx context.Context, e boil.ContextExecutor, singular bool, maybeTask interface{}, mods queries.Applicator) error {
+	var slice []*Task
+	var object *Task
+
+	if singular {
+		var ok bool
+		object, ok = maybeTask.(*Task)
+		if !ok {
+			object = new(Task)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeTask))
+			}
+		}
+	} else {
+		s, ok := maybeTask.(*[]*Task)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeTask))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &taskR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &taskR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.Select("\"tags\".\"id\", \"tags\".\"user_id\", \"tags\".\"name\", \"a\".\"task_id\""),
+		qm.From("\"tags\""),
+		qm.InnerJoin("\"task_tags\" as \"a\" on \"tags\".\"id\" = \"a\".\"tag_id\""),
+		qm.WhereIn("\"a\".\"task_id\" in ?", argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load tags")
+	}
+
+	var resultSlice []*Tag
+
+	var localJoinCols []int64
+	for results.Next() {
+		one := new(Tag)
+		var localJoinCol int64
+
+		err = results.Scan(&one.ID, &one.UserID, &one.Name, &localJoinCol)
+		if err != nil {
+			return errors.Wrap(err, "failed to scan eager loaded results for tags")
+		}
+		if err = results.Err(); err != nil {
+			return errors.Wrap(err, "failed to plebian-bind eager loaded slice tags")
+		}
+
+		resultSlice = append(resultSlice, one)
+		localJoinCols = append(localJoinCols, localJoinCol)
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on tags")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for tags")
+	}
+
+	if len(tagAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.Tags = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &tagR{}
+			}
+			foreign.R.Tasks = append(foreign.R.Tasks, object)
+		}
+		return nil
+	}
+
+	for i, foreign := range resultSlice {
+		localJoinCol := localJoinCols[i]
+		for _, local := range slice {
+			if queries.Equal(local.ID, localJoinCol) {
+				local.R.Tags = append(local.R.Tags, foreign)
+				if foreign.R == nil {
+					foreign.R = &tagR{}
+				}
+				foreign.R.Tasks = append(foreign.R.Tasks, local)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadParentTasks allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (taskL) LoadParentTasks(ctx context.Context, e boil.ContextExecutor, singular bool, maybeTask interface{}, mods queries.Applicator) error {
+	var slice []*Task
+	var object *Task
+
+	if singular {
+		var ok bool
+		object, ok = maybeTask.(*Task)
+		if !ok {
+			object = new(Task)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeTask))
+			}
+		}
+	} else {
+		s, ok := maybeTask.(*[]*Task)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeTask)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeTask))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &taskR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &taskR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`tasks`),
+		qm.WhereIn(`tasks.parent_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load tasks")
+	}
+
+	var resultSlice []*Task
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice tasks")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on tasks")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for tasks")
+	}
+
+	if len(taskAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.ParentTasks = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &taskR{}
+			}
+			foreign.R.Parent = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.ParentID) {
+				local.R.ParentTasks = append(local.R.ParentTasks, foreign)
+				if foreign.R == nil {
+					foreign.R = &taskR{}
+				}
+				foreign.R.Parent = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetParent of the task to the related item.
+// Sets o.R.Parent to related.
+// Adds o to related.R.ParentTasks.
+func (o *Task) SetParent(ctx context.Context, exec boil.ContextExecutor, insert bool, related *Task) error {
+	var err error
+	if insert {
+		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
+			return errors.Wrap(err, "failed to insert into foreign table")
+		}
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE \"tasks\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, []string{"parent_id"}),
+		strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
+	)
+	values := []interface{}{related.ID, o.ID}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, updateQuery)
+		fmt.Fprintln(writer, values)
+	}
+	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	queries.Assign(&o.ParentID, related.ID)
+	if o.R == nil {
+		o.R = &taskR{
+			Parent: related,
+		}
+	} else {
+		o.R.Parent = related
+	}
+
+	if related.R == nil {
+		related.R = &taskR{
+			ParentTasks: TaskSlice{o},
+		}
+	} else {
+		related.R.ParentTasks = append(related.R.ParentTasks, o)
+	}
+
+	return nil
+}
+
+// RemoveParent relationship.
+// Sets o.R.Parent to nil.
+// Removes o from all passed in related items' relationships struct.
+func (o *Task) RemoveParent(ctx context.Context, exec boil.ContextExecutor, related *Task) error {
+	var err error
+
+	queries.SetScanner(&o.ParentID, nil)
+	if _, err = o.Update(ctx, exec, boil.Whitelist("parent_id")); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	if o.R != nil {
+		o.R.Parent = nil
+	}
+	if related == nil || related.R == nil {
+		return nil
+	}
+
+	for i, ri := range related.R.ParentTasks {
+		if queries.Equal(o.ParentID, ri.ParentID) {
+			continue
+		}
+
+		ln := len(related.R.ParentTasks)
+		if ln > 1 && i < ln-1 {
+			related.R.ParentTasks[i] = related.R.ParentTasks[ln-1]
+		}
+		related.R.ParentTasks = related.R.ParentTasks[:ln-1]
+		break
+	}
+	return nil
+}
+
+// SetProject of the task to the related item.
+// Sets o.R.Project to related.
+// Adds o to related.R.Tasks.
+func (o *Task) SetProject(ctx context.Context, exec boil.ContextExecutor, insert bool, related *Project) error {
+	var err error
+	if insert {
+		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
+			return errors.Wrap(err, "failed to insert into foreign table")
+		}
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE \"tasks\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, []string{"project_id"}),
+		strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
+	)
+	values := []interface{}{related.ID, o.ID}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, updateQuery)
+		fmt.Fprintln(writer, values)
+	}
+	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	queries.Assign(&o.ProjectID, related.ID)
+	if o.R == nil {
+		o.R = &taskR{
+			Project: related,
+		}
+	} else {
+		o.R.Project = related
+	}
+
+	if related.R == nil {
+		related.R = &projectR{
+			Tasks: TaskSlice{o},
+		}
+	} else {
+		related.R.Tasks = append(related.R.Tasks, o)
+	}
+
+	return nil
+}
+
+// RemoveProject relationship.
+// Sets o.R.Project to nil.
+// Removes o from all passed in related items' relationships struct.
+func (o *Task) RemoveProject(ctx context.Context, exec boil.ContextExecutor, related *Project) error {
+	var err error
+
+	queries.SetScanner(&o.ProjectID, nil)
+	if _, err = o.Update(ctx, exec, boil.Whitelist("project_id")); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	if o.R != nil {
+		o.R.Project = nil
+	}
+	if related == nil || related.R == nil {
+		return nil
+	}
+
+	for i, ri := range related.R.Tasks {
+		if queries.Equal(o.ProjectID, ri.ProjectID) {
+			continue
+		}
+
+		ln := len(related.R.Tasks)
+		if ln > 1 && i < ln-1 {
+			related.R.Tasks[i] = related.R.Tasks[ln-1]
+		}
+		related.R.Tasks = related.R.Tasks[:ln-1]
+		break
+	}
+	return nil
+}
+
+// SetUser of the task to the related item.
+// Sets o.R.User to related.
+// Adds o to related.R.Tasks.
+func (o *Task) SetUser(ctx context.Context, exec boil.ContextExecutor, insert bool, related *User) error {
+	var err error
+	if insert {
+		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
+			return errors.Wrap(err, "failed to insert into foreign table")
+		}
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE \"tasks\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+		strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
+	)
+	values := []interface{}{related.ID, o.ID}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, updateQuery)
+		fmt.Fprintln(writer, values)
+	}
+	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	queries.Assign(&o.UserID, related.ID)
+	if o.R == nil {
+		o.R = &taskR{
+			User: related,
+		}
+	} else {
+		o.R.User = related
+	}
+
+	if related.R == nil {
+		related.R = &userR{
+			Tasks: TaskSlice{o},
+		}
+	} else {
+		related.R.Tasks = append(related.R.Tasks, o)
+	}
+
+	return nil
+}
+
+// RemoveUser relationship.
+// Sets o.R.User to nil.
+// Removes o from all passed in related items' relationships struct.
+func (o *Task) RemoveUser(ctx context.Context, exec boil.ContextExecutor, related *User) error {
+	var err error
+
+	queries.SetScanner(&o.UserID, nil)
+	if _, err = o.Update(ctx, exec, boil.Whitelist("user_id")); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	if o.R != nil {
+		o.R.User = nil
+	}
+	if related == nil || related.R == nil {
+		return nil
+	}
+
+	for i, ri := range related.R.Tasks {
 		if queries.Equal(o.UserID, ri.UserID) {
 			continue
 		}
@@ -722,6 +1537,278 @@ func (o *Task) RemoveUser(ctx context.Context, exec boil.ContextExecutor, relate
 	return nil
 }
 
+// AddTags adds the given related objects to the existing relationships
+// of the task, optionally inserting them as new records.
+// Appends related to o.R.Tags.
+// Sets related.R.Tasks appropriately.
+func (o *Task) AddTags(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Tag) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		}
+	}
+
+	for _, rel := range related {
+		query := "insert into \"task_tags\" (\"task_id\", \"tag_id\") values (?, ?)"
+		values := []interface{}{o.ID, rel.ID}
+
+		if boil.IsDebug(ctx) {
+			writer := boil.DebugWriterFrom(ctx)
+			fmt.Fprintln(writer, query)
+			fmt.Fprintln(writer, values)
+		}
+		_, err = exec.ExecContext(ctx, query, values...)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert into join table")
+		}
+	}
+	if o.R == nil {
+		o.R = &taskR{
+			Tags: related,
+		}
+	} else {
+		o.R.Tags = append(o.R.Tags, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &tagR{
+				Tasks: TaskSlice{o},
+			}
+		} else {
+			rel.R.Tasks = append(rel.R.Tasks, o)
+		}
+	}
+	return nil
+}
+
+// SetTags removes all previously related items of the
+// task replacing them completely with the passed
+// in related items, optionally inserting them as new records.
+// Sets o.R.Tasks's Tags accordingly.
+// Replaces o.R.Tags with related.
+// Sets related.R.Tasks's Tags accordingly.
+func (o *Task) SetTags(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Tag) error {
+	query := "delete from \"task_tags\" where \"task_id\" = ?"
+	values := []interface{}{o.ID}
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, query)
+		fmt.Fprintln(writer, values)
+	}
+	_, err := exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove relationships before set")
+	}
+
+	removeTagsFromTasksSlice(o, related)
+	if o.R != nil {
+		o.R.Tags = nil
+	}
+
+	return o.AddTags(ctx, exec, insert, related...)
+}
+
+// RemoveTags relationships from objects passed in.
+// Removes related items from R.Tags (uses pointer comparison, removal does not keep order)
+// Sets related.R.Tasks.
+func (o *Task) RemoveTags(ctx context.Context, exec boil.ContextExecutor, related ...*Tag) error {
+	if len(related) == 0 {
+		return nil
+	}
+
+	var err error
+	query := fmt.Sprintf(
+		"delete from \"task_tags\" where \"task_id\" = ? and \"tag_id\" in (%s)",
+		strmangle.Placeholders(dialect.UseIndexPlaceholders, len(related), 2, 1),
+	)
+	values := []interface{}{o.ID}
+	for _, rel := range related {
+		values = append(values, rel.ID)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, query)
+		fmt.Fprintln(writer, values)
+	}
+	_, err = exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove relationships before set")
+	}
+	removeTagsFromTasksSlice(o, related)
+	if o.R == nil {
+		return nil
+	}
+
+	for _, rel := range related {
+		for i, ri := range o.R.Tags {
+			if rel != ri {
+				continue
+			}
+
+			ln := len(o.R.Tags)
+			if ln > 1 && i < ln-1 {
+				o.R.Tags[i] = o.R.Tags[ln-1]
+			}
+			o.R.Tags = o.R.Tags[:ln-1]
+			break
+		}
+	}
+
+	return nil
+}
+
+func removeTagsFromTasksSlice(o *Task, related []*Tag) {
+	for _, rel := range related {
+		if rel.R == nil {
+			continue
+		}
+		for i, ri := range rel.R.Tasks {
+			if !queries.Equal(o.ID, ri.ID) {
+				continue
+			}
+
+			ln := len(rel.R.Tasks)
+			if ln > 1 && i < ln-1 {
+				rel.R.Tasks[i] = rel.R.Tasks[ln-1]
+			}
+			rel.R.Tasks = rel.R.Tasks[:ln-1]
+			break
+		}
+	}
+}
+
+// AddParentTasks adds the given related objects to the existing relationships
+// of the task, optionally inserting them as new records.
+// Appends related to o.R.ParentTasks.
+// Sets related.R.Parent appropriately.
+func (o *Task) AddParentTasks(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Task) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.ParentID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"tasks\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"parent_id"}),
+				strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.ParentID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &taskR{
+			ParentTasks: related,
+		}
+	} else {
+		o.R.ParentTasks = append(o.R.ParentTasks, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &taskR{
+				Parent: o,
+			}
+		} else {
+			rel.R.Parent = o
+		}
+	}
+	return nil
+}
+
+// SetParentTasks removes all previously related items of the
+// task replacing them completely with the passed
+// in related items, optionally inserting them as new records.
+// Sets o.R.Parent's ParentTasks accordingly.
+// Replaces o.R.ParentTasks with related.
+// Sets related.R.Parent's ParentTasks accordingly.
+func (o *Task) SetParentTasks(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Task) error {
+	query := "update \"tasks\" set \"parent_id\" = null where \"parent_id\" = ?"
+	values := []interface{}{o.ID}
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, query)
+		fmt.Fprintln(writer, values)
+	}
+	_, err := exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove relationships before set")
+	}
+
+	if o.R != nil {
+		for _, rel := range o.R.ParentTasks {
+			queries.SetScanner(&rel.ParentID, nil)
+			if rel.R == nil {
+				continue
+			}
+
+			rel.R.Parent = nil
+		}
+		o.R.ParentTasks = nil
+	}
+
+	return o.AddParentTasks(ctx, exec, insert, related...)
+}
+
+// RemoveParentTasks relationships from objects passed in.
+// Removes related items from R.ParentTasks (uses pointer comparison, removal does not keep order)
+// Sets related.R.Parent.
+func (o *Task) RemoveParentTasks(ctx context.Context, exec boil.ContextExecutor, related ...*Task) error {
+	if len(related) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, rel := range related {
+		queries.SetScanner(&rel.ParentID, nil)
+		if rel.R != nil {
+			rel.R.Parent = nil
+		}
+		if _, err = rel.Update(ctx, exec, boil.Whitelist("parent_id")); err != nil {
+			return err
+		}
+	}
+	if o.R == nil {
+		return nil
+	}
+
+	for _, rel := range related {
+		for i, ri := range o.R.ParentTasks {
+			if rel != ri {
+				continue
+			}
+
+			ln := len(o.R.ParentTasks)
+			if ln > 1 && i < ln-1 {
+				o.R.ParentTasks[i] = o.R.ParentTasks[ln-1]
+			}
+			o.R.ParentTasks = o.R.ParentTasks[:ln-1]
+			break
+		}
+	}
+
+	return nil
+}
+
 // Tasks retrieves all the records using an executor.
 func Tasks(mods ...qm.QueryMod) taskQuery {
 	mods = append(mods, qm.From("\"tasks\""))
@@ -771,6 +1858,13 @@ func (o *Task) Insert(ctx context.Context, exec boil.ContextExecutor, columns bo
 	}
 
 	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if queries.MustTime(o.UpdatedAt).IsZero() {
+			queries.SetScanner(&o.UpdatedAt, currTime)
+		}
+	}
 
 	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
 		return err
@@ -847,6 +1941,12 @@ func (o *Task) Insert(ctx context.Context, exec boil.ContextExecutor, columns bo
 // See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
 // Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
 func (o *Task) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		queries.SetScanner(&o.UpdatedAt, currTime)
+	}
+
 	var err error
 	if err = o.doBeforeUpdateHooks(ctx, exec); err != nil {
 		return 0, err
@@ -978,6 +2078,11 @@ func (o *Task) Upsert(ctx context.Context, exec boil.ContextExecutor, updateOnCo
 	if o == nil {
 		return errors.New("models: no tasks provided for upsert")
 	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		queries.SetScanner(&o.UpdatedAt, currTime)
+	}
 
 	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
 		return err
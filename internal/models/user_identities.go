@@ -0,0 +1,1170 @@
+// Code generated by SQLBoiler 4.19.1 (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friendsofgo/errors"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"github.com/volatiletech/sqlboiler/v4/queries/qmhelper"
+	"github.com/volatiletech/strmangle"
+)
+
+// UserIdentity is an object representing the database table.
+type UserIdentity struct {
+	ID        null.Int64  `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
+	UserID    int64       `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
+	Issuer    string      `boil:"issuer" json:"issuer" toml:"issuer" yaml:"issuer"`
+	Subject   string      `boil:"subject" json:"subject" toml:"subject" yaml:"subject"`
+	Email     null.String `boil:"email" json:"email,omitempty" toml:"email" yaml:"email,omitempty"`
+	CreatedAt time.Time   `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+
+	R *userIdentityR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L userIdentityL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var UserIdentityColumns = struct {
+	ID        string
+	UserID    string
+	Issuer    string
+	Subject   string
+	Email     string
+	CreatedAt string
+}{
+	ID:        "id",
+	UserID:    "user_id",
+	Issuer:    "issuer",
+	Subject:   "subject",
+	Email:     "email",
+	CreatedAt: "created_at",
+}
+
+var UserIdentityTableColumns = struct {
+	ID        string
+	UserID    string
+	Issuer    string
+	Subject   string
+	Email     string
+	CreatedAt string
+}{
+	ID:        "user_identities.id",
+	UserID:    "user_identities.user_id",
+	Issuer:    "user_identities.issuer",
+	Subject:   "user_identities.subject",
+	Email:     "user_identities.email",
+	CreatedAt: "user_identities.created_at",
+}
+
+// Generated where
+
+var UserIdentityWhere = struct {
+	ID        whereHelpernull_Int64
+	UserID    whereHelperint64
+	Issuer    whereHelperstring
+	Subject   whereHelperstring
+	Email     whereHelpernull_String
+	CreatedAt whereHelpertime_Time
+}{
+	ID:        whereHelpernull_Int64{field: "\"user_identities\".\"id\""},
+	UserID:    whereHelperint64{field: "\"user_identities\".\"user_id\""},
+	Issuer:    whereHelperstring{field: "\"user_identities\".\"issuer\""},
+	Subject:   whereHelperstring{field: "\"user_identities\".\"subject\""},
+	Email:     whereHelpernull_String{field: "\"user_identities\".\"email\""},
+	CreatedAt: whereHelpertime_Time{field: "\"user_identities\".\"created_at\""},
+}
+
+// UserIdentityRels is where relationship names are stored.
+var UserIdentityRels = struct {
+	User string
+}{
+	User: "User",
+}
+
+// userIdentityR is where relationships are stored.
+type userIdentityR struct {
+	User *User `boil:"User" json:"User" toml:"User" yaml:"User"`
+}
+
+// NewStruct creates a new relationship struct
+func (*userIdentityR) NewStruct() *userIdentityR {
+	return &userIdentityR{}
+}
+
+func (o *UserIdentity) GetUser() *User {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetUser()
+}
+
+func (r *userIdentityR) GetUser() *User {
+	if r == nil {
+		return nil
+	}
+
+	return r.User
+}
+
+// userIdentityL is where Load methods for each relationship are stored.
+type userIdentityL struct{}
+
+var (
+	userIdentityAllColumns            = []string{"id", "user_id", "issuer", "subject", "email", "created_at"}
+	userIdentityColumnsWithoutDefault = []string{"user_id", "issuer", "subject", "created_at"}
+	userIdentityColumnsWithDefault    = []string{"id", "email"}
+	userIdentityPrimaryKeyColumns     = []string{"id"}
+	userIdentityGeneratedColumns      = []string{"id"}
+)
+
+type (
+	// UserIdentitySlice is an alias for a slice of pointers to UserIdentity.
+	// This should almost always be used instead of []UserIdentity.
+	UserIdentitySlice []*UserIdentity
+	// UserIdentityHook is the signature for custom UserIdentity hook methods
+	UserIdentityHook func(context.Context, boil.ContextExecutor, *UserIdentity) error
+
+	userIdentityQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	userIdentityType                 = reflect.TypeOf(&UserIdentity{})
+	userIdentityMapping              = queries.MakeStructMapping(userIdentityType)
+	userIdentityPrimaryKeyMapping, _ = queries.BindMapping(userIdentityType, userIdentityMapping, userIdentityPrimaryKeyColumns)
+	userIdentityInsertCacheMut       sync.RWMutex
+	userIdentityInsertCache          = make(map[string]insertCache)
+	userIdentityUpdateCacheMut       sync.RWMutex
+	userIdentityUpdateCache          = make(map[string]updateCache)
+	userIdentityUpsertCacheMut       sync.RWMutex
+	userIdentityUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var userIdentityAfterSelectMu sync.Mutex
+var userIdentityAfterSelectHooks []UserIdentityHook
+
+var userIdentityBeforeInsertMu sync.Mutex
+var userIdentityBeforeInsertHooks []UserIdentityHook
+var userIdentityAfterInsertMu sync.Mutex
+var userIdentityAfterInsertHooks []UserIdentityHook
+
+var userIdentityBeforeUpdateMu sync.Mutex
+var userIdentityBeforeUpdateHooks []UserIdentityHook
+var userIdentityAfterUpdateMu sync.Mutex
+var userIdentityAfterUpdateHooks []UserIdentityHook
+
+var userIdentityBeforeDeleteMu sync.Mutex
+var userIdentityBeforeDeleteHooks []UserIdentityHook
+var userIdentityAfterDeleteMu sync.Mutex
+var userIdentityAfterDeleteHooks []UserIdentityHook
+
+var userIdentityBeforeUpsertMu sync.Mutex
+var userIdentityBeforeUpsertHooks []UserIdentityHook
+var userIdentityAfterUpsertMu sync.Mutex
+var userIdentityAfterUpsertHooks []UserIdentityHook
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *UserIdentity) doAfterSelectHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityAfterSelectHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *UserIdentity) doBeforeInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityBeforeInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *UserIdentity) doAfterInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityAfterInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *UserIdentity) doBeforeUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityBeforeUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *UserIdentity) doAfterUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityAfterUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *UserIdentity) doBeforeDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityBeforeDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *UserIdentity) doAfterDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityAfterDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *UserIdentity) doBeforeUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityBeforeUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *UserIdentity) doAfterUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range userIdentityAfterUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddUserIdentityHook registers your hook function for all future operations.
+func AddUserIdentityHook(hookPoint boil.HookPoint, userIdentityHook UserIdentityHook) {
+	switch hookPoint {
+	case boil.AfterSelectHook:
+		userIdentityAfterSelectMu.Lock()
+		userIdentityAfterSelectHooks = append(userIdentityAfterSelectHooks, userIdentityHook)
+		userIdentityAfterSelectMu.Unlock()
+	case boil.BeforeInsertHook:
+		userIdentityBeforeInsertMu.Lock()
+		userIdentityBeforeInsertHooks = append(userIdentityBeforeInsertHooks, userIdentityHook)
+		userIdentityBeforeInsertMu.Unlock()
+	case boil.AfterInsertHook:
+		userIdentityAfterInsertMu.Lock()
+		userIdentityAfterInsertHooks = append(userIdentityAfterInsertHooks, userIdentityHook)
+		userIdentityAfterInsertMu.Unlock()
+	case boil.BeforeUpdateHook:
+		userIdentityBeforeUpdateMu.Lock()
+		userIdentityBeforeUpdateHooks = append(userIdentityBeforeUpdateHooks, userIdentityHook)
+		userIdentityBeforeUpdateMu.Unlock()
+	case boil.AfterUpdateHook:
+		userIdentityAfterUpdateMu.Lock()
+		userIdentityAfterUpdateHooks = append(userIdentityAfterUpdateHooks, userIdentityHook)
+		userIdentityAfterUpdateMu.Unlock()
+	case boil.BeforeDeleteHook:
+		userIdentityBeforeDeleteMu.Lock()
+		userIdentityBeforeDeleteHooks = append(userIdentityBeforeDeleteHooks, userIdentityHook)
+		userIdentityBeforeDeleteMu.Unlock()
+	case boil.AfterDeleteHook:
+		userIdentityAfterDeleteMu.Lock()
+		userIdentityAfterDeleteHooks = append(userIdentityAfterDeleteHooks, userIdentityHook)
+		userIdentityAfterDeleteMu.Unlock()
+	case boil.BeforeUpsertHook:
+		userIdentityBeforeUpsertMu.Lock()
+		userIdentityBeforeUpsertHooks = append(userIdentityBeforeUpsertHooks, userIdentityHook)
+		userIdentityBeforeUpsertMu.Unlock()
+	case boil.AfterUpsertHook:
+		userIdentityAfterUpsertMu.Lock()
+		userIdentityAfterUpsertHooks = append(userIdentityAfterUpsertHooks, userIdentityHook)
+		userIdentityAfterUpsertMu.Unlock()
+	}
+}
+
+// One returns a single userIdentity record from the query.
+func (q userIdentityQuery) One(ctx context.Context, exec boil.ContextExecutor) (*UserIdentity, error) {
+	o := &UserIdentity{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for user_identities")
+	}
+
+	if err := o.doAfterSelectHooks(ctx, exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// All returns all UserIdentity records from the query.
+func (q userIdentityQuery) All(ctx context.Context, exec boil.ContextExecutor) (UserIdentitySlice, error) {
+	var o []*UserIdentity
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to UserIdentity slice")
+	}
+
+	if len(userIdentityAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(ctx, exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all UserIdentity records in the query.
+func (q userIdentityQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count user_identities rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q userIdentityQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if user_identities exists")
+	}
+
+	return count > 0, nil
+}
+
+// User pointed to by the foreign key.
+func (o *UserIdentity) User(mods ...qm.QueryMod) userQuery {
+	queryMods := []qm.QueryMod{
+		qm.Where("\"id\" = ?", o.UserID),
+	}
+
+	queryMods = append(queryMods, mods...)
+
+	return Users(queryMods...)
+}
+
+// LoadUser allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for an N-1 relationship.
+func (userIdentityL) LoadUser(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUserIdentity interface{}, mods queries.Applicator) error {
+	var slice []*UserIdentity
+	var object *UserIdentity
+
+	if singular {
+		var ok bool
+		object, ok = maybeUserIdentity.(*UserIdentity)
+		if !ok {
+			object = new(UserIdentity)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeUserIdentity)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeUserIdentity))
+			}
+		}
+	} else {
+		s, ok := maybeUserIdentity.(*[]*UserIdentity)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeUserIdentity)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeUserIdentity))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &userIdentityR{}
+		}
+		if !queries.IsNil(object.UserID) {
+			args[object.UserID] = struct{}{}
+		}
+
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &userIdentityR{}
+			}
+
+			if !queries.IsNil(obj.UserID) {
+				args[obj.UserID] = struct{}{}
+			}
+
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`users`),
+		qm.WhereIn(`users.id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load User")
+	}
+
+	var resultSlice []*User
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice User")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results of eager load for users")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for users")
+	}
+
+	if len(userAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(resultSlice) == 0 {
+		return nil
+	}
+
+	if singular {
+		foreign := resultSlice[0]
+		object.R.User = foreign
+		if foreign.R == nil {
+			foreign.R = &userR{}
+		}
+		foreign.R.UserIdentities = append(foreign.R.UserIdentities, object)
+		return nil
+	}
+
+	for _, local := range slice {
+		for _, foreign := range resultSlice {
+			if queries.Equal(local.UserID, foreign.ID) {
+				local.R.User = foreign
+				if foreign.R == nil {
+					foreign.R = &userR{}
+				}
+				foreign.R.UserIdentities = append(foreign.R.UserIdentities, local)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetUser of the userIdentity to the related item.
+// Sets o.R.User to related.
+// Adds o to related.R.UserIdentities.
+func (o *UserIdentity) SetUser(ctx context.Context, exec boil.ContextExecutor, insert bool, related *User) error {
+	var err error
+	if insert {
+		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
+			return errors.Wrap(err, "failed to insert into foreign table")
+		}
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE \"user_identities\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+		strmangle.WhereClause("\"", "\"", 0, userIdentityPrimaryKeyColumns),
+	)
+	values := []interface{}{related.ID, o.ID}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, updateQuery)
+		fmt.Fprintln(writer, values)
+	}
+	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	queries.Assign(&o.UserID, related.ID)
+	if o.R == nil {
+		o.R = &userIdentityR{
+			User: related,
+		}
+	} else {
+		o.R.User = related
+	}
+
+	if related.R == nil {
+		related.R = &userR{
+			UserIdentities: UserIdentitySlice{o},
+		}
+	} else {
+		related.R.UserIdentities = append(related.R.UserIdentities, o)
+	}
+
+	return nil
+}
+
+// UserIdentities retrieves all the records using an executor.
+func UserIdentities(mods ...qm.QueryMod) userIdentityQuery {
+	mods = append(mods, qm.From("\"user_identities\""))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"\"user_identities\".*"})
+	}
+
+	return userIdentityQuery{q}
+}
+
+// FindUserIdentity retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindUserIdentity(ctx context.Context, exec boil.ContextExecutor, iD null.Int64, selectCols ...string) (*UserIdentity, error) {
+	userIdentityObj := &UserIdentity{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"user_identities\" where \"id\"=?", sel,
+	)
+
+	q := queries.Raw(query, iD)
+
+	err := q.Bind(ctx, exec, userIdentityObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from user_identities")
+	}
+
+	if err = userIdentityObj.doAfterSelectHooks(ctx, exec); err != nil {
+		return userIdentityObj, err
+	}
+
+	return userIdentityObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *UserIdentity) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_identities provided for insertion")
+	}
+
+	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
+
+	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userIdentityColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	userIdentityInsertCacheMut.RLock()
+	cache, cached := userIdentityInsertCache[key]
+	userIdentityInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			userIdentityAllColumns,
+			userIdentityColumnsWithDefault,
+			userIdentityColumnsWithoutDefault,
+			nzDefaults,
+		)
+		wl = strmangle.SetComplement(wl, userIdentityGeneratedColumns)
+
+		cache.valueMapping, err = queries.BindMapping(userIdentityType, userIdentityMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(userIdentityType, userIdentityMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"user_identities\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"user_identities\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into user_identities")
+	}
+
+	if !cached {
+		userIdentityInsertCacheMut.Lock()
+		userIdentityInsertCache[key] = cache
+		userIdentityInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(ctx, exec)
+}
+
+// Update uses an executor to update the UserIdentity.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *UserIdentity) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	userIdentityUpdateCacheMut.RLock()
+	cache, cached := userIdentityUpdateCache[key]
+	userIdentityUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			userIdentityAllColumns,
+			userIdentityPrimaryKeyColumns,
+		)
+		wl = strmangle.SetComplement(wl, userIdentityGeneratedColumns)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update user_identities, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"user_identities\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 0, wl),
+			strmangle.WhereClause("\"", "\"", 0, userIdentityPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(userIdentityType, userIdentityMapping, append(wl, userIdentityPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update user_identities row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for user_identities")
+	}
+
+	if !cached {
+		userIdentityUpdateCacheMut.Lock()
+		userIdentityUpdateCache[key] = cache
+		userIdentityUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(ctx, exec)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q userIdentityQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for user_identities")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for user_identities")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o UserIdentitySlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userIdentityPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"user_identities\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 0, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, userIdentityPrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in userIdentity slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all userIdentity")
+	}
+	return rowsAff, nil
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *UserIdentity) Upsert(ctx context.Context, exec boil.ContextExecutor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_identities provided for upsert")
+	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
+
+	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userIdentityColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	userIdentityUpsertCacheMut.RLock()
+	cache, cached := userIdentityUpsertCache[key]
+	userIdentityUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			userIdentityAllColumns,
+			userIdentityColumnsWithDefault,
+			userIdentityColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			userIdentityAllColumns,
+			userIdentityPrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert user_identities, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(userIdentityAllColumns, strmangle.SetIntersect(insert, update))
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(userIdentityPrimaryKeyColumns))
+			copy(conflict, userIdentityPrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQuerySQLite(dialect, "\"user_identities\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(userIdentityType, userIdentityMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(userIdentityType, userIdentityMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(returns...)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert user_identities")
+	}
+
+	if !cached {
+		userIdentityUpsertCacheMut.Lock()
+		userIdentityUpsertCache[key] = cache
+		userIdentityUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(ctx, exec)
+}
+
+// Delete deletes a single UserIdentity record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *UserIdentity) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no UserIdentity provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), userIdentityPrimaryKeyMapping)
+	sql := "DELETE FROM \"user_identities\" WHERE \"id\"=?"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from user_identities")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for user_identities")
+	}
+
+	if err := o.doAfterDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q userIdentityQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no userIdentityQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from user_identities")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_identities")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o UserIdentitySlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(userIdentityBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userIdentityPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"user_identities\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, userIdentityPrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from userIdentity slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_identities")
+	}
+
+	if len(userIdentityAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *UserIdentity) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindUserIdentity(ctx, exec, o.ID)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserIdentitySlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := UserIdentitySlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userIdentityPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"user_identities\".* FROM \"user_identities\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, userIdentityPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in UserIdentitySlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// UserIdentityExists checks if the UserIdentity row exists.
+func UserIdentityExists(ctx context.Context, exec boil.ContextExecutor, iD null.Int64) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"user_identities\" where \"id\"=? limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, iD)
+	}
+	row := exec.QueryRowContext(ctx, sql, iD)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if user_identities exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the UserIdentity row exists.
+func (o *UserIdentity) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return UserIdentityExists(ctx, exec, o.ID)
+}
@@ -24,56 +24,99 @@ import (
 
 // User is an object representing the database table.
 type User struct {
-	ID           null.Int64 `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
-	Username     string     `boil:"username" json:"username" toml:"username" yaml:"username"`
-	PasswordHash string     `boil:"password_hash" json:"password_hash" toml:"password_hash" yaml:"password_hash"`
+	ID             null.Int64  `boil:"id" json:"id,omitempty" toml:"id" yaml:"id,omitempty"`
+	Username       string      `boil:"username" json:"username" toml:"username" yaml:"username"`
+	PasswordHash   string      `boil:"password_hash" json:"password_hash" toml:"password_hash" yaml:"password_hash"`
+	TasksUpdatedAt null.Time   `boil:"tasks_updated_at" json:"tasks_updated_at,omitempty" toml:"tasks_updated_at" yaml:"tasks_updated_at,omitempty"`
+	TotpSecret     null.String `boil:"totp_secret" json:"totp_secret,omitempty" toml:"totp_secret" yaml:"totp_secret,omitempty"`
+	TotpEnabledAt  null.Time   `boil:"totp_enabled_at" json:"totp_enabled_at,omitempty" toml:"totp_enabled_at" yaml:"totp_enabled_at,omitempty"`
+	TotpLastStep   null.Int64  `boil:"totp_last_step" json:"totp_last_step,omitempty" toml:"totp_last_step" yaml:"totp_last_step,omitempty"`
 
 	R *userR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L userL  `boil:"-" json:"-" toml:"-" yaml:"-"`
 }
 
 var UserColumns = struct {
-	ID           string
-	Username     string
-	PasswordHash string
+	ID             string
+	Username       string
+	PasswordHash   string
+	TasksUpdatedAt string
+	TotpSecret     string
+	TotpEnabledAt  string
+	TotpLastStep   string
 }{
-	ID:           "id",
-	Username:     "username",
-	PasswordHash: "password_hash",
+	ID:             "id",
+	Username:       "username",
+	PasswordHash:   "password_hash",
+	TasksUpdatedAt: "tasks_updated_at",
+	TotpSecret:     "totp_secret",
+	TotpEnabledAt:  "totp_enabled_at",
+	TotpLastStep:   "totp_last_step",
 }
 
 var UserTableColumns = struct {
-	ID           string
-	Username     string
-	PasswordHash string
+	ID             string
+	Username       string
+	PasswordHash   string
+	TasksUpdatedAt string
+	TotpSecret     string
+	TotpEnabledAt  string
+	TotpLastStep   string
 }{
-	ID:           "users.id",
-	Username:     "users.username",
-	PasswordHash: "users.password_hash",
+	ID:             "users.id",
+	Username:       "users.username",
+	PasswordHash:   "users.password_hash",
+	TasksUpdatedAt: "users.tasks_updated_at",
+	TotpSecret:     "users.totp_secret",
+	TotpEnabledAt:  "users.totp_enabled_at",
+	TotpLastStep:   "users.totp_last_step",
 }
 
 // Generated where
 
 var UserWhere = struct {
-	ID           whereHelpernull_Int64
-	Username     whereHelperstring
-	PasswordHash whereHelperstring
+	ID             whereHelpernull_Int64
+	Username       whereHelperstring
+	PasswordHash   whereHelperstring
+	TasksUpdatedAt whereHelpernull_Time
+	TotpSecret     whereHelpernull_String
+	TotpEnabledAt  whereHelpernull_Time
+	TotpLastStep   whereHelpernull_Int64
 }{
-	ID:           whereHelpernull_Int64{field: "\"users\".\"id\""},
-	Username:     whereHelperstring{field: "\"users\".\"username\""},
-	PasswordHash: whereHelperstring{field: "\"users\".\"password_hash\""},
+	ID:             whereHelpernull_Int64{field: "\"users\".\"id\""},
+	Username:       whereHelperstring{field: "\"users\".\"username\""},
+	PasswordHash:   whereHelperstring{field: "\"users\".\"password_hash\""},
+	TasksUpdatedAt: whereHelpernull_Time{field: "\"users\".\"tasks_updated_at\""},
+	TotpSecret:     whereHelpernull_String{field: "\"users\".\"totp_secret\""},
+	TotpEnabledAt:  whereHelpernull_Time{field: "\"users\".\"totp_enabled_at\""},
+	TotpLastStep:   whereHelpernull_Int64{field: "\"users\".\"totp_last_step\""},
 }
 
 // UserRels is where relationship names are stored.
 var UserRels = struct {
-	Tasks string
+	APITokens         string
+	Projects          string
+	Tags              string
+	Tasks             string
+	UserIdentities    string
+	UserRecoveryCodes string
 }{
-	Tasks: "Tasks",
+	APITokens:         "APITokens",
+	Projects:          "Projects",
+	Tags:              "Tags",
+	Tasks:             "Tasks",
+	UserIdentities:    "UserIdentities",
+	UserRecoveryCodes: "UserRecoveryCodes",
 }
 
 // userR is where relationships are stored.
 type userR struct {
-	Tasks TaskSlice `boil:"Tasks" json:"Tasks" toml:"Tasks" yaml:"Tasks"`
+	APITokens         APITokenSlice         `boil:"APITokens" json:"APITokens" toml:"APITokens" yaml:"APITokens"`
+	Projects          ProjectSlice          `boil:"Projects" json:"Projects" toml:"Projects" yaml:"Projects"`
+	Tags              TagSlice              `boil:"Tags" json:"Tags" toml:"Tags" yaml:"Tags"`
+	Tasks             TaskSlice             `boil:"Tasks" json:"Tasks" toml:"Tasks" yaml:"Tasks"`
+	UserIdentities    UserIdentitySlice     `boil:"UserIdentities" json:"UserIdentities" toml:"UserIdentities" yaml:"UserIdentities"`
+	UserRecoveryCodes UserRecoveryCodeSlice `boil:"UserRecoveryCodes" json:"UserRecoveryCodes" toml:"UserRecoveryCodes" yaml:"UserRecoveryCodes"`
 }
 
 // NewStruct creates a new relationship struct
@@ -81,6 +124,54 @@ func (*userR) NewStruct() *userR {
 	return &userR{}
 }
 
+func (o *User) GetAPITokens() APITokenSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetAPITokens()
+}
+
+func (r *userR) GetAPITokens() APITokenSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.APITokens
+}
+
+func (o *User) GetProjects() ProjectSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetProjects()
+}
+
+func (r *userR) GetProjects() ProjectSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.Projects
+}
+
+func (o *User) GetTags() TagSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetTags()
+}
+
+func (r *userR) GetTags() TagSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.Tags
+}
+
 func (o *User) GetTasks() TaskSlice {
 	if o == nil {
 		return nil
@@ -97,13 +188,45 @@ func (r *userR) GetTasks() TaskSlice {
 	return r.Tasks
 }
 
+func (o *User) GetUserIdentities() UserIdentitySlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetUserIdentities()
+}
+
+func (r *userR) GetUserIdentities() UserIdentitySlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.UserIdentities
+}
+
+func (o *User) GetUserRecoveryCodes() UserRecoveryCodeSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetUserRecoveryCodes()
+}
+
+func (r *userR) GetUserRecoveryCodes() UserRecoveryCodeSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.UserRecoveryCodes
+}
+
 // userL is where Load methods for each relationship are stored.
 type userL struct{}
 
 var (
-	userAllColumns            = []string{"id", "username", "password_hash"}
+	userAllColumns            = []string{"id", "username", "password_hash", "tasks_updated_at", "totp_secret", "totp_enabled_at", "totp_last_step"}
 	userColumnsWithoutDefault = []string{"username", "password_hash"}
-	userColumnsWithDefault    = []string{"id"}
+	userColumnsWithDefault    = []string{"id", "tasks_updated_at", "totp_secret", "totp_enabled_at", "totp_last_step"}
 	userPrimaryKeyColumns     = []string{"id"}
 	userGeneratedColumns      = []string{"id"}
 )
@@ -413,6 +536,48 @@ func (q userQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool,
 	return count > 0, nil
 }
 
+// APITokens retrieves all the api_token's APITokens with an executor.
+func (o *User) APITokens(mods ...qm.QueryMod) apiTokenQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"api_tokens\".\"user_id\"=?", o.ID),
+	)
+
+	return APITokens(queryMods...)
+}
+
+// Projects retrieves all the project's Projects with an executor.
+func (o *User) Projects(mods ...qm.QueryMod) projectQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"projects\".\"user_id\"=?", o.ID),
+	)
+
+	return Projects(queryMods...)
+}
+
+// Tags retrieves all the tag's Tags with an executor.
+func (o *User) Tags(mods ...qm.QueryMod) tagQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"tags\".\"user_id\"=?", o.ID),
+	)
+
+	return Tags(queryMods...)
+}
+
 // Tasks retrieves all the task's Tasks with an executor.
 func (o *User) Tasks(mods ...qm.QueryMod) taskQuery {
 	var queryMods []qm.QueryMod
@@ -427,9 +592,37 @@ func (o *User) Tasks(mods ...qm.QueryMod) taskQuery {
 	return Tasks(queryMods...)
 }
 
-// LoadTasks allows an eager lookup of values, cached into the
+// UserIdentities retrieves all the user_identity's UserIdentities with an executor.
+func (o *User) UserIdentities(mods ...qm.QueryMod) userIdentityQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"user_identities\".\"user_id\"=?", o.ID),
+	)
+
+	return UserIdentities(queryMods...)
+}
+
+// UserRecoveryCodes retrieves all the user_recovery_code's UserRecoveryCodes with an executor.
+func (o *User) UserRecoveryCodes(mods ...qm.QueryMod) userRecoveryCodeQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"user_recovery_codes\".\"user_id\"=?", o.ID),
+	)
+
+	return UserRecoveryCodes(queryMods...)
+}
+
+// LoadAPITokens allows an eager lookup of values, cached into the
 // loaded structs of the objects. This is for a 1-M or N-M relationship.
-func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
+func (userL) LoadAPITokens(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
 	var slice []*User
 	var object *User
 
@@ -482,8 +675,8 @@ func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular boo
 	}
 
 	query := NewQuery(
-		qm.From(`tasks`),
-		qm.WhereIn(`tasks.user_id in ?`, argsSlice...),
+		qm.From(`api_tokens`),
+		qm.WhereIn(`api_tokens.user_id in ?`, argsSlice...),
 	)
 	if mods != nil {
 		mods.Apply(query)
@@ -491,22 +684,22 @@ func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular boo
 
 	results, err := query.QueryContext(ctx, e)
 	if err != nil {
-		return errors.Wrap(err, "failed to eager load tasks")
+		return errors.Wrap(err, "failed to eager load api_tokens")
 	}
 
-	var resultSlice []*Task
+	var resultSlice []*APIToken
 	if err = queries.Bind(results, &resultSlice); err != nil {
-		return errors.Wrap(err, "failed to bind eager loaded slice tasks")
+		return errors.Wrap(err, "failed to bind eager loaded slice api_tokens")
 	}
 
 	if err = results.Close(); err != nil {
-		return errors.Wrap(err, "failed to close results in eager load on tasks")
+		return errors.Wrap(err, "failed to close results in eager load on api_tokens")
 	}
 	if err = results.Err(); err != nil {
-		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for tasks")
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for api_tokens")
 	}
 
-	if len(taskAfterSelectHooks) != 0 {
+	if len(apiTokenAfterSelectHooks) != 0 {
 		for _, obj := range resultSlice {
 			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
 				return err
@@ -514,10 +707,10 @@ func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular boo
 		}
 	}
 	if singular {
-		object.R.Tasks = resultSlice
+		object.R.APITokens = resultSlice
 		for _, foreign := range resultSlice {
 			if foreign.R == nil {
-				foreign.R = &taskR{}
+				foreign.R = &apiTokenR{}
 			}
 			foreign.R.User = object
 		}
@@ -527,9 +720,9 @@ func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular boo
 	for _, foreign := range resultSlice {
 		for _, local := range slice {
 			if queries.Equal(local.ID, foreign.UserID) {
-				local.R.Tasks = append(local.R.Tasks, foreign)
+				local.R.APITokens = append(local.R.APITokens, foreign)
 				if foreign.R == nil {
-					foreign.R = &taskR{}
+					foreign.R = &apiTokenR{}
 				}
 				foreign.R.User = local
 				break
@@ -540,106 +733,830 @@ func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular boo
 	return nil
 }
 
-// AddTasks adds the given related objects to the existing relationships
-// of the user, optionally inserting them as new records.
-// Appends related to o.R.Tasks.
-// Sets related.R.User appropriately.
-func (o *User) AddTasks(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Task) error {
-	var err error
-	for _, rel := range related {
-		if insert {
-			queries.Assign(&rel.UserID, o.ID)
-			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
-				return errors.Wrap(err, "failed to insert into foreign table")
-			}
-		} else {
-			updateQuery := fmt.Sprintf(
-				"UPDATE \"tasks\" SET %s WHERE %s",
-				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
-				strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
-			)
-			values := []interface{}{o.ID, rel.ID}
+// LoadProjects allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (userL) LoadProjects(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
+	var slice []*User
+	var object *User
 
-			if boil.IsDebug(ctx) {
-				writer := boil.DebugWriterFrom(ctx)
-				fmt.Fprintln(writer, updateQuery)
-				fmt.Fprintln(writer, values)
+	if singular {
+		var ok bool
+		object, ok = maybeUser.(*User)
+		if !ok {
+			object = new(User)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeUser))
 			}
-			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
-				return errors.Wrap(err, "failed to update foreign table")
+		}
+	} else {
+		s, ok := maybeUser.(*[]*User)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeUser))
 			}
-
-			queries.Assign(&rel.UserID, o.ID)
 		}
 	}
 
-	if o.R == nil {
-		o.R = &userR{
-			Tasks: related,
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &userR{}
 		}
+		args[object.ID] = struct{}{}
 	} else {
-		o.R.Tasks = append(o.R.Tasks, related...)
-	}
-
-	for _, rel := range related {
-		if rel.R == nil {
-			rel.R = &taskR{
-				User: o,
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &userR{}
 			}
-		} else {
-			rel.R.User = o
+			args[obj.ID] = struct{}{}
 		}
 	}
-	return nil
-}
 
-// SetTasks removes all previously related items of the
-// user replacing them completely with the passed
-// in related items, optionally inserting them as new records.
-// Sets o.R.User's Tasks accordingly.
-// Replaces o.R.Tasks with related.
-// Sets related.R.User's Tasks accordingly.
-func (o *User) SetTasks(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Task) error {
-	query := "update \"tasks\" set \"user_id\" = null where \"user_id\" = ?"
-	values := []interface{}{o.ID}
-	if boil.IsDebug(ctx) {
-		writer := boil.DebugWriterFrom(ctx)
-		fmt.Fprintln(writer, query)
-		fmt.Fprintln(writer, values)
+	if len(args) == 0 {
+		return nil
 	}
-	_, err := exec.ExecContext(ctx, query, values...)
-	if err != nil {
-		return errors.Wrap(err, "failed to remove relationships before set")
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
 	}
 
-	if o.R != nil {
-		for _, rel := range o.R.Tasks {
-			queries.SetScanner(&rel.UserID, nil)
-			if rel.R == nil {
-				continue
-			}
+	query := NewQuery(
+		qm.From(`projects`),
+		qm.WhereIn(`projects.user_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
 
-			rel.R.User = nil
-		}
-		o.R.Tasks = nil
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load projects")
 	}
 
-	return o.AddTasks(ctx, exec, insert, related...)
-}
+	var resultSlice []*Project
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice projects")
+	}
 
-// RemoveTasks relationships from objects passed in.
-// Removes related items from R.Tasks (uses pointer comparison, removal does not keep order)
-// Sets related.R.User.
-func (o *User) RemoveTasks(ctx context.Context, exec boil.ContextExecutor, related ...*Task) error {
-	if len(related) == 0 {
-		return nil
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on projects")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for projects")
 	}
 
-	var err error
-	for _, rel := range related {
-		queries.SetScanner(&rel.UserID, nil)
-		if rel.R != nil {
-			rel.R.User = nil
+	if len(projectAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.Projects = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &projectR{}
+			}
+			foreign.R.User = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.UserID) {
+				local.R.Projects = append(local.R.Projects, foreign)
+				if foreign.R == nil {
+					foreign.R = &projectR{}
+				}
+				foreign.R.User = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadTags allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (userL) LoadTags(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
+	var slice []*User
+	var object *User
+
+	if singular {
+		var ok bool
+		object, ok = maybeUser.(*User)
+		if !ok {
+			object = new(User)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeUser))
+			}
+		}
+	} else {
+		s, ok := maybeUser.(*[]*User)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeUser))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &userR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &userR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`tags`),
+		qm.WhereIn(`tags.user_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load tags")
+	}
+
+	var resultSlice []*Tag
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice tags")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on tags")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for tags")
+	}
+
+	if len(tagAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.Tags = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &tagR{}
+			}
+			foreign.R.User = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.UserID) {
+				local.R.Tags = append(local.R.Tags, foreign)
+				if foreign.R == nil {
+					foreign.R = &tagR{}
+				}
+				foreign.R.User = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadTasks allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (userL) LoadTasks(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
+	var slice []*User
+	var object *User
+
+	if singular {
+		var ok bool
+		object, ok = maybeUser.(*User)
+		if !ok {
+			object = new(User)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeUser))
+			}
+		}
+	} else {
+		s, ok := maybeUser.(*[]*User)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeUser))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &userR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &userR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`tasks`),
+		qm.WhereIn(`tasks.user_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load tasks")
+	}
+
+	var resultSlice []*Task
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice tasks")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on tasks")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for tasks")
+	}
+
+	if len(taskAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.Tasks = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &taskR{}
+			}
+			foreign.R.User = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.UserID) {
+				local.R.Tasks = append(local.R.Tasks, foreign)
+				if foreign.R == nil {
+					foreign.R = &taskR{}
+				}
+				foreign.R.User = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadUserIdentities allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (userL) LoadUserIdentities(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
+	var slice []*User
+	var object *User
+
+	if singular {
+		var ok bool
+		object, ok = maybeUser.(*User)
+		if !ok {
+			object = new(User)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeUser))
+			}
+		}
+	} else {
+		s, ok := maybeUser.(*[]*User)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeUser))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &userR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &userR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`user_identities`),
+		qm.WhereIn(`user_identities.user_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load user_identities")
+	}
+
+	var resultSlice []*UserIdentity
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice user_identities")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on user_identities")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for user_identities")
+	}
+
+	if len(userIdentityAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.UserIdentities = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &userIdentityR{}
+			}
+			foreign.R.User = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.UserID) {
+				local.R.UserIdentities = append(local.R.UserIdentities, foreign)
+				if foreign.R == nil {
+					foreign.R = &userIdentityR{}
+				}
+				foreign.R.User = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadUserRecoveryCodes allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (userL) LoadUserRecoveryCodes(ctx context.Context, e boil.ContextExecutor, singular bool, maybeUser interface{}, mods queries.Applicator) error {
+	var slice []*User
+	var object *User
+
+	if singular {
+		var ok bool
+		object, ok = maybeUser.(*User)
+		if !ok {
+			object = new(User)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeUser))
+			}
+		}
+	} else {
+		s, ok := maybeUser.(*[]*User)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeUser)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeUser))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &userR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &userR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`user_recovery_codes`),
+		qm.WhereIn(`user_recovery_codes.user_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load user_recovery_codes")
+	}
+
+	var resultSlice []*UserRecoveryCode
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice user_recovery_codes")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on user_recovery_codes")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for user_recovery_codes")
+	}
+
+	if len(userRecoveryCodeAfterSelectHooks) != 0 {
+		for _, obj := range resultSlice {
+			if err := obj.doAfterSelectHooks(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	if singular {
+		object.R.UserRecoveryCodes = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &userRecoveryCodeR{}
+			}
+			foreign.R.User = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.UserID) {
+				local.R.UserRecoveryCodes = append(local.R.UserRecoveryCodes, foreign)
+				if foreign.R == nil {
+					foreign.R = &userRecoveryCodeR{}
+				}
+				foreign.R.User = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// AddAPITokens adds the given related objects to the existing relationships
+// of the user, optionally inserting them as new records.
+// Appends related to o.R.APITokens.
+// Sets related.R.User appropriately.
+func (o *User) AddAPITokens(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*APIToken) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.UserID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"api_tokens\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+				strmangle.WhereClause("\"", "\"", 0, apiTokenPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.UserID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &userR{
+			APITokens: related,
+		}
+	} else {
+		o.R.APITokens = append(o.R.APITokens, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &apiTokenR{
+				User: o,
+			}
+		} else {
+			rel.R.User = o
+		}
+	}
+	return nil
+}
+
+// AddProjects adds the given related objects to the existing relationships
+// of the user, optionally inserting them as new records.
+// Appends related to o.R.Projects.
+// Sets related.R.User appropriately.
+func (o *User) AddProjects(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Project) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.UserID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"projects\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+				strmangle.WhereClause("\"", "\"", 0, projectPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.UserID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &userR{
+			Projects: related,
+		}
+	} else {
+		o.R.Projects = append(o.R.Projects, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &projectR{
+				User: o,
+			}
+		} else {
+			rel.R.User = o
+		}
+	}
+	return nil
+}
+
+// AddTags adds the given related objects to the existing relationships
+// of the user, optionally inserting them as new records.
+// Appends related to o.R.Tags.
+// Sets related.R.User appropriately.
+func (o *User) AddTags(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Tag) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.UserID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"tags\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+				strmangle.WhereClause("\"", "\"", 0, tagPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.UserID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &userR{
+			Tags: related,
+		}
+	} else {
+		o.R.Tags = append(o.R.Tags, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &tagR{
+				User: o,
+			}
+		} else {
+			rel.R.User = o
+		}
+	}
+	return nil
+}
+
+// AddTasks adds the given related objects to the existing relationships
+// of the user, optionally inserting them as new records.
+// Appends related to o.R.Tasks.
+// Sets related.R.User appropriately.
+func (o *User) AddTasks(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Task) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.UserID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"tasks\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+				strmangle.WhereClause("\"", "\"", 0, taskPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.UserID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &userR{
+			Tasks: related,
+		}
+	} else {
+		o.R.Tasks = append(o.R.Tasks, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &taskR{
+				User: o,
+			}
+		} else {
+			rel.R.User = o
+		}
+	}
+	return nil
+}
+
+// SetTasks removes all previously related items of the
+// user replacing them completely with the passed
+// in related items, optionally inserting them as new records.
+// Sets o.R.User's Tasks accordingly.
+// Replaces o.R.Tasks with related.
+// Sets related.R.User's Tasks accordingly.
+func (o *User) SetTasks(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Task) error {
+	query := "update \"tasks\" set \"user_id\" = null where \"user_id\" = ?"
+	values := []interface{}{o.ID}
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, query)
+		fmt.Fprintln(writer, values)
+	}
+	_, err := exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove relationships before set")
+	}
+
+	if o.R != nil {
+		for _, rel := range o.R.Tasks {
+			queries.SetScanner(&rel.UserID, nil)
+			if rel.R == nil {
+				continue
+			}
+
+			rel.R.User = nil
+		}
+		o.R.Tasks = nil
+	}
+
+	return o.AddTasks(ctx, exec, insert, related...)
+}
+
+// RemoveTasks relationships from objects passed in.
+// Removes related items from R.Tasks (uses pointer comparison, removal does not keep order)
+// Sets related.R.User.
+func (o *User) RemoveTasks(ctx context.Context, exec boil.ContextExecutor, related ...*Task) error {
+	if len(related) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, rel := range related {
+		queries.SetScanner(&rel.UserID, nil)
+		if rel.R != nil {
+			rel.R.User = nil
 		}
 		if _, err = rel.Update(ctx, exec, boil.Whitelist("user_id")); err != nil {
 			return err
@@ -667,6 +1584,112 @@ func (o *User) RemoveTasks(ctx context.Context, exec boil.ContextExecutor, relat
 	return nil
 }
 
+// AddUserIdentities adds the given related objects to the existing relationships
+// of the user, optionally inserting them as new records.
+// Appends related to o.R.UserIdentities.
+// Sets related.R.User appropriately.
+func (o *User) AddUserIdentities(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*UserIdentity) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.UserID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"user_identities\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+				strmangle.WhereClause("\"", "\"", 0, userIdentityPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.UserID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &userR{
+			UserIdentities: related,
+		}
+	} else {
+		o.R.UserIdentities = append(o.R.UserIdentities, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &userIdentityR{
+				User: o,
+			}
+		} else {
+			rel.R.User = o
+		}
+	}
+	return nil
+}
+
+// AddUserRecoveryCodes adds the given related objects to the existing relationships
+// of the user, optionally inserting them as new records.
+// Appends related to o.R.UserRecoveryCodes.
+// Sets related.R.User appropriately.
+func (o *User) AddUserRecoveryCodes(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*UserRecoveryCode) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.UserID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"user_recovery_codes\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 0, []string{"user_id"}),
+				strmangle.WhereClause("\"", "\"", 0, userRecoveryCodePrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.UserID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &userR{
+			UserRecoveryCodes: related,
+		}
+	} else {
+		o.R.UserRecoveryCodes = append(o.R.UserRecoveryCodes, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &userRecoveryCodeR{
+				User: o,
+			}
+		} else {
+			rel.R.User = o
+		}
+	}
+	return nil
+}
+
 // Users retrieves all the records using an executor.
 func Users(mods ...qm.QueryMod) userQuery {
 	mods = append(mods, qm.From("\"users\""))
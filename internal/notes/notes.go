@@ -0,0 +1,102 @@
+// Package notes convierte a HTML las notas en Markdown de las tareas. El HTML
+// resultante es seguro para insertarlo en una página con la política CSP de
+// midleware.CspControl: no lleva HTML del usuario, scripts, estilos en línea
+// ni imágenes, y los enlaces solo pueden ir a http, https, mailto o a rutas
+// relativas.
+package notes
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// MaxLength es la longitud máxima de unas notas, en bytes.
+const MaxLength = 20000
+
+// extensions son las extensiones de Markdown admitidas. No se activan las que
+// añaden atributos a partir del texto (HeadingIDs, Footnotes, Titleblock).
+const extensions = blackfriday.NoIntraEmphasis | blackfriday.Tables |
+	blackfriday.FencedCode | blackfriday.Autolink | blackfriday.Strikethrough |
+	blackfriday.SpaceHeadings | blackfriday.BackslashLineBreak
+
+// Normalize limpia unas notas antes de guardarlas: quita los espacios del
+// principio y del final y unifica los saltos de línea. Devuelve un error si
+// son demasiado largas.
+func Normalize(s string) (string, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimSpace(s)
+	if len(s) > MaxLength {
+		return "", fmt.Errorf("las notas no pueden superar los %d caracteres", MaxLength)
+	}
+	return s, nil
+}
+
+// Render convierte unas notas en Markdown a HTML saneado.
+func Render(src string) template.HTML {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+	r := &renderer{HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Flags: blackfriday.UseXHTML,
+	})}
+	out := blackfriday.Run([]byte(src), blackfriday.WithRenderer(r), blackfriday.WithExtensions(extensions))
+	return template.HTML(out)
+}
+
+// renderer es el HTMLRenderer de blackfriday con los nodos que pueden meter
+// HTML o atributos del usuario reescritos: el HTML se muestra como texto, los
+// bloques de código no llevan clase, las imágenes se convierten en enlaces y
+// los enlaces con esquemas no permitidos, en texto.
+type renderer struct {
+	*blackfriday.HTMLRenderer
+}
+
+func (r *renderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	switch node.Type {
+	case blackfriday.HTMLBlock:
+		io.WriteString(w, "<p>"+html.EscapeString(strings.TrimSpace(string(node.Literal)))+"</p>\n")
+		return blackfriday.GoToNext
+	case blackfriday.HTMLSpan:
+		io.WriteString(w, html.EscapeString(string(node.Literal)))
+		return blackfriday.GoToNext
+	case blackfriday.CodeBlock:
+		io.WriteString(w, "<pre><code>"+html.EscapeString(string(node.Literal))+"</code></pre>\n")
+		return blackfriday.GoToNext
+	case blackfriday.Link, blackfriday.Image:
+		dest := string(node.LinkData.Destination)
+		if !safeLink(dest) {
+			return blackfriday.GoToNext
+		}
+		if entering {
+			io.WriteString(w, `<a href="`+html.EscapeString(dest)+`" rel="nofollow noopener noreferrer">`)
+		} else {
+			io.WriteString(w, "</a>")
+		}
+		return blackfriday.GoToNext
+	}
+	return r.HTMLRenderer.RenderNode(w, node, entering)
+}
+
+// safeLink indica si dest es un enlace permitido: http, https, mailto o una
+// ruta relativa.
+func safeLink(dest string) bool {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	case "":
+		return u.Host == "" && !strings.HasPrefix(dest, "//")
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Package priority define los niveles de prioridad de las tareas, que se
+// guardan como enteros en tasks.priority.
+package priority
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Niveles de prioridad, de menor a mayor.
+const (
+	None int64 = iota
+	Low
+	Medium
+	High
+	Urgent
+)
+
+// Names son los nombres canónicos (los de la API y la CLI) por nivel.
+var Names = []string{"none", "low", "medium", "high", "urgent"}
+
+// Labels son las etiquetas que se muestran en la web por nivel.
+var Labels = []string{"Sin prioridad", "Baja", "Media", "Alta", "Urgente"}
+
+var aliases = map[string]int64{
+	"ninguna": None,
+	"baja":    Low,
+	"media":   Medium,
+	"alta":    High,
+	"urgente": Urgent,
+}
+
+// Parse acepta el nombre canónico, su equivalente en castellano o el número
+// del nivel. Una cadena vacía equivale a None.
+func Parse(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return None, nil
+	}
+	for level, name := range Names {
+		if s == name {
+			return int64(level), nil
+		}
+	}
+	if level, ok := aliases[s]; ok {
+		return level, nil
+	}
+	if level, err := strconv.ParseInt(s, 10, 64); err == nil && Valid(level) {
+		return level, nil
+	}
+	return None, fmt.Errorf("prioridad inválida %q: usa %s", s, strings.Join(Names, "|"))
+}
+
+// Valid indica si level es un nivel conocido.
+func Valid(level int64) bool {
+	return level >= None && level <= Urgent
+}
+
+// Name devuelve el nombre canónico de level.
+func Name(level int64) string {
+	if !Valid(level) {
+		return Names[None]
+	}
+	return Names[level]
+}
+
+// Label devuelve la etiqueta para la web de level.
+func Label(level int64) string {
+	if !Valid(level) {
+		return Labels[None]
+	}
+	return Labels[level]
+}
@@ -0,0 +1,131 @@
+// Package project valida los nombres de los proyectos y reúne las consultas
+// sobre proyectos que comparten la web, la API y la CLI.
+package project
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+// MaxLength es la longitud máxima del nombre de un proyecto.
+const MaxLength = 64
+
+// None es el valor que designa "sin proyecto" en filtros y flags, por lo que
+// no puede usarse como nombre.
+const None = "none"
+
+// Normalize quita los espacios alrededor de name y comprueba que es un nombre
+// de proyecto válido.
+func Normalize(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", errors.New("el nombre del proyecto no puede estar vacío")
+	case strings.EqualFold(name, None):
+		return "", fmt.Errorf("%q está reservado y no puede usarse como nombre de proyecto", None)
+	case utf8.RuneCountInString(name) > MaxLength:
+		return "", fmt.Errorf("el nombre del proyecto no puede superar los %d caracteres", MaxLength)
+	}
+	return name, nil
+}
+
+// Find busca el proyecto del usuario con ese nombre.
+func Find(ctx context.Context, exec boil.ContextExecutor, userID int64, name string) (*models.Project, error) {
+	p, err := models.Projects(
+		models.ProjectWhere.UserID.EQ(userID),
+		models.ProjectWhere.Name.EQ(strings.TrimSpace(name)),
+	).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("el proyecto %q no existe", strings.TrimSpace(name))
+	}
+	return p, err
+}
+
+// Counts son los totales de tareas de un proyecto.
+type Counts struct {
+	Tasks   int64 `json:"tasks"`
+	Pending int64 `json:"pending"`
+}
+
+// TaskCounts devuelve los totales de tareas del usuario por ID de proyecto,
+// sin contar las subtareas. La clave 0 agrupa las tareas sin proyecto.
+func TaskCounts(ctx context.Context, exec boil.ContextExecutor, userID int64) (map[int64]Counts, error) {
+	rows, err := exec.QueryContext(ctx, `SELECT COALESCE(project_id, 0), COUNT(*), COALESCE(SUM(done = 0 OR done IS NULL), 0)
+		FROM tasks WHERE user_id = ? AND parent_id IS NULL GROUP BY COALESCE(project_id, 0)`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int64]Counts{}
+	for rows.Next() {
+		var id int64
+		var c Counts
+		if err := rows.Scan(&id, &c.Tasks, &c.Pending); err != nil {
+			return nil, err
+		}
+		counts[id] = c
+	}
+	return counts, rows.Err()
+}
+
+// Reassign pasa las tareas de un usuario de sus proyectos a los proyectos del
+// mismo nombre de otro, creándolos si hace falta. Se usa al reasignar tareas
+// para que no pierdan su proyecto.
+func Reassign(ctx context.Context, exec boil.ContextExecutor, fromUserID, toUserID int64) error {
+	from, err := models.Projects(models.ProjectWhere.UserID.EQ(fromUserID)).All(ctx, exec)
+	if err != nil {
+		return err
+	}
+	for _, p := range from {
+		target, err := models.Projects(
+			models.ProjectWhere.UserID.EQ(toUserID),
+			models.ProjectWhere.Name.EQ(p.Name),
+		).One(ctx, exec)
+		if errors.Is(err, sql.ErrNoRows) {
+			target = &models.Project{UserID: toUserID, Name: p.Name}
+			err = target.Insert(ctx, exec, boil.Infer())
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := exec.ExecContext(ctx, "UPDATE tasks SET project_id = ? WHERE project_id = ?", target.ID, p.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveID convierte el valor de un formulario (el ID del proyecto, o vacío o
+// None para ninguno) en el project_id de una tarea del usuario, comprobando
+// que el proyecto es suyo.
+func ResolveID(ctx context.Context, exec boil.ContextExecutor, userID int64, value string) (null.Int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == None {
+		return null.Int64{}, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return null.Int64{}, fmt.Errorf("proyecto inválido %q", value)
+	}
+	exists, err := models.Projects(
+		models.ProjectWhere.ID.EQ(null.Int64From(id)),
+		models.ProjectWhere.UserID.EQ(userID),
+	).Exists(ctx, exec)
+	if err != nil {
+		return null.Int64{}, err
+	}
+	if !exists {
+		return null.Int64{}, fmt.Errorf("el proyecto %d no existe", id)
+	}
+	return null.Int64From(id), nil
+}
@@ -0,0 +1,302 @@
+// Package recurrence implementa las tareas que se repiten: un subconjunto de
+// las RRULE de RFC 5545 (FREQ=DAILY|WEEKLY|MONTHLY con INTERVAL, BYDAY, UNTIL
+// y COUNT) y la creación de la siguiente repetición al completar una tarea.
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freq es la frecuencia de una regla.
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+)
+
+// MaxInterval es el mayor INTERVAL admitido.
+const MaxInterval = 366
+
+// untilLayout es el formato de UNTIL en las reglas que genera String.
+const untilLayout = "20060102T150405Z"
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var weekdayLabels = []string{"do", "lu", "ma", "mi", "ju", "vi", "sá"}
+
+// aliases son los nombres cortos que se aceptan en lugar de una RRULE.
+var aliases = map[string]Freq{
+	"daily":   Daily,
+	"diaria":  Daily,
+	"weekly":  Weekly,
+	"semanal": Weekly,
+	"monthly": Monthly,
+	"mensual": Monthly,
+}
+
+// Rule es una regla de repetición. Until cero significa sin fecha límite y
+// Count cero sin límite de repeticiones; Count cuenta la repetición actual.
+type Rule struct {
+	Freq     Freq
+	Interval int
+	ByDay    []time.Weekday
+	Until    time.Time
+	Count    int
+}
+
+// Parse interpreta una RRULE como "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH" (con o
+// sin el prefijo "RRULE:") o uno de los alias daily, weekly y monthly. Un
+// UNTIL con solo fecha (AAAAMMDD o AAAA-MM-DD) se entiende como el final de
+// ese día en loc.
+func Parse(s string, loc *time.Location) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	if freq, ok := aliases[strings.ToLower(s)]; ok {
+		return Rule{Freq: freq, Interval: 1}, nil
+	}
+
+	r := Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("regla de repetición inválida %q: usa FREQ=DAILY|WEEKLY|MONTHLY;... o daily, weekly, monthly", s)
+		}
+		if seen[key] {
+			return Rule{}, fmt.Errorf("%s aparece dos veces en la regla de repetición", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch f := Freq(strings.ToUpper(value)); f {
+			case Daily, Weekly, Monthly:
+				r.Freq = f
+			default:
+				return Rule{}, fmt.Errorf("frecuencia no admitida %q: usa DAILY, WEEKLY o MONTHLY", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > MaxInterval {
+				return Rule{}, fmt.Errorf("INTERVAL inválido %q: debe estar entre 1 y %d", value, MaxInterval)
+			}
+			r.Interval = n
+		case "BYDAY":
+			days, err := parseWeekdays(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			r.ByDay = days
+		case "UNTIL":
+			until, err := parseUntil(value, loc)
+			if err != nil {
+				return Rule{}, err
+			}
+			r.Until = until
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("COUNT inválido %q: debe ser un número positivo", value)
+			}
+			r.Count = n
+		default:
+			return Rule{}, fmt.Errorf("%s no está admitido en las reglas de repetición", key)
+		}
+	}
+	if r.Freq == "" {
+		return Rule{}, fmt.Errorf("falta FREQ en la regla de repetición %q", s)
+	}
+	if !r.Until.IsZero() && r.Count > 0 {
+		return Rule{}, fmt.Errorf("la regla de repetición no puede tener UNTIL y COUNT a la vez")
+	}
+	return r, nil
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	seen := map[time.Weekday]bool{}
+	for _, code := range strings.Split(strings.ToUpper(value), ",") {
+		code = strings.TrimSpace(code)
+		found := false
+		for d, c := range weekdayCodes {
+			if c == code {
+				if !seen[time.Weekday(d)] {
+					days = append(days, time.Weekday(d))
+					seen[time.Weekday(d)] = true
+				}
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("día inválido %q en BYDAY: usa MO, TU, WE, TH, FR, SA o SU", code)
+		}
+	}
+	// Se guardan de lunes a domingo
+	sort.Slice(days, func(i, j int) bool { return (days[i]+6)%7 < (days[j]+6)%7 })
+	return days, nil
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	// Con Z la hora es UTC; sin ella, la de loc
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range []string{"20060102", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.Add(24*time.Hour - time.Second).UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("UNTIL inválido %q: usa AAAAMMDD o AAAAMMDDTHHMMSSZ", value)
+}
+
+// String devuelve la regla en formato RRULE, que es como se guarda.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			codes = append(codes, weekdayCodes[d])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Label describe la regla en castellano, por ejemplo "cada 2 semanas (lu, ju)".
+// Las fechas se muestran en loc.
+func (r Rule) Label(loc *time.Location) string {
+	var label string
+	switch r.Freq {
+	case Daily:
+		label = plural(r.Interval, "cada día", "cada %d días")
+	case Weekly:
+		label = plural(r.Interval, "cada semana", "cada %d semanas")
+	case Monthly:
+		label = plural(r.Interval, "cada mes", "cada %d meses")
+	}
+	if len(r.ByDay) > 0 {
+		names := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			names = append(names, weekdayLabels[d])
+		}
+		label += " (" + strings.Join(names, ", ") + ")"
+	}
+	if !r.Until.IsZero() {
+		label += ", hasta el " + r.Until.In(loc).Format("02/01/2006")
+	}
+	if r.Count > 0 {
+		label += plural(r.Count, ", queda 1 vez", ", quedan %d veces")
+	}
+	return label
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return fmt.Sprintf(many, n)
+}
+
+// Next devuelve la siguiente repetición de una serie cuya repetición actual
+// es la de start, con la misma hora del día en la zona de start. Devuelve
+// false si la serie se acaba con la repetición actual.
+func (r Rule) Next(start time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	// Cualquier regla válida tiene una repetición antes de este límite
+	limit := 4 * 366 * interval
+	for days := 1; days <= limit; days++ {
+		t := start.AddDate(0, 0, days)
+		if !r.matches(start, t, interval) {
+			continue
+		}
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Advance devuelve la regla de la siguiente repetición: la misma, con una
+// repetición menos si tiene COUNT.
+func (r Rule) Advance() Rule {
+	if r.Count > 0 {
+		r.Count--
+	}
+	return r
+}
+
+func (r Rule) matches(start, t time.Time, interval int) bool {
+	switch r.Freq {
+	case Daily:
+		return civilDays(start, t)%interval == 0 && r.onDay(t.Weekday(), t.Weekday())
+	case Weekly:
+		return weeksBetween(start, t)%interval == 0 && r.onDay(t.Weekday(), start.Weekday())
+	case Monthly:
+		months := (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+		if months%interval != 0 {
+			return false
+		}
+		if len(r.ByDay) > 0 {
+			return r.onDay(t.Weekday(), t.Weekday())
+		}
+		// Como en RFC 5545, los meses sin ese día (31, 30...) se saltan
+		return t.Day() == start.Day()
+	}
+	return false
+}
+
+// onDay indica si d está en BYDAY o, si la regla no tiene BYDAY, si es def.
+func (r Rule) onDay(d, def time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return d == def
+	}
+	for _, day := range r.ByDay {
+		if day == d {
+			return true
+		}
+	}
+	return false
+}
+
+// civilDays cuenta los días naturales de a a b, sin que influyan los cambios
+// de hora.
+func civilDays(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// weeksBetween cuenta las semanas (de lunes a domingo, el WKST por defecto de
+// RFC 5545) entre la de a y la de b.
+func weeksBetween(a, b time.Time) int {
+	return (civilDays(a, b) + (int(a.Weekday())+6)%7) / 7
+}
@@ -0,0 +1,167 @@
+package recurrence
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// None es el valor que quita la regla de repetición de una tarea.
+const None = "none"
+
+// ErrNotRecurring indica que la tarea no se repite.
+var ErrNotRecurring = errors.New("la tarea no se repite")
+
+// ErrSeriesEnded indica que la serie no tiene más repeticiones.
+var ErrSeriesEnded = errors.New("la serie no tiene más repeticiones")
+
+// ErrSubtask indica que se ha intentado repetir una subtarea.
+var ErrSubtask = errors.New("una subtarea no puede repetirse")
+
+// Apply interpreta s con Parse y la guarda como regla de task. Una cadena
+// vacía o None quita la regla, lo que detiene la serie.
+func Apply(task *models.Task, s string, loc *time.Location) error {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, None) {
+		task.Recurrence = null.String{}
+		return nil
+	}
+	if task.ParentID.Valid {
+		return ErrSubtask
+	}
+	rule, err := Parse(s, loc)
+	if err != nil {
+		return err
+	}
+	task.Recurrence = null.StringFrom(rule.String())
+	return nil
+}
+
+// Label describe la regla de una tarea, o devuelve "" si no se repite.
+func Label(recurrence null.String, loc *time.Location) string {
+	if !recurrence.Valid {
+		return ""
+	}
+	rule, err := Parse(recurrence.String, time.UTC)
+	if err != nil {
+		return recurrence.String
+	}
+	return rule.Label(loc)
+}
+
+// Complete crea la siguiente repetición de task, que se acaba de marcar como
+// hecha, y le pasa la regla. La nueva tarea copia el título, las notas, la
+// prioridad, el proyecto, las etiquetas y las subtareas (pendientes). La siguiente fecha se
+// calcula a partir del vencimiento de task o, si no tiene, de now, en la zona
+// de now. Devuelve nil si task no se repite, la serie ha terminado o otra
+// copia de task ya la ha completado.
+func Complete(ctx context.Context, exec boil.ContextExecutor, task *models.Task, now time.Time) (*models.Task, error) {
+	if !task.Recurrence.Valid {
+		return nil, nil
+	}
+	rule, err := Parse(task.Recurrence.String, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	// La regla la lleva solo la repetición pendiente. Se quita solo si la
+	// tarea aún la tiene, en la misma sentencia: si otra copia de la tarea ya
+	// la ha completado, la siguiente repetición ya existe
+	n, err := models.Tasks(
+		models.TaskWhere.ID.EQ(task.ID),
+		models.TaskWhere.Recurrence.IsNotNull(),
+	).UpdateAll(ctx, exec, models.M{models.TaskColumns.Recurrence: nil})
+	if err != nil {
+		return nil, err
+	}
+	task.Recurrence = null.String{}
+	if n == 0 {
+		return nil, nil
+	}
+	next, ok := rule.Next(base(task, now))
+	if !ok {
+		return nil, nil
+	}
+
+	spawn := &models.Task{
+		Title:       task.Title,
+		Done:        null.BoolFrom(false),
+		UserID:      task.UserID,
+		DueAt:       null.TimeFrom(next.UTC().Truncate(time.Second)),
+		Priority:    task.Priority,
+		ProjectID:   task.ProjectID,
+		Recurrence:  null.StringFrom(rule.Advance().String()),
+		Description: task.Description,
+	}
+	if err := spawn.Insert(ctx, exec, boil.Infer()); err != nil {
+		return nil, err
+	}
+	tags, err := task.Tags(qm.OrderBy(models.TagColumns.Name)).All(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+	if err := spawn.SetTags(ctx, exec, false, tags...); err != nil {
+		return nil, err
+	}
+	children, err := task.ParentTasks(qm.OrderBy(models.TaskColumns.ID)).All(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if _, err := subtask.Add(ctx, exec, spawn, child.Title); err != nil {
+			return nil, err
+		}
+	}
+	return spawn, nil
+}
+
+// Skip salta la repetición actual de task sin completarla: le pone la fecha
+// de la siguiente y deja sus subtareas pendientes. Devuelve ErrSeriesEnded si
+// era la última.
+func Skip(ctx context.Context, exec boil.ContextExecutor, task *models.Task, now time.Time) error {
+	if !task.Recurrence.Valid {
+		return ErrNotRecurring
+	}
+	rule, err := Parse(task.Recurrence.String, time.UTC)
+	if err != nil {
+		return err
+	}
+	next, ok := rule.Next(base(task, now))
+	if !ok {
+		return ErrSeriesEnded
+	}
+	task.DueAt = null.TimeFrom(next.UTC().Truncate(time.Second))
+	task.Recurrence = null.StringFrom(rule.Advance().String())
+	if _, err := task.Update(ctx, exec, boil.Whitelist(models.TaskColumns.DueAt, models.TaskColumns.Recurrence)); err != nil {
+		return err
+	}
+	_, err = models.Tasks(models.TaskWhere.ParentID.EQ(task.ID)).UpdateAll(ctx, exec, models.M{models.TaskColumns.Done: false})
+	return err
+}
+
+// Stop detiene la serie de task: la tarea se queda como está pero ya no se
+// repetirá.
+func Stop(ctx context.Context, exec boil.ContextExecutor, task *models.Task) error {
+	if !task.Recurrence.Valid {
+		return ErrNotRecurring
+	}
+	task.Recurrence = null.String{}
+	_, err := task.Update(ctx, exec, boil.Whitelist(models.TaskColumns.Recurrence))
+	return err
+}
+
+// base es la repetición actual de task en la zona de now: su vencimiento o,
+// si no tiene, now.
+func base(task *models.Task, now time.Time) time.Time {
+	if task.DueAt.Valid {
+		return task.DueAt.Time.In(now.Location())
+	}
+	return now
+}
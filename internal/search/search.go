@@ -0,0 +1,97 @@
+// Package search implementa la búsqueda de texto completo en el título y las
+// notas de las tareas, sobre el índice FTS5 tasks_fts.
+package search
+
+import (
+	"context"
+	"errors"
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+)
+
+// DefaultLimit y MaxLimit son el número de resultados por defecto y el máximo.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrEmptyQuery indica que la búsqueda no tiene ningún término.
+var ErrEmptyQuery = errors.New("la búsqueda no puede estar vacía")
+
+// Marcas con las que snippet() rodea los términos encontrados. Son caracteres
+// de control para que no se confundan con el texto de la tarea.
+const (
+	markOpen  = "\x02"
+	markClose = "\x03"
+)
+
+// Result es una tarea encontrada, con el fragmento de texto donde aparecen
+// los términos buscados.
+type Result struct {
+	models.Task `boil:",bind"`
+	// Snippet lleva los términos encontrados entre markOpen y markClose; se
+	// muestra con Highlight o HTML.
+	Snippet string  `boil:"snippet" json:"-"`
+	Rank    float64 `boil:"rank" json:"rank"`
+}
+
+// Highlight devuelve el fragmento con los términos encontrados entre open y
+// close, por ejemplo "[" y "]".
+func (r Result) Highlight(open, close string) string {
+	return strings.NewReplacer(markOpen, open, markClose, close).Replace(r.Snippet)
+}
+
+// HTML devuelve el fragmento escapado, con los términos encontrados en <mark>.
+func (r Result) HTML() template.HTML {
+	return template.HTML(strings.NewReplacer(markOpen, "<mark>", markClose, "</mark>").Replace(html.EscapeString(r.Snippet)))
+}
+
+// Query convierte lo que escribe el usuario en una consulta FTS5: cada palabra
+// se busca como prefijo y tienen que aparecer todas. Las comillas y los
+// operadores de FTS5 se tratan como texto, así que nunca da error de sintaxis.
+func Query(s string) (string, error) {
+	var terms []string
+	for _, word := range strings.Fields(s) {
+		word = strings.Trim(word, `"*`)
+		if word == "" {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	if len(terms) == 0 {
+		return "", ErrEmptyQuery
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// Tasks busca q en las tareas de userID y devuelve hasta limit resultados,
+// los más relevantes primero. Las coincidencias en el título pesan más que
+// las de las notas.
+func Tasks(ctx context.Context, exec boil.ContextExecutor, userID int64, q string, limit int) ([]*Result, error) {
+	match, err := Query(q)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	var results []*Result
+	err = queries.Raw(`
+		SELECT tasks.*,
+			snippet(tasks_fts, -1, ?, ?, '…', 12) AS snippet,
+			bm25(tasks_fts, 10.0, 1.0) AS rank
+		FROM tasks_fts
+		JOIN tasks ON tasks.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH ? AND tasks.user_id = ?
+		ORDER BY rank, tasks.id
+		LIMIT ?`, markOpen, markClose, match, userID, limit).Bind(ctx, exec, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
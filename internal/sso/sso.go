@@ -0,0 +1,262 @@
+// Package sso implementa el login con un proveedor OpenID Connect: el flujo
+// authorization code con PKCE y la vinculación de las cuentas externas con
+// los usuarios.
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrNotLinked indica una cuenta externa que no está vinculada a ningún
+	// usuario.
+	ErrNotLinked = errors.New("la cuenta externa no está vinculada a ningún usuario")
+	// ErrLinkedElsewhere indica una cuenta externa que ya está vinculada a
+	// otro usuario.
+	ErrLinkedElsewhere = errors.New("la cuenta externa ya está vinculada a otro usuario")
+)
+
+// Config es la configuración del proveedor. Name es el nombre que se le
+// muestra al usuario y, con AutoProvision, las cuentas externas que no están
+// vinculadas crean un usuario al entrar.
+type Config struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Name          string
+	AutoProvision bool
+}
+
+// Provider es un proveedor OpenID Connect ya descubierto.
+type Provider struct {
+	Config
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// New lee la configuración del proveedor de su documento de descubrimiento,
+// en Issuer/.well-known/openid-configuration.
+func New(ctx context.Context, cfg Config) (*Provider, error) {
+	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("descubriendo el proveedor OIDC %s: %w", cfg.Issuer, err)
+	}
+	return &Provider{
+		Config: cfg,
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+// Request es lo que hay que guardar entre la redirección al proveedor y la
+// vuelta: el state que protege la vuelta, el nonce que tiene que traer el ID
+// token y el verificador PKCE.
+type Request struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// NewRequest genera los valores aleatorios de un login.
+func NewRequest() (Request, error) {
+	state := make([]byte, 16)
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		return Request{}, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return Request{}, err
+	}
+	return Request{
+		State:    hex.EncodeToString(state),
+		Nonce:    hex.EncodeToString(nonce),
+		Verifier: oauth2.GenerateVerifier(),
+	}, nil
+}
+
+// AuthCodeURL es la página del proveedor a la que se redirige al usuario para
+// que inicie sesión.
+func (p *Provider) AuthCodeURL(req Request) string {
+	return p.oauth.AuthCodeURL(req.State, oauth2.S256ChallengeOption(req.Verifier), oidc.Nonce(req.Nonce))
+}
+
+// Identity es la cuenta externa con la que ha entrado el usuario.
+type Identity struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// Exchange canjea el código con el que vuelve el usuario y comprueba el ID
+// token: la firma, el emisor, la audiencia, la caducidad y el nonce de req.
+func (p *Provider) Exchange(ctx context.Context, code string, req Request) (*Identity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(req.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("canjeando el código: %w", err)
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("el proveedor no ha devuelto un ID token")
+	}
+	idToken, err := p.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("ID token inválido: %w", err)
+	}
+	if idToken.Nonce != req.Nonce {
+		return nil, errors.New("ID token inválido: el nonce no coincide")
+	}
+	var id Identity
+	if err := idToken.Claims(&id); err != nil {
+		return nil, fmt.Errorf("leyendo el ID token: %w", err)
+	}
+	return &id, nil
+}
+
+// FindUser devuelve el usuario vinculado a la cuenta externa id del emisor
+// issuer, o ErrNotLinked.
+func FindUser(ctx context.Context, exec boil.ContextExecutor, issuer string, id *Identity) (*models.User, error) {
+	identity, err := models.UserIdentities(
+		models.UserIdentityWhere.Issuer.EQ(issuer),
+		models.UserIdentityWhere.Subject.EQ(id.Subject),
+	).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models.FindUser(ctx, exec, null.Int64From(identity.UserID))
+}
+
+// Link vincula la cuenta externa id con el usuario. Si ya estaba vinculada con
+// él no hace nada; si lo está con otro devuelve ErrLinkedElsewhere.
+func Link(ctx context.Context, exec boil.ContextExecutor, userID int64, issuer string, id *Identity) (*models.UserIdentity, error) {
+	identity, err := models.UserIdentities(
+		models.UserIdentityWhere.Issuer.EQ(issuer),
+		models.UserIdentityWhere.Subject.EQ(id.Subject),
+	).One(ctx, exec)
+	switch {
+	case err == nil && identity.UserID == userID:
+		return identity, nil
+	case err == nil:
+		return nil, ErrLinkedElsewhere
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+	identity = &models.UserIdentity{
+		UserID:  userID,
+		Issuer:  issuer,
+		Subject: id.Subject,
+		Email:   null.NewString(id.Email, id.Email != ""),
+	}
+	if err := identity.Insert(ctx, exec, boil.Infer()); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// Provision crea un usuario sin contraseña para la cuenta externa id y la
+// vincula con él. El nombre de usuario sale de Username, con un número detrás
+// si ya existe, sin distinguir mayúsculas, y tiene que cumplir policy.
+func Provision(ctx context.Context, exec boil.ContextExecutor, issuer string, id *Identity, policy credentials.Policy) (*models.User, error) {
+	base := Username(id, policy)
+	username := base
+	for n := 2; ; n++ {
+		if err := policy.CheckUsername(username); err != nil {
+			return nil, fmt.Errorf("nombre de usuario %q: %w", username, err)
+		}
+		exists, err := credentials.UsernameTaken(ctx, exec, username)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			break
+		}
+		suffix := strconv.Itoa(n)
+		username = truncate(base, policy.UsernameMaxLength-len(suffix)) + suffix
+	}
+	user := &models.User{Username: username}
+	if err := user.Insert(ctx, exec, boil.Infer()); err != nil {
+		return nil, err
+	}
+	if _, err := Link(ctx, exec, user.ID.Int64, issuer, id); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Username es el nombre de usuario que se propone para la cuenta externa id:
+// preferred_username o, si no lo tiene, la parte local del email, solo con
+// letras, números y . _ - y sin pasar de la longitud máxima de policy. Si aun
+// así no cumple policy, es "usuario".
+func Username(id *Identity, policy credentials.Policy) string {
+	name := strings.TrimSpace(id.PreferredUsername)
+	if name == "" {
+		name, _, _ = strings.Cut(strings.TrimSpace(id.Email), "@")
+	}
+	name = truncate(cleanUsername(credentials.NormalizeUsername(name)), policy.UsernameMaxLength)
+	if policy.CheckUsername(name) != nil {
+		return "usuario"
+	}
+	return name
+}
+
+// cleanUsername cambia cada tramo de caracteres que no son letras, números o
+// . _ - por un punto y quita los signos del principio y del final.
+func cleanUsername(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !strings.ContainsRune("._-", r) {
+			sep = true
+			continue
+		}
+		if sep && b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		sep = false
+		b.WriteRune(r)
+	}
+	return strings.Trim(b.String(), "._-")
+}
+
+// truncate corta s en max caracteres; con max <= 0 no lo corta.
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// Identities devuelve las cuentas externas vinculadas al usuario.
+func Identities(ctx context.Context, exec boil.ContextExecutor, userID int64) (models.UserIdentitySlice, error) {
+	return models.UserIdentities(
+		models.UserIdentityWhere.UserID.EQ(userID),
+		qm.OrderBy(models.UserIdentityColumns.ID),
+	).All(ctx, exec)
+}
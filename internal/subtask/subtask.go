@@ -0,0 +1,115 @@
+// Package subtask reúne las reglas de las subtareas: una tarea puede tener
+// una lista de subtareas, que a su vez no pueden tener las suyas.
+package subtask
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// ErrNotFound indica que la tarea no existe o no es del usuario.
+var ErrNotFound = errors.New("tarea no encontrada")
+
+// ErrNested indica que se ha intentado colgar una subtarea de otra subtarea.
+var ErrNested = errors.New("una subtarea no puede tener subtareas")
+
+// Progress es el avance de las subtareas de una tarea.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// String devuelve el avance como "3/5".
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d", p.Done, p.Total)
+}
+
+// Complete indica si la tarea tiene subtareas y están todas hechas.
+func (p Progress) Complete() bool {
+	return p.Total > 0 && p.Done == p.Total
+}
+
+// Of calcula el avance de unas subtareas.
+func Of(children models.TaskSlice) Progress {
+	p := Progress{Total: len(children)}
+	for _, c := range children {
+		if c.Done.Bool {
+			p.Done++
+		}
+	}
+	return p
+}
+
+// Load carga las subtareas de una tarea en t.R.ParentTasks, por orden de
+// creación.
+func Load(ctx context.Context, exec boil.ContextExecutor, t *models.Task) error {
+	return t.L.LoadParentTasks(ctx, exec, true, t, qm.OrderBy(models.TaskColumns.ID))
+}
+
+// FindParent busca la tarea id del usuario a la que se quieren añadir
+// subtareas. Devuelve ErrNotFound si no es suya y ErrNested si ya es una
+// subtarea.
+func FindParent(ctx context.Context, exec boil.ContextExecutor, userID, id int64) (*models.Task, error) {
+	parent, err := models.FindTask(ctx, exec, null.Int64From(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && parent.UserID.Int64 != userID) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID.Valid {
+		return nil, ErrNested
+	}
+	return parent, nil
+}
+
+// Add crea una subtarea pendiente de parent. La subtarea hereda el usuario y
+// el proyecto de parent.
+func Add(ctx context.Context, exec boil.ContextExecutor, parent *models.Task, title string) (*models.Task, error) {
+	if parent.ParentID.Valid {
+		return nil, ErrNested
+	}
+	child := &models.Task{
+		Title:     title,
+		Done:      null.BoolFrom(false),
+		UserID:    parent.UserID,
+		Priority:  priority.None,
+		ProjectID: parent.ProjectID,
+		ParentID:  parent.ID,
+	}
+	if err := child.Insert(ctx, exec, boil.Infer()); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// SuggestComplete indica si conviene proponer marcar como hecha la tarea
+// parentID: sigue pendiente pero todas sus subtareas están hechas.
+func SuggestComplete(ctx context.Context, exec boil.ContextExecutor, parentID int64) (bool, error) {
+	parent, err := models.FindTask(ctx, exec, null.Int64From(parentID))
+	if err != nil {
+		return false, err
+	}
+	if parent.Done.Bool {
+		return false, nil
+	}
+	if err := Load(ctx, exec, parent); err != nil {
+		return false, err
+	}
+	return Of(parent.R.GetParentTasks()).Complete(), nil
+}
+
+// SyncProject pasa las subtareas de parent al proyecto de parent, para que
+// sigan a su tarea al moverla.
+func SyncProject(ctx context.Context, exec boil.ContextExecutor, parent *models.Task) error {
+	_, err := models.Tasks(models.TaskWhere.ParentID.EQ(parent.ID)).UpdateAll(ctx, exec, models.M{models.TaskColumns.ProjectID: parent.ProjectID})
+	return err
+}
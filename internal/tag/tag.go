@@ -0,0 +1,113 @@
+// Package tag valida los nombres de las etiquetas y resuelve las etiquetas de
+// un usuario a partir de sus nombres.
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+// MaxLength es la longitud máxima de un nombre de etiqueta.
+const MaxLength = 32
+
+// Normalize devuelve name en minúsculas y sin espacios alrededor. Un nombre
+// válido no está vacío, no empieza por "-" (se usa para excluir en los
+// filtros) y no contiene espacios ni comas.
+func Normalize(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == "":
+		return "", errors.New("la etiqueta no puede estar vacía")
+	case strings.HasPrefix(name, "-"):
+		return "", fmt.Errorf("etiqueta inválida %q: no puede empezar por \"-\"", name)
+	case strings.ContainsFunc(name, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }):
+		return "", fmt.Errorf("etiqueta inválida %q: no puede contener espacios ni comas", name)
+	case utf8.RuneCountInString(name) > MaxLength:
+		return "", fmt.Errorf("la etiqueta no puede superar los %d caracteres", MaxLength)
+	}
+	return name, nil
+}
+
+// ParseList normaliza una lista de etiquetas separadas por comas, como la de
+// los formularios, quitando las repetidas. Una cadena vacía no tiene
+// etiquetas.
+func ParseList(s string) ([]string, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, err := Normalize(part)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Ensure devuelve las etiquetas del usuario con los nombres indicados,
+// creando las que no existan. Los nombres deben estar normalizados.
+func Ensure(ctx context.Context, exec boil.ContextExecutor, userID int64, names []string) (models.TagSlice, error) {
+	tags := make(models.TagSlice, 0, len(names))
+	for _, name := range names {
+		t, err := models.Tags(
+			models.TagWhere.UserID.EQ(userID),
+			models.TagWhere.Name.EQ(name),
+		).One(ctx, exec)
+		if err == nil {
+			tags = append(tags, t)
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		t = &models.Tag{UserID: userID, Name: name}
+		if err := t.Insert(ctx, exec, boil.Infer()); err != nil {
+			return nil, fmt.Errorf("error creando etiqueta %q: %w", name, err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// Names devuelve los nombres de tags en el mismo orden.
+func Names(tags models.TagSlice) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Reassign pasa las etiquetas de las tareas de un usuario a las etiquetas del
+// mismo nombre de otro, creándolas si hace falta. Se usa al reasignar tareas
+// para que no pierdan sus etiquetas.
+func Reassign(ctx context.Context, exec boil.ContextExecutor, fromUserID, toUserID int64) error {
+	from, err := models.Tags(models.TagWhere.UserID.EQ(fromUserID)).All(ctx, exec)
+	if err != nil {
+		return err
+	}
+	to, err := Ensure(ctx, exec, toUserID, Names(from))
+	if err != nil {
+		return err
+	}
+	for i, t := range from {
+		if _, err := exec.ExecContext(ctx, "UPDATE task_tags SET tag_id = ? WHERE tag_id = ?", to[i].ID, t.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package taskquery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/search"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// DefaultLimit y MaxLimit son el tamaño de página por defecto y el máximo de
+// los listados paginados.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// ErrInvalidCursor indica que el cursor no es uno devuelto por Page para el
+// mismo orden.
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// Filter describe un listado de tareas: cuáles, en qué orden y desde dónde.
+// Los campos a cero no filtran.
+type Filter struct {
+	// UserID limita el listado a las tareas del usuario; la CLI usa 0 para
+	// listar las de todos.
+	UserID int64
+	Done   null.Bool
+	// Text busca en el título y las notas con el índice de internal/search.
+	Text    string
+	Tags    TagFilter
+	Project ProjectFilter
+	// DueBefore deja solo las tareas que vencen antes de ese instante.
+	DueBefore null.Time
+	// TopLevel deja fuera las subtareas.
+	TopLevel bool
+	// Extra son condiciones propias de quien lista, como el proyecto por
+	// nombre de la CLI.
+	Extra []qm.QueryMod
+
+	Sort Sort
+	// Limit es el tamaño de página; 0 lista todas.
+	Limit int
+	// After es el cursor de la página anterior.
+	After *Cursor
+}
+
+// ParseFilter interpreta los parámetros de un listado: done, q, tag, project,
+// sort, order, limit y cursor.
+func ParseFilter(values url.Values) (Filter, error) {
+	var f Filter
+	var err error
+	if f.Done, err = ParseDone(values.Get("done")); err != nil {
+		return Filter{}, err
+	}
+	if q := strings.TrimSpace(values.Get("q")); q != "" {
+		if _, err := search.Query(q); err != nil {
+			return Filter{}, err
+		}
+		f.Text = q
+	}
+	if f.Tags, err = ParseTagFilter(values["tag"]); err != nil {
+		return Filter{}, err
+	}
+	if f.Project, err = ParseProjectFilter(values.Get("project")); err != nil {
+		return Filter{}, err
+	}
+	if f.Sort, err = ParseSort(values.Get("sort"), values.Get("order")); err != nil {
+		return Filter{}, err
+	}
+	if s := values.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > MaxLimit {
+			return Filter{}, fmt.Errorf("limit inválido %q: debe estar entre 1 y %d", s, MaxLimit)
+		}
+		f.Limit = n
+	}
+	if s := values.Get("cursor"); s != "" {
+		if f.After, err = ParseCursor(s, f.Sort); err != nil {
+			return Filter{}, err
+		}
+	}
+	return f, nil
+}
+
+// ParseDone interpreta el filtro de estado: true/done para las hechas,
+// false/pending para las pendientes y vacío para todas.
+func ParseDone(s string) (null.Bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return null.Bool{}, nil
+	case "true", "1", "done":
+		return null.BoolFrom(true), nil
+	case "false", "0", "pending":
+		return null.BoolFrom(false), nil
+	}
+	return null.Bool{}, fmt.Errorf("estado inválido %q: usa true o false", s)
+}
+
+// Where traduce las condiciones del filtro, sin orden ni página, a sqlboiler.
+func (f Filter) Where() []qm.QueryMod {
+	var mods []qm.QueryMod
+	if f.UserID != 0 {
+		mods = append(mods, models.TaskWhere.UserID.EQ(null.Int64From(f.UserID)))
+	}
+	if f.Done.Valid {
+		mods = append(mods, models.TaskWhere.Done.EQ(f.Done))
+	}
+	if f.Text != "" {
+		// ParseFilter ya ha comprobado que la búsqueda no está vacía
+		match, _ := search.Query(f.Text)
+		mods = append(mods, qm.Where("tasks.id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH ?)", match))
+	}
+	mods = append(mods, f.Tags.Mods()...)
+	mods = append(mods, f.Project.Mods()...)
+	if f.DueBefore.Valid {
+		mods = append(mods, models.TaskWhere.DueAt.LT(null.TimeFrom(f.DueBefore.Time.UTC())))
+	}
+	if f.TopLevel {
+		mods = append(mods, TopLevel())
+	}
+	return append(mods, f.Extra...)
+}
+
+// Mods traduce el filtro completo a sqlboiler: condiciones, orden, cursor y
+// límite.
+func (f Filter) Mods() []qm.QueryMod {
+	mods := f.Where()
+	if f.After != nil {
+		mods = append(mods, f.Sort.after(f.After))
+	}
+	mods = append(mods, f.Sort.OrderBy())
+	if f.Limit > 0 {
+		mods = append(mods, qm.Limit(f.Limit))
+	}
+	return mods
+}
+
+// Page es una página de un listado de tareas.
+type Page struct {
+	Tasks models.TaskSlice
+	// Total es el número de tareas que cumplen el filtro, en todas las
+	// páginas.
+	Total int64
+	// Next es el cursor de la página siguiente, o "" si es la última.
+	Next string
+}
+
+// Page busca la página de tareas de f; load son las relaciones que cargar.
+func (f Filter) Page(ctx context.Context, exec boil.ContextExecutor, load ...qm.QueryMod) (Page, error) {
+	total, err := models.Tasks(f.Where()...).Count(ctx, exec)
+	if err != nil {
+		return Page{}, err
+	}
+	// Se pide una de más para saber si hay página siguiente
+	query := f
+	if query.Limit > 0 {
+		query.Limit++
+	}
+	tasks, err := models.Tasks(append(query.Mods(), load...)...).All(ctx, exec)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{Tasks: tasks, Total: total}
+	if f.Limit > 0 && len(tasks) > f.Limit {
+		page.Tasks = tasks[:f.Limit]
+		page.Next = f.Sort.Cursor(page.Tasks[f.Limit-1]).String()
+	}
+	return page, nil
+}
+
+// SortFields son los campos por los que se puede ordenar un listado.
+var SortFields = []string{"priority", "id", "title", "status", "due"}
+
+// Sort es el orden de un listado. Field vacío es "priority", el orden por
+// defecto: primero las de mayor prioridad, después las que vencen antes (las
+// que no vencen al final) y por último por ID. Desc invierte el orden.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort interpreta los parámetros sort (uno de SortFields) y order
+// (asc o desc).
+func ParseSort(field, order string) (Sort, error) {
+	var s Sort
+	field = strings.ToLower(strings.TrimSpace(field))
+	if _, ok := sortKeys[field]; !ok && field != "" {
+		return Sort{}, fmt.Errorf("orden inválido %q: usa %s", field, strings.Join(SortFields, ", "))
+	}
+	if field != "priority" {
+		s.Field = field
+	}
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "", "asc":
+	case "desc":
+		s.Desc = true
+	default:
+		return Sort{}, fmt.Errorf("sentido del orden inválido %q: usa asc o desc", order)
+	}
+	return s, nil
+}
+
+// sortKey es una de las expresiones por las que se ordena. value devuelve
+// el valor de la expresión para la tarea de un cursor.
+type sortKey struct {
+	expr  string
+	desc  bool
+	value func(c *Cursor) interface{}
+}
+
+var (
+	idKey       = sortKey{"tasks.id", false, func(c *Cursor) interface{} { return c.ID }}
+	dueNullKey  = sortKey{"tasks.due_at IS NULL", false, func(c *Cursor) interface{} { return c.DueAt == nil }}
+	dueKey      = sortKey{"tasks.due_at", false, func(c *Cursor) interface{} { return c.dueAt() }}
+	priorityKey = sortKey{"tasks.priority", true, func(c *Cursor) interface{} { return c.Priority }}
+	titleKey    = sortKey{"tasks.title", false, func(c *Cursor) interface{} { return c.Title }}
+	statusKey   = sortKey{"tasks.done", false, func(c *Cursor) interface{} { return c.Done }}
+)
+
+// sortKeys son las claves de cada campo de SortFields. Todas acaban en el ID
+// para que el orden sea total y los cursores no salten ni repitan tareas.
+var sortKeys = map[string][]sortKey{
+	"":         {priorityKey, dueNullKey, dueKey, idKey},
+	"priority": {priorityKey, dueNullKey, dueKey, idKey},
+	"id":       {idKey},
+	"title":    {titleKey, idKey},
+	"status":   {statusKey, idKey},
+	"due":      {dueNullKey, dueKey, idKey},
+}
+
+// OrderBy traduce el orden a sqlboiler.
+func (s Sort) OrderBy() qm.QueryMod {
+	keys := sortKeys[s.Field]
+	clauses := make([]string, 0, len(keys))
+	for _, k := range keys {
+		dir := " ASC"
+		if k.desc != s.Desc {
+			dir = " DESC"
+		}
+		clauses = append(clauses, k.expr+dir)
+	}
+	return qm.OrderBy(strings.Join(clauses, ", "))
+}
+
+// after es la condición de las tareas que van detrás de la del cursor. Las
+// expresiones van entre paréntesis porque IS tiene menos precedencia que < y >
+// en SQLite.
+func (s Sort) after(c *Cursor) qm.QueryMod {
+	keys := sortKeys[s.Field]
+	var or []string
+	var args []interface{}
+	for i, k := range keys {
+		var and []string
+		for _, prev := range keys[:i] {
+			and = append(and, "("+prev.expr+") IS ?")
+			args = append(args, prev.value(c))
+		}
+		op := " > ?"
+		if k.desc != s.Desc {
+			op = " < ?"
+		}
+		and = append(and, "("+k.expr+")"+op)
+		args = append(args, k.value(c))
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return qm.Where("("+strings.Join(or, " OR ")+")", args...)
+}
+
+// Cursor guarda los campos de orden de la última tarea de una página, para
+// seguir el listado por detrás de ella aunque se creen o borren tareas.
+type Cursor struct {
+	Sort     string     `json:"s,omitempty"`
+	Desc     bool       `json:"d,omitempty"`
+	ID       int64      `json:"id"`
+	Priority int64      `json:"p,omitempty"`
+	Title    string     `json:"t,omitempty"`
+	Done     bool       `json:"x,omitempty"`
+	DueAt    *time.Time `json:"due,omitempty"`
+}
+
+// Cursor devuelve el cursor que sigue el listado detrás de t.
+func (s Sort) Cursor(t *models.Task) *Cursor {
+	c := &Cursor{Sort: s.Field, Desc: s.Desc, ID: t.ID.Int64, Priority: t.Priority, Title: t.Title, Done: t.Done.Bool}
+	if t.DueAt.Valid {
+		due := t.DueAt.Time.UTC()
+		c.DueAt = &due
+	}
+	return c
+}
+
+// String codifica el cursor como lo devuelve la API en next_cursor.
+func (c *Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodifica un cursor de String y comprueba que sea del orden s.
+func ParseCursor(value string, s Sort) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != s.Field || c.Desc != s.Desc {
+		return nil, fmt.Errorf("%w: es de otro orden", ErrInvalidCursor)
+	}
+	return &c, nil
+}
+
+func (c *Cursor) dueAt() interface{} {
+	if c.DueAt == nil {
+		return nil
+	}
+	return c.DueAt.UTC()
+}
@@ -0,0 +1,145 @@
+// Package taskquery reúne las piezas de consulta de tareas que comparten la
+// web, la API y la CLI.
+package taskquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// DefaultOrder aplica el orden por defecto de las tareas (ver Sort) a una
+// consulta de sqlboiler.
+func DefaultOrder() qm.QueryMod {
+	return Sort{}.OrderBy()
+}
+
+// HasTagClause es la condición SQL que cumple una tarea con la etiqueta cuyo
+// nombre se pasa como argumento.
+const HasTagClause = "EXISTS (SELECT 1 FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id = tasks.id AND g.name = ?)"
+
+// WithTags carga las etiquetas de las tareas ordenadas por nombre.
+func WithTags() qm.QueryMod {
+	return qm.Load(models.TaskRels.Tags, qm.OrderBy(models.TagColumns.Name))
+}
+
+// TagFilter selecciona las tareas que tienen todas las etiquetas de Include y
+// ninguna de Exclude.
+type TagFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// ParseTagFilter interpreta valores como los de ?tag=work&tag=-home: un
+// nombre exige la etiqueta y un nombre precedido de "-" la excluye.
+func ParseTagFilter(values []string) (TagFilter, error) {
+	var f TagFilter
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		exclude := strings.HasPrefix(v, "-")
+		name, err := tag.Normalize(strings.TrimPrefix(v, "-"))
+		if err != nil {
+			return TagFilter{}, err
+		}
+		if exclude {
+			f.Exclude = append(f.Exclude, name)
+		} else {
+			f.Include = append(f.Include, name)
+		}
+	}
+	return f, nil
+}
+
+// Empty indica si el filtro no restringe nada.
+func (f TagFilter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Values devuelve el filtro con la misma sintaxis que acepta ParseTagFilter.
+func (f TagFilter) Values() []string {
+	values := make([]string, 0, len(f.Include)+len(f.Exclude))
+	values = append(values, f.Include...)
+	for _, name := range f.Exclude {
+		values = append(values, "-"+name)
+	}
+	return values
+}
+
+// Mods traduce el filtro a condiciones de sqlboiler.
+func (f TagFilter) Mods() []qm.QueryMod {
+	mods := make([]qm.QueryMod, 0, len(f.Include)+len(f.Exclude))
+	for _, name := range f.Include {
+		mods = append(mods, qm.Where(HasTagClause, name))
+	}
+	for _, name := range f.Exclude {
+		mods = append(mods, qm.Where("NOT "+HasTagClause, name))
+	}
+	return mods
+}
+
+// ProjectFilter selecciona las tareas de un proyecto. Si Active es false no
+// filtra; un ID 0 selecciona las tareas sin proyecto.
+type ProjectFilter struct {
+	Active bool
+	ID     int64
+}
+
+// ParseProjectFilter interpreta valores como los de ?project=3 o
+// ?project=none. Una cadena vacía no filtra.
+func ParseProjectFilter(s string) (ProjectFilter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ProjectFilter{}, nil
+	}
+	if s == project.None {
+		return ProjectFilter{Active: true}, nil
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return ProjectFilter{}, fmt.Errorf("proyecto inválido %q: usa su ID o %q", s, project.None)
+	}
+	return ProjectFilter{Active: true, ID: id}, nil
+}
+
+// Value devuelve el filtro con la misma sintaxis que acepta
+// ParseProjectFilter.
+func (f ProjectFilter) Value() string {
+	switch {
+	case !f.Active:
+		return ""
+	case f.ID == 0:
+		return project.None
+	}
+	return strconv.FormatInt(f.ID, 10)
+}
+
+// Mods traduce el filtro a condiciones de sqlboiler.
+func (f ProjectFilter) Mods() []qm.QueryMod {
+	switch {
+	case !f.Active:
+		return nil
+	case f.ID == 0:
+		return []qm.QueryMod{models.TaskWhere.ProjectID.IsNull()}
+	}
+	return []qm.QueryMod{models.TaskWhere.ProjectID.EQ(null.Int64From(f.ID))}
+}
+
+// TopLevel deja fuera de la consulta las subtareas, que se muestran dentro de
+// su tarea.
+func TopLevel() qm.QueryMod {
+	return models.TaskWhere.ParentID.IsNull()
+}
+
+// WithSubtasks carga las subtareas de las tareas por orden de creación.
+func WithSubtasks() qm.QueryMod {
+	return qm.Load(models.TaskRels.ParentTasks, qm.OrderBy(models.TaskColumns.ID))
+}
@@ -0,0 +1,29 @@
+// Package tasktitle define las reglas del título de una tarea, las mismas en
+// la CLI, la API y la web.
+package tasktitle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength es la longitud máxima de un título, en caracteres.
+const MaxLength = 200
+
+// ErrEmpty es el error de un título vacío o solo con espacios.
+var ErrEmpty = errors.New("el título no puede estar vacío")
+
+// Normalize quita los espacios de alrededor del título y comprueba que no
+// esté vacío ni pase de MaxLength caracteres.
+func Normalize(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", ErrEmpty
+	}
+	if utf8.RuneCountInString(title) > MaxLength {
+		return "", fmt.Errorf("el título no puede superar los %d caracteres", MaxLength)
+	}
+	return title, nil
+}
@@ -0,0 +1,230 @@
+// Package twofactor implementa la verificación en dos pasos con códigos TOTP
+// (RFC 6238) y los códigos de recuperación de un solo uso. El alta tiene dos
+// pasos: Enroll genera el secreto y Confirm lo activa cuando el usuario
+// demuestra que su aplicación genera bien los códigos.
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Issuer es el nombre con el que aparece la cuenta en la aplicación de
+// autenticación.
+const Issuer = "todo-list"
+
+// RecoveryCodeCount es el número de códigos de recuperación que se generan al
+// activar la verificación en dos pasos.
+const RecoveryCodeCount = 10
+
+// Period es la duración en segundos de cada código TOTP.
+const Period = 30
+
+// skew es el número de pasos anteriores y posteriores al actual que se
+// aceptan, para tolerar relojes algo desajustados.
+const skew = 1
+
+var (
+	// ErrInvalidCode indica un código incorrecto, caducado o ya usado.
+	ErrInvalidCode = errors.New("código incorrecto")
+	// ErrNotEnabled indica un usuario sin la verificación en dos pasos activada.
+	ErrNotEnabled = errors.New("la verificación en dos pasos no está activada")
+	// ErrAlreadyEnabled indica un usuario que ya la tiene activada.
+	ErrAlreadyEnabled = errors.New("la verificación en dos pasos ya está activada")
+	// ErrNotEnrolling indica que el usuario no ha empezado el alta.
+	ErrNotEnrolling = errors.New("no hay ningún alta de verificación en dos pasos pendiente")
+)
+
+// Enabled indica si el usuario tiene activada la verificación en dos pasos.
+func Enabled(user *models.User) bool {
+	return user.TotpEnabledAt.Valid
+}
+
+// Enroll empieza el alta: genera un secreto nuevo y lo guarda pendiente de
+// Confirm. Si había un alta a medias, la sustituye.
+func Enroll(ctx context.Context, exec boil.ContextExecutor, user *models.User) (*otp.Key, error) {
+	if Enabled(user) {
+		return nil, ErrAlreadyEnabled
+	}
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: Issuer, AccountName: user.Username})
+	if err != nil {
+		return nil, err
+	}
+	user.TotpSecret = null.StringFrom(key.Secret())
+	user.TotpLastStep = null.Int64{}
+	if _, err := user.Update(ctx, exec, boil.Whitelist(models.UserColumns.TotpSecret, models.UserColumns.TotpLastStep)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PendingKey devuelve la clave del alta pendiente del usuario, con la que se
+// muestran la URI otpauth:// y el código QR.
+func PendingKey(user *models.User) (*otp.Key, error) {
+	if Enabled(user) {
+		return nil, ErrAlreadyEnabled
+	}
+	if !user.TotpSecret.Valid {
+		return nil, ErrNotEnrolling
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(user.TotpSecret.String)
+	if err != nil {
+		return nil, err
+	}
+	return totp.Generate(totp.GenerateOpts{Issuer: Issuer, AccountName: user.Username, Secret: secret})
+}
+
+// Confirm termina el alta si code es un código válido del secreto pendiente.
+// Devuelve los códigos de recuperación, que no se pueden volver a obtener.
+func Confirm(ctx context.Context, exec boil.ContextExecutor, user *models.User, code string, now time.Time) ([]string, error) {
+	if Enabled(user) {
+		return nil, ErrAlreadyEnabled
+	}
+	if !user.TotpSecret.Valid {
+		return nil, ErrNotEnrolling
+	}
+	step, ok := matchTOTP(user, code, now)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+	user.TotpEnabledAt = null.TimeFrom(now.UTC().Truncate(time.Second))
+	user.TotpLastStep = null.Int64From(step)
+	if _, err := user.Update(ctx, exec, boil.Whitelist(models.UserColumns.TotpEnabledAt, models.UserColumns.TotpLastStep)); err != nil {
+		return nil, err
+	}
+	return RegenerateRecoveryCodes(ctx, exec, user.ID.Int64)
+}
+
+// Verify comprueba el segundo paso del login: un código TOTP que no se haya
+// usado ya o un código de recuperación, que se borra al usarlo.
+func Verify(ctx context.Context, exec boil.ContextExecutor, user *models.User, code string, now time.Time) error {
+	if !Enabled(user) {
+		return ErrNotEnabled
+	}
+	if step, ok := matchTOTP(user, code, now); ok {
+		return claimStep(ctx, exec, user, step)
+	}
+	n, err := models.UserRecoveryCodes(
+		models.UserRecoveryCodeWhere.UserID.EQ(user.ID.Int64),
+		models.UserRecoveryCodeWhere.CodeHash.EQ(hash(normalizeRecoveryCode(code))),
+	).DeleteAll(ctx, exec)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// claimStep marca el paso como usado solo si es posterior al último, en la
+// misma sentencia, para que dos logins a la vez no acepten el mismo código.
+func claimStep(ctx context.Context, exec boil.ContextExecutor, user *models.User, step int64) error {
+	n, err := models.Users(
+		models.UserWhere.ID.EQ(user.ID),
+		qm.Where("("+models.UserColumns.TotpLastStep+" IS NULL OR "+models.UserColumns.TotpLastStep+" < ?)", step),
+	).UpdateAll(ctx, exec, models.M{models.UserColumns.TotpLastStep: step})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidCode
+	}
+	user.TotpLastStep = null.Int64From(step)
+	return nil
+}
+
+// Disable desactiva la verificación en dos pasos del usuario y borra sus
+// códigos de recuperación. También cancela un alta a medias.
+func Disable(ctx context.Context, exec boil.ContextExecutor, user *models.User) error {
+	user.TotpSecret = null.String{}
+	user.TotpEnabledAt = null.Time{}
+	user.TotpLastStep = null.Int64{}
+	if _, err := user.Update(ctx, exec, boil.Whitelist(models.UserColumns.TotpSecret, models.UserColumns.TotpEnabledAt, models.UserColumns.TotpLastStep)); err != nil {
+		return err
+	}
+	_, err := models.UserRecoveryCodes(models.UserRecoveryCodeWhere.UserID.EQ(user.ID.Int64)).DeleteAll(ctx, exec)
+	return err
+}
+
+// RegenerateRecoveryCodes sustituye los códigos de recuperación del usuario
+// por RecoveryCodeCount nuevos y los devuelve.
+func RegenerateRecoveryCodes(ctx context.Context, exec boil.ContextExecutor, userID int64) ([]string, error) {
+	if _, err := models.UserRecoveryCodes(models.UserRecoveryCodeWhere.UserID.EQ(userID)).DeleteAll(ctx, exec); err != nil {
+		return nil, err
+	}
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		random := make([]byte, 5)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		// 5 bytes son 8 caracteres base32, que se muestran como xxxx-xxxx
+		s := strings.ToLower(base32.StdEncoding.EncodeToString(random))
+		codes[i] = s[:4] + "-" + s[4:]
+		rc := &models.UserRecoveryCode{UserID: userID, CodeHash: hash(normalizeRecoveryCode(codes[i]))}
+		if err := rc.Insert(ctx, exec, boil.Infer()); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// RemainingRecoveryCodes devuelve cuántos códigos de recuperación le quedan
+// al usuario.
+func RemainingRecoveryCodes(ctx context.Context, exec boil.ContextExecutor, userID int64) (int64, error) {
+	return models.UserRecoveryCodes(models.UserRecoveryCodeWhere.UserID.EQ(userID)).Count(ctx, exec)
+}
+
+// matchTOTP busca el paso de code entre los que se aceptan en now, sin
+// contar los anteriores al último usado.
+func matchTOTP(user *models.User, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != 6 {
+		return 0, false
+	}
+	current := now.Unix() / Period
+	for step := current - skew; step <= current+skew; step++ {
+		if user.TotpLastStep.Valid && step <= user.TotpLastStep.Int64 {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(user.TotpSecret.String, time.Unix(step*Period, 0), totp.ValidateOpts{
+			Period:    Period,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeRecoveryCode quita los guiones y espacios de un código de
+// recuperación y lo pasa a minúsculas.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
+
+// hash es lo que se guarda de un código de recuperación.
+func hash(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
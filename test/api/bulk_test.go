@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// bulkResponse es la respuesta de POST /api/tasks/bulk.
+type bulkResponse struct {
+	Error *struct {
+		Code string `json:"code"`
+	} `json:"error"`
+	Results []struct {
+		ID     int64 `json:"id"`
+		Status int   `json:"status"`
+		Task   *struct {
+			Title     string `json:"title"`
+			Done      bool   `json:"done"`
+			Priority  int64  `json:"priority"`
+			ProjectID *int64 `json:"project_id"`
+		} `json:"task"`
+		Error *struct {
+			Code string `json:"code"`
+		} `json:"error"`
+		Errors map[string]string `json:"errors"`
+	} `json:"results"`
+}
+
+func TestApiBulkTasks(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	w := httptest.NewRecorder()
+	h.ApiAddProject(w, jsonRequest("POST", "/api/projects", `{"name":"Oficina"}`, cookie))
+	for _, title := range []string{"Uno", "Dos", "Tres", "Cuatro"} {
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{"title":"`+title+`"}`, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+	}
+	w = httptest.NewRecorder()
+	h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{"title":"Ajena"}`, other))
+
+	bulk := func(t *testing.T, body string, status int) bulkResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ApiBulkTasks(w, jsonRequest("POST", "/api/tasks/bulk", body, cookie))
+		if w.Result().StatusCode != status {
+			t.Fatalf("expected status %d, got %d: %s", status, w.Result().StatusCode, w.Body.String())
+		}
+		var response bulkResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	// title es el título de la tarea id, o "" si no existe
+	title := func(id string) string {
+		var title string
+		h.Db.QueryRow("SELECT COALESCE((SELECT title FROM tasks WHERE id = ?), '')", id).Scan(&title)
+		return title
+	}
+
+	t.Run("Operations", func(t *testing.T) {
+		got := bulk(t, `{"operations":[
+			{"op":"complete","id":1,"if_match":"*"},
+			{"op":"retitle","id":2,"if_match":"*","title":"Dos bis"},
+			{"op":"set","id":3,"if_match":"*","fields":{"priority":"high","project_id":1}},
+			{"op":"delete","id":4,"if_match":"*"}
+		]}`, http.StatusOK)
+		if len(got.Results) != 4 {
+			t.Fatalf("expected 4 results, got %+v", got.Results)
+		}
+		for i, r := range got.Results {
+			if r.Status != http.StatusOK || r.Error != nil {
+				t.Errorf("operation %d failed: %+v", i, r)
+			}
+		}
+		if task := got.Results[0].Task; task == nil || !task.Done {
+			t.Errorf("expected task 1 done, got %+v", task)
+		}
+		if task := got.Results[1].Task; task == nil || task.Title != "Dos bis" {
+			t.Errorf("expected task 2 retitled, got %+v", task)
+		}
+		if task := got.Results[2].Task; task == nil || task.Priority != 3 || task.ProjectID == nil || *task.ProjectID != 1 {
+			t.Errorf("expected task 3 moved, got %+v", task)
+		}
+		if got.Results[3].Task != nil || title("4") != "" {
+			t.Errorf("expected task 4 deleted, got %+v", got.Results[3])
+		}
+
+		got = bulk(t, `{"operations":[{"op":"reopen","id":1,"if_match":"*"}]}`, http.StatusOK)
+		if task := got.Results[0].Task; task == nil || task.Done {
+			t.Errorf("expected task 1 reopened, got %+v", task)
+		}
+	})
+
+	t.Run("Partial Failure", func(t *testing.T) {
+		got := bulk(t, `{"operations":[
+			{"op":"retitle","id":1,"if_match":"*","title":"Uno bis"},
+			{"op":"retitle","id":5,"if_match":"*","title":"Mía"},
+			{"op":"set","id":2,"if_match":"*","fields":{"priority":"máxima"}},
+			{"op":"complete","id":3,"if_match":"\"999\""}
+		]}`, http.StatusOK)
+		want := []int{http.StatusOK, http.StatusForbidden, http.StatusBadRequest, http.StatusPreconditionFailed}
+		for i, r := range got.Results {
+			if r.Status != want[i] {
+				t.Errorf("operation %d: expected status %d, got %+v", i, want[i], r)
+			}
+		}
+		if got.Results[2].Errors["priority"] == "" {
+			t.Errorf("expected a priority error, got %+v", got.Results[2])
+		}
+		if title("1") != "Uno bis" || title("5") != "Ajena" {
+			t.Errorf("unexpected titles %q and %q", title("1"), title("5"))
+		}
+	})
+
+	t.Run("Atomic", func(t *testing.T) {
+		got := bulk(t, `{"atomic":true,"operations":[
+			{"op":"retitle","id":1,"if_match":"*","title":"Nunca"},
+			{"op":"delete","id":2,"if_match":"*"},
+			{"op":"complete","id":99,"if_match":"*"}
+		]}`, http.StatusConflict)
+		if got.Error == nil || got.Error.Code != "bulk_failed" {
+			t.Errorf("unexpected error %+v", got.Error)
+		}
+		want := []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusForbidden}
+		for i, r := range got.Results {
+			if r.Status != want[i] {
+				t.Errorf("operation %d: expected status %d, got %+v", i, want[i], r)
+			}
+		}
+		if got.Results[0].Error == nil || got.Results[0].Error.Code != "rolled_back" {
+			t.Errorf("unexpected result %+v", got.Results[0])
+		}
+		if title("1") != "Uno bis" || title("2") != "Dos bis" {
+			t.Errorf("expected nothing applied, got %q and %q", title("1"), title("2"))
+		}
+
+		bulk(t, `{"atomic":true,"operations":[{"op":"retitle","id":1,"if_match":"*","title":"Uno final"},{"op":"delete","id":2,"if_match":"*"}]}`, http.StatusOK)
+		if title("1") != "Uno final" || title("2") != "" {
+			t.Errorf("expected every operation applied, got %q and %q", title("1"), title("2"))
+		}
+	})
+
+	t.Run("Missing If-Match", func(t *testing.T) {
+		// Como en PUT, PATCH y DELETE, sin ETag no se cambia la tarea
+		got := bulk(t, `{"atomic":true,"operations":[
+			{"op":"retitle","id":1,"if_match":"*","title":"Nunca"},
+			{"op":"delete","id":3}
+		]}`, http.StatusConflict)
+		want := []int{http.StatusFailedDependency, http.StatusPreconditionRequired}
+		for i, r := range got.Results {
+			if r.Status != want[i] {
+				t.Errorf("operation %d: expected status %d, got %+v", i, want[i], r)
+			}
+		}
+		if r := got.Results[1]; r.Error == nil || r.Error.Code != "precondition_required" {
+			t.Errorf("unexpected result %+v", r)
+		}
+		if title("1") != "Uno final" || title("3") != "Tres" {
+			t.Errorf("expected nothing applied, got %q and %q", title("1"), title("3"))
+		}
+	})
+
+	t.Run("Invalid Request", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			body  string
+			field string
+		}{
+			{"No Operations", `{"operations":[]}`, "operations"},
+			{"Not An Array", `{"operations":{}}`, "operations"},
+			{"Not An Object", `{"operations":[1]}`, "operations[0]"},
+			{"Unknown Op", `{"operations":[{"op":"archive","id":1}]}`, "operations[0].op"},
+			{"Missing ID", `{"operations":[{"op":"complete"}]}`, "operations[0].id"},
+			{"Missing Title", `{"operations":[{"op":"complete","id":1},{"op":"retitle","id":1}]}`, "operations[1].title"},
+			{"Missing Fields", `{"operations":[{"op":"set","id":1}]}`, "operations[0].fields"},
+			{"Unknown Field", `{"operations":[{"op":"set","id":1,"fields":{"color":"rojo"}}]}`, "operations[0].fields.color"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				h.ApiBulkTasks(w, jsonRequest("POST", "/api/tasks/bulk", tt.body, cookie))
+				if got := fieldErrors(t, w); got[tt.field] == "" {
+					t.Errorf("expected an error in %s, got %v", tt.field, got)
+				}
+			})
+		}
+		if title("1") != "Uno final" {
+			t.Errorf("invalid requests must not change anything, got %q", title("1"))
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// decodeTask lee la tarea de una respuesta {"task":...}.
+func decodeTask(t *testing.T, w *httptest.ResponseRecorder) (task struct {
+	ETag     string `json:"etag"`
+	Title    string `json:"title"`
+	Progress *struct {
+		Done  int `json:"done"`
+		Total int `json:"total"`
+	} `json:"progress"`
+}) {
+	t.Helper()
+	var response struct {
+		Task json.RawMessage `json:"task"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(response.Task, &task); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestApiConditionalGet(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+	vars := map[string]string{"id": "1"}
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{"title":"Informe","tags":["trabajo"]}`, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+	}
+
+	get := func(cookie *http.Cookie, header, value string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := mux.SetURLVars(formRequest("GET", "/api/tasks/1", nil, cookie), vars)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		h.ApiGetTask(w, req)
+		return w
+	}
+	list := func(header, value string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := formRequest("GET", "/api/tasks", nil, cookie)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		h.ApiListTasks(w, req)
+		return w
+	}
+
+	t.Run("Get Task", func(t *testing.T) {
+		w := get(cookie, "", "")
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		etag := w.Result().Header.Get("ETag")
+		modified := w.Result().Header.Get("Last-Modified")
+		if etag == "" || modified == "" {
+			t.Fatalf("expected ETag and Last-Modified, got %q and %q", etag, modified)
+		}
+		if task := decodeTask(t, w); task.Title != "Informe" || task.ETag != etag {
+			t.Errorf("unexpected task %+v", task)
+		}
+
+		if w := get(other, "", ""); w.Result().StatusCode != http.StatusForbidden {
+			t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Get Task Not Modified", func(t *testing.T) {
+		etag := get(cookie, "", "").Result().Header.Get("ETag")
+		modified, _ := http.ParseTime(get(cookie, "", "").Result().Header.Get("Last-Modified"))
+
+		tests := []struct {
+			name   string
+			header string
+			value  string
+			status int
+		}{
+			{"Same ETag", "If-None-Match", etag, http.StatusNotModified},
+			{"Weak ETag", "If-None-Match", "W/" + etag, http.StatusNotModified},
+			{"ETag List", "If-None-Match", `"0", ` + etag, http.StatusNotModified},
+			{"Other ETag", "If-None-Match", `"0"`, http.StatusOK},
+			{"Not Modified Since", "If-Modified-Since", modified.Format(http.TimeFormat), http.StatusNotModified},
+			{"Modified Since", "If-Modified-Since", modified.Add(-time.Second).Format(http.TimeFormat), http.StatusOK},
+			{"Invalid Date", "If-Modified-Since", "ayer", http.StatusOK},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := get(cookie, tt.header, tt.value)
+				if w.Result().StatusCode != tt.status {
+					t.Errorf("expected status %d, got %d", tt.status, w.Result().StatusCode)
+				}
+				if tt.status == http.StatusNotModified && w.Body.Len() != 0 {
+					t.Errorf("expected empty body, got %q", w.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("Subtasks And Tags Change ETag", func(t *testing.T) {
+		etag := get(cookie, "", "").Result().Header.Get("ETag")
+		w := httptest.NewRecorder()
+		h.ApiAddSubtask(w, mux.SetURLVars(formRequest("POST", "/api/tasks/1/subtasks", url.Values{"title": {"Borrador"}}, cookie), vars))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+		w = get(cookie, "If-None-Match", etag)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d after adding a subtask, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+		if task := decodeTask(t, w); task.Progress == nil || task.Progress.Total != 1 {
+			t.Errorf("expected progress in %+v", task)
+		}
+
+		etag = w.Result().Header.Get("ETag")
+		w = httptest.NewRecorder()
+		h.ApiUpdateTag(w, mux.SetURLVars(formRequest("PUT", "/api/tags/1", url.Values{"name": {"oficina"}}, cookie), vars))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		if w := get(cookie, "If-None-Match", etag); w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d after renaming a tag, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("List Not Modified", func(t *testing.T) {
+		w := list("", "")
+		etag := w.Result().Header.Get("ETag")
+		modified, err := http.ParseTime(w.Result().Header.Get("Last-Modified"))
+		if etag == "" || err != nil {
+			t.Fatalf("expected ETag and Last-Modified, got %q and %v", etag, err)
+		}
+		if w := list("If-None-Match", etag); w.Result().StatusCode != http.StatusNotModified {
+			t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Result().StatusCode)
+		}
+		if w := list("If-Modified-Since", modified.Format(http.TimeFormat)); w.Result().StatusCode != http.StatusNotModified {
+			t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Result().StatusCode)
+		}
+		if w := list("If-Modified-Since", modified.Add(-time.Second).Format(http.TimeFormat)); w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+
+		// Borrar una tarea cambia el listado
+		w = httptest.NewRecorder()
+		h.ApiDeleteTask(w, withIfMatch(mux.SetURLVars(formRequest("DELETE", "/api/tasks/1", nil, cookie), vars), "*"))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		w = list("If-None-Match", etag)
+		if w.Result().StatusCode != http.StatusOK || w.Result().Header.Get("ETag") == etag {
+			t.Errorf("expected a new list, got status %d and ETag %q", w.Result().StatusCode, w.Result().Header.Get("ETag"))
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestApiRegisterValidation(t *testing.T) {
+	h := getTestHandler(t)
+	policy := credentials.DefaultPolicy
+	sum := sha1.Sum([]byte("password123"))
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.ToUpper(hex.EncodeToString(sum[:]))+":100\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := credentials.OpenBreachedList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy.Breached = list
+	h.Credentials = &policy
+
+	register := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ApiRegisterHandler(w, jsonRequest("POST", "/api/register", body, nil))
+		return w
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+		want  string
+	}{
+		{"empty username", `{"username":"  ","password":"correcto caballo"}`, "username", "obligatorio"},
+		{"empty password", `{"username":"ana","password":""}`, "password", "obligatorio"},
+		{"short username", `{"username":"an","password":"correcto caballo"}`, "username", "el nombre de usuario tiene que tener al menos 3 caracteres"},
+		{"invalid username", `{"username":"ana luisa","password":"correcto caballo"}`, "username", "el nombre de usuario solo puede tener letras, números, puntos, guiones y guiones bajos, y empezar por letra o número"},
+		{"short password", `{"username":"ana","password":"corta"}`, "password", "la contraseña tiene que tener al menos 8 caracteres"},
+		{"password equals username", `{"username":"anaLuisa","password":"analuisa"}`, "password", "la contraseña no puede ser igual al nombre de usuario"},
+		{"breached password", `{"username":"ana","password":"password123"}`, "password", "la contraseña aparece en filtraciones conocidas; elige otra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := fieldErrors(t, register(tt.body)); errs[tt.field] != tt.want {
+				t.Errorf("expected %s error %q, got %v", tt.field, tt.want, errs)
+			}
+		})
+	}
+
+	t.Run("NormalisedUsername", func(t *testing.T) {
+		w := register(`{"username":"  Ana  ","password":"correcto caballo"}`)
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"username":"Ana"`) {
+			t.Errorf("expected the username to be trimmed, got %s", w.Body.String())
+		}
+
+		w = register(`{"username":"ANA","password":"otro caballo"}`)
+		if w.Result().StatusCode != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"username_taken"`) {
+			t.Errorf("expected username_taken, got %d: %s", w.Result().StatusCode, w.Body.String())
+		}
+
+		// El login tampoco distingue mayúsculas
+		w = httptest.NewRecorder()
+		h.ApiLoginHandler(w, jsonRequest("POST", "/api/login", `{"username":"ana","password":"correcto caballo"}`, nil))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"username":"Ana"`) {
+			t.Errorf("expected the stored username, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestApiLoginRehash(t *testing.T) {
+	h := getTestHandler(t)
+	loginTestUser(t, h, 1, "testuser")
+
+	var hash string
+	if err := h.Db.QueryRow("SELECT password_hash FROM users WHERE id = 1").Scan(&hash); err != nil {
+		t.Fatal(err)
+	}
+	// loginTestUser guarda el hash con bcrypt.MinCost; el login lo rehace
+	if cost, _ := bcrypt.Cost([]byte(hash)); cost != bcrypt.DefaultCost {
+		t.Errorf("expected the hash to be rehashed with cost %d, got %d", bcrypt.DefaultCost, cost)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("testpass")); err != nil {
+		t.Errorf("the new hash does not match the password: %v", err)
+	}
+
+	// Bajar el coste no rehace nada
+	policy := credentials.DefaultPolicy
+	policy.BcryptCost = bcrypt.MinCost
+	h.Credentials = &policy
+	w := httptest.NewRecorder()
+	h.ApiLoginHandler(w, jsonRequest("POST", "/api/login", `{"username":"testuser","password":"testpass"}`, nil))
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+	}
+	var after string
+	if err := h.Db.QueryRow("SELECT password_hash FROM users WHERE id = 1").Scan(&after); err != nil {
+		t.Fatal(err)
+	}
+	if after != hash {
+		t.Error("expected the hash to be left alone")
+	}
+}
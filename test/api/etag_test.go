@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// withIfMatch añade a req la cabecera If-Match.
+func withIfMatch(req *http.Request, etag string) *http.Request {
+	req.Header.Set("If-Match", etag)
+	return req
+}
+
+func TestApiTaskETags(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	vars := map[string]string{"id": "1"}
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Informe"}, "tags": {"trabajo"}}, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+	}
+	etag := w.Result().Header.Get("ETag")
+	var created struct {
+		Task struct {
+			ETag string `json:"etag"`
+		} `json:"task"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if etag == "" || created.Task.ETag != etag {
+		t.Fatalf("expected the same ETag in header and body, got %q and %q", etag, created.Task.ETag)
+	}
+
+	// Los listados llevan el ETag de cada tarea
+	w = httptest.NewRecorder()
+	h.ApiListTasks(w, formRequest("GET", "/api/tasks", nil, cookie))
+	var list struct {
+		Tasks []struct {
+			ETag string `json:"etag"`
+		} `json:"tasks"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].ETag != etag {
+		t.Errorf("expected ETag %s in list, got %+v", etag, list.Tasks)
+	}
+
+	patch := func(etag, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := mux.SetURLVars(jsonRequest("PATCH", "/api/tasks/1", body, cookie), vars)
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+		h.ApiPatchTask(w, req)
+		return w
+	}
+
+	t.Run("Precondition Required", func(t *testing.T) {
+		w := patch("", `{"done":true}`)
+		if w.Result().StatusCode != http.StatusPreconditionRequired {
+			t.Errorf("expected status %d, got %d", http.StatusPreconditionRequired, w.Result().StatusCode)
+		}
+		if code := errorCode(t, w); code != "precondition_required" {
+			t.Errorf("unexpected code %q", code)
+		}
+	})
+
+	t.Run("Update Changes ETag", func(t *testing.T) {
+		w := patch(etag, `{"title":"Informe final"}`)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		next := w.Result().Header.Get("ETag")
+		if next == "" || next == etag {
+			t.Fatalf("expected a new ETag, got %q", next)
+		}
+
+		// Con el ETag viejo ya no se puede cambiar
+		w = patch(etag, `{"done":true}`)
+		if w.Result().StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("expected status %d, got %d", http.StatusPreconditionFailed, w.Result().StatusCode)
+		}
+		if code := errorCode(t, w); code != "precondition_failed" {
+			t.Errorf("unexpected code %q", code)
+		}
+		// Los ETag débiles no valen, pero sí una lista con el actual
+		if w := patch("W/"+next, `{"done":true}`); w.Result().StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("expected status %d for weak ETag, got %d", http.StatusPreconditionFailed, w.Result().StatusCode)
+		}
+		if w := patch(etag+", "+next, `{"done":true}`); w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+	})
+
+	t.Run("Tag Changes Change ETag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiListTasks(w, formRequest("GET", "/api/tasks", nil, cookie))
+		if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		etag = list.Tasks[0].ETag
+		if _, err := h.Db.Exec("DELETE FROM task_tags WHERE task_id = 1"); err != nil {
+			t.Fatal(err)
+		}
+		if w := patch(etag, `{"done":false}`); w.Result().StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("expected status %d, got %d", http.StatusPreconditionFailed, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Put And Delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiDeleteTask(w, mux.SetURLVars(formRequest("DELETE", "/api/tasks/1", nil, cookie), vars))
+		if w.Result().StatusCode != http.StatusPreconditionRequired {
+			t.Errorf("expected status %d, got %d", http.StatusPreconditionRequired, w.Result().StatusCode)
+		}
+		w = httptest.NewRecorder()
+		body := `{"title":"Informe","done":false,"due_at":null,"priority":0,"tags":[],"project_id":null,"description":"","recurrence":null}`
+		h.ApiUpdateTask(w, withIfMatch(mux.SetURLVars(jsonRequest("PUT", "/api/tasks/1", body, cookie), vars), `"999"`))
+		if w.Result().StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("expected status %d, got %d", http.StatusPreconditionFailed, w.Result().StatusCode)
+		}
+		w = httptest.NewRecorder()
+		h.ApiDeleteTask(w, withIfMatch(mux.SetURLVars(formRequest("DELETE", "/api/tasks/1", nil, cookie), vars), "*"))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestApiListTasksQuery(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	for _, form := range []url.Values{
+		{"title": {"Comprar leche"}},
+		{"title": {"Banco"}, "priority": {"high"}},
+		{"title": {"Alquiler"}, "description": {"No olvidar la leche"}},
+		{"title": {"Dentista"}},
+	} {
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, formRequest("POST", "/api/tasks", form, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Leche ajena"}}, other))
+	if _, err := h.Db.Exec("UPDATE tasks SET done = 1 WHERE id = 4"); err != nil {
+		t.Fatal(err)
+	}
+
+	type listResponse struct {
+		Tasks []struct {
+			ID    int64  `json:"id"`
+			Title string `json:"title"`
+		} `json:"tasks"`
+		NextCursor *string `json:"next_cursor"`
+	}
+	list := func(t *testing.T, query string) (listResponse, *httptest.ResponseRecorder) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ApiListTasks(w, formRequest("GET", "/api/tasks?"+query, nil, cookie))
+		var response listResponse
+		if w.Result().StatusCode == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return response, w
+	}
+	titles := func(response listResponse) []string {
+		var out []string
+		for _, task := range response.Tasks {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	t.Run("Filters", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  string
+		}{
+			{"", "[Banco Comprar leche Alquiler Dentista]"},
+			{"done=false", "[Banco Comprar leche Alquiler]"},
+			{"done=true", "[Dentista]"},
+			{"q=leche", "[Comprar leche Alquiler]"},
+			{"q=leche&done=true", "[]"},
+			{"sort=title", "[Alquiler Banco Comprar leche Dentista]"},
+			{"sort=id&order=desc", "[Dentista Alquiler Banco Comprar leche]"},
+			{"sort=status&order=desc", "[Dentista Alquiler Banco Comprar leche]"},
+		}
+		for _, tt := range tests {
+			response, w := list(t, tt.query)
+			if w.Result().StatusCode != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d", tt.query, http.StatusOK, w.Result().StatusCode)
+			}
+			if got := fmt.Sprint(titles(response)); got != tt.want {
+				t.Errorf("%s: expected %s, got %s", tt.query, tt.want, got)
+			}
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		var got []string
+		query := "sort=title&limit=3"
+		for pages := 1; ; pages++ {
+			response, w := list(t, query)
+			if w.Result().StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+			}
+			if total := w.Result().Header.Get("X-Total-Count"); total != "4" {
+				t.Errorf("expected X-Total-Count 4, got %q", total)
+			}
+			got = append(got, titles(response)...)
+			if response.NextCursor == nil {
+				if pages != 2 {
+					t.Errorf("expected 2 pages, got %d", pages)
+				}
+				break
+			}
+			query = "sort=title&limit=3&cursor=" + url.QueryEscape(*response.NextCursor)
+		}
+		if fmt.Sprint(got) != "[Alquiler Banco Comprar leche Dentista]" {
+			t.Errorf("unexpected tasks %v", got)
+		}
+	})
+
+	t.Run("Bad Requests", func(t *testing.T) {
+		response, _ := list(t, "sort=title&limit=1")
+		for _, query := range []string{
+			"done=quizá",
+			"sort=color",
+			"order=arriba",
+			"limit=0",
+			"limit=1000",
+			"cursor=basura",
+			// El cursor es de otro orden
+			"sort=id&cursor=" + url.QueryEscape(*response.NextCursor),
+		} {
+			_, w := list(t, query)
+			if w.Result().StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, w.Result().StatusCode)
+			}
+		}
+	})
+}
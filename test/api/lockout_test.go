@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/lockout"
+)
+
+func TestApiLoginLockout(t *testing.T) {
+	h := getTestHandler(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	h.Now = func() time.Time { return now }
+	h.Lockout = &lockout.Policy{MaxFailures: 3, MaxFailuresIP: 5, Lockout: time.Minute, MaxLockout: time.Hour}
+	loginTestUser(t, h, 1, "testuser")
+
+	login := func(body, ip string) *httptest.ResponseRecorder {
+		req := jsonRequest("POST", "/api/login", body, nil)
+		req.RemoteAddr = ip + ":4321"
+		w := httptest.NewRecorder()
+		h.ApiLoginHandler(w, req)
+		return w
+	}
+	errorCode := func(t *testing.T, w *httptest.ResponseRecorder, status int) string {
+		t.Helper()
+		if w.Result().StatusCode != status {
+			t.Fatalf("expected status %d, got %d: %s", status, w.Result().StatusCode, w.Body.String())
+		}
+		var response struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Error.Code
+	}
+	const wrong = `{"username":"testuser","password":"mal"}`
+	const right = `{"username":"testuser","password":"testpass"}`
+
+	t.Run("Username", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if code := errorCode(t, login(wrong, "192.0.2.1"), http.StatusUnauthorized); code != "invalid_credentials" {
+				t.Errorf("expected code invalid_credentials, got %q", code)
+			}
+		}
+		// El tercer fallo bloquea, aunque venga de otra IP
+		w := login(wrong, "192.0.2.2")
+		if code := errorCode(t, w, http.StatusTooManyRequests); code != "too_many_attempts" {
+			t.Errorf("expected code too_many_attempts, got %q", code)
+		}
+		if got := w.Header().Get("Retry-After"); got != "60" {
+			t.Errorf("expected Retry-After 60, got %q", got)
+		}
+
+		// Durante el bloqueo ni la contraseña correcta sirve
+		now = now.Add(30 * time.Second)
+		w = login(right, "192.0.2.3")
+		errorCode(t, w, http.StatusTooManyRequests)
+		if got := w.Header().Get("Retry-After"); got != "30" {
+			t.Errorf("expected Retry-After 30, got %q", got)
+		}
+
+		// Al terminar, otro fallo dobla el bloqueo
+		now = now.Add(30 * time.Second)
+		w = login(wrong, "192.0.2.3")
+		errorCode(t, w, http.StatusTooManyRequests)
+		if got := w.Header().Get("Retry-After"); got != "120" {
+			t.Errorf("expected Retry-After 120, got %q", got)
+		}
+
+		now = now.Add(2 * time.Minute)
+		if w := login(right, "192.0.2.4"); w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		// El login correcto pone a cero los fallos
+		errorCode(t, login(wrong, "192.0.2.4"), http.StatusUnauthorized)
+	})
+
+	t.Run("IP", func(t *testing.T) {
+		// Usuarios distintos, incluso inexistentes, desde la misma IP
+		for _, username := range []string{"a", "b", "c", "d"} {
+			errorCode(t, login(`{"username":"`+username+`","password":"x"}`, "198.51.100.7"), http.StatusUnauthorized)
+		}
+		errorCode(t, login(`{"username":"e","password":"x"}`, "198.51.100.7"), http.StatusTooManyRequests)
+		errorCode(t, login(right, "198.51.100.7"), http.StatusTooManyRequests)
+		if w := login(right, "198.51.100.8"); w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d from another IP, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+
+		// Detrás de un proxy de confianza cuenta la IP que añade el proxy al
+		// final de X-Forwarded-For, no las que manda el cliente
+		h.TrustProxy = true
+		defer func() { h.TrustProxy = false }()
+		viaProxy := func(fwd string) *httptest.ResponseRecorder {
+			req := jsonRequest("POST", "/api/login", right, nil)
+			req.RemoteAddr = "10.0.0.1:4321"
+			req.Header.Set("X-Forwarded-For", fwd)
+			w := httptest.NewRecorder()
+			h.ApiLoginHandler(w, req)
+			return w
+		}
+		errorCode(t, viaProxy("198.51.100.7"), http.StatusTooManyRequests)
+		errorCode(t, viaProxy("203.0.113.5, 198.51.100.7"), http.StatusTooManyRequests)
+		if w := viaProxy("198.51.100.7, 203.0.113.5"); w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+	})
+
+	t.Run("Audit", func(t *testing.T) {
+		failures, err := lockout.Failures(t.Context(), h.Db, "", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reasons := map[string]int{}
+		for _, f := range failures {
+			reasons[f.Reason]++
+		}
+		if reasons[lockout.ReasonPassword] != 5 || reasons[lockout.ReasonUnknownUser] != 5 || reasons[lockout.ReasonLocked] != 4 {
+			t.Errorf("unexpected audit trail %v", reasons)
+		}
+	})
+}
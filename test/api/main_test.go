@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -9,8 +10,10 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/JorgeePG/todo-list/internal/handlers"
+	"github.com/JorgeePG/todo-list/internal/migrations"
 	"github.com/gorilla/sessions"
 	"golang.org/x/crypto/bcrypt"
 	_ "modernc.org/sqlite"
@@ -23,22 +26,11 @@ func getTestDB(t *testing.T) *sql.DB {
 		t.Fatal(err)
 	}
 
-	// Crear tablas
-	_, err = db.Exec(`
-		CREATE TABLE users (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			username TEXT UNIQUE NOT NULL,
-			password_hash TEXT NOT NULL
-		);
-		CREATE TABLE tasks (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			title TEXT,
-			done BOOLEAN,
-			user_id INTEGER,
-			FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
-		);
-	`)
-	if err != nil {
+	// Con :memory: cada conexión es una base de datos distinta
+	db.SetMaxOpenConns(1)
+
+	// Crear tablas con las mismas migraciones que usa el servidor
+	if err := migrations.Apply(context.Background(), db); err != nil {
 		t.Fatal(err)
 	}
 
@@ -77,7 +69,7 @@ func TestApiRegisterHandler(t *testing.T) {
 			username:   "testuser",
 			password:   "testpass",
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `"error":"Usuario ya existe"`,
+			wantBody:   `"error":{"code":"username_taken","message":"Usuario ya existe"}`,
 		},
 	}
 
@@ -162,7 +154,10 @@ func TestApiLoginHandler(t *testing.T) {
 			// Parsear la respuesta JSON
 			var response struct {
 				Message string `json:"message"`
-				Error   string `json:"error"`
+				Error   struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
 			}
 			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
 				t.Fatalf("failed to parse response: %v", err)
@@ -174,8 +169,11 @@ func TestApiLoginHandler(t *testing.T) {
 			}
 
 			// Verificar el mensaje de error si se espera
-			if tt.wantError != "" && response.Error != tt.wantError {
-				t.Errorf("expected error %q, got %q", tt.wantError, response.Error)
+			if tt.wantError != "" && response.Error.Message != tt.wantError {
+				t.Errorf("expected error %q, got %q", tt.wantError, response.Error.Message)
+			}
+			if tt.wantError != "" && response.Error.Code != "invalid_credentials" {
+				t.Errorf("expected code %q, got %q", "invalid_credentials", response.Error.Code)
 			}
 		})
 	}
@@ -254,9 +252,14 @@ func TestApiTaskHandlers(t *testing.T) {
 		form.Add("id", "1")
 		form.Add("title", "Updated Task")
 		form.Add("done", "true")
+		// PUT sustituye la tarea entera: van todos los campos
+		for _, field := range []string{"due_at", "priority", "tags", "project_id", "description", "recurrence"} {
+			form.Add(field, "")
+		}
 
 		req := httptest.NewRequest("PUT", "/api/tasks/1", strings.NewReader(form.Encode()))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("If-Match", `"1"`)
 		req.AddCookie(cookie)
 		w := httptest.NewRecorder()
 
@@ -287,6 +290,7 @@ func TestApiTaskHandlers(t *testing.T) {
 
 		req := httptest.NewRequest("POST", "/api/delete-task", strings.NewReader(form.Encode()))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("If-Match", `"1"`)
 		// Crear el formulario con el ID
 
 		req.AddCookie(cookie)
@@ -300,3 +304,143 @@ func TestApiTaskHandlers(t *testing.T) {
 		}
 	})
 }
+
+func TestApiTaskDueDates(t *testing.T) {
+	h := getTestHandler(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.DefaultCost)
+	_, err := h.Db.Exec("INSERT INTO users (id, username, password_hash) VALUES (?, ?, ?)", 1, "testuser", hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loginForm := url.Values{}
+	loginForm.Add("username", "testuser")
+	loginForm.Add("password", "testpass")
+	loginReq := httptest.NewRequest("POST", "/api/login", strings.NewReader(loginForm.Encode()))
+	loginReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	loginW := httptest.NewRecorder()
+	h.ApiLoginHandler(loginW, loginReq)
+	cookie := loginW.Result().Cookies()[0]
+
+	t.Run("Add Task with due date", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("title", "Con vencimiento")
+		form.Add("due_at", "2030-01-02T10:00:00Z")
+
+		req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+
+		h.ApiAddTask(w, req)
+
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+		}
+		var response struct {
+			Task struct {
+				DueAt time.Time `json:"due_at"`
+			} `json:"task"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if !response.Task.DueAt.Equal(time.Date(2030, 1, 2, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected due_at %s", response.Task.DueAt)
+		}
+	})
+
+	t.Run("Add Task with invalid due date", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("title", "Fecha rota")
+		form.Add("due_at", "pasado mañana")
+
+		req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+
+		h.ApiAddTask(w, req)
+
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+	})
+}
+
+func TestApiTaskPriorities(t *testing.T) {
+	h := getTestHandler(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.DefaultCost)
+	_, err := h.Db.Exec("INSERT INTO users (id, username, password_hash) VALUES (?, ?, ?)", 1, "testuser", hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loginForm := url.Values{}
+	loginForm.Add("username", "testuser")
+	loginForm.Add("password", "testpass")
+	loginReq := httptest.NewRequest("POST", "/api/login", strings.NewReader(loginForm.Encode()))
+	loginReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	loginW := httptest.NewRecorder()
+	h.ApiLoginHandler(loginW, loginReq)
+	cookie := loginW.Result().Cookies()[0]
+
+	addTask := func(title, priority string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("title", title)
+		form.Add("priority", priority)
+		req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, req)
+		return w
+	}
+
+	t.Run("Add Task with priority", func(t *testing.T) {
+		for _, tt := range []struct{ title, priority string }{
+			{"Sin prioridad", ""},
+			{"Media", "medium"},
+			{"Urgente", "4"},
+		} {
+			w := addTask(tt.title, tt.priority)
+			if w.Result().StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+			}
+		}
+	})
+
+	t.Run("Add Task with invalid priority", func(t *testing.T) {
+		w := addTask("Rota", "máxima")
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("List Tasks by priority", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/tasks", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+
+		h.ApiListTasks(w, req)
+
+		var response struct {
+			Tasks []struct {
+				Title    string `json:"title"`
+				Priority int64  `json:"priority"`
+			} `json:"tasks"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		for _, task := range response.Tasks {
+			titles = append(titles, task.Title)
+		}
+		if strings.Join(titles, ",") != "Urgente,Media,Sin prioridad" {
+			t.Errorf("unexpected order %v", titles)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/gorilla/mux"
+)
+
+func TestApiTaskDescription(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+
+	type taskResponse struct {
+		ID          int64  `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Viaje"}, "description": {"  - billetes\r\n- hotel\r\n"}}, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+	}
+	var created struct {
+		Task taskResponse `json:"task"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Task.Description != "- billetes\n- hotel" {
+		t.Errorf("unexpected description %q", created.Task.Description)
+	}
+
+	t.Run("Update Without Description Keeps It", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiPatchTask(w, withIfMatch(mux.SetURLVars(formRequest("PATCH", "/api/tasks/1", url.Values{"title": {"Viaje a Roma"}}, cookie), map[string]string{"id": "1"}), "*"))
+		var response struct {
+			Task taskResponse `json:"task"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Task.Description != "- billetes\n- hotel" {
+			t.Errorf("description changed to %q", response.Task.Description)
+		}
+	})
+
+	t.Run("Update Clears Description", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiPatchTask(w, withIfMatch(mux.SetURLVars(formRequest("PATCH", "/api/tasks/1", url.Values{"description": {""}}, cookie), map[string]string{"id": "1"}), "*"))
+		var response struct {
+			Task taskResponse `json:"task"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Task.Description != "" {
+			t.Errorf("expected empty description, got %q", response.Task.Description)
+		}
+	})
+
+	t.Run("Too Long", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Larga"}, "description": {strings.Repeat("a", notes.MaxLength+1)}}, cookie))
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+	})
+}
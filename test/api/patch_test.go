@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func patchRequest(contentType, body string, cookie *http.Cookie) *http.Request {
+	req := httptest.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("If-Match", "*")
+	req.AddCookie(cookie)
+	return mux.SetURLVars(req, map[string]string{"id": "1"})
+}
+
+func TestApiPatchTask(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	w := httptest.NewRecorder()
+	h.ApiAddProject(w, jsonRequest("POST", "/api/projects", `{"name":"Oficina"}`, cookie))
+	w = httptest.NewRecorder()
+	h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{
+		"title": "Informe",
+		"priority": "high",
+		"due_at": "2025-03-10T09:30:00Z",
+		"project_id": 1,
+		"tags": ["trabajo"],
+		"description": "Para el lunes"
+	}`, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+	}
+
+	type task struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Done        bool     `json:"done"`
+		DueAt       *string  `json:"due_at"`
+		Priority    int64    `json:"priority"`
+		ProjectID   *int64   `json:"project_id"`
+		Tags        []string `json:"tags"`
+	}
+	patch := func(t *testing.T, req *http.Request) task {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ApiPatchTask(w, req)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		var response struct {
+			Task task `json:"task"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Task
+	}
+
+	t.Run("Merge Patch", func(t *testing.T) {
+		// Solo cambia done: el título y el resto se quedan como estaban
+		got := patch(t, patchRequest("application/merge-patch+json", `{"done":true}`, cookie))
+		if got.Title != "Informe" || !got.Done || got.Priority != 3 || got.DueAt == nil || got.ProjectID == nil ||
+			got.Description != "Para el lunes" || strings.Join(got.Tags, ",") != "trabajo" {
+			t.Errorf("unexpected task %+v", got)
+		}
+
+		// null vacía el campo
+		got = patch(t, patchRequest("application/merge-patch+json", `{"due_at":null,"project_id":null,"tags":["trabajo","urgente"]}`, cookie))
+		if got.DueAt != nil || got.ProjectID != nil || strings.Join(got.Tags, ",") != "trabajo,urgente" || got.Title != "Informe" {
+			t.Errorf("unexpected task %+v", got)
+		}
+
+		// application/json y los formularios también valen
+		got = patch(t, patchRequest("application/json", `{"done":false}`, cookie))
+		if got.Done || got.Title != "Informe" {
+			t.Errorf("unexpected task %+v", got)
+		}
+		form := withIfMatch(mux.SetURLVars(formRequest("PATCH", "/api/tasks/1", url.Values{"priority": {"low"}}, cookie), map[string]string{"id": "1"}), "*")
+		if got = patch(t, form); got.Priority != 1 || got.Title != "Informe" {
+			t.Errorf("unexpected task %+v", got)
+		}
+	})
+
+	t.Run("JSON Patch", func(t *testing.T) {
+		got := patch(t, patchRequest("application/json-patch+json", `[
+			{"op": "test", "path": "/title", "value": "Informe"},
+			{"op": "replace", "path": "/title", "value": "Informe final"},
+			{"op": "add", "path": "/tags/-", "value": "revisar"},
+			{"op": "remove", "path": "/description"}
+		]`, cookie))
+		if got.Title != "Informe final" || got.Description != "" || got.Priority != 1 ||
+			strings.Join(got.Tags, ",") != "trabajo,urgente,revisar" {
+			t.Errorf("unexpected task %+v", got)
+		}
+
+		tests := []struct {
+			name   string
+			body   string
+			status int
+		}{
+			{"Test Fails", `[{"op":"test","path":"/title","value":"Informe"}]`, http.StatusConflict},
+			{"Missing Path", `[{"op":"remove","path":"/tags/7"}]`, http.StatusConflict},
+			{"Invalid Patch", `{"op":"add"}`, http.StatusBadRequest},
+			{"Unknown Field", `[{"op":"add","path":"/color","value":"rojo"}]`, http.StatusBadRequest},
+			{"Read Only Field", `[{"op":"replace","path":"/id","value":7}]`, http.StatusConflict},
+			{"Invalid Value", `[{"op":"replace","path":"/priority","value":"máxima"}]`, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				h.ApiPatchTask(w, patchRequest("application/json-patch+json", tt.body, cookie))
+				if w.Result().StatusCode != tt.status {
+					t.Errorf("expected status %d, got %d: %s", tt.status, w.Result().StatusCode, w.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiPatchTask(w, patchRequest("application/merge-patch+json", `{"title":null}`, cookie))
+		if got := fieldErrors(t, w); got["title"] != "obligatorio" {
+			t.Errorf("unexpected errors %v", got)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiPatchTask(w, patchRequest("application/merge-patch+json", `{"title":"`+strings.Repeat("a", 201)+`"}`, cookie))
+		if got := fieldErrors(t, w); got["title"] != "el título no puede superar los 200 caracteres" {
+			t.Errorf("unexpected errors %v", got)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiPatchTask(w, patchRequest("application/merge-patch+json", `{"done":true}`, other))
+		if w.Result().StatusCode != http.StatusForbidden {
+			t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Result().StatusCode)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiPatchTask(w, patchRequest("text/plain", `done`, cookie))
+		if w.Result().StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("expected status %d, got %d", http.StatusUnsupportedMediaType, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Put Requires Every Field", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiUpdateTask(w, withIfMatch(mux.SetURLVars(jsonRequest("PUT", "/api/tasks/1", `{"title":"Informe","done":true}`, cookie), map[string]string{"id": "1"}), "*"))
+		got := fieldErrors(t, w)
+		for _, field := range []string{"due_at", "priority", "tags", "project_id", "description", "recurrence"} {
+			if got[field] != "obligatorio" {
+				t.Errorf("expected %s required, got %v", field, got)
+			}
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiUpdateTask(w, withIfMatch(mux.SetURLVars(jsonRequest("PUT", "/api/tasks/1", `{
+			"title": "Informe",
+			"done": true,
+			"due_at": null,
+			"priority": 0,
+			"tags": [],
+			"project_id": null,
+			"description": "",
+			"recurrence": null
+		}`, cookie), map[string]string{"id": "1"}), "*"))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"tags":[]`) || !strings.Contains(w.Body.String(), `"done":true`) {
+			t.Errorf("unexpected response %s", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestApiProjects(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	t.Run("Add Project", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiAddProject(w, formRequest("POST", "/api/projects", url.Values{"name": {"Casa"}}, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiAddProject(w, formRequest("POST", "/api/projects", url.Values{"name": {"Casa"}}, cookie))
+		if w.Result().StatusCode != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, w.Result().StatusCode)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiAddProject(w, formRequest("POST", "/api/projects", url.Values{"name": {"Trabajo"}}, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Add and Move Tasks", func(t *testing.T) {
+		for _, form := range []url.Values{
+			{"title": {"Fregar"}, "project_id": {"1"}},
+			{"title": {"Barrer"}, "project_id": {"1"}, "done": {"true"}},
+			{"title": {"Informe"}},
+		} {
+			w := httptest.NewRecorder()
+			h.ApiAddTask(w, formRequest("POST", "/api/tasks", form, cookie))
+			if w.Result().StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+			}
+		}
+
+		// No se puede usar el proyecto de otro usuario
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Ajena"}, "project_id": {"1"}}, other))
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiPatchTask(w, withIfMatch(mux.SetURLVars(formRequest("PATCH", "/api/tasks/3", url.Values{"project_id": {"2"}}, cookie), map[string]string{"id": "3"}), "*"))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+		var response struct {
+			Task struct {
+				ProjectID int64 `json:"project_id"`
+			} `json:"task"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Task.ProjectID != 2 {
+			t.Errorf("expected project 2, got %d", response.Task.ProjectID)
+		}
+	})
+
+	t.Run("List Projects", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/projects", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ApiListProjects(w, req)
+
+		var response struct {
+			Projects []struct {
+				Name    string `json:"name"`
+				Tasks   int64  `json:"tasks"`
+				Pending int64  `json:"pending"`
+			} `json:"projects"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Projects) != 2 {
+			t.Fatalf("unexpected projects %+v", response.Projects)
+		}
+		casa := response.Projects[0]
+		if casa.Name != "Casa" || casa.Tasks != 2 || casa.Pending != 1 {
+			t.Errorf("unexpected project %+v", casa)
+		}
+	})
+
+	t.Run("List Project Tasks", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/projects/1/tasks", nil)
+		req.AddCookie(cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.ApiListProjectTasks(w, req)
+
+		var response struct {
+			Tasks []struct {
+				Title string `json:"title"`
+			} `json:"tasks"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Tasks) != 2 {
+			t.Errorf("unexpected tasks %+v", response.Tasks)
+		}
+
+		req = httptest.NewRequest("GET", "/api/projects/1/tasks", nil)
+		req.AddCookie(other)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w = httptest.NewRecorder()
+		h.ApiListProjectTasks(w, req)
+		if w.Result().StatusCode != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Filter Tasks without Project", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/tasks?project=none", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ApiListTasks(w, req)
+
+		var response struct {
+			Tasks []struct {
+				Title string `json:"title"`
+			} `json:"tasks"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Tasks) != 0 {
+			t.Errorf("unexpected tasks %+v", response.Tasks)
+		}
+	})
+
+	t.Run("Rename and Delete Project", func(t *testing.T) {
+		req := formRequest("PUT", "/api/projects/2", url.Values{"name": {"Oficina"}}, cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "2"})
+		w := httptest.NewRecorder()
+		h.ApiUpdateProject(w, req)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+
+		req = httptest.NewRequest("DELETE", "/api/projects/2", nil)
+		req.AddCookie(cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "2"})
+		w = httptest.NewRecorder()
+		h.ApiDeleteProject(w, req)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+
+		var projectID sql.NullInt64
+		if err := h.Db.QueryRow("SELECT project_id FROM tasks WHERE id = 3").Scan(&projectID); err != nil {
+			t.Fatal(err)
+		}
+		if projectID.Valid {
+			t.Errorf("expected task without project, got %d", projectID.Int64)
+		}
+	})
+}
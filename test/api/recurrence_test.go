@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestApiRecurringTasks(t *testing.T) {
+	h := getTestHandler(t)
+	h.Now = func() time.Time { return time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC) }
+	cookie := loginTestUser(t, h, 1, "testuser")
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Informe"}, "recurrence": {"FREQ=MONTHLY;BYDAY=XX"}}, cookie))
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+	}
+
+	w = httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{
+		"title":      {"Informe"},
+		"due_at":     {"2025-01-31T10:00:00Z"},
+		"recurrence": {"monthly"},
+		"tags":       {"trabajo"},
+	}, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+	}
+
+	type taskResponse struct {
+		ID         int64     `json:"id"`
+		DueAt      time.Time `json:"due_at"`
+		Recurrence string    `json:"recurrence"`
+		Tags       []string  `json:"tags"`
+	}
+
+	t.Run("Complete Spawns Next", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiPatchTask(w, withIfMatch(mux.SetURLVars(formRequest("PATCH", "/api/tasks/1", url.Values{"done": {"true"}, "due_at": {"2025-01-31T10:00:00Z"}}, cookie), map[string]string{"id": "1"}), "*"))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+		var response struct {
+			Task taskResponse  `json:"task"`
+			Next *taskResponse `json:"next"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Task.Recurrence != "" {
+			t.Errorf("completed task kept recurrence %q", response.Task.Recurrence)
+		}
+		if response.Next == nil {
+			t.Fatal("expected next occurrence")
+		}
+		// Febrero no tiene día 31
+		if !response.Next.DueAt.Equal(time.Date(2025, 3, 31, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected next due date %s", response.Next.DueAt)
+		}
+		if response.Next.Recurrence != "FREQ=MONTHLY" || len(response.Next.Tags) != 1 {
+			t.Errorf("unexpected next task %+v", response.Next)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		req := mux.SetURLVars(formRequest("POST", "/api/tasks/2/skip", nil, cookie), map[string]string{"id": "2"})
+		w := httptest.NewRecorder()
+		h.ApiSkipTask(w, req)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+		var response struct {
+			Task taskResponse `json:"task"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if !response.Task.DueAt.Equal(time.Date(2025, 5, 31, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected due date %s", response.Task.DueAt)
+		}
+
+		req = mux.SetURLVars(formRequest("POST", "/api/tasks/1/skip", nil, cookie), map[string]string{"id": "1"})
+		w = httptest.NewRecorder()
+		h.ApiSkipTask(w, req)
+		if w.Result().StatusCode != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, w.Result().StatusCode)
+		}
+	})
+}
+
+func TestApiRecurringTasksAtomic(t *testing.T) {
+	h := getTestHandler(t)
+	h.Now = func() time.Time { return time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC) }
+	cookie := loginTestUser(t, h, 1, "testuser")
+	vars := map[string]string{"id": "1"}
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{
+		"title":      {"Informe"},
+		"due_at":     {"2025-01-31T10:00:00Z"},
+		"recurrence": {"monthly"},
+	}, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+	}
+	w = httptest.NewRecorder()
+	h.ApiAddSubtask(w, mux.SetURLVars(formRequest("POST", "/api/tasks/1/subtasks", url.Values{"title": {"Borrador"}}, cookie), vars))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+	}
+	if _, err := h.Db.Exec("UPDATE tasks SET done = 1 WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		Done       bool
+		Version    int64
+		DueAt      string
+		Recurrence string
+		Tasks      int
+	}
+	load := func(t *testing.T) row {
+		var r row
+		err := h.Db.QueryRow(`SELECT done, version, due_at, recurrence, (SELECT COUNT(*) FROM tasks)
+			FROM tasks WHERE id = 1`).Scan(&r.Done, &r.Version, &r.DueAt, &r.Recurrence, &r.Tasks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	before := load(t)
+
+	t.Run("Complete", func(t *testing.T) {
+		// Falla al crear la siguiente repetición, después de marcar la tarea
+		if _, err := h.Db.Exec("CREATE TRIGGER fallo BEFORE INSERT ON tasks BEGIN SELECT RAISE(ABORT, 'fallo'); END"); err != nil {
+			t.Fatal(err)
+		}
+		defer h.Db.Exec("DROP TRIGGER fallo")
+
+		w := httptest.NewRecorder()
+		h.ApiPatchTask(w, withIfMatch(mux.SetURLVars(formRequest("PATCH", "/api/tasks/1", url.Values{"done": {"true"}}, cookie), vars), "*"))
+		if w.Result().StatusCode != http.StatusInternalServerError {
+			t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, w.Result().StatusCode)
+		}
+		if after := load(t); after != before {
+			t.Errorf("failed completion wrote changes: %+v, want %+v", after, before)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		// Falla al reabrir las subtareas, después de mover la fecha
+		if _, err := h.Db.Exec("CREATE TRIGGER fallo BEFORE UPDATE OF done ON tasks WHEN NEW.parent_id IS NOT NULL BEGIN SELECT RAISE(ABORT, 'fallo'); END"); err != nil {
+			t.Fatal(err)
+		}
+		defer h.Db.Exec("DROP TRIGGER fallo")
+
+		w := httptest.NewRecorder()
+		h.ApiSkipTask(w, mux.SetURLVars(formRequest("POST", "/api/tasks/1/skip", nil, cookie), vars))
+		if w.Result().StatusCode != http.StatusInternalServerError {
+			t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, w.Result().StatusCode)
+		}
+		if after := load(t); after != before {
+			t.Errorf("failed skip wrote changes: %+v, want %+v", after, before)
+		}
+	})
+}
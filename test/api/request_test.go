@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func jsonRequest(method, target, body string, cookie *http.Cookie) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+// fieldErrors decodifica una respuesta {"errors":{...}}.
+func fieldErrors(t *testing.T, w *httptest.ResponseRecorder) map[string]string {
+	t.Helper()
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, w.Result().StatusCode, w.Body.String())
+	}
+	var response struct {
+		Errors map[string]string `json:"errors"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	return response.Errors
+}
+
+func TestApiJSONRequests(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+
+	w := httptest.NewRecorder()
+	h.ApiAddProject(w, jsonRequest("POST", "/api/projects", `{"name":"Casa"}`, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+	}
+
+	type taskResponse struct {
+		Task struct {
+			ID        int64    `json:"id"`
+			Title     string   `json:"title"`
+			Done      bool     `json:"done"`
+			Priority  int64    `json:"priority"`
+			ProjectID *int64   `json:"project_id"`
+			Tags      []string `json:"tags"`
+		} `json:"task"`
+	}
+
+	t.Run("Add Task", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{
+			"title": "Fregar",
+			"done": false,
+			"priority": 3,
+			"project_id": 1,
+			"tags": ["Casa", "limpieza"],
+			"due_at": null
+		}`, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+		var response taskResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		task := response.Task
+		if task.Title != "Fregar" || task.Priority != 3 || task.ProjectID == nil || *task.ProjectID != 1 {
+			t.Errorf("unexpected task %+v", task)
+		}
+		if strings.Join(task.Tags, ",") != "casa,limpieza" {
+			t.Errorf("unexpected tags %v", task.Tags)
+		}
+	})
+
+	t.Run("Patch Task By Route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := mux.SetURLVars(jsonRequest("PATCH", "/api/tasks/1", `{"title":"Fregar platos","done":true,"priority":"low"}`, cookie), map[string]string{"id": "1"})
+		h.ApiPatchTask(w, withIfMatch(req, "*"))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		var response taskResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		// Lo que no se envía no cambia: etiquetas y proyecto
+		task := response.Task
+		if task.Title != "Fregar platos" || !task.Done || task.Priority != 1 || task.ProjectID == nil || len(task.Tags) != 2 {
+			t.Errorf("unexpected task %+v", task)
+		}
+	})
+
+	t.Run("Field Errors", func(t *testing.T) {
+		tests := []struct {
+			body string
+			want map[string]string
+		}{
+			{`{}`, map[string]string{"title": "obligatorio"}},
+			{`{"title":" "}`, map[string]string{"title": "obligatorio"}},
+			{`{"title":"A","color":"rojo","size":1}`, map[string]string{"color": "campo desconocido", "size": "campo desconocido"}},
+			{`{"title":"A","done":"yes","tags":{"a":1}}`, map[string]string{"done": "debe ser un booleano", "tags": "debe ser una lista de textos"}},
+			{`{"title":7}`, map[string]string{"title": "debe ser un texto"}},
+			{`{"title":"` + strings.Repeat("a", 201) + `"}`, map[string]string{"title": "el título no puede superar los 200 caracteres"}},
+		}
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", tt.body, cookie))
+			got := fieldErrors(t, w)
+			if len(got) != len(tt.want) {
+				t.Errorf("%s: expected %v, got %v", tt.body, tt.want, got)
+			}
+			for field, msg := range tt.want {
+				if got[field] != msg {
+					t.Errorf("%s: expected %s %q, got %q", tt.body, field, msg, got[field])
+				}
+			}
+		}
+
+		// Los errores de validación de cada campo también van por campo
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{"title":"A","priority":"máxima","project_id":99}`, cookie))
+		got := fieldErrors(t, w)
+		if got["priority"] == "" || got["project_id"] == "" {
+			t.Errorf("expected priority and project_id errors, got %v", got)
+		}
+	})
+
+	t.Run("Invalid Bodies", func(t *testing.T) {
+		tests := []struct {
+			contentType string
+			body        string
+			want        int
+		}{
+			{"application/json", `{"title":`, http.StatusBadRequest},
+			{"application/json", `["title"]`, http.StatusBadRequest},
+			{"application/json", `null`, http.StatusBadRequest},
+			{"application/json", ``, http.StatusBadRequest},
+			{"application/json", `{"title":"A"} {"title":"B"}`, http.StatusBadRequest},
+			{"application/json", `{"title":"` + strings.Repeat("a", 2<<20) + `"}`, http.StatusRequestEntityTooLarge},
+			{"text/plain", `title=A`, http.StatusUnsupportedMediaType},
+			{"application/xml", `<title>A</title>`, http.StatusUnsupportedMediaType},
+			{"", `title=A`, http.StatusUnsupportedMediaType},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			req.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			h.ApiAddTask(w, req)
+			if w.Result().StatusCode != tt.want {
+				t.Errorf("%s %.40q: expected status %d, got %d", tt.contentType, tt.body, tt.want, w.Result().StatusCode)
+			}
+		}
+	})
+
+	t.Run("Forms", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Barrer"}, "done": {"on"}, "tags": {"casa, rapido"}}, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Barrer"}, "colour": {"rojo"}, "done": {"quizá"}}, cookie))
+		got := fieldErrors(t, w)
+		if got["colour"] != "campo desconocido" || got["done"] != "debe ser un booleano" {
+			t.Errorf("unexpected errors %v", got)
+		}
+	})
+
+	t.Run("Subtasks", func(t *testing.T) {
+		vars := map[string]string{"id": "1"}
+		w := httptest.NewRecorder()
+		h.ApiAddSubtask(w, mux.SetURLVars(jsonRequest("POST", "/api/tasks/1/subtasks", `{"title":"Secar"}`, cookie), vars))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+
+		vars["subtask_id"] = "3"
+		w = httptest.NewRecorder()
+		h.ApiUpdateSubtask(w, mux.SetURLVars(jsonRequest("PUT", "/api/tasks/1/subtasks/3", `{"done":true}`, cookie), vars))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"suggest_complete_parent":false`) {
+			t.Errorf("unexpected response %s", w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiUpdateSubtask(w, mux.SetURLVars(jsonRequest("PUT", "/api/tasks/1/subtasks/3", `{"title":""}`, cookie), vars))
+		if got := fieldErrors(t, w); got["title"] != "obligatorio" {
+			t.Errorf("unexpected errors %v", got)
+		}
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiLoginHandler(w, jsonRequest("POST", "/api/login", `{"username":"testuser","password":"testpass"}`, nil))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiLoginHandler(w, jsonRequest("POST", "/api/login", `{"username":"testuser"}`, nil))
+		if got := fieldErrors(t, w); got["password"] != "obligatorio" {
+			t.Errorf("unexpected errors %v", got)
+		}
+	})
+}
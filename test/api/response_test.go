@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// errorCode decodifica una respuesta {"error":{"code":...,"message":...}}.
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var response struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Error.Message == "" {
+		t.Errorf("error without message for code %q", response.Error.Code)
+	}
+	return response.Error.Code
+}
+
+func TestApiTaskResponse(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Informe"}, "due_at": {"2025-03-10T09:30:00+01:00"}}, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+	}
+	var response struct {
+		Task map[string]json.RawMessage `json:"task"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	// Siempre los mismos campos, sin los del modelo que no son de la API
+	var keys []string
+	for k := range response.Task {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	want := "description,done,due_at,etag,id,parent_id,priority,progress,project_id,recurrence,tags,title"
+	if strings.Join(keys, ",") != want {
+		t.Errorf("expected fields %s, got %s", want, strings.Join(keys, ","))
+	}
+	for field, value := range map[string]string{
+		"done":       `false`,
+		"due_at":     `"2025-03-10T08:30:00Z"`,
+		"project_id": `null`,
+		"recurrence": `null`,
+		"tags":       `[]`,
+		"progress":   `null`,
+	} {
+		if string(response.Task[field]) != value {
+			t.Errorf("expected %s %s, got %s", field, value, response.Task[field])
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.ApiRegisterHandler(w, formRequest("POST", "/api/register", url.Values{"username": {"nuevo"}, "password": {"secreta123"}}, cookie))
+	if !strings.Contains(w.Body.String(), `"user":{"id":2,"username":"nuevo"}`) {
+		t.Errorf("unexpected register response %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Errorf("register response leaks the password hash: %s", w.Body.String())
+	}
+}
+
+func TestApiErrorEnvelope(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Informe"}}, cookie))
+
+	tests := []struct {
+		name   string
+		call   func(w http.ResponseWriter)
+		status int
+		code   string
+	}{
+		{"unauthorized", func(w http.ResponseWriter) {
+			h.ApiListTasks(w, httptest.NewRequest("GET", "/api/tasks", nil))
+		}, http.StatusUnauthorized, "unauthorized"},
+		{"forbidden", func(w http.ResponseWriter) {
+			h.ApiDeleteTask(w, mux.SetURLVars(formRequest("DELETE", "/api/tasks/1", nil, other), map[string]string{"id": "1"}))
+		}, http.StatusForbidden, "forbidden"},
+		{"not found", func(w http.ResponseWriter) {
+			h.ApiGetProject(w, mux.SetURLVars(formRequest("GET", "/api/projects/9", nil, cookie), map[string]string{"id": "9"}))
+		}, http.StatusNotFound, "not_found"},
+		{"bad request", func(w http.ResponseWriter) {
+			h.ApiListTasks(w, formRequest("GET", "/api/tasks?sort=color", nil, cookie))
+		}, http.StatusBadRequest, "bad_request"},
+		{"invalid cursor", func(w http.ResponseWriter) {
+			h.ApiListTasks(w, formRequest("GET", "/api/tasks?cursor=basura", nil, cookie))
+		}, http.StatusBadRequest, "invalid_cursor"},
+		{"not recurring", func(w http.ResponseWriter) {
+			h.ApiSkipTask(w, mux.SetURLVars(formRequest("POST", "/api/tasks/1/skip", nil, cookie), map[string]string{"id": "1"}))
+		}, http.StatusConflict, "not_recurring"},
+		{"validation", func(w http.ResponseWriter) {
+			h.ApiAddTask(w, jsonRequest("POST", "/api/tasks", `{"title":""}`, cookie))
+		}, http.StatusBadRequest, "validation_failed"},
+		{"unsupported media type", func(w http.ResponseWriter) {
+			req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader("title"))
+			req.Header.Set("Content-Type", "text/plain")
+			req.AddCookie(cookie)
+			h.ApiAddTask(w, req)
+		}, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.call(w)
+			if w.Result().StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Result().StatusCode)
+			}
+			if code := errorCode(t, w); code != tt.code {
+				t.Errorf("expected code %q, got %q", tt.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestApiSearchTasks(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	for _, form := range []url.Values{
+		{"title": {"Comprar leche"}, "tags": {"casa"}},
+		{"title": {"Ir al súper"}, "description": {"pan, leche & huevos"}},
+		{"title": {"Llamar al banco"}},
+	} {
+		w := httptest.NewRecorder()
+		h.ApiAddTask(w, formRequest("POST", "/api/tasks", form, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Leche ajena"}}, other))
+
+	type searchResponse struct {
+		Results []struct {
+			Task struct {
+				ID    int64    `json:"id"`
+				Title string   `json:"title"`
+				Tags  []string `json:"tags"`
+			} `json:"task"`
+			Snippet string  `json:"snippet"`
+			Rank    float64 `json:"rank"`
+		} `json:"results"`
+	}
+
+	t.Run("Ranked Results", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiSearchTasks(w, formRequest("GET", "/api/tasks/search?q=leche", nil, cookie))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+		var response searchResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Results) != 2 {
+			t.Fatalf("expected 2 results, got %+v", response.Results)
+		}
+		first, second := response.Results[0], response.Results[1]
+		if first.Task.ID != 1 || second.Task.ID != 2 {
+			t.Errorf("unexpected order %d, %d", first.Task.ID, second.Task.ID)
+		}
+		if first.Snippet != "Comprar <mark>leche</mark>" {
+			t.Errorf("unexpected snippet %q", first.Snippet)
+		}
+		if second.Snippet != "pan, <mark>leche</mark> &amp; huevos" {
+			t.Errorf("unexpected snippet %q", second.Snippet)
+		}
+		if len(first.Task.Tags) != 1 || first.Task.Tags[0] != "casa" {
+			t.Errorf("unexpected tags %v", first.Task.Tags)
+		}
+		if first.Rank > second.Rank {
+			t.Errorf("expected ascending rank, got %f, %f", first.Rank, second.Rank)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiSearchTasks(w, formRequest("GET", "/api/tasks/search?q=leche&limit=1", nil, cookie))
+		var response searchResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Results) != 1 {
+			t.Errorf("expected 1 result, got %d", len(response.Results))
+		}
+	})
+
+	t.Run("Bad Requests", func(t *testing.T) {
+		for _, target := range []string{"/api/tasks/search", "/api/tasks/search?q=%20%22", "/api/tasks/search?q=leche&limit=0"} {
+			w := httptest.NewRecorder()
+			h.ApiSearchTasks(w, formRequest("GET", target, nil, cookie))
+			if w.Result().StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", target, http.StatusBadRequest, w.Result().StatusCode)
+			}
+		}
+	})
+}
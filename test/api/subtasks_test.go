@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestApiSubtasks(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	w := httptest.NewRecorder()
+	h.ApiAddTask(w, formRequest("POST", "/api/tasks", url.Values{"title": {"Mudanza"}}, cookie))
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+	}
+
+	subtaskRequest := func(method, target string, form url.Values, cookie *http.Cookie, vars map[string]string) *http.Request {
+		return mux.SetURLVars(formRequest(method, target, form, cookie), vars)
+	}
+
+	t.Run("Add Subtasks", func(t *testing.T) {
+		for _, title := range []string{"Cajas", "Camión"} {
+			w := httptest.NewRecorder()
+			h.ApiAddSubtask(w, subtaskRequest("POST", "/api/tasks/1/subtasks", url.Values{"title": {title}}, cookie, map[string]string{"id": "1"}))
+			if w.Result().StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+			}
+		}
+
+		w := httptest.NewRecorder()
+		h.ApiAddSubtask(w, subtaskRequest("POST", "/api/tasks/1/subtasks", url.Values{"title": {" "}}, cookie, map[string]string{"id": "1"}))
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+
+		// Una subtarea no puede tener subtareas
+		w = httptest.NewRecorder()
+		h.ApiAddSubtask(w, subtaskRequest("POST", "/api/tasks/2/subtasks", url.Values{"title": {"Cinta"}}, cookie, map[string]string{"id": "2"}))
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiAddSubtask(w, subtaskRequest("POST", "/api/tasks/1/subtasks", url.Values{"title": {"Ajena"}}, other, map[string]string{"id": "1"}))
+		if w.Result().StatusCode != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("List Tasks Hides Subtasks", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/tasks", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ApiListTasks(w, req)
+
+		var response struct {
+			Tasks []struct {
+				Title    string `json:"title"`
+				Progress *struct {
+					Done  int `json:"done"`
+					Total int `json:"total"`
+				} `json:"progress"`
+			} `json:"tasks"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Tasks) != 1 || response.Tasks[0].Progress == nil || response.Tasks[0].Progress.Total != 2 {
+			t.Errorf("unexpected tasks %+v", response.Tasks)
+		}
+	})
+
+	t.Run("Toggle Subtasks", func(t *testing.T) {
+		type toggleResponse struct {
+			Progress struct {
+				Done  int `json:"done"`
+				Total int `json:"total"`
+			} `json:"progress"`
+			SuggestCompleteParent bool `json:"suggest_complete_parent"`
+		}
+		toggle := func(id string) toggleResponse {
+			w := httptest.NewRecorder()
+			h.ApiUpdateSubtask(w, subtaskRequest("PUT", "/api/tasks/1/subtasks/"+id, url.Values{"done": {"true"}}, cookie, map[string]string{"id": "1", "subtask_id": id}))
+			if w.Result().StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+			}
+			var response toggleResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			return response
+		}
+
+		response := toggle("2")
+		if response.Progress.Done != 1 || response.SuggestCompleteParent {
+			t.Errorf("unexpected response %+v", response)
+		}
+		response = toggle("3")
+		if response.Progress.Done != 2 || !response.SuggestCompleteParent {
+			t.Errorf("unexpected response %+v", response)
+		}
+
+		// La subtarea tiene que ser de la tarea de la ruta
+		w := httptest.NewRecorder()
+		h.ApiUpdateSubtask(w, subtaskRequest("PUT", "/api/tasks/1/subtasks/1", url.Values{"done": {"true"}}, cookie, map[string]string{"id": "1", "subtask_id": "1"}))
+		if w.Result().StatusCode != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("List and Delete Subtasks", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiDeleteSubtask(w, subtaskRequest("DELETE", "/api/tasks/1/subtasks/3", nil, cookie, map[string]string{"id": "1", "subtask_id": "3"}))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+
+		req := httptest.NewRequest("GET", "/api/tasks/1/subtasks", nil)
+		req.AddCookie(cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w = httptest.NewRecorder()
+		h.ApiListSubtasks(w, req)
+
+		var response struct {
+			Subtasks []struct {
+				Title    string `json:"title"`
+				ParentID int64  `json:"parent_id"`
+			} `json:"subtasks"`
+			Progress struct {
+				Done  int `json:"done"`
+				Total int `json:"total"`
+			} `json:"progress"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Subtasks) != 1 || response.Subtasks[0].Title != "Cajas" || response.Subtasks[0].ParentID != 1 {
+			t.Errorf("unexpected subtasks %+v", response.Subtasks)
+		}
+		if response.Progress.Done != 1 || response.Progress.Total != 1 {
+			t.Errorf("unexpected progress %+v", response.Progress)
+		}
+	})
+}
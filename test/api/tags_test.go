@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/handlers"
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginTestUser crea el usuario con el id indicado y devuelve su cookie de
+// sesión.
+func loginTestUser(t *testing.T, h *handlers.WebHandler, id int, username string) *http.Cookie {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.MinCost)
+	if _, err := h.Db.Exec("INSERT INTO users (id, username, password_hash) VALUES (?, ?, ?)", id, username, hash); err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{}
+	form.Add("username", username)
+	form.Add("password", "testpass")
+	req := httptest.NewRequest("POST", "/api/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ApiLoginHandler(w, req)
+	return w.Result().Cookies()[0]
+}
+
+func formRequest(method, target string, form url.Values, cookie *http.Cookie) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	return req
+}
+
+func TestApiTags(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	var tagID int64
+	t.Run("Add Tag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiAddTag(w, formRequest("POST", "/api/tags", url.Values{"name": {"Trabajo"}}, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+		}
+		var response struct {
+			Tag struct {
+				ID   int64  `json:"id"`
+				Name string `json:"name"`
+			} `json:"tag"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Tag.Name != "trabajo" {
+			t.Errorf("unexpected name %q", response.Tag.Name)
+		}
+		tagID = response.Tag.ID
+
+		w = httptest.NewRecorder()
+		h.ApiAddTag(w, formRequest("POST", "/api/tags", url.Values{"name": {"trabajo"}}, cookie))
+		if w.Result().StatusCode != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, w.Result().StatusCode)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiAddTag(w, formRequest("POST", "/api/tags", url.Values{"name": {"-casa"}}, cookie))
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Filter Tasks by Tag", func(t *testing.T) {
+		for _, form := range []url.Values{
+			{"title": {"Informe"}, "tags": {"trabajo"}},
+			{"title": {"Teletrabajo"}, "tags": {"trabajo, casa"}},
+			{"title": {"Compra"}, "tags": {"casa"}},
+		} {
+			w := httptest.NewRecorder()
+			h.ApiAddTask(w, formRequest("POST", "/api/tasks", form, cookie))
+			if w.Result().StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Result().StatusCode)
+			}
+		}
+
+		req := httptest.NewRequest("GET", "/api/tasks?tag=trabajo&tag=-casa", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ApiListTasks(w, req)
+
+		var response struct {
+			Tasks []struct {
+				Title string   `json:"title"`
+				Tags  []string `json:"tags"`
+			} `json:"tasks"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Tasks) != 1 || response.Tasks[0].Title != "Informe" {
+			t.Fatalf("unexpected tasks %+v", response.Tasks)
+		}
+		if strings.Join(response.Tasks[0].Tags, ",") != "trabajo" {
+			t.Errorf("unexpected tags %v", response.Tasks[0].Tags)
+		}
+	})
+
+	t.Run("List Tags", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/tags", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ApiListTags(w, req)
+
+		var response struct {
+			Tags []struct {
+				Name  string `json:"name"`
+				Tasks int64  `json:"tasks"`
+			} `json:"tags"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Tags) != 2 || response.Tags[1].Name != "trabajo" || response.Tags[1].Tasks != 2 {
+			t.Errorf("unexpected tags %+v", response.Tags)
+		}
+	})
+
+	t.Run("Update Tag", func(t *testing.T) {
+		req := formRequest("PUT", "/api/tags/1", url.Values{"name": {"oficina"}}, cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.ApiUpdateTag(w, req)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+
+		req = formRequest("PUT", "/api/tags/1", url.Values{"name": {"casa"}}, cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w = httptest.NewRecorder()
+		h.ApiUpdateTag(w, req)
+		if w.Result().StatusCode != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Delete Tag of another user", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/tags/1", nil)
+		req.AddCookie(other)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.ApiDeleteTag(w, req)
+		if w.Result().StatusCode != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Delete Tag", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/tags/1", nil)
+		req.AddCookie(cookie)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.ApiDeleteTag(w, req)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+		var n int
+		if err := h.Db.QueryRow("SELECT COUNT(*) FROM task_tags WHERE tag_id = ?", tagID).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("expected task_tags to be cleaned up, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// bearerRequest es una petición JSON autenticada con un token personal.
+func bearerRequest(method, target, body, secret string) *http.Request {
+	req := jsonRequest(method, target, body, nil)
+	req.Header.Set("Authorization", "Bearer "+secret)
+	return req
+}
+
+func TestApiTokens(t *testing.T) {
+	h := getTestHandler(t)
+	cookie := loginTestUser(t, h, 1, "testuser")
+	other := loginTestUser(t, h, 2, "otro")
+
+	// create crea un token con la sesión de cookie y devuelve su ID y su
+	// secreto.
+	create := func(t *testing.T, body string) (int64, string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ApiCreateToken(w, jsonRequest("POST", "/api/tokens", body, cookie))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+		var response struct {
+			Token struct {
+				ID        int64    `json:"id"`
+				Prefix    string   `json:"prefix"`
+				Scopes    []string `json:"scopes"`
+				ExpiresAt *string  `json:"expires_at"`
+			} `json:"token"`
+			Secret string `json:"secret"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(response.Secret, response.Token.Prefix) || len(response.Token.Scopes) == 0 {
+			t.Errorf("unexpected response %+v", response)
+		}
+		return response.Token.ID, response.Secret
+	}
+	// call ejecuta handler detrás de TokenAuth, como en el servidor.
+	call := func(handler http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.TokenAuth(handler).ServeHTTP(w, req)
+		return w
+	}
+	errorCode := func(t *testing.T, w *httptest.ResponseRecorder, status int) string {
+		t.Helper()
+		if w.Result().StatusCode != status {
+			t.Fatalf("expected status %d, got %d: %s", status, w.Result().StatusCode, w.Body.String())
+		}
+		var response struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Error.Code
+	}
+
+	readID, read := create(t, `{"name":"lectura","scopes":["tasks:read"]}`)
+	_, write := create(t, `{"name":"ci","scopes":"tasks:write","expires_at":"30d"}`)
+
+	t.Run("Scopes", func(t *testing.T) {
+		w := call(h.ApiAddTask, bearerRequest("POST", "/api/tasks", `{"title":"Desde CI"}`, write))
+		if w.Result().StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Result().StatusCode, w.Body.String())
+		}
+
+		w = call(h.ApiListTasks, bearerRequest("GET", "/api/tasks", "", read))
+		if w.Result().StatusCode != http.StatusOK || !strings.Contains(w.Body.String(), "Desde CI") {
+			t.Errorf("expected the user's tasks, got %d: %s", w.Result().StatusCode, w.Body.String())
+		}
+
+		w = call(h.ApiAddTask, bearerRequest("POST", "/api/tasks", `{"title":"No"}`, read))
+		if code := errorCode(t, w, http.StatusForbidden); code != "insufficient_scope" {
+			t.Errorf("expected insufficient_scope, got %q", code)
+		}
+		if got := w.Result().Header.Get("WWW-Authenticate"); !strings.Contains(got, `scope="tasks:write"`) {
+			t.Errorf("unexpected WWW-Authenticate %q", got)
+		}
+	})
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		for _, header := range []string{"Bearer todo_nope", "Bearer ", "Basic dXNlcjpwYXNz"} {
+			req := jsonRequest("GET", "/api/tasks", "", cookie)
+			req.Header.Set("Authorization", header)
+			w := call(h.ApiListTasks, req)
+			if code := errorCode(t, w, http.StatusUnauthorized); code != "invalid_token" {
+				t.Errorf("%s: expected invalid_token, got %q", header, code)
+			}
+			if w.Result().Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("%s: expected WWW-Authenticate", header)
+			}
+		}
+	})
+
+	t.Run("Session Still Works", func(t *testing.T) {
+		w := call(h.ApiListTasks, jsonRequest("GET", "/api/tasks", "", cookie))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, w.Result().StatusCode)
+		}
+	})
+
+	t.Run("Tokens Cannot Manage Tokens", func(t *testing.T) {
+		w := call(h.ApiCreateToken, bearerRequest("POST", "/api/tokens", `{"name":"otro","scopes":["tasks:write"]}`, write))
+		errorCode(t, w, http.StatusForbidden)
+		w = call(h.ApiListTokens, bearerRequest("GET", "/api/tokens", "", read))
+		errorCode(t, w, http.StatusForbidden)
+	})
+
+	t.Run("List Tokens", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiListTokens(w, jsonRequest("GET", "/api/tokens", "", cookie))
+		var response struct {
+			Tokens []struct {
+				Name       string  `json:"name"`
+				LastUsedAt *string `json:"last_used_at"`
+			} `json:"tokens"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Tokens) != 2 || response.Tokens[0].Name != "lectura" || response.Tokens[0].LastUsedAt == nil {
+			t.Errorf("unexpected tokens %+v", response.Tokens)
+		}
+		if strings.Contains(w.Body.String(), read) {
+			t.Error("the list must not include secrets")
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiListTokens(w, jsonRequest("GET", "/api/tokens", "", other))
+		if strings.Contains(w.Body.String(), "lectura") {
+			t.Errorf("expected only the user's tokens, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("Invalid Request", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			body  string
+			field string
+		}{
+			{"Missing Name", `{"scopes":["tasks:read"]}`, "name"},
+			{"Missing Scopes", `{"name":"x"}`, "scopes"},
+			{"Unknown Scope", `{"name":"x","scopes":["admin"]}`, "scopes"},
+			{"Invalid Expiry", `{"name":"x","scopes":["tasks:read"],"expires_at":"pronto"}`, "expires_at"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				h.ApiCreateToken(w, jsonRequest("POST", "/api/tokens", tt.body, cookie))
+				if got := fieldErrors(t, w); got[tt.field] == "" {
+					t.Errorf("expected an error in %s, got %v", tt.field, got)
+				}
+			})
+		}
+
+		w := httptest.NewRecorder()
+		h.ApiCreateToken(w, jsonRequest("POST", "/api/tokens", `{"name":"ci","scopes":["tasks:read"]}`, cookie))
+		errorCode(t, w, http.StatusConflict)
+	})
+
+	t.Run("Revoke Token", func(t *testing.T) {
+		vars := map[string]string{"id": strconv.FormatInt(readID, 10)}
+		w := httptest.NewRecorder()
+		h.ApiRevokeToken(w, mux.SetURLVars(jsonRequest("DELETE", "/api/tokens/1", "", other), vars))
+		errorCode(t, w, http.StatusNotFound)
+
+		w = httptest.NewRecorder()
+		h.ApiRevokeToken(w, mux.SetURLVars(jsonRequest("DELETE", "/api/tokens/1", "", cookie), vars))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		w = call(h.ApiListTasks, bearerRequest("GET", "/api/tasks", "", read))
+		errorCode(t, w, http.StatusUnauthorized)
+	})
+}
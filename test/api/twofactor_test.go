@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/pquerna/otp/totp"
+	"github.com/volatiletech/null/v8"
+)
+
+func TestApiLoginTwoFactor(t *testing.T) {
+	h := getTestHandler(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	h.Now = func() time.Time { return now }
+	loginTestUser(t, h, 1, "testuser")
+
+	// Activa la verificación en dos pasos del usuario
+	ctx := context.Background()
+	user, err := models.FindUser(ctx, h.Db, null.Int64From(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := twofactor.Enroll(ctx, h.Db, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := totp.GenerateCode(key.Secret(), now)
+	codes, err := twofactor.Confirm(ctx, h.Db, user, first, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errorCode := func(t *testing.T, w *httptest.ResponseRecorder, status int) string {
+		t.Helper()
+		if w.Result().StatusCode != status {
+			t.Fatalf("expected status %d, got %d: %s", status, w.Result().StatusCode, w.Body.String())
+		}
+		var response struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Error.Code
+	}
+	login := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ApiLoginHandler(w, jsonRequest("POST", "/api/login", body, nil))
+		return w
+	}
+	// loggedIn indica si la cookie da acceso a la API.
+	loggedIn := func(cookie *http.Cookie) bool {
+		w := httptest.NewRecorder()
+		h.ApiListTasks(w, jsonRequest("GET", "/api/tasks", "", cookie))
+		return w.Result().StatusCode == http.StatusOK
+	}
+
+	t.Run("OTPRequired", func(t *testing.T) {
+		w := login(`{"username":"testuser","password":"testpass"}`)
+		cookies := w.Result().Cookies()
+		if code := errorCode(t, w, http.StatusUnauthorized); code != "otp_required" {
+			t.Errorf("expected code otp_required, got %q", code)
+		}
+		if len(cookies) == 0 {
+			t.Fatal("expected a pending session cookie")
+		}
+		if loggedIn(cookies[0]) {
+			t.Error("the pending session must not give access")
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiLoginTwoFactor(w, jsonRequest("POST", "/api/login/2fa", `{"code":"000000"}`, cookies[0]))
+		if code := errorCode(t, w, http.StatusUnauthorized); code != "invalid_otp" {
+			t.Errorf("expected code invalid_otp, got %q", code)
+		}
+
+		w = httptest.NewRecorder()
+		h.ApiLoginTwoFactor(w, jsonRequest("POST", "/api/login/2fa", `{"code":"`+codes[0]+`"}`, cookies[0]))
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		if !loggedIn(w.Result().Cookies()[0]) {
+			t.Error("expected the session to be logged in")
+		}
+	})
+
+	t.Run("InlineOTP", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		otp, _ := totp.GenerateCode(key.Secret(), now)
+		body := `{"username":"testuser","password":"testpass","otp":"` + otp + `"}`
+		w := login(body)
+		if w.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Result().StatusCode, w.Body.String())
+		}
+		if !loggedIn(w.Result().Cookies()[0]) {
+			t.Error("expected the session to be logged in")
+		}
+
+		// El mismo código no vale dos veces
+		if code := errorCode(t, login(body), http.StatusUnauthorized); code != "invalid_otp" {
+			t.Errorf("expected code invalid_otp, got %q", code)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		w := login(`{"username":"testuser","password":"testpass"}`)
+		cookie := w.Result().Cookies()[0]
+		now = now.Add(10 * time.Minute)
+		otp, _ := totp.GenerateCode(key.Secret(), now)
+
+		w = httptest.NewRecorder()
+		h.ApiLoginTwoFactor(w, jsonRequest("POST", "/api/login/2fa", `{"code":"`+otp+`"}`, cookie))
+		if code := errorCode(t, w, http.StatusUnauthorized); code != "unauthorized" {
+			t.Errorf("expected code unauthorized, got %q", code)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ApiLoginTwoFactor(w, jsonRequest("POST", "/api/login/2fa", `{}`, nil))
+		if errs := fieldErrors(t, w); errs["code"] != "obligatorio" {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+}
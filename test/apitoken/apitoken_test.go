@@ -0,0 +1,140 @@
+package apitoken_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/apitoken"
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v8"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x')`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := apitoken.ParseScopes("tasks:write, tasks:read tasks:write")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tasks:write", "tasks:read"}, scopes)
+
+	for _, in := range []string{"", " , ", "tasks:delete", "tasks:read,admin"} {
+		_, err := apitoken.ParseScopes(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	expires, err := apitoken.ParseExpiry("", time.UTC, now)
+	require.NoError(t, err)
+	assert.False(t, expires.Valid)
+
+	expires, err = apitoken.ParseExpiry("30d", time.UTC, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 30), expires.Time)
+
+	expires, err = apitoken.ParseExpiry("2025-04-01", time.UTC, now)
+	require.NoError(t, err)
+	assert.True(t, expires.Valid)
+
+	for _, in := range []string{"0d", "-3d", "muchos", "2025-03-01"} {
+		_, err := apitoken.ParseExpiry(in, time.UTC, now)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	read := &models.APIToken{Scopes: "tasks:read"}
+	write := &models.APIToken{Scopes: "tasks:write"}
+
+	assert.True(t, apitoken.Allows(read, apitoken.ScopeTasksRead))
+	assert.False(t, apitoken.Allows(read, apitoken.ScopeTasksWrite))
+	assert.True(t, apitoken.Allows(write, apitoken.ScopeTasksRead))
+	assert.True(t, apitoken.Allows(write, apitoken.ScopeTasksWrite))
+}
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	token, secret, err := apitoken.Create(ctx, db, 1, "ci", []string{"tasks:read"}, null.Time{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, apitoken.Prefix))
+	assert.True(t, strings.HasPrefix(secret, token.Prefix))
+	assert.NotContains(t, token.TokenHash, secret, "the secret must not be stored")
+
+	_, _, err = apitoken.Create(ctx, db, 1, "ci", []string{"tasks:read"}, null.Time{})
+	assert.ErrorIs(t, err, apitoken.ErrNameTaken)
+	_, _, err = apitoken.Create(ctx, db, 2, "ci", []string{"tasks:read"}, null.Time{})
+	assert.NoError(t, err, "another user can use the same name")
+
+	got, err := apitoken.Authenticate(ctx, db, secret, now)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, got.ID)
+	assert.True(t, got.LastUsedAt.Valid)
+
+	for _, bad := range []string{"", "todo_", secret + "0", strings.TrimPrefix(secret, apitoken.Prefix)} {
+		_, err := apitoken.Authenticate(ctx, db, bad, now)
+		assert.ErrorIs(t, err, apitoken.ErrInvalid, bad)
+	}
+
+	expiring, secret, err := apitoken.Create(ctx, db, 1, "temporal", []string{"tasks:write"}, null.TimeFrom(now.Add(time.Hour)))
+	require.NoError(t, err)
+	_, err = apitoken.Authenticate(ctx, db, secret, now)
+	assert.NoError(t, err)
+	_, err = apitoken.Authenticate(ctx, db, secret, expiring.ExpiresAt.Time)
+	assert.ErrorIs(t, err, apitoken.ErrInvalid, "expired token")
+}
+
+func TestRevoke(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	token, secret, err := apitoken.Create(ctx, db, 1, "ci", []string{"tasks:read"}, null.Time{})
+	require.NoError(t, err)
+	_, _, err = apitoken.Create(ctx, db, 1, "portátil", []string{"tasks:write"}, null.Time{})
+	require.NoError(t, err)
+
+	_, err = apitoken.Revoke(ctx, db, 2, "ci")
+	assert.ErrorIs(t, err, apitoken.ErrNotFound, "other users cannot revoke it")
+
+	revoked, err := apitoken.Revoke(ctx, db, 1, "ci")
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, revoked.ID)
+	_, err = apitoken.Authenticate(ctx, db, secret, time.Now())
+	assert.ErrorIs(t, err, apitoken.ErrInvalid)
+
+	tokens, err := apitoken.List(ctx, db, 1)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	_, err = apitoken.Revoke(ctx, db, 1, "2")
+	assert.NoError(t, err, "tokens can be revoked by ID")
+
+	// Borrar el usuario borra sus tokens
+	_, _, err = apitoken.Create(ctx, db, 1, "ci", []string{"tasks:read"}, null.Time{})
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM users WHERE id = 1`)
+	require.NoError(t, err)
+	count, err := models.APITokens().Count(ctx, db)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -1,64 +1,961 @@
 package cli_test
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/JorgeePG/todo-list/internal/migrations"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	_ "modernc.org/sqlite"
 )
 
-func TestListCommand_TextOutput(t *testing.T) {
-	cmd := exec.Command("go", "run", ".", "list", "--output", "text")
-	cmd.Dir = "../../cmd"
-	output, err := cmd.CombinedOutput()
+// todoBin es el binario de la CLI compilado una sola vez en TestMain.
+var todoBin string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "todo-cli")
 	if err != nil {
-		t.Logf("Output: %s", string(output))
+		panic(err)
 	}
-	assert.NoError(t, err)
-	assert.NotEmpty(t, string(output))
+	todoBin = filepath.Join(dir, "todo")
+	build := exec.Command("go", "build", "-o", todoBin, ".")
+	build.Dir = "../../cmd"
+	if out, err := build.CombinedOutput(); err != nil {
+		panic(string(out))
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
 }
 
-func TestListCommand_JSONOutput(t *testing.T) {
-	cmd := exec.Command("go", "run", ".", "list", "--output", "json")
-	cmd.Dir = "../../cmd"
+// newTestDB crea una base de datos temporal con el esquema aplicado y
+// devuelve su ruta.
+func newTestDB(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "todo.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, migrations.Apply(context.Background(), db))
+	return path
+}
+
+func execSQL(t *testing.T, dbPath, query string, args ...interface{}) {
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(query, args...)
+	require.NoError(t, err)
+}
+
+func queryRow(t *testing.T, dbPath, query string, args ...interface{}) *sql.Row {
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db.QueryRow(query, args...)
+}
+
+func createUser(t *testing.T, dbPath, username string) int64 {
+	execSQL(t, dbPath, "INSERT INTO users (username, password_hash) VALUES (?, ?)", username, "x")
+	var id int64
+	require.NoError(t, queryRow(t, dbPath, "SELECT id FROM users WHERE username = ?", username).Scan(&id))
+	return id
+}
+
+func createTask(t *testing.T, dbPath string, userID int64, title string, done bool) string {
+	execSQL(t, dbPath, "INSERT INTO tasks (title, done, user_id) VALUES (?, ?, ?)", title, done, userID)
+	var id int64
+	require.NoError(t, queryRow(t, dbPath, "SELECT MAX(id) FROM tasks").Scan(&id))
+	return strconv.FormatInt(id, 10)
+}
+
+// runTodo ejecuta la CLI contra la base de datos dbPath.
+func runTodo(t *testing.T, dbPath string, args ...string) (string, error) {
+	return runTodoInput(t, dbPath, "", args...)
+}
+
+// runTodoInput ejecuta la CLI pasándole input por la entrada estándar.
+func runTodoInput(t *testing.T, dbPath, input string, args ...string) (string, error) {
+	cmd := exec.Command(todoBin, args...)
+	cmd.Env = append(os.Environ(), "TODO_DB_DSN="+dbPath)
+	cmd.Stdin = strings.NewReader(input)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		t.Logf("Output: %s", string(output))
 	}
+	return string(output), err
+}
+
+func TestListCommand_TextOutput(t *testing.T) {
+	dbPath := newTestDB(t)
+	execSQL(t, dbPath, "INSERT INTO tasks (title, done) VALUES (?, ?)", "Tarea de prueba", false)
+
+	output, err := runTodo(t, dbPath, "list", "--output", "text")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Tarea de prueba - Pendiente")
+}
+
+func TestListCommand_JSONOutput(t *testing.T) {
+	output, err := runTodo(t, newTestDB(t), "list", "--output", "json")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output, "[") || strings.HasPrefix(output, "null"))
+}
+
+func TestListCommand_CreatesSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "nueva.db")
+	_, err := runTodo(t, dbPath, "list")
+	assert.NoError(t, err)
+	_, err = os.Stat(dbPath)
+	assert.NoError(t, err)
+}
+
+func TestMigrateDownIsNotReapplied(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	output, err := runTodo(t, dbPath, "migrate", "down")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Revertida ")
+
+	// Los demás comandos no deshacen el migrate down: piden migrate up
+	output, err = runTodo(t, dbPath, "list")
+	assert.Error(t, err)
+	assert.Contains(t, output, "ejecuta todo migrate up")
+	output, err = runTodo(t, dbPath, "migrate", "status")
+	require.NoError(t, err)
+	assert.Contains(t, output, "\tpendiente")
+
+	// Con auto_migrate se aplican al abrir la base de datos
+	t.Setenv("TODO_AUTO_MIGRATE", "true")
+	_, err = runTodo(t, dbPath, "list")
 	assert.NoError(t, err)
-	assert.True(t, strings.HasPrefix(string(output), "[") || strings.HasPrefix(string(output), "null"))
+	output, err = runTodo(t, dbPath, "migrate", "up")
+	require.NoError(t, err)
+	assert.Contains(t, output, "No hay migraciones pendientes")
+}
+
+func TestServeRequiresSessionSecret(t *testing.T) {
+	output, err := runTodo(t, newTestDB(t), "serve")
+	assert.Error(t, err)
+	assert.Contains(t, output, "clave de sesión es la de por defecto")
+	assert.Contains(t, output, "--dev")
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	envDB := newTestDB(t)
+	flagDB := newTestDB(t)
+	execSQL(t, flagDB, "INSERT INTO tasks (title, done) VALUES (?, ?)", "Desde flag", false)
+
+	// El flag --db gana a TODO_DB_DSN
+	output, err := runTodo(t, envDB, "--db", flagDB, "list")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Desde flag")
+
+	// El fichero de configuración pierde frente a la variable de entorno
+	fileDB := newTestDB(t)
+	cfgPath := filepath.Join(t.TempDir(), "todo.toml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("db_dsn = \""+fileDB+"\"\n"), 0o644))
+	output, err = runTodo(t, flagDB, "--config", cfgPath, "list")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Desde flag")
 }
 
 func TestAddCommand(t *testing.T) {
-	cmd := exec.Command("go", "run", ".", "add", "--title", "Tarea de prueba", "--finish", "false")
-	cmd.Dir = "../../cmd"
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		t.Logf("Output: %s", string(output))
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+
+	output, err := runTodo(t, dbPath, "add", "--user", "ana", "--title", "Tarea de prueba", "--finish", "false")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Tarea creada con ID")
+
+	var title string
+	var done bool
+	queryRow(t, dbPath, "SELECT t.title, t.done FROM tasks t JOIN users u ON u.id = t.user_id WHERE u.username = ?", "ana").Scan(&title, &done)
+	assert.Equal(t, "Tarea de prueba", title)
+	assert.False(t, done)
+}
+
+func TestAddCommand_JSONAndDefaultUser(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+	t.Setenv("TODO_DEFAULT_USER", "ana")
+
+	output, err := runTodo(t, dbPath, "add", "-t", "  Hecha  ", "--finish", "true", "-o", "json")
+	require.NoError(t, err)
+
+	var task struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title"`
+		Done  bool   `json:"done"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &task))
+	assert.NotZero(t, task.ID)
+	assert.Equal(t, "Hecha", task.Title)
+	assert.True(t, task.Done)
+}
+
+func TestAddCommand_Errors(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+
+	for name, args := range map[string][]string{
+		"título vacío":        {"add", "--user", "ana", "--title", "   "},
+		"usuario inexistente": {"add", "--user", "nadie", "--title", "x"},
+		"sin usuario":         {"add", "--title", "x"},
+		"finish no válido":    {"add", "--user", "ana", "--title", "x", "--finish", "quizá"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := runTodo(t, dbPath, args...)
+			assert.Error(t, err)
+		})
+	}
+
+	var n int
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM tasks").Scan(&n)
+	assert.Zero(t, n)
+}
+
+func TestDueDates(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Vencida", "--due", "2001-01-01")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Lejana", "--due", "2999-01-01T10:00")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Sin fecha")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Rota", "--due", "algún día")
+	assert.Error(t, err)
+
+	output, err := runTodo(t, dbPath, "list", "--overdue")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Vencida - Pendiente (venció hace")
+	assert.NotContains(t, output, "Lejana")
+	assert.NotContains(t, output, "Sin fecha")
+
+	output, err = runTodo(t, dbPath, "list", "--due-before", "3000-01-01", "-o", "json")
+	require.NoError(t, err)
+	var tasks []struct {
+		Title string     `json:"title"`
+		DueAt *time.Time `json:"due_at"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &tasks))
+	require.Len(t, tasks, 2)
+	for _, task := range tasks {
+		assert.NotNil(t, task.DueAt, task.Title)
+	}
+
+	// Una tarea hecha no está vencida, y "none" elimina el vencimiento
+	_, err = runTodo(t, dbPath, "done", "-u", "ana", "1")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "list", "--overdue")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Vencida")
+
+	_, err = runTodo(t, dbPath, "edit", "-u", "ana", "--due", "none", "2")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "show", "-u", "ana", "2")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Vence:")
+}
+
+func TestPriorities(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Algún día")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Importante", "--priority", "high", "--due", "2999-01-01")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Importante y próxima", "-p", "alta", "--due", "2998-01-01")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Rota", "--priority", "máxima")
+	assert.Error(t, err)
+
+	titles := func(args ...string) []string {
+		output, err := runTodo(t, dbPath, append([]string{"list", "-o", "json"}, args...)...)
+		require.NoError(t, err)
+		var tasks []struct {
+			Title    string `json:"title"`
+			Priority string `json:"priority"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(output), &tasks))
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.Title+":"+task.Priority)
+		}
+		return out
+	}
+
+	// Por defecto: prioridad, vencimiento e ID
+	want := []string{"Importante y próxima:high", "Importante:high", "Algún día:none"}
+	assert.Equal(t, want, titles())
+	assert.Equal(t, want, titles("--sort", "priority"))
+	assert.Equal(t, []string{"Algún día:none", "Importante:high", "Importante y próxima:high"}, titles("--sort", "id"))
+	assert.Equal(t, []string{"Importante y próxima:high", "Importante:high"}, titles("--sort", "id", "--order", "desc", "--limit", "2"))
+	assert.Equal(t, []string{"Algún día:none", "Importante:high", "Importante y próxima:high"}, titles("--sort", "due", "--order", "desc"))
+	_, err = runTodo(t, dbPath, "list", "--sort", "color")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "list", "--order", "arriba")
+	assert.Error(t, err)
+
+	_, err = runTodo(t, dbPath, "edit", "-u", "ana", "--priority", "urgent", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Algún día:urgent", titles()[0])
+
+	output, err := runTodo(t, dbPath, "show", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Prioridad: Urgente")
+
+	output, err = runTodo(t, dbPath, "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Algún día - Pendiente [urgent]")
+}
+
+func TestTagCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+	createUser(t, dbPath, "luis")
+
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Informe", "--tag", "trabajo", "--tag", "Urgente")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Compra", "--tag", "casa")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Rota", "--tag", "dos palabras")
+	assert.Error(t, err)
+
+	output, err := runTodo(t, dbPath, "tag", "add", "-u", "ana", "2", "trabajo", "casa")
+	require.NoError(t, err)
+	assert.Contains(t, output, "[2] Compra - Etiquetas: casa, trabajo")
+
+	output, err = runTodo(t, dbPath, "tag", "rm", "-u", "ana", "1", "urgente")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Etiquetas: trabajo")
+	_, err = runTodo(t, dbPath, "tag", "rm", "-u", "ana", "1", "casa")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "tag", "add", "-u", "luis", "1", "ajena")
+	assert.Error(t, err)
+
+	output, err = runTodo(t, dbPath, "tag", "list", "-u", "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "casa (1)\ntrabajo (2)\nurgente (0)\n", output)
+
+	titles := func(args ...string) []string {
+		output, err := runTodo(t, dbPath, append([]string{"list", "-o", "json", "--sort", "id"}, args...)...)
+		require.NoError(t, err)
+		var tasks []struct {
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(output), &tasks))
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.Title+":"+strings.Join(task.Tags, ","))
+		}
+		return out
+	}
+	assert.Equal(t, []string{"Informe:trabajo", "Compra:casa,trabajo"}, titles("--tag", "trabajo"))
+	assert.Equal(t, []string{"Informe:trabajo"}, titles("--tag", "trabajo", "--tag", "-casa"))
+	assert.Empty(t, titles("--tag", "ninguna"))
+
+	output, err = runTodo(t, dbPath, "show", "-u", "ana", "2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Etiquetas: casa, trabajo")
+
+	// Al reasignar las tareas conservan sus etiquetas
+	_, err = runTodo(t, dbPath, "user", "delete", "--username", "ana", "--reassign-to", "luis", "--force")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "tag", "list", "-u", "luis")
+	require.NoError(t, err)
+	assert.Contains(t, output, "trabajo (2)")
+}
+
+func TestProjectCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+	createUser(t, dbPath, "luis")
+
+	output, err := runTodo(t, dbPath, "project", "add", "-u", "ana", "Casa")
+	require.NoError(t, err)
+	assert.Contains(t, output, `Proyecto "Casa" creado`)
+	_, err = runTodo(t, dbPath, "project", "add", "-u", "ana", "Casa")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "project", "add", "-u", "ana", "none")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "project", "add", "-u", "ana", "Trabajo")
+	require.NoError(t, err)
+
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Fregar", "--project", "Casa")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Informe")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Rota", "--project", "Jardín")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "luis", "-t", "Ajena", "--project", "Casa")
+	assert.Error(t, err)
+
+	output, err = runTodo(t, dbPath, "project", "move", "-u", "ana", "--to", "Trabajo", "2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "[2] Informe - Proyecto: Trabajo")
+
+	output, err = runTodo(t, dbPath, "list", "--project", "Trabajo")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Informe - Pendiente @Trabajo")
+	assert.NotContains(t, output, "Fregar")
+
+	output, err = runTodo(t, dbPath, "show", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Proyecto:  Casa")
+
+	_, err = runTodo(t, dbPath, "project", "rename", "-u", "ana", "Trabajo", "Oficina")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "project", "list", "-u", "ana")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Casa - 1 pendiente(s) de 1")
+	assert.Contains(t, output, "Oficina - 1 pendiente(s) de 1")
+	assert.Contains(t, output, "Sin proyecto - 0 pendiente(s) de 0")
+
+	_, err = runTodo(t, dbPath, "project", "rm", "-u", "ana", "--force", "Casa")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "list", "--project", "none", "-o", "json")
+	require.NoError(t, err)
+	var tasks []struct {
+		Title   string `json:"title"`
+		Project string `json:"project"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Fregar", tasks[0].Title)
+	assert.Empty(t, tasks[0].Project)
+}
+
+func TestSubtaskCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+	createUser(t, dbPath, "luis")
+
+	_, err := runTodo(t, dbPath, "project", "add", "-u", "ana", "Casa")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Mudanza", "--project", "Casa")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Informe")
+	require.NoError(t, err)
+	for _, title := range []string{"Cajas", "Camión"} {
+		_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", title, "--parent", "1")
+		require.NoError(t, err)
+	}
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Nieta", "--parent", "3")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "luis", "-t", "Ajena", "--parent", "1")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Doble", "--parent", "1", "--project", "Casa")
+	assert.Error(t, err)
+
+	output, err := runTodo(t, dbPath, "done", "-u", "ana", "3")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "todo done 1")
+
+	output, err = runTodo(t, dbPath, "list", "--sort", "id")
+	require.NoError(t, err)
+	assert.Equal(t, "[1] Mudanza (1/2) - Pendiente @Casa\n"+
+		"    └ [3] Cajas - Hecha @Casa\n"+
+		"    └ [4] Camión - Pendiente @Casa\n"+
+		"[2] Informe - Pendiente\n", output)
+
+	output, err = runTodo(t, dbPath, "done", "-u", "ana", "4")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Todas las subtareas de [1] Mudanza están hechas. Complétala con: todo done 1")
+
+	output, err = runTodo(t, dbPath, "show", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Subtareas: 2/2")
+	assert.Contains(t, output, "  [x] 4 Camión")
+
+	// Las subtareas siguen a su tarea al moverla y se borran con ella
+	_, err = runTodo(t, dbPath, "project", "move", "-u", "ana", "--to", "none", "1")
+	require.NoError(t, err)
+	var projects int
+	require.NoError(t, queryRow(t, dbPath, "SELECT COUNT(*) FROM tasks WHERE parent_id = 1 AND project_id IS NOT NULL").Scan(&projects))
+	assert.Zero(t, projects)
+
+	_, err = runTodo(t, dbPath, "rm", "-u", "ana", "--force", "1")
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, queryRow(t, dbPath, "SELECT COUNT(*) FROM tasks").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestRecurringTaskCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Basura", "--due", "2030-06-03T20:00:00Z", "--repeat", "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Mala", "--repeat", "yearly")
+	assert.Error(t, err)
+
+	output, err := runTodo(t, dbPath, "show", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Se repite: cada semana (lu, ju), quedan 3 veces")
+
+	output, err = runTodo(t, dbPath, "done", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "[1] Basura - Hecha")
+	assert.Contains(t, output, "Siguiente repetición: [2] Basura")
+	var dueAt time.Time
+	var rule string
+	require.NoError(t, queryRow(t, dbPath, "SELECT due_at, recurrence FROM tasks WHERE id = 2").Scan(&dueAt, &rule))
+	assert.True(t, time.Date(2030, 6, 6, 20, 0, 0, 0, time.UTC).Equal(dueAt), "got %s", dueAt)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=2", rule)
+
+	output, err = runTodo(t, dbPath, "list", "--pending-only")
+	require.NoError(t, err)
+	assert.Contains(t, output, "↻ cada semana (lu, ju), quedan 2 veces")
+
+	_, err = runTodo(t, dbPath, "repeat", "skip", "-u", "ana", "2")
+	require.NoError(t, err)
+	require.NoError(t, queryRow(t, dbPath, "SELECT due_at, recurrence FROM tasks WHERE id = 2").Scan(&dueAt, &rule))
+	assert.True(t, time.Date(2030, 6, 10, 20, 0, 0, 0, time.UTC).Equal(dueAt), "got %s", dueAt)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=1", rule)
+	_, err = runTodo(t, dbPath, "repeat", "skip", "-u", "ana", "2")
+	assert.Error(t, err)
+
+	// La última repetición no crea otra
+	output, err = runTodo(t, dbPath, "done", "-u", "ana", "2")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Siguiente repetición")
+
+	_, err = runTodo(t, dbPath, "edit", "-u", "ana", "--repeat", "monthly", "2")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "repeat", "stop", "-u", "ana", "2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "[2] Basura - Ya no se repite")
+	_, err = runTodo(t, dbPath, "repeat", "stop", "-u", "ana", "2")
+	assert.Error(t, err)
+}
+
+func TestDoneRepeatedRecurringTask(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Basura", "--due", "2030-06-03T20:00:00Z", "--repeat", "FREQ=WEEKLY;COUNT=3")
+	require.NoError(t, err)
+
+	// Repetir el ID no completa la repetición dos veces ni gasta COUNT
+	output, err := runTodo(t, dbPath, "done", "-u", "ana", "1", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(output, "Siguiente repetición"), output)
+	var count int
+	var rule string
+	require.NoError(t, queryRow(t, dbPath, "SELECT COUNT(*) FROM tasks").Scan(&count))
+	assert.Equal(t, 2, count)
+	require.NoError(t, queryRow(t, dbPath, "SELECT recurrence FROM tasks WHERE id = 2").Scan(&rule))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", rule)
+}
+
+func TestTaskNotesCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Viaje", "--description", "Reservar **hotel**")
+	require.NoError(t, err)
+
+	output, err := runTodo(t, dbPath, "show", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Notas:\n  Reservar **hotel**\n")
+
+	// "-" lee las notas de la entrada estándar
+	_, err = runTodoInput(t, dbPath, "- billetes\n- hotel\n", "edit", "-u", "ana", "--description", "-", "1")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "show", "-u", "ana", "-o", "json", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"description": "- billetes\n- hotel"`)
+
+	_, err = runTodo(t, dbPath, "edit", "-u", "ana", "--description", "", "1")
+	require.NoError(t, err)
+	output, err = runTodo(t, dbPath, "show", "-u", "ana", "1")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Notas:")
+}
+
+func TestSearchCommand(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "ana")
+	luis := createUser(t, dbPath, "luis")
+	createTask(t, dbPath, luis, "Leche de luis", false)
+
+	_, err := runTodo(t, dbPath, "add", "-u", "ana", "-t", "Comprar leche")
+	require.NoError(t, err)
+	_, err = runTodo(t, dbPath, "add", "-u", "ana", "-t", "Ir al súper", "--description", "pan, leche\ny huevos")
+	require.NoError(t, err)
+
+	output, err := runTodo(t, dbPath, "search", "-u", "ana", "leche")
+	require.NoError(t, err)
+	assert.Contains(t, output, "[2] Comprar leche - Pendiente\n    Comprar [leche]\n")
+	assert.Contains(t, output, "[3] Ir al súper - Pendiente\n    pan, [leche] y huevos\n")
+	assert.Less(t, strings.Index(output, "[2]"), strings.Index(output, "[3]"))
+	assert.NotContains(t, output, "luis")
+
+	output, err = runTodo(t, dbPath, "search", "-u", "ana", "-o", "json", "--limit", "1", "super")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"snippet": "Ir al [súper]"`)
+
+	output, err = runTodo(t, dbPath, "search", "-u", "ana", "banco")
+	require.NoError(t, err)
+	assert.Contains(t, output, "No hay tareas que coincidan")
+	_, err = runTodo(t, dbPath, "search", "-u", "ana")
+	assert.Error(t, err)
+}
+
+func TestDoneUndoCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	ana := createUser(t, dbPath, "ana")
+	id1 := createTask(t, dbPath, ana, "Una", false)
+	id2 := createTask(t, dbPath, ana, "Dos", false)
+
+	output, err := runTodo(t, dbPath, "done", "--user", "ana", id1, id2)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Una - Hecha")
+	assert.Contains(t, output, "Dos - Hecha")
+
+	// Un ID repetido cuenta una vez
+	output, err = runTodo(t, dbPath, "done", "--user", "ana", id1, id1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(output, "Una - Hecha"), output)
+
+	output, err = runTodo(t, dbPath, "undo", "--user", "ana", "-o", "json", id2)
+	require.NoError(t, err)
+	var tasks []struct {
+		ID   int64 `json:"id"`
+		Done bool  `json:"done"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &tasks))
+	require.Len(t, tasks, 1)
+	assert.False(t, tasks[0].Done)
+
+	var done1, done2 bool
+	queryRow(t, dbPath, "SELECT done FROM tasks WHERE id = ?", id1).Scan(&done1)
+	queryRow(t, dbPath, "SELECT done FROM tasks WHERE id = ?", id2).Scan(&done2)
+	assert.True(t, done1)
+	assert.False(t, done2)
+}
+
+func TestTaskCommands_Ownership(t *testing.T) {
+	dbPath := newTestDB(t)
+	ana := createUser(t, dbPath, "ana")
+	luis := createUser(t, dbPath, "luis")
+	propia := createTask(t, dbPath, ana, "De Ana", false)
+	ajena := createTask(t, dbPath, luis, "De Luis", false)
+
+	// Si una de las tareas no es del usuario no se modifica ninguna
+	_, err := runTodo(t, dbPath, "done", "--user", "ana", propia, ajena)
+	assert.Error(t, err)
+	var done bool
+	queryRow(t, dbPath, "SELECT done FROM tasks WHERE id = ?", propia).Scan(&done)
+	assert.False(t, done)
+
+	for _, args := range [][]string{
+		{"show", "--user", "ana", ajena},
+		{"edit", "--user", "ana", "--title", "x", ajena},
+		{"rm", "--user", "ana", "--force", ajena},
+		{"show", "--user", "ana", "999"},
+		{"show", "--user", "ana", "abc"},
+	} {
+		_, err := runTodo(t, dbPath, args...)
+		assert.Error(t, err, "%v", args)
 	}
+}
+
+func TestEditAndShowCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	ana := createUser(t, dbPath, "ana")
+	id := createTask(t, dbPath, ana, "Original", true)
+
+	_, err := runTodo(t, dbPath, "edit", "--user", "ana", "--title", "Renombrada", id)
+	require.NoError(t, err)
+
+	output, err := runTodo(t, dbPath, "show", "--user", "ana", id)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Título:    Renombrada")
+	assert.Contains(t, output, "Estado:    Hecha")
+
+	output, err = runTodo(t, dbPath, "show", "--user", "ana", "-o", "json", id)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"title": "Renombrada"`)
+
+	_, err = runTodo(t, dbPath, "edit", "--user", "ana", "--title", " ", id)
+	assert.Error(t, err)
+}
+
+func TestRmCommand(t *testing.T) {
+	dbPath := newTestDB(t)
+	ana := createUser(t, dbPath, "ana")
+	id1 := createTask(t, dbPath, ana, "Una", false)
+	id2 := createTask(t, dbPath, ana, "Dos", false)
+
+	// Sin confirmación no se borra nada
+	_, err := runTodoInput(t, dbPath, "n\n", "rm", "--user", "ana", id1)
+	assert.Error(t, err)
+
+	_, err = runTodoInput(t, dbPath, "s\n", "rm", "--user", "ana", id1)
 	assert.NoError(t, err)
-	assert.Contains(t, string(output), "Título: Tarea de prueba Tarea acabada: false")
+
+	output, err := runTodo(t, dbPath, "rm", "--user", "ana", "--force", id2, id2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(output, "Dos"), output)
+
+	var n int
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM tasks").Scan(&n)
+	assert.Zero(t, n)
 }
 
 func TestUserAddCommand(t *testing.T) {
-	cmd := exec.Command("go", "run", ".", "user", "add", "--nombre", "Juan", "--atareado", "true")
-	cmd.Dir = "../../cmd"
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		t.Logf("Output: %s", string(output))
+	dbPath := newTestDB(t)
+
+	output, err := runTodoInput(t, dbPath, "secreta123\n", "user", "add", "--username", "juan", "--password-stdin")
+	require.NoError(t, err)
+	assert.Contains(t, output, `Usuario "juan" creado`)
+
+	var hash string
+	require.NoError(t, queryRow(t, dbPath, "SELECT password_hash FROM users WHERE username = ?", "juan").Scan(&hash))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("secreta123")))
+
+	// Duplicado, sin contraseña y sin --password-stdin
+	_, err = runTodoInput(t, dbPath, "otra-clave\n", "user", "add", "--username", "juan", "--password-stdin")
+	assert.Error(t, err)
+	_, err = runTodoInput(t, dbPath, "\n", "user", "add", "--username", "pedro", "--password-stdin")
+	assert.Error(t, err)
+	_, err = runTodo(t, dbPath, "user", "add", "--username", "pedro")
+	assert.Error(t, err)
+}
+
+func TestUserAddPolicy(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	output, err := runTodoInput(t, dbPath, "corta\n", "user", "add", "--username", "pedro", "--password-stdin")
+	assert.Error(t, err)
+	assert.Contains(t, output, "al menos 8 caracteres")
+	output, err = runTodoInput(t, dbPath, "secreta123\n", "user", "add", "--username", "pe dro", "--password-stdin")
+	assert.Error(t, err)
+	assert.Contains(t, output, "el nombre de usuario solo puede tener")
+
+	_, err = runTodoInput(t, dbPath, "secreta123\n", "user", "add", "--username", " Pedro ", "--password-stdin")
+	require.NoError(t, err)
+	output, err = runTodoInput(t, dbPath, "secreta123\n", "user", "add", "--username", "PEDRO", "--password-stdin")
+	assert.Error(t, err, "usernames are unique ignoring case")
+	assert.Contains(t, output, "ya existe")
+
+	// passwd busca el usuario sin distinguir mayúsculas y aplica la política
+	_, err = runTodoInput(t, dbPath, "pedro\n", "user", "passwd", "--username", "pedro", "--password-stdin")
+	assert.Error(t, err)
+	t.Setenv("TODO_PASSWORD_MIN_LENGTH", "4")
+	_, err = runTodoInput(t, dbPath, "clave\n", "user", "passwd", "--username", "pedro", "--password-stdin")
+	require.NoError(t, err)
+
+	var hash string
+	require.NoError(t, queryRow(t, dbPath, "SELECT password_hash FROM users WHERE username = ?", "Pedro").Scan(&hash))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("clave")))
+}
+
+func TestUserPasswdCommand(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "juan")
+
+	_, err := runTodoInput(t, dbPath, "nueva-clave\n", "user", "passwd", "--username", "juan", "--password-stdin")
+	require.NoError(t, err)
+
+	var hash string
+	require.NoError(t, queryRow(t, dbPath, "SELECT password_hash FROM users WHERE username = ?", "juan").Scan(&hash))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("nueva-clave")))
+}
+
+func TestUserListAndShowCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	juan := createUser(t, dbPath, "juan")
+	createUser(t, dbPath, "ana")
+	createTask(t, dbPath, juan, "Una", true)
+	createTask(t, dbPath, juan, "Dos", false)
+
+	output, err := runTodo(t, dbPath, "user", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "ana - 0 tarea(s)")
+	assert.Contains(t, output, "juan - 2 tarea(s), 1 pendiente(s)")
+
+	output, err = runTodo(t, dbPath, "user", "show", "--username", "juan", "-o", "json")
+	require.NoError(t, err)
+	var user struct {
+		Username string `json:"username"`
+		Tasks    int    `json:"tasks"`
+		Done     int    `json:"done"`
+		Pending  int    `json:"pending"`
 	}
-	assert.NoError(t, err)
-	assert.Contains(t, string(output), "Nombre: Juan Tareas pendientes: true")
+	require.NoError(t, json.Unmarshal([]byte(output), &user))
+	assert.Equal(t, "juan", user.Username)
+	assert.Equal(t, 2, user.Tasks)
+	assert.Equal(t, 1, user.Done)
+	assert.Equal(t, 1, user.Pending)
+
+	_, err = runTodo(t, dbPath, "user", "show", "--username", "nadie")
+	assert.Error(t, err)
 }
 
 func TestUserDeleteCommand(t *testing.T) {
-	cmd := exec.Command("go", "run", ".", "user", "delete", "--nombre", "Juan")
-	cmd.Dir = "../../cmd"
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		t.Logf("Output: %s", string(output))
+	dbPath := newTestDB(t)
+	juan := createUser(t, dbPath, "juan")
+	createTask(t, dbPath, juan, "De Juan", false)
+
+	_, err := runTodoInput(t, dbPath, "n\n", "user", "delete", "--username", "juan")
+	assert.Error(t, err)
+
+	output, err := runTodo(t, dbPath, "user", "delete", "--username", "juan", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, output, `El usuario "juan" ha sido eliminado junto con 1 tarea(s)`)
+
+	var users, tasks int
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM users").Scan(&users)
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM tasks").Scan(&tasks)
+	assert.Zero(t, users)
+	assert.Zero(t, tasks)
+}
+
+func TestUserDeleteCommand_Reassign(t *testing.T) {
+	dbPath := newTestDB(t)
+	juan := createUser(t, dbPath, "juan")
+	ana := createUser(t, dbPath, "ana")
+	id := createTask(t, dbPath, juan, "De Juan", false)
+
+	_, err := runTodo(t, dbPath, "user", "delete", "--username", "juan", "--reassign-to", "ana", "--force")
+	require.NoError(t, err)
+
+	var owner int64
+	queryRow(t, dbPath, "SELECT user_id FROM tasks WHERE id = ?", id).Scan(&owner)
+	assert.Equal(t, ana, owner)
+}
+
+func TestTokenCommands(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "juan")
+	createUser(t, dbPath, "ana")
+
+	output, err := runTodo(t, dbPath, "token", "create", "--user", "juan", "--name", "ci", "--scope", "tasks:write", "--expires", "30d")
+	require.NoError(t, err)
+	assert.Contains(t, output, `Token "ci" creado con ID 1`)
+	assert.Contains(t, output, "todo_")
+
+	output, err = runTodo(t, dbPath, "token", "create", "--user", "juan", "--name", "lectura", "-o", "json")
+	require.NoError(t, err)
+	var created struct {
+		Scopes []string `json:"scopes"`
+		Secret string   `json:"secret"`
 	}
-	assert.NoError(t, err)
-	assert.Contains(t, string(output), "El usuario \" Juan \" ha sido eliminado")
+	require.NoError(t, json.Unmarshal([]byte(output), &created))
+	assert.Equal(t, []string{"tasks:read"}, created.Scopes)
+	assert.True(t, strings.HasPrefix(created.Secret, "todo_"))
+
+	var stored int
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM api_tokens WHERE token_hash = ?", created.Secret).Scan(&stored)
+	assert.Zero(t, stored, "the secret must not be stored")
+
+	_, err = runTodo(t, dbPath, "token", "create", "--user", "juan", "--name", "ci")
+	assert.Error(t, err, "duplicate name")
+	_, err = runTodo(t, dbPath, "token", "create", "--user", "juan", "--name", "x", "--scope", "admin")
+	assert.Error(t, err, "unknown scope")
+
+	output, err = runTodo(t, dbPath, "token", "list", "--user", "juan")
+	require.NoError(t, err)
+	assert.Contains(t, output, "1. ci (todo_")
+	assert.Contains(t, output, "[tasks:write] caduca el")
+	assert.Contains(t, output, "2. lectura")
+	assert.NotContains(t, output, created.Secret)
+
+	output, err = runTodo(t, dbPath, "token", "list", "--user", "ana", "-o", "json")
+	require.NoError(t, err)
+	assert.Equal(t, "[]", strings.TrimSpace(output))
+
+	_, err = runTodo(t, dbPath, "token", "revoke", "--user", "ana", "ci")
+	assert.Error(t, err, "other users cannot revoke it")
+	output, err = runTodo(t, dbPath, "token", "revoke", "--user", "juan", "ci")
+	require.NoError(t, err)
+	assert.Contains(t, output, `Token "ci" revocado`)
+	_, err = runTodo(t, dbPath, "token", "revoke", "--user", "juan", "2")
+	require.NoError(t, err)
+
+	var count int
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM api_tokens").Scan(&count)
+	assert.Zero(t, count)
+}
+
+func TestUserTwoFactorReset(t *testing.T) {
+	dbPath := newTestDB(t)
+	id := createUser(t, dbPath, "juan")
+	createUser(t, dbPath, "ana")
+	execSQL(t, dbPath, "UPDATE users SET totp_secret = 'JBSWY3DPEHPK3PXP', totp_enabled_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	execSQL(t, dbPath, "INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, 'x', CURRENT_TIMESTAMP)", id)
+
+	_, err := runTodo(t, dbPath, "user", "2fa", "reset", "--username", "ana", "--force")
+	assert.Error(t, err, "ana does not have 2FA")
+
+	_, err = runTodoInput(t, dbPath, "n\n", "user", "2fa", "reset", "--username", "juan")
+	assert.Error(t, err)
+
+	output, err := runTodoInput(t, dbPath, "s\n", "user", "2fa", "reset", "--username", "juan")
+	require.NoError(t, err)
+	assert.Contains(t, output, `Verificación en dos pasos de "juan" desactivada`)
+
+	var secret sql.NullString
+	var codes int
+	queryRow(t, dbPath, "SELECT totp_secret FROM users WHERE id = ?", id).Scan(&secret)
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ?", id).Scan(&codes)
+	assert.False(t, secret.Valid)
+	assert.Zero(t, codes)
+}
+
+func TestUserUnlock(t *testing.T) {
+	dbPath := newTestDB(t)
+	createUser(t, dbPath, "juan")
+	execSQL(t, dbPath, "INSERT INTO login_throttles (scope, subject, failures, last_failure_at, locked_until) VALUES ('username', 'juan', 5, ?, ?)",
+		time.Now().UTC(), time.Now().Add(time.Hour).UTC())
+	execSQL(t, dbPath, "INSERT INTO login_failures (username, ip, reason, created_at) VALUES ('juan', '10.0.0.1', 'password', ?), ('ana', '10.0.0.2', 'unknown_user', ?)",
+		time.Now().UTC(), time.Now().UTC())
+
+	_, err := runTodo(t, dbPath, "user", "unlock")
+	assert.Error(t, err, "username or ip is required")
+	_, err = runTodo(t, dbPath, "user", "unlock", "--username", "juan", "--ip", "10.0.0.1")
+	assert.Error(t, err, "only one of username or ip")
+
+	output, err := runTodo(t, dbPath, "user", "unlock", "--username", "Juan")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"Juan" desbloqueado`)
+	var count int
+	queryRow(t, dbPath, "SELECT COUNT(*) FROM login_throttles").Scan(&count)
+	assert.Zero(t, count)
+
+	output, err = runTodo(t, dbPath, "user", "unlock", "--ip", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "no estaba bloqueado")
+
+	output, err = runTodo(t, dbPath, "user", "failed-logins")
+	require.NoError(t, err)
+	assert.Contains(t, output, "juan desde 10.0.0.1 (password)")
+	assert.Contains(t, output, "ana desde 10.0.0.2 (unknown_user)")
+
+	output, err = runTodo(t, dbPath, "user", "failed-logins", "--username", "ana", "-o", "json")
+	require.NoError(t, err)
+	var failures []struct {
+		Username string `json:"username"`
+		Reason   string `json:"reason"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &failures))
+	require.Len(t, failures, 1)
+	assert.Equal(t, "unknown_user", failures[0].Reason)
 }
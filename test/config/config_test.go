@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	cfg := config.Default()
+	assert.Equal(t, "todo.db", cfg.DBDSN)
+	assert.Equal(t, ":8080", cfg.ListenAddr)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadTOMLAndYAML(t *testing.T) {
+	tomlPath := writeFile(t, "todo.toml", "db_dsn = \"/tmp/a.db\"\nlisten_addr = \":9000\"\n")
+	cfg := config.Default()
+	require.NoError(t, cfg.LoadFile(tomlPath))
+	assert.Equal(t, "/tmp/a.db", cfg.DBDSN)
+	assert.Equal(t, ":9000", cfg.ListenAddr)
+	// Lo que no está en el fichero conserva el valor por defecto
+	assert.Equal(t, "web_templates", cfg.TemplatesDir)
+
+	yamlPath := writeFile(t, "todo.yaml", "db_dsn: /tmp/b.db\nlog_level: debug\n")
+	cfg = config.Default()
+	require.NoError(t, cfg.LoadFile(yamlPath))
+	assert.Equal(t, "/tmp/b.db", cfg.DBDSN)
+	assert.Equal(t, "debug", cfg.LogLevel)
+
+	assert.Error(t, cfg.LoadFile(writeFile(t, "todo.ini", "x=1")))
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	path := writeFile(t, "todo.toml", "db_dsn = \"fichero.db\"\nsession_secret = \"del-fichero\"\n")
+	t.Setenv("TODO_DB_DSN", "entorno.db")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "entorno.db", cfg.DBDSN)
+	assert.Equal(t, "del-fichero", cfg.SessionSecret)
+}
+
+func TestInvalidEnv(t *testing.T) {
+	t.Setenv("TODO_LOGIN_MAX_FAILURES_IP", "no")
+	t.Setenv("TODO_BCRYPT_COST", "caro")
+	t.Setenv("TODO_OIDC_AUTO_PROVISION", "quizá")
+
+	// Un valor inválido impide arrancar, igual que una duración inválida
+	_, err := config.Load(writeFile(t, "todo.toml", "bcrypt_cost = 12\n"))
+	require.Error(t, err)
+	assert.Equal(t, `valor inválido en TODO_BCRYPT_COST "caro": tiene que ser un número entero
+valor inválido en TODO_LOGIN_MAX_FAILURES_IP "no": tiene que ser un número entero
+valor inválido en TODO_OIDC_AUTO_PROVISION "quizá": usa true o false`, err.Error())
+
+	cfg := config.Default()
+	require.Error(t, cfg.LoadEnv(func(env string) (string, bool) {
+		return "x", env == "TODO_LOGIN_MAX_FAILURES"
+	}))
+	assert.Equal(t, 5, cfg.LoginMaxFailures, "invalid values are not applied")
+}
+
+func TestValidate(t *testing.T) {
+	cfg := config.Default()
+	cfg.LogLevel = "ruidoso"
+	assert.Error(t, cfg.Validate())
+
+	cfg = config.Default()
+	cfg.DBDSN = ""
+	assert.Error(t, cfg.Validate())
+}
+
+func TestOIDC(t *testing.T) {
+	t.Setenv("TODO_OIDC_ISSUER", "https://idp.example.com")
+	t.Setenv("TODO_OIDC_CLIENT_ID", "todo")
+	t.Setenv("TODO_OIDC_REDIRECT_URL", "https://todo.example.com/login/oidc/callback")
+	t.Setenv("TODO_OIDC_AUTO_PROVISION", "true")
+
+	cfg, err := config.Load(writeFile(t, "todo.toml", "oidc_name = \"Empresa\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com", cfg.OIDCIssuer)
+	assert.Equal(t, "Empresa", cfg.OIDCName)
+	assert.True(t, cfg.OIDCAutoProvision)
+
+	cfg.OIDCClientID = ""
+	assert.Error(t, cfg.Validate(), "the issuer needs a client ID")
+}
+
+func TestLoginLockout(t *testing.T) {
+	cfg := config.Default()
+	lockout, maxLockout, err := cfg.LoginLockouts()
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, lockout)
+	assert.Equal(t, time.Hour, maxLockout)
+	assert.Equal(t, 5, cfg.LoginMaxFailures)
+
+	t.Setenv("TODO_LOGIN_MAX_FAILURES", "10")
+	t.Setenv("TODO_LOGIN_TRUST_PROXY", "1")
+	cfg, err = config.Load(writeFile(t, "todo.yaml", "login_lockout: 30s\nlogin_lockout_max: 15m\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.LoginMaxFailures)
+	assert.Equal(t, 20, cfg.LoginMaxFailuresIP)
+	assert.True(t, cfg.LoginTrustProxy)
+	lockout, maxLockout, err = cfg.LoginLockouts()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, lockout)
+	assert.Equal(t, 15*time.Minute, maxLockout)
+
+	cfg.LoginLockoutMax = "10s"
+	assert.Error(t, cfg.Validate(), "the maximum is shorter than the lockout")
+	cfg.LoginLockoutMax = "1h"
+	cfg.LoginLockout = "pronto"
+	assert.Error(t, cfg.Validate())
+	cfg.LoginLockout = "1m"
+	cfg.LoginMaxFailures = -1
+	assert.Error(t, cfg.Validate())
+}
+
+func TestCredentials(t *testing.T) {
+	cfg := config.Default()
+	assert.Equal(t, 3, cfg.UsernameMinLength)
+	assert.Equal(t, 8, cfg.PasswordMinLength)
+	assert.Equal(t, bcrypt.DefaultCost, cfg.BcryptCost)
+	pattern, err := cfg.UsernameRegexp()
+	require.NoError(t, err)
+	assert.True(t, pattern.MatchString("ana.luisa"))
+
+	t.Setenv("TODO_PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("TODO_BREACHED_PASSWORDS_FILE", "/srv/pwned.txt")
+	cfg, err = config.Load(writeFile(t, "todo.toml", "username_pattern = \"\"\nbcrypt_cost = 12\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 12, cfg.PasswordMinLength)
+	assert.Equal(t, 12, cfg.BcryptCost)
+	assert.Equal(t, "/srv/pwned.txt", cfg.BreachedPasswordsFile)
+	pattern, err = cfg.UsernameRegexp()
+	require.NoError(t, err)
+	assert.Nil(t, pattern, "an empty pattern allows any username")
+
+	cfg.UsernamePattern = "[a-z"
+	assert.Error(t, cfg.Validate())
+	cfg.UsernamePattern = ""
+	cfg.BcryptCost = 40
+	assert.Error(t, cfg.Validate())
+	cfg.BcryptCost = bcrypt.DefaultCost
+	cfg.UsernameMaxLength = 2
+	assert.Error(t, cfg.Validate(), "the maximum is shorter than the minimum")
+	cfg.UsernameMaxLength = 0
+	assert.NoError(t, cfg.Validate(), "0 disables the maximum")
+	cfg.PasswordMinLength = -1
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,185 @@
+package credentials_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"golang.org/x/crypto/bcrypt"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+	return db
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestCheckUsername(t *testing.T) {
+	policy := credentials.DefaultPolicy
+
+	assert.Equal(t, "José", credentials.NormalizeUsername("  José "), "the username is trimmed and composed")
+
+	for _, username := range []string{"ana", "Ana.Luisa", "luis_2", "josé", "a-b"} {
+		assert.NoError(t, policy.CheckUsername(username), username)
+	}
+	for _, username := range []string{"", "ab", strings.Repeat("a", 33), "ana luisa", ".ana", "ana@casa"} {
+		assert.Error(t, policy.CheckUsername(username), username)
+	}
+
+	// Sin patrón ni límites solo se exige que no esté vacío
+	var none credentials.Policy
+	assert.NoError(t, none.CheckUsername("a b@c"))
+	assert.Error(t, none.CheckUsername(""))
+}
+
+func TestCheckPassword(t *testing.T) {
+	policy := credentials.DefaultPolicy
+
+	assert.NoError(t, policy.CheckPassword("correcto caballo", "ana"))
+	assert.NoError(t, policy.CheckPassword("ñandúñandú", "ana"), "the length is counted in characters")
+	assert.Error(t, policy.CheckPassword("", "ana"))
+	assert.Error(t, policy.CheckPassword("corta", "ana"))
+	assert.Error(t, policy.CheckPassword("AnaLuisa", "analuisa"), "the password equals the username")
+	assert.Error(t, policy.CheckPassword(strings.Repeat("x", 73), "ana"), "bcrypt ignores what is past 72 bytes")
+
+	policy.PasswordMinLength = 0
+	assert.NoError(t, policy.CheckPassword("x", "ana"))
+}
+
+func TestBreachedList(t *testing.T) {
+	breached := []string{"password", "123456", "qwerty123", "iloveyou"}
+
+	t.Run("Sorted", func(t *testing.T) {
+		var lines []string
+		for _, p := range breached {
+			lines = append(lines, sha1Hex(p)+":42")
+		}
+		// Relleno para que la búsqueda binaria tenga que saltar
+		var filler []string
+		for i := 0; i < 500; i++ {
+			filler = append(filler, strings.Repeat("r", i+1)+"relleno")
+			lines = append(lines, sha1Hex(filler[i])+":1")
+		}
+		sort.Strings(lines)
+		path := filepath.Join(t.TempDir(), "pwned.txt")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o644))
+
+		list, err := credentials.OpenBreachedList(path)
+		require.NoError(t, err)
+		for _, p := range append(breached, filler...) {
+			found, err := list.Contains(p)
+			require.NoError(t, err)
+			assert.True(t, found, p)
+		}
+		for _, p := range []string{"correcto caballo", "", "Password"} {
+			found, err := list.Contains(p)
+			require.NoError(t, err)
+			assert.False(t, found, p)
+		}
+	})
+
+	t.Run("Ranges", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, p := range breached {
+			hash := sha1Hex(p)
+			f, err := os.OpenFile(filepath.Join(dir, hash[:5]+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			require.NoError(t, err)
+			_, err = f.WriteString(hash[5:] + ":42\n")
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+		}
+
+		policy := credentials.DefaultPolicy
+		policy.Breached, _ = credentials.OpenBreachedList(dir)
+		assert.ErrorIs(t, policy.CheckBreached("qwerty123"), credentials.ErrBreached)
+		assert.NoError(t, policy.CheckBreached("correcto caballo"))
+	})
+
+	_, err := credentials.OpenBreachedList(filepath.Join(t.TempDir(), "no-existe.txt"))
+	assert.Error(t, err)
+	assert.NoError(t, credentials.DefaultPolicy.CheckBreached("password"), "without a list nothing is checked")
+}
+
+func TestRehash(t *testing.T) {
+	policy := credentials.DefaultPolicy
+	policy.BcryptCost = bcrypt.MinCost + 1
+
+	hash, err := policy.Hash("correcto caballo")
+	require.NoError(t, err)
+	cost, _ := bcrypt.Cost([]byte(hash))
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+	assert.False(t, policy.NeedsRehash(hash))
+
+	old, _ := bcrypt.GenerateFromPassword([]byte("correcto caballo"), bcrypt.MinCost)
+	assert.True(t, policy.NeedsRehash(string(old)))
+	assert.False(t, policy.NeedsRehash(""), "users without a password are left alone")
+}
+
+func TestUsernameNoCase(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+
+	ana := &models.User{Username: "Ana", PasswordHash: "x"}
+	require.NoError(t, ana.Insert(ctx, db, boil.Infer()))
+
+	taken, err := credentials.UsernameTaken(ctx, db, " ANA ")
+	require.NoError(t, err)
+	assert.True(t, taken)
+	taken, err = credentials.UsernameTaken(ctx, db, "luis")
+	require.NoError(t, err)
+	assert.False(t, taken)
+
+	user, err := credentials.FindUser(ctx, db, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, ana.ID, user.ID)
+	_, err = credentials.FindUser(ctx, db, "luis")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// La base de datos tampoco admite el mismo nombre con otras mayúsculas
+	assert.Error(t, (&models.User{Username: "aNA", PasswordHash: "x"}).Insert(ctx, db, boil.Infer()))
+	luis := &models.User{Username: "luis", PasswordHash: "x"}
+	require.NoError(t, luis.Insert(ctx, db, boil.Infer()))
+	luis.Username = "ANA"
+	_, err = luis.Update(ctx, db, boil.Whitelist(models.UserColumns.Username))
+	assert.Error(t, err)
+}
+
+func TestUsernameNoCaseLegacyDuplicates(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+
+	// Nombres duplicados de antes de la migración: gana el exacto
+	_, err := db.Exec("DROP TRIGGER users_username_nocase_insert")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users (username, password_hash) VALUES ('ana', 'x'), ('Ana', 'y')")
+	require.NoError(t, err)
+
+	user, err := credentials.FindUser(ctx, db, "Ana")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana", user.Username)
+	user, err = credentials.FindUser(ctx, db, "ANA")
+	require.NoError(t, err)
+	assert.Equal(t, "ana", user.Username, "otherwise the oldest one")
+}
@@ -0,0 +1,66 @@
+package due_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/due"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v8"
+)
+
+var madrid = time.FixedZone("CET", 3600)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2025-03-10T18:30:00Z", time.Date(2025, 3, 10, 18, 30, 0, 0, time.UTC)},
+		{"2025-03-10T18:30", time.Date(2025, 3, 10, 17, 30, 0, 0, time.UTC)},
+		{"2025-03-10 18:30", time.Date(2025, 3, 10, 17, 30, 0, 0, time.UTC)},
+		// Solo fecha: final del día
+		{"2025-03-10", time.Date(2025, 3, 10, 22, 59, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := due.Parse(tt.in, madrid)
+		require.NoError(t, err, tt.in)
+		assert.True(t, got.Valid)
+		assert.True(t, tt.want.Equal(got.Time), "%s: got %s", tt.in, got.Time)
+		assert.Equal(t, time.UTC, got.Time.Location())
+	}
+
+	empty, err := due.Parse("  ", madrid)
+	require.NoError(t, err)
+	assert.False(t, empty.Valid)
+
+	_, err = due.Parse("mañana", madrid)
+	assert.Error(t, err)
+}
+
+func TestLabel(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, madrid)
+	at := func(days int, hour int) null.Time {
+		return null.TimeFrom(time.Date(2025, 3, 10+days, hour, 0, 0, 0, madrid))
+	}
+
+	assert.Equal(t, "vence hoy a las 18:00", due.Label(at(0, 18), now))
+	assert.Equal(t, "venció hoy a las 09:00", due.Label(at(0, 9), now))
+	assert.Equal(t, "vence mañana", due.Label(at(1, 9), now))
+	assert.Equal(t, "venció ayer", due.Label(at(-1, 23), now))
+	assert.Equal(t, "vence en 5 días", due.Label(at(5, 0), now))
+	assert.Equal(t, "venció hace 3 días", due.Label(at(-3, 12), now))
+	assert.Equal(t, "", due.Label(null.Time{}, now))
+}
+
+func TestOverdue(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := null.TimeFrom(now.Add(-time.Hour))
+	future := null.TimeFrom(now.Add(time.Hour))
+
+	assert.True(t, due.Overdue(past, false, now))
+	assert.False(t, due.Overdue(past, true, now))
+	assert.False(t, due.Overdue(future, false, now))
+	assert.False(t, due.Overdue(null.Time{}, false, now))
+}
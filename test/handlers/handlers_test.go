@@ -1,15 +1,23 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/JorgeePG/todo-list/internal/handlers"
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 
 	_ "modernc.org/sqlite"
@@ -20,33 +28,22 @@ type App struct {
 	DB *sql.DB
 }
 
-// cleanDB crea una base de datos en memoria y las tablas necesarias
+// cleanDB crea una base de datos en memoria y aplica las migraciones
 func cleanDB(t *testing.T) *sql.DB {
 	db, err := sql.Open("sqlite", ":memory:")
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	// Con :memory: cada conexión es una base de datos distinta
+	db.SetMaxOpenConns(1)
+
 	_, err = db.Exec(`PRAGMA foreign_keys = ON;`)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = db.Exec(`
-	CREATE TABLE users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		username TEXT UNIQUE NOT NULL,
-		password_hash TEXT NOT NULL
-	);
-	CREATE TABLE tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		user_id INTEGER,
-		description TEXT,
-		completed BOOLEAN,
-		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
-	);
-	`)
-	if err != nil {
+	if err := migrations.Apply(context.Background(), db); err != nil {
 		t.Fatal(err)
 	}
 
@@ -196,3 +193,805 @@ func TestDeleteTaskHandlerBadPOST(t *testing.T) {
 	res = w.Result()
 	assert.Equal(t, http.StatusForbidden, res.StatusCode, "POST /deleteTask con ID no perteneciente al usuario: want %d, got %d", http.StatusBadRequest, res.StatusCode)
 }
+
+func TestIndexShowsOverdueTasks(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	_, err := h.Db.Exec("INSERT INTO tasks (title, done, user_id, due_at) VALUES (?, ?, ?, ?)",
+		"Vencida", false, 1, time.Now().UTC().Add(-72*time.Hour))
+	assert.NoError(t, err)
+	_, err = h.Db.Exec("INSERT INTO tasks (title, done, user_id, due_at) VALUES (?, ?, ?, ?)",
+		"Futura", false, 1, time.Now().UTC().Add(72*time.Hour))
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.Handler(w, req)
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, body, "venció hace 3 días")
+	assert.Contains(t, body, "vence en 3 días")
+	assert.Contains(t, body, `class="task-due overdue"`)
+}
+
+func TestAddTaskWithDueDate(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	req = httptest.NewRequest("POST", "/addTask", strings.NewReader("title=Informe&due_at=2030-05-01T09:30"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.AddTask(w, req)
+	assert.Equal(t, http.StatusSeeOther, w.Result().StatusCode)
+
+	var dueAt time.Time
+	err := h.Db.QueryRow("SELECT due_at FROM tasks WHERE title = ?", "Informe").Scan(&dueAt)
+	assert.NoError(t, err)
+	assert.True(t, dueAt.Equal(time.Date(2030, 5, 1, 9, 30, 0, 0, time.Local)))
+}
+
+func TestIndexOrdersByPriority(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	for _, form := range []string{"title=Normal", "title=Urgente&priority=urgent", "title=Baja&priority=low"} {
+		req = httptest.NewRequest("POST", "/addTask", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		h.AddTask(w, req)
+		assert.Equal(t, http.StatusSeeOther, w.Result().StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.Handler(w, req)
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, body, `class="task-priority priority-urgent"`)
+	urgent := strings.Index(body, ">Urgente</span>")
+	low := strings.Index(body, ">Baja</span>")
+	normal := strings.Index(body, ">Normal</span>")
+	assert.True(t, urgent < low && low < normal, "orden inesperado: %d %d %d", urgent, low, normal)
+}
+
+func TestIndexFiltersByTag(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	for _, form := range []string{"title=Informe&tags=trabajo", "title=Compra&tags=casa,recados", "title=Nada"} {
+		req = httptest.NewRequest("POST", "/addTask", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		h.AddTask(w, req)
+		assert.Equal(t, http.StatusSeeOther, w.Result().StatusCode)
+	}
+
+	get := func(target string) (int, string) {
+		req := httptest.NewRequest("GET", target, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		h.Handler(w, req)
+		return w.Result().StatusCode, w.Body.String()
+	}
+
+	status, body := get("/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `<a class="tag-chip" href="/?tag=recados">recados</a>`)
+	assert.Contains(t, body, `data-tags="casa, recados"`)
+
+	status, body = get("/?tag=-casa")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, ">Informe</span>")
+	assert.Contains(t, body, ">Nada</span>")
+	assert.NotContains(t, body, ">Compra</span>")
+	assert.Contains(t, body, "Quitar filtro")
+
+	status, _ = get("/?tag=-")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestIndexFiltersAndSorts(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	for _, form := range []string{"title=Banco", "title=Compra&description=leche", "title=Alquiler&priority=high"} {
+		req = httptest.NewRequest("POST", "/addTask", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		h.AddTask(w, req)
+		assert.Equal(t, http.StatusSeeOther, w.Result().StatusCode)
+	}
+	_, err := h.Db.Exec("UPDATE tasks SET done = 1 WHERE title = 'Banco'")
+	assert.NoError(t, err)
+
+	get := func(target string) (int, string) {
+		req := httptest.NewRequest("GET", target, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		h.Handler(w, req)
+		return w.Result().StatusCode, w.Body.String()
+	}
+
+	status, body := get("/?sort=title&order=desc")
+	assert.Equal(t, http.StatusOK, status)
+	compra := strings.Index(body, ">Compra</span>")
+	banco := strings.Index(body, ">Banco</span>")
+	alquiler := strings.Index(body, ">Alquiler</span>")
+	assert.True(t, compra < banco && banco < alquiler, "orden inesperado: %d %d %d", compra, banco, alquiler)
+
+	status, body = get("/?done=false&q=leche")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, ">Compra</span>")
+	assert.NotContains(t, body, ">Banco</span>")
+	assert.NotContains(t, body, ">Alquiler</span>")
+
+	status, _ = get("/?sort=color")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestIndexProjectSidebar(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	post := func(target, form string, handler http.HandlerFunc) *http.Response {
+		req := httptest.NewRequest("POST", target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	}
+
+	resp := post("/projects", "name=Casa", h.AddProject)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Equal(t, "/?project=1", resp.Header.Get("Location"))
+	assert.Equal(t, http.StatusConflict, post("/projects", "name=Casa", h.AddProject).StatusCode)
+
+	resp = post("/addTask", "title=Fregar&project_id=1", h.AddTask)
+	assert.Equal(t, "/?project=1", resp.Header.Get("Location"))
+	post("/addTask", "title=Barrer&project_id=1&done=on", h.AddTask)
+	post("/addTask", "title=Informe", h.AddTask)
+
+	req = httptest.NewRequest("GET", "/?project=1", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.Handler(w, req)
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, body, `<a href="/?project=1">Casa <span class="project-count">1/2</span></a>`)
+	assert.Contains(t, body, `Todas <span class="project-count">2/3</span>`)
+	assert.Contains(t, body, `Sin proyecto <span class="project-count">1/1</span>`)
+	assert.Contains(t, body, ">Fregar</span>")
+	assert.NotContains(t, body, ">Informe</span>")
+	assert.Contains(t, body, `href="/addTask?project=1"`)
+}
+
+func TestIndexSubtasks(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	post := func(target, form string, handler http.HandlerFunc) *http.Response {
+		req := httptest.NewRequest("POST", target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	}
+
+	post("/addTask", "title=Mudanza", h.AddTask)
+	resp := post("/addTask", "parent_id=1&title=Cajas", h.AddTask)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	post("/addTask", "parent_id=1&title=Camión", h.AddTask)
+	post("/addTask", "parent_id=1&title=Cinta", h.AddTask)
+	assert.Equal(t, http.StatusBadRequest, post("/addTask", "parent_id=2&title=Nieta", h.AddTask).StatusCode)
+	assert.Equal(t, http.StatusForbidden, post("/addTask", "parent_id=99&title=Huérfana", h.AddTask).StatusCode)
+	post("/update", "id=2&title=Cajas&done=on", h.UpdateTask)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.Handler(w, req)
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, body, `<span class="subtask-progress " title="Subtareas hechas">1/3</span>`)
+	assert.Contains(t, body, `<ul class="subtask-list" data-parent="1">`)
+	assert.Contains(t, body, `<span class="subtask-title completed">Cajas</span>`)
+	assert.Contains(t, body, `<span class="subtask-title ">Cinta</span>`)
+	// Las subtareas no aparecen como tareas sueltas
+	assert.Equal(t, 1, strings.Count(body, `class="task-info"`))
+	assert.Contains(t, body, `Todas <span class="project-count">1/1</span>`)
+}
+
+func TestRecurringTasks(t *testing.T) {
+	h := newTestHandler(t)
+	h.Now = func() time.Time { return time.Date(2025, 3, 12, 18, 0, 0, 0, time.Local) }
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	post := func(target, form string, handler http.HandlerFunc) *http.Response {
+		req := httptest.NewRequest("POST", target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	}
+
+	resp := post("/addTask", "title=Basura&due_at=2025-03-10T21:00&recurrence=FREQ%3DWEEKLY%3BBYDAY%3DMO%2CTH", h.AddTask)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, post("/addTask", "title=Mala&recurrence=FREQ%3DYEARLY", h.AddTask).StatusCode)
+
+	// Completarla crea la siguiente repetición; volver a guardarla hecha no
+	assert.Equal(t, http.StatusOK, post("/update", "id=1&title=Basura&done=on&due_at=2025-03-10T21:00", h.UpdateTask).StatusCode)
+	assert.Equal(t, http.StatusOK, post("/update", "id=1&title=Basura&done=on&due_at=2025-03-10T21:00", h.UpdateTask).StatusCode)
+
+	var count int
+	var dueAt time.Time
+	var rule string
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM tasks").Scan(&count))
+	assert.Equal(t, 2, count)
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT due_at, recurrence FROM tasks WHERE id = 2").Scan(&dueAt, &rule))
+	assert.True(t, time.Date(2025, 3, 13, 21, 0, 0, 0, time.Local).Equal(dueAt), "got %s", dueAt)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH", rule)
+
+	resp = post("/skip", "id=2", h.SkipTask)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT due_at FROM tasks WHERE id = 2").Scan(&dueAt))
+	assert.True(t, time.Date(2025, 3, 17, 21, 0, 0, 0, time.Local).Equal(dueAt), "got %s", dueAt)
+	assert.Equal(t, http.StatusConflict, post("/skip", "id=1", h.SkipTask).StatusCode)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.Handler(w, req)
+	body := w.Body.String()
+	assert.Contains(t, body, `<span class="task-recurrence" title="FREQ=WEEKLY;BYDAY=MO,TH">&#8635; cada semana (lu, ju)</span>`)
+	assert.Equal(t, 1, strings.Count(body, `action="/skip"`))
+
+	// Un formulario con la regla vacía detiene la serie
+	post("/update", "id=2&title=Basura&due_at=2025-03-17T21:00&recurrence=", h.UpdateTask)
+	var stopped sql.NullString
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT recurrence FROM tasks WHERE id = 2").Scan(&stopped))
+	assert.False(t, stopped.Valid)
+}
+
+func TestAddTaskTitleLength(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	add := func(title string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/addTask", strings.NewReader("title="+title))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		h.AddTask(w, req)
+		return w
+	}
+
+	// El mismo límite que en la CLI y la API
+	w = add(strings.Repeat("a", 201))
+	assert.Contains(t, w.Body.String(), "el título no puede superar los 200 caracteres")
+	w = add("+")
+	assert.Contains(t, w.Body.String(), "el título no puede estar vacío")
+	var count int
+	assert.NoError(t, h.Db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&count))
+	assert.Zero(t, count)
+
+	w = add("++Informe++")
+	assert.Equal(t, http.StatusSeeOther, w.Result().StatusCode)
+	var title string
+	assert.NoError(t, h.Db.QueryRow("SELECT title FROM tasks").Scan(&title))
+	assert.Equal(t, "Informe", title, "the title is trimmed")
+}
+
+func TestUpdateTaskOnlySuppliedFields(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	post := func(target, form string, handler http.HandlerFunc) *http.Response {
+		req := httptest.NewRequest("POST", target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	}
+
+	post("/addTask", "title=Informe&priority=high&due_at=2025-03-10T09:30&tags=trabajo", h.AddTask)
+
+	// Marcarla como hecha no cambia el resto de campos
+	assert.Equal(t, http.StatusOK, post("/update", "id=1&done=on", h.UpdateTask).StatusCode)
+	var title string
+	var done bool
+	var level int64
+	var dueAt sql.NullTime
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT title, done, priority, due_at FROM tasks WHERE id = 1").Scan(&title, &done, &level, &dueAt))
+	assert.Equal(t, "Informe", title)
+	assert.True(t, done)
+	assert.Equal(t, int64(3), level)
+	assert.True(t, dueAt.Valid)
+	var tags int
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM task_tags WHERE task_id = 1").Scan(&tags))
+	assert.Equal(t, 1, tags)
+
+	// Y cambiar el título no la vuelve a dejar pendiente
+	assert.Equal(t, http.StatusOK, post("/update", "id=1&title=Informe+final", h.UpdateTask).StatusCode)
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT title, done FROM tasks WHERE id = 1").Scan(&title, &done))
+	assert.Equal(t, "Informe final", title)
+	assert.True(t, done)
+
+	assert.Equal(t, http.StatusBadRequest, post("/update", "id=1&title=", h.UpdateTask).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, post("/update", "id=1&title="+strings.Repeat("a", 201), h.UpdateTask).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, post("/update", "title=Sin+ID", h.UpdateTask).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, post("/update", "id=1&color=rojo", h.UpdateTask).StatusCode)
+	assert.Equal(t, http.StatusForbidden, post("/update", "id=9&done=on", h.UpdateTask).StatusCode)
+}
+
+func TestTaskNotesPage(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	do := func(method, target, form, id string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if id != "" {
+			req = mux.SetURLVars(req, map[string]string{"id": id})
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	resp := do("POST", "/addTask", "title=Viaje&description=%2A%2Amaleta%2A%2A+%3Cscript%3Ealert(1)%3C%2Fscript%3E", "", h.AddTask)
+	assert.Equal(t, http.StatusSeeOther, resp.Code)
+
+	w = do("GET", "/tasks/1", "", "1", h.TaskPage)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `<div class="notes-body"><p><strong>maleta</strong> &lt;script&gt;alert(1)&lt;/script&gt;</p>`)
+	assert.NotContains(t, body, "<script>alert")
+	assert.Contains(t, body, "**maleta** &lt;script&gt;alert(1)&lt;/script&gt;</textarea>")
+
+	// Guardar desde la página de detalle
+	resp = do("POST", "/tasks/1", "description=-+billetes%0D%0A-+hotel", "1", h.UpdateTaskNotes)
+	assert.Equal(t, http.StatusSeeOther, resp.Code)
+	assert.Equal(t, "/tasks/1", resp.Header().Get("Location"))
+	var description string
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT description FROM tasks WHERE id = 1").Scan(&description))
+	assert.Equal(t, "- billetes\n- hotel", description)
+
+	// El formulario de index.html no incluye las notas y no las borra
+	do("POST", "/update", "id=1&title=Viaje+a+Roma", "", h.UpdateTask)
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT description FROM tasks WHERE id = 1").Scan(&description))
+	assert.Equal(t, "- billetes\n- hotel", description)
+
+	w = do("GET", "/", "", "", h.Handler)
+	assert.Contains(t, w.Body.String(), `<a href="/tasks/1" class="notes-link has-notes" title="Ver notas">Notas</a>`)
+
+	w = do("POST", "/tasks/1", "description="+strings.Repeat("a", notes.MaxLength+1), "1", h.UpdateTaskNotes)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `<div class="error-message">`)
+
+	assert.Equal(t, http.StatusForbidden, do("GET", "/tasks/99", "", "99", h.TaskPage).Code)
+}
+
+func TestTaskConflicts(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	do := func(target, form, etag, id string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if id != "" {
+			req = mux.SetURLVars(req, map[string]string{"id": id})
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	do("/addTask", "title=Informe", "", "", h.AddTask)
+	req = httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.Handler(w, req)
+	assert.Contains(t, w.Body.String(), `data-etag="&#34;1&#34;"`)
+
+	// Dos pestañas con la misma versión: la segunda en guardar recibe 412
+	w = do("/update", "id=1&title=Informe+final", `"1"`, "", h.UpdateTask)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEqual(t, `"1"`, etag)
+	w = do("/update", "id=1&title=Otro+informe", `"1"`, "", h.UpdateTask)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	var title string
+	assert.NoError(t, h.Db.QueryRowContext(context.Background(), "SELECT title FROM tasks WHERE id = 1").Scan(&title))
+	assert.Equal(t, "Informe final", title)
+
+	// Las notas de task.html llevan la versión en el formulario
+	w = do("/tasks/1", "description=Nuevas&version=1", "", "1", h.UpdateTaskNotes)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "La tarea ha cambiado")
+	assert.Contains(t, w.Body.String(), `Nuevas</textarea>`)
+	version := strings.Trim(etag, `"`)
+	assert.Contains(t, w.Body.String(), `name="version" value="`+version+`"`)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	w = do("/tasks/1", "description=Nuevas&version="+version, "", "1", h.UpdateTaskNotes)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+}
+
+func TestSearchPage(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	do := func(method, target, form string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	do("POST", "/addTask", "title=Comprar+leche", h.AddTask)
+	do("POST", "/addTask", "title=Ir+al+s%C3%BAper&description=pan+y+%3Cb%3Eleche%3C%2Fb%3E", h.AddTask)
+	do("POST", "/addTask", "title=Llamar+al+banco", h.AddTask)
+
+	w = do("GET", "/", "", h.Handler)
+	assert.Contains(t, w.Body.String(), `<form method="GET" action="/search" class="search-form">`)
+
+	w = do("GET", "/search?q=leche", "", h.SearchPage)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `<a href="/tasks/1" class="task-title ">Comprar leche</a>`)
+	assert.Contains(t, body, `<p class="search-snippet">Comprar <mark>leche</mark></p>`)
+	assert.Contains(t, body, `<p class="search-snippet">pan y &lt;b&gt;<mark>leche</mark>&lt;/b&gt;</p>`)
+	assert.NotContains(t, body, "banco")
+	assert.Less(t, strings.Index(body, "/tasks/1"), strings.Index(body, "/tasks/2"))
+
+	w = do("GET", "/search?q=nada", "", h.SearchPage)
+	assert.Contains(t, w.Body.String(), "No hay tareas que coincidan con «nada».")
+}
+
+func TestSettingsTokens(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	do := func(method, target, form string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w = do("GET", "/settings", "", h.SettingsPage)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<a href="/settings">Ajustes</a>`)
+	assert.Contains(t, w.Body.String(), "No tienes tokens.")
+
+	w = do("POST", "/settings/tokens", "name=ci&scopes=tasks%3Aread&scopes=tasks%3Awrite&expires_at=30d", h.CreateToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Token «ci» creado")
+	assert.Contains(t, body, `<div class="token-secret">`)
+	assert.Contains(t, body, "tasks:read tasks:write")
+	assert.Contains(t, body, "caduca el")
+
+	// El secreto solo se muestra al crearlo
+	w = do("GET", "/settings", "", h.SettingsPage)
+	assert.NotContains(t, w.Body.String(), `<div class="token-secret">`)
+	assert.Contains(t, w.Body.String(), "sin usar")
+
+	w = do("POST", "/settings/tokens", "name=ci&scopes=tasks%3Aread", h.CreateToken)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Ya existe un token con ese nombre")
+	w = do("POST", "/settings/tokens", "name=otro", h.CreateToken)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	revoke := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/settings/tokens/"+id+"/revoke", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		h.RevokeToken(w, mux.SetURLVars(req, map[string]string{"id": id}))
+		return w
+	}
+	assert.Equal(t, http.StatusForbidden, revoke("9").Code)
+	w = revoke("1")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	w = do("GET", "/settings", "", h.SettingsPage)
+	assert.Contains(t, w.Body.String(), "No tienes tokens.")
+}
+
+func TestTwoFactor(t *testing.T) {
+	h := newTestHandler(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	h.Now = func() time.Time { return now }
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	cookies := w.Result().Cookies()
+
+	// do guarda las cookies de la respuesta, como un navegador
+	do := func(method, target, form string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if c := w.Result().Cookies(); len(c) > 0 {
+			cookies = c
+		}
+		return w
+	}
+	code := func(secret string) string {
+		c, err := totp.GenerateCode(secret, now)
+		assert.NoError(t, err)
+		return c
+	}
+
+	w = do("GET", "/settings", "", h.SettingsPage)
+	assert.Contains(t, w.Body.String(), `action="/settings/2fa"`)
+
+	// Alta: secreto y QR, y se activa con el primer código
+	assert.Equal(t, http.StatusSeeOther, do("POST", "/settings/2fa", "", h.EnrollTwoFactor).Code)
+	w = do("GET", "/settings", "", h.SettingsPage)
+	m := regexp.MustCompile(`Secreto: <code>([A-Z2-7]+)</code>`).FindStringSubmatch(w.Body.String())
+	if !assert.Len(t, m, 2) {
+		return
+	}
+	secret := m[1]
+	assert.Contains(t, w.Body.String(), "otpauth://totp/todo-list:testuser?")
+	w = do("GET", "/settings/2fa/qr.png", "", h.TwoFactorQR)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+
+	w = do("POST", "/settings/2fa/confirm", "code=000000", h.ConfirmTwoFactor)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do("POST", "/settings/2fa/confirm", "code="+code(secret), h.ConfirmTwoFactor)
+	assert.Equal(t, http.StatusOK, w.Code)
+	recovery := regexp.MustCompile(`<li><code>([a-z2-7]{4}-[a-z2-7]{4})</code></li>`).FindAllStringSubmatch(w.Body.String(), -1)
+	assert.Len(t, recovery, 10)
+
+	// Los códigos de recuperación solo se muestran una vez
+	w = do("GET", "/settings", "", h.SettingsPage)
+	assert.Contains(t, w.Body.String(), "Te quedan 10 códigos de recuperación")
+	assert.NotContains(t, w.Body.String(), `class="recovery-codes"`)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/settings/2fa/qr.png", "", h.TwoFactorQR).Code)
+
+	// El login pide el segundo paso antes de iniciar la sesión
+	do("GET", "/logout", "", h.LogoutHandler)
+	w = do("POST", "/login", "username=testuser&password=testpass", h.LoginHandler)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/login/2fa", w.Header().Get("Location"))
+	w = do("GET", "/", "", h.Handler)
+	assert.Equal(t, "/login", w.Header().Get("Location"))
+
+	w = do("GET", "/login/2fa", "", h.LoginTwoFactor)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = do("POST", "/login/2fa", "code=123456", h.LoginTwoFactor)
+	assert.Contains(t, w.Body.String(), "Código incorrecto")
+	now = now.Add(time.Minute)
+	w = do("POST", "/login/2fa", "code="+code(secret), h.LoginTwoFactor)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/", w.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, do("GET", "/", "", h.Handler).Code)
+
+	// Sin login pendiente, el segundo paso vuelve al login
+	do("GET", "/logout", "", h.LogoutHandler)
+	w = do("GET", "/login/2fa", "", h.LoginTwoFactor)
+	assert.Equal(t, "/login", w.Header().Get("Location"))
+
+	// Un código de recuperación también vale para entrar y para desactivarla
+	do("POST", "/login", "username=testuser&password=testpass", h.LoginHandler)
+	w = do("POST", "/login/2fa", "code="+recovery[0][1], h.LoginTwoFactor)
+	assert.Equal(t, "/", w.Header().Get("Location"))
+	w = do("POST", "/settings/2fa/disable", "code="+recovery[0][1], h.DisableTwoFactor)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do("POST", "/settings/2fa/disable", "code="+recovery[1][1], h.DisableTwoFactor)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+
+	do("GET", "/logout", "", h.LogoutHandler)
+	w = do("POST", "/login", "username=testuser&password=testpass", h.LoginHandler)
+	assert.Equal(t, "/", w.Header().Get("Location"))
+}
+
+func TestLoginLockout(t *testing.T) {
+	h := newTestHandler(t)
+	h.Lockout = &lockout.Policy{MaxFailures: 2, MaxFailuresIP: 10, Lockout: 5 * time.Minute, MaxLockout: time.Hour}
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader("username=testuser&password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.RegisterHandler(httptest.NewRecorder(), req)
+
+	login := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/login", strings.NewReader("username=testuser&password="+password))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.LoginHandler(w, req)
+		return w
+	}
+
+	w := login("mal")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Usuario o contraseña incorrectos")
+
+	w = login("mal")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "300", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "vuelve a intentarlo en 5 minutos")
+
+	w = login("testpass")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRegisterValidation(t *testing.T) {
+	h := newTestHandler(t)
+
+	register := func(form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/register", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.RegisterHandler(w, req)
+		return w
+	}
+
+	w := register("username=&password=")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "el nombre de usuario no puede estar vacío")
+	assert.Contains(t, w.Body.String(), "la contraseña no puede estar vacía")
+
+	// El nombre escrito se conserva en el formulario
+	w = register("username=+Ana+&password=corta")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "la contraseña tiene que tener al menos 8 caracteres")
+	assert.Contains(t, w.Body.String(), `value="Ana"`)
+
+	w = register("username=Ana&password=correcto+caballo")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+
+	w = register("username=ana&password=otro+caballo")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Ya existe un usuario con ese nombre")
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader("username=ANA&password=correcto+caballo"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h.LoginHandler(w, req)
+	assert.Equal(t, http.StatusSeeOther, w.Code, "the login ignores case")
+}
@@ -0,0 +1,95 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/jsonpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+// Los ejemplos son los del apéndice A de RFC 6902.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"Añadir clave", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"Añadir en array", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"Añadir al final", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"Quitar clave", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"Quitar de array", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"Sustituir", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"Sustituir por null", `{"baz":"qux"}`, `[{"op":"replace","path":"/baz","value":null}]`, `{"baz":null}`},
+		{"Mover", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"Mover en array", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"Copiar", `{"foo":["a"]}`, `[{"op":"copy","from":"/foo","path":"/bar"},{"op":"add","path":"/bar/-","value":"b"}]`, `{"bar":["a","b"],"foo":["a"]}`},
+		{"Test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"Escapes", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+		{"Documento entero", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":1}}]`, `{"baz":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.Decode([]byte(tt.patch))
+			require.NoError(t, err)
+			doc := decode(t, tt.doc)
+			got, err := patch.Apply(doc)
+			require.NoError(t, err)
+			assert.Equal(t, decode(t, tt.want), got)
+			// El documento original no cambia
+			assert.Equal(t, decode(t, tt.doc), doc)
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"Test falla", `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{"Test de número", `[{"op":"test","path":"/foo/1","value":"2"}]`},
+		{"Clave inexistente", `[{"op":"remove","path":"/nada"}]`},
+		{"Sustituir inexistente", `[{"op":"replace","path":"/nada","value":1}]`},
+		{"Padre inexistente", `[{"op":"add","path":"/nada/baz","value":1}]`},
+		{"Fuera del array", `[{"op":"add","path":"/foo/4","value":1}]`},
+		{"Posición con ceros", `[{"op":"remove","path":"/foo/01"}]`},
+		{"Posición con signo", `[{"op":"remove","path":"/foo/+1"}]`},
+		{"Guion al quitar", `[{"op":"remove","path":"/foo/-"}]`},
+		{"Dentro de un texto", `[{"op":"add","path":"/baz/x","value":1}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.Decode([]byte(tt.patch))
+			require.NoError(t, err)
+			_, err = patch.Apply(decode(t, `{"baz":"qux","foo":["a",2,"c"]}`))
+			assert.ErrorIs(t, err, jsonpatch.ErrFailed)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, patch := range []string{
+		``,
+		`{"op":"add","path":"/a","value":1}`,
+		`null`,
+		`[{"op":"sumar","path":"/a"}]`,
+		`[{"op":"add","path":"a","value":1}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","from":"/a","path":"/a/b"}]`,
+		`[{"op":"copy","from":"a","path":"/b"}]`,
+	} {
+		_, err := jsonpatch.Decode([]byte(patch))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalid, patch)
+	}
+}
@@ -0,0 +1,150 @@
+package lockout_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/lockout"
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+	return db
+}
+
+var policy = lockout.Policy{
+	MaxFailures:   3,
+	MaxFailuresIP: 10,
+	Lockout:       time.Minute,
+	MaxLockout:    10 * time.Minute,
+}
+
+func TestBackoff(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	fail := func(username, ip string) time.Duration {
+		t.Helper()
+		wait, err := policy.Fail(ctx, db, username, ip, lockout.ReasonPassword, now)
+		require.NoError(t, err)
+		return wait
+	}
+	locked := func(username, ip string) time.Duration {
+		t.Helper()
+		wait, err := policy.Locked(ctx, db, username, ip, now)
+		require.NoError(t, err)
+		return wait
+	}
+
+	assert.Zero(t, fail("ana", "10.0.0.1"))
+	assert.Zero(t, fail("Ana ", "10.0.0.2"), "the username is normalised")
+	assert.Zero(t, locked("ana", "10.0.0.1"))
+	assert.Equal(t, time.Minute, fail("ana", "10.0.0.3"))
+	assert.Equal(t, time.Minute, locked("ANA", "10.0.0.9"))
+	assert.Zero(t, locked("luis", "10.0.0.1"), "other users are not locked")
+
+	// Cada fallo más dobla el bloqueo, hasta MaxLockout
+	for _, want := range []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+		now = now.Add(locked("ana", ""))
+		assert.Zero(t, locked("ana", ""))
+		assert.Equal(t, want, fail("ana", "10.0.0.4"))
+	}
+
+	// Tras MaxLockout sin fallos, se olvidan
+	now = now.Add(11 * time.Minute)
+	assert.Zero(t, fail("ana", "10.0.0.5"))
+
+	// Un login correcto pone a cero los fallos del usuario
+	fail("ana", "10.0.0.5")
+	require.NoError(t, lockout.Succeed(ctx, db, "ana"))
+	assert.Zero(t, fail("ana", "10.0.0.5"))
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	// Igual que el login: la misma letra escrita de dos formas es el mismo
+	// nombre, y solo las mayúsculas ASCII son el mismo usuario
+	assert.Equal(t, "josé", lockout.NormalizeUsername(" Jose\u0301 "))
+	assert.Equal(t, lockout.NormalizeUsername("jos\u00e9"), lockout.NormalizeUsername("JOSe\u0301"))
+	assert.NotEqual(t, lockout.NormalizeUsername("josé"), lockout.NormalizeUsername("JOSÉ"))
+	assert.NotEqual(t, lockout.NormalizeUsername("ωmega"), lockout.NormalizeUsername("Ωmega"))
+}
+
+func TestIPLimit(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	// Probar un usuario distinto cada vez no esquiva el límite de la IP
+	var wait time.Duration
+	for i := 0; i < policy.MaxFailuresIP; i++ {
+		var err error
+		wait, err = policy.Fail(ctx, db, "usuario"+string(rune('a'+i)), "10.0.0.1", lockout.ReasonUnknownUser, now)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, time.Minute, wait)
+	wait, err := policy.Locked(ctx, db, "otro", "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+
+	// Un login correcto no desbloquea la IP
+	require.NoError(t, lockout.Succeed(ctx, db, "usuarioa"))
+	wait, err = policy.Locked(ctx, db, "usuarioa", "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+
+	unlocked, err := lockout.Unlock(ctx, db, lockout.ScopeIP, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.True(t, unlocked)
+	wait, err = policy.Locked(ctx, db, "otro", "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	unlocked, err = lockout.Unlock(ctx, db, lockout.ScopeIP, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.False(t, unlocked)
+
+	// Con el límite a 0 no se bloquea
+	disabled := lockout.Policy{Lockout: time.Minute, MaxLockout: time.Hour}
+	for i := 0; i < 50; i++ {
+		wait, err = disabled.Fail(ctx, db, "ana", "10.0.0.2", lockout.ReasonPassword, now)
+		require.NoError(t, err)
+	}
+	assert.Zero(t, wait)
+}
+
+func TestFailures(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, lockout.Record(ctx, db, "Ana", "10.0.0.1", lockout.ReasonPassword, now))
+	require.NoError(t, lockout.Record(ctx, db, "luis", "10.0.0.2", lockout.ReasonUnknownUser, now))
+	require.NoError(t, lockout.Record(ctx, db, "ana", "10.0.0.3", lockout.ReasonOTP, now.Add(time.Second)))
+
+	failures, err := lockout.Failures(ctx, db, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, failures, 3)
+
+	failures, err = lockout.Failures(ctx, db, " ANA", 10)
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	assert.Equal(t, lockout.ReasonOTP, failures[0].Reason)
+	assert.Equal(t, "ana", failures[1].Username)
+	assert.Equal(t, "10.0.0.1", failures[1].IP)
+
+	failures, err = lockout.Failures(ctx, db, "", 1)
+	require.NoError(t, err)
+	assert.Len(t, failures, 1)
+}
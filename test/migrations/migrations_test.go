@@ -0,0 +1,172 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func memoryDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	require.NoError(t, err)
+	return n == 1
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"0001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"0002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+		"0002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"README.md":       {Data: []byte("ignorado")},
+	}
+}
+
+func TestApplyEmbeddedMigrations(t *testing.T) {
+	db := memoryDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, migrations.Apply(ctx, db))
+	assert.True(t, tableExists(t, db, "tasks"))
+	assert.True(t, tableExists(t, db, "users"))
+
+	// Aplicar de nuevo no debe hacer nada
+	m, err := migrations.New(db)
+	require.NoError(t, err)
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestEmbeddedMigrationsRoundTrip(t *testing.T) {
+	db := memoryDB(t)
+	ctx := context.Background()
+
+	m, err := migrations.New(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	// Todas las migraciones embebidas deben poder revertirse y reaplicarse
+	reverted, err := m.Down(ctx, len(m.Migrations()))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(m.Migrations()))
+	assert.False(t, tableExists(t, db, "tasks"))
+
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.True(t, tableExists(t, db, "tasks"))
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := memoryDB(t)
+	ctx := context.Background()
+
+	m, err := migrations.NewFromFS(db, testFS())
+	require.NoError(t, err)
+	require.Len(t, m.Migrations(), 2)
+
+	initialized, err := m.Initialized(ctx)
+	require.NoError(t, err)
+	assert.False(t, initialized)
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.True(t, tableExists(t, db, "b"))
+
+	initialized, err = m.Initialized(ctx)
+	require.NoError(t, err)
+	assert.True(t, initialized)
+
+	reverted, err := m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, 2, reverted[0].Version)
+	assert.False(t, tableExists(t, db, "b"))
+	assert.True(t, tableExists(t, db, "a"))
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	pending, err := m.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 2, pending[0].Version)
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	db := memoryDB(t)
+	ctx := context.Background()
+
+	fsys := testFS()
+	fsys["0002_b.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE b (id INTEGER); INSERT INTO nope VALUES (1);")}
+	m, err := migrations.NewFromFS(db, fsys)
+	require.NoError(t, err)
+
+	applied, err := m.Up(ctx)
+	assert.Error(t, err)
+	assert.Len(t, applied, 1)
+	assert.False(t, tableExists(t, db, "b"))
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, statuses[1].Applied)
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	db := memoryDB(t)
+	ctx := context.Background()
+
+	m, err := migrations.NewFromFS(db, testFS())
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	fsys := testFS()
+	fsys["0001_a.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id INTEGER, extra TEXT);")}
+	m, err = migrations.NewFromFS(db, fsys)
+	require.NoError(t, err)
+
+	_, err = m.Up(ctx)
+	assert.ErrorIs(t, err, migrations.ErrChecksumMismatch)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Modified)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_init.up.sql"), []byte("SELECT 1;"), 0o644))
+
+	up, down, err := migrations.Create(dir, "Add Due Dates")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "0002_add_due_dates.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "0002_add_due_dates.down.sql"), down)
+
+	loaded, err := migrations.Load(os.DirFS(dir))
+	require.NoError(t, err)
+	assert.Len(t, loaded, 2)
+}
@@ -0,0 +1,58 @@
+package notes_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/notes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Vacío", "  \n", ""},
+		{"Formato", "**negrita** _cursiva_ `a<b>` ~~no~~", "<p><strong>negrita</strong> <em>cursiva</em> <code>a&lt;b&gt;</code> <del>no</del></p>\n"},
+		{"Lista", "- uno\n- dos", "<ul>\n<li>uno</li>\n<li>dos</li>\n</ul>\n"},
+		{"Enlace", "[web](https://example.com/?a=1&b=2)", `<p><a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener noreferrer">web</a></p>` + "\n"},
+		{"Enlace relativo", "[otra](/tasks/2)", `<p><a href="/tasks/2" rel="nofollow noopener noreferrer">otra</a></p>` + "\n"},
+		{"Mailto", "[correo](mailto:ana@example.com)", `<p><a href="mailto:ana@example.com" rel="nofollow noopener noreferrer">correo</a></p>` + "\n"},
+		{"Autoenlace", "<https://example.com>", `<p><a href="https://example.com" rel="nofollow noopener noreferrer">https://example.com</a></p>` + "\n"},
+		{"Bloque HTML", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"HTML en línea", `hola <b onclick="x()">sí</b>`, "<p>hola &lt;b onclick=&#34;x()&#34;&gt;sí&lt;/b&gt;</p>\n"},
+		{"Esquema no permitido", "[x](javascript:alert%281%29)", "<p>x</p>\n"},
+		{"Data URI", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>\n"},
+		{"Sin esquema", "[x](//evil.example.com)", "<p>x</p>\n"},
+		{"Imagen", `![logo](https://example.com/logo.png "t")`, `<p><a href="https://example.com/logo.png" rel="nofollow noopener noreferrer">logo</a></p>` + "\n"},
+		{"Código con lenguaje", "```\"><script>\nx < y\n```", "<pre><code>x &lt; y\n</code></pre>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(notes.Render(tt.in)))
+		})
+	}
+}
+
+func TestRenderNoUnsafeOutput(t *testing.T) {
+	in := "# T\n\n<div style=\"color:red\">x</div>\n\n<img src=x onerror=alert(1)>\n\n" +
+		"[a](JaVaScRiPt:alert(1)) [b](vbscript:x) ![c](javascript:x)\n\n| a | b |\n|:--|--:|\n| 1 | 2 |\n"
+	out := strings.ToLower(string(notes.Render(in)))
+	// El HTML del usuario queda como texto
+	for _, bad := range []string{"<script", "<img", "<div", "href=\"javascript", "href=\"vbscript"} {
+		assert.NotContains(t, out, bad)
+	}
+	assert.Contains(t, out, "&lt;img src=x onerror=alert(1)&gt;")
+	assert.Contains(t, out, `<th align="left">a</th>`)
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := notes.Normalize("  línea 1\r\nlínea 2\r\n\r\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "línea 1\nlínea 2", got)
+
+	_, err = notes.Normalize(strings.Repeat("a", notes.MaxLength+1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package priority_test
+
+import (
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/priority"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", priority.None},
+		{"none", priority.None},
+		{"low", priority.Low},
+		{"Medium", priority.Medium},
+		{" alta ", priority.High},
+		{"urgente", priority.Urgent},
+		{"4", priority.Urgent},
+	}
+	for _, tt := range tests {
+		got, err := priority.Parse(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"muy alta", "5", "-1"} {
+		_, err := priority.Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestNameAndLabel(t *testing.T) {
+	assert.Equal(t, "high", priority.Name(priority.High))
+	assert.Equal(t, "Alta", priority.Label(priority.High))
+	// Un valor fuera de rango se trata como sin prioridad
+	assert.Equal(t, "none", priority.Name(9))
+	assert.Equal(t, "Sin prioridad", priority.Label(9))
+}
@@ -0,0 +1,84 @@
+package project_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x');
+		INSERT INTO projects (id, user_id, name) VALUES (1, 1, 'Casa'), (2, 2, 'Casa');
+		INSERT INTO tasks (title, done, user_id, project_id) VALUES
+			('Fregar', 0, 1, 1), ('Barrer', 1, 1, 1), ('Suelta', 0, 1, NULL)`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestNormalize(t *testing.T) {
+	name, err := project.Normalize("  Casa nueva ")
+	require.NoError(t, err)
+	assert.Equal(t, "Casa nueva", name)
+
+	for _, in := range []string{"", "   ", "none", "NONE"} {
+		_, err := project.Normalize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestTaskCounts(t *testing.T) {
+	db := testDB(t)
+
+	counts, err := project.TaskCounts(context.Background(), db, 1)
+	require.NoError(t, err)
+	assert.Equal(t, project.Counts{Tasks: 2, Pending: 1}, counts[1])
+	assert.Equal(t, project.Counts{Tasks: 1, Pending: 1}, counts[0])
+}
+
+func TestResolveID(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	id, err := project.ResolveID(ctx, db, 1, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id.Int64)
+
+	id, err = project.ResolveID(ctx, db, 1, "none")
+	require.NoError(t, err)
+	assert.False(t, id.Valid)
+
+	// El proyecto 2 es de otro usuario
+	_, err = project.ResolveID(ctx, db, 1, "2")
+	assert.Error(t, err)
+	_, err = project.ResolveID(ctx, db, 1, "casa")
+	assert.Error(t, err)
+}
+
+func TestReassignAndDelete(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, project.Reassign(ctx, db, 1, 2))
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tasks WHERE project_id = 2").Scan(&n))
+	assert.Equal(t, 2, n)
+
+	// Al borrar un proyecto sus tareas quedan sin proyecto
+	_, err := db.Exec("DELETE FROM projects WHERE id = 2")
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tasks WHERE project_id IS NULL").Scan(&n))
+	assert.Equal(t, 3, n)
+}
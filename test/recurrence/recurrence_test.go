@@ -0,0 +1,238 @@
+package recurrence_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/recurrence"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+
+	_ "modernc.org/sqlite"
+)
+
+var madrid = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		return time.FixedZone("CET", 3600)
+	}
+	return loc
+}()
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"weekly", "FREQ=WEEKLY"},
+		{"Mensual", "FREQ=MONTHLY"},
+		{"FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TH,MO", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"freq=monthly;count=3", "FREQ=MONTHLY;COUNT=3"},
+		{"FREQ=WEEKLY;UNTIL=20250315T120000Z", "FREQ=WEEKLY;UNTIL=20250315T120000Z"},
+	}
+	for _, tt := range tests {
+		rule, err := recurrence.Parse(tt.in, madrid)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, rule.String(), tt.in)
+	}
+
+	rule, err := recurrence.Parse("FREQ=DAILY;UNTIL=2025-03-15", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 15, 23, 59, 59, 0, time.UTC), rule.Until)
+
+	for _, in := range []string{
+		"", "cada lunes", "INTERVAL=2", "FREQ=YEARLY", "FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=DAILY;BYMONTH=1", "FREQ=DAILY;FREQ=WEEKLY",
+	} {
+		_, err := recurrence.Parse(in, madrid)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// El 10 de marzo de 2025 es lunes
+	monday := time.Date(2025, 3, 10, 9, 0, 0, 0, madrid)
+	tests := []struct {
+		rule  string
+		start time.Time
+		want  time.Time
+	}{
+		{"FREQ=DAILY", monday, time.Date(2025, 3, 11, 9, 0, 0, 0, madrid)},
+		{"FREQ=DAILY;INTERVAL=3", monday, time.Date(2025, 3, 13, 9, 0, 0, 0, madrid)},
+		{"FREQ=DAILY;BYDAY=MO,FR", monday, time.Date(2025, 3, 14, 9, 0, 0, 0, madrid)},
+		{"FREQ=WEEKLY", monday, time.Date(2025, 3, 17, 9, 0, 0, 0, madrid)},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", monday, time.Date(2025, 3, 13, 9, 0, 0, 0, madrid)},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", monday.AddDate(0, 0, 3), time.Date(2025, 3, 17, 9, 0, 0, 0, madrid)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", monday.AddDate(0, 0, 3), time.Date(2025, 3, 24, 9, 0, 0, 0, madrid)},
+		{"FREQ=MONTHLY", monday, time.Date(2025, 4, 10, 9, 0, 0, 0, madrid)},
+		{"FREQ=MONTHLY;INTERVAL=2", monday, time.Date(2025, 5, 10, 9, 0, 0, 0, madrid)},
+		// Febrero no tiene día 31
+		{"FREQ=MONTHLY", time.Date(2025, 1, 31, 9, 0, 0, 0, madrid), time.Date(2025, 3, 31, 9, 0, 0, 0, madrid)},
+		// Se mantiene la hora local aunque cambie la hora oficial
+		{"FREQ=DAILY", time.Date(2025, 3, 29, 9, 0, 0, 0, madrid), time.Date(2025, 3, 30, 9, 0, 0, 0, madrid)},
+	}
+	for _, tt := range tests {
+		rule, err := recurrence.Parse(tt.rule, madrid)
+		require.NoError(t, err)
+		got, ok := rule.Next(tt.start)
+		require.True(t, ok, tt.rule)
+		assert.True(t, tt.want.Equal(got), "%s desde %s: got %s", tt.rule, tt.start, got)
+	}
+
+	rule, err := recurrence.Parse("FREQ=WEEKLY;UNTIL=20250315", madrid)
+	require.NoError(t, err)
+	_, ok := rule.Next(monday)
+	assert.False(t, ok)
+
+	rule, err = recurrence.Parse("FREQ=DAILY;COUNT=2", madrid)
+	require.NoError(t, err)
+	_, ok = rule.Next(monday)
+	assert.True(t, ok)
+	_, ok = rule.Advance().Next(monday)
+	assert.False(t, ok)
+}
+
+func TestLabel(t *testing.T) {
+	tests := map[string]string{
+		"FREQ=DAILY":                         "cada día",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH": "cada 2 semanas (lu, ju)",
+		"FREQ=MONTHLY;COUNT=3":               "cada mes, quedan 3 veces",
+		"FREQ=DAILY;UNTIL=20250315T225959Z":  "cada día, hasta el 15/03/2025",
+		"FREQ=MONTHLY;INTERVAL=6;COUNT=1":    "cada 6 meses, queda 1 vez",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, recurrence.Label(null.StringFrom(in), time.UTC), in)
+	}
+	assert.Empty(t, recurrence.Label(null.String{}, time.UTC))
+}
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x')`)
+	require.NoError(t, err)
+	return db
+}
+
+func newTask(t *testing.T, db *sql.DB, rule string, dueAt null.Time) *models.Task {
+	task := &models.Task{
+		Title:       "Informe",
+		Done:        null.BoolFrom(false),
+		UserID:      null.Int64From(1),
+		DueAt:       dueAt,
+		Priority:    3,
+		Description: "Enviar a **dirección**",
+	}
+	require.NoError(t, recurrence.Apply(task, rule, time.UTC))
+	require.NoError(t, task.Insert(context.Background(), db, boil.Infer()))
+	return task
+}
+
+func TestComplete(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC)
+
+	task := newTask(t, db, "FREQ=WEEKLY;COUNT=2", null.TimeFrom(time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)))
+	tags, err := tag.Ensure(ctx, db, 1, []string{"trabajo"})
+	require.NoError(t, err)
+	require.NoError(t, task.AddTags(ctx, db, false, tags...))
+	_, err = db.Exec("INSERT INTO tasks (title, done, user_id, parent_id) VALUES ('Datos', 1, 1, ?)", task.ID)
+	require.NoError(t, err)
+
+	task.Done = null.BoolFrom(true)
+	next, err := recurrence.Complete(ctx, db, task, now)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	// La fecha sale del vencimiento, no de cuándo se completó
+	assert.True(t, time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC).Equal(next.DueAt.Time))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=1", next.Recurrence.String)
+	assert.Equal(t, int64(3), next.Priority)
+	assert.Equal(t, "Enviar a **dirección**", next.Description)
+	assert.False(t, next.Done.Bool)
+
+	require.NoError(t, task.Reload(ctx, db))
+	assert.False(t, task.Recurrence.Valid)
+	nextTags, err := next.Tags().All(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trabajo"}, tag.Names(nextTags))
+	children, err := next.ParentTasks(qm.OrderBy("id")).All(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, "Datos", children[0].Title)
+	assert.False(t, children[0].Done.Bool)
+
+	// Era la última repetición
+	last, err := recurrence.Complete(ctx, db, next, now)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	// Sin vencimiento se cuenta desde que se completa
+	undated := newTask(t, db, "daily", null.Time{})
+	next, err = recurrence.Complete(ctx, db, undated, now)
+	require.NoError(t, err)
+	assert.True(t, now.AddDate(0, 0, 1).Equal(next.DueAt.Time))
+
+	plain := newTask(t, db, "", null.Time{})
+	next, err = recurrence.Complete(ctx, db, plain, now)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestCompleteTwice(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC)
+
+	// Dos copias de la misma tarea, leídas antes de completar ninguna
+	task := newTask(t, db, "FREQ=WEEKLY;COUNT=3", null.TimeFrom(time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)))
+	stale, err := models.FindTask(ctx, db, task.ID)
+	require.NoError(t, err)
+
+	next, err := recurrence.Complete(ctx, db, task, now)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	again, err := recurrence.Complete(ctx, db, stale, now)
+	require.NoError(t, err)
+	assert.Nil(t, again, "the next occurrence already exists")
+
+	count, err := models.Tasks().Count(ctx, db)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", next.Recurrence.String)
+}
+
+func TestSkipAndStop(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 12, 18, 0, 0, 0, time.UTC)
+
+	task := newTask(t, db, "FREQ=MONTHLY;COUNT=2", null.TimeFrom(time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)))
+	require.NoError(t, recurrence.Skip(ctx, db, task, now))
+	require.NoError(t, task.Reload(ctx, db))
+	assert.True(t, time.Date(2025, 4, 10, 9, 0, 0, 0, time.UTC).Equal(task.DueAt.Time))
+	assert.Equal(t, "FREQ=MONTHLY;COUNT=1", task.Recurrence.String)
+	assert.ErrorIs(t, recurrence.Skip(ctx, db, task, now), recurrence.ErrSeriesEnded)
+
+	require.NoError(t, recurrence.Stop(ctx, db, task))
+	require.NoError(t, task.Reload(ctx, db))
+	assert.False(t, task.Recurrence.Valid)
+	assert.ErrorIs(t, recurrence.Skip(ctx, db, task, now), recurrence.ErrNotRecurring)
+	assert.ErrorIs(t, recurrence.Stop(ctx, db, task), recurrence.ErrNotRecurring)
+
+	child := &models.Task{ParentID: task.ID}
+	assert.ErrorIs(t, recurrence.Apply(child, "weekly", time.UTC), recurrence.ErrSubtask)
+}
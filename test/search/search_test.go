@@ -0,0 +1,108 @@
+package search_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x');
+		INSERT INTO tasks (id, title, done, user_id, description) VALUES
+			(1, 'Comprar leche', 0, 1, ''),
+			(2, 'Ir al súper', 0, 1, 'Lista: pan, leche y huevos'),
+			(3, 'Leche de luis', 0, 2, ''),
+			(4, 'Llamar a Canción <b>', 1, 1, 'Preguntar por la canción')`)
+	require.NoError(t, err)
+	return db
+}
+
+func ids(results []*search.Result) []int64 {
+	out := make([]int64, 0, len(results))
+	for _, r := range results {
+		out = append(out, r.ID.Int64)
+	}
+	return out
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"leche", `"leche"*`},
+		{"  pan   leche ", `"pan"* "leche"*`},
+		{`a"b OR NOT`, `"a""b"* "OR"* "NOT"*`},
+		{`"frase" pre*`, `"frase"* "pre"*`},
+	}
+	for _, tt := range tests {
+		got, err := search.Query(tt.in)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+	for _, in := range []string{"", "   ", `"" *`} {
+		_, err := search.Query(in)
+		assert.ErrorIs(t, err, search.ErrEmptyQuery)
+	}
+}
+
+func TestTasks(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	// Solo las del usuario, y el título pesa más que las notas
+	results, err := search.Tasks(ctx, db, 1, "leche", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(results))
+	assert.Equal(t, "Comprar [leche]", results[0].Highlight("[", "]"))
+	assert.Contains(t, results[1].Highlight("[", "]"), "pan, [leche] y huevos")
+
+	// Prefijos, varias palabras y sin tener en cuenta acentos ni mayúsculas
+	results, err = search.Tasks(ctx, db, 1, "LECH hue", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, ids(results))
+	results, err = search.Tasks(ctx, db, 1, "cancion", 0)
+	require.NoError(t, err)
+	require.Equal(t, []int64{4}, ids(results))
+	assert.Equal(t, "Llamar a <mark>Canción</mark> &lt;b&gt;", string(results[0].HTML()))
+
+	// Los operadores de FTS5 no rompen la consulta
+	_, err = search.Tasks(ctx, db, 1, `leche" OR (NEAR - :`, 0)
+	assert.NoError(t, err)
+
+	results, err = search.Tasks(ctx, db, 1, "leche", 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestIndexFollowsTasks(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(`UPDATE tasks SET title = 'Comprar pan', description = 'integral' WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM tasks WHERE id = 2`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO tasks (id, title, done, user_id) VALUES (5, 'Leche de avena', 0, 1)`)
+	require.NoError(t, err)
+
+	results, err := search.Tasks(ctx, db, 1, "leche", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5}, ids(results))
+	results, err = search.Tasks(ctx, db, 1, "integral", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids(results))
+}
@@ -0,0 +1,421 @@
+package sso_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/credentials"
+	"github.com/JorgeePG/todo-list/internal/handlers"
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/sso"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/coreos/go-oidc/v3/oidc/oidctest"
+	"github.com/gorilla/sessions"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	clientID     = "todo"
+	clientSecret = "secreto"
+	redirectURL  = "http://todo.test/login/oidc/callback"
+)
+
+// fakeIdP es un proveedor OpenID Connect en memoria. /auth inicia sesión
+// directamente con la cuenta Subject y devuelve un código; /token lo canjea
+// comprobando el cliente y el verificador PKCE.
+type fakeIdP struct {
+	oidctest.Server
+	URL string
+	key *rsa.PrivateKey
+
+	mu                sync.Mutex
+	Subject           string
+	Email             string
+	PreferredUsername string
+	codes             map[string]url.Values
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &fakeIdP{
+		Server:  oidctest.Server{PublicKeys: []oidctest.PublicKey{{PublicKey: key.Public(), KeyID: "k1", Algorithm: oidc.RS256}}},
+		key:     key,
+		Subject: "ext-123",
+		Email:   "ana@example.com",
+		codes:   map[string]url.Values{},
+	}
+	srv := httptest.NewServer(idp)
+	t.Cleanup(srv.Close)
+	idp.URL = srv.URL
+	idp.SetIssuer(srv.URL)
+	return idp
+}
+
+func (idp *fakeIdP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/auth":
+		idp.authorize(w, r)
+	case "/token":
+		idp.token(w, r)
+	default:
+		idp.Server.ServeHTTP(w, r)
+	}
+}
+
+func (idp *fakeIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != clientID || q.Get("response_type") != "code" ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "petición inválida", http.StatusBadRequest)
+		return
+	}
+	idp.mu.Lock()
+	code := "code-" + strconv.Itoa(len(idp.codes))
+	q.Set("sub", idp.Subject)
+	q.Set("email", idp.Email)
+	q.Set("preferred_username", idp.PreferredUsername)
+	idp.codes[code] = q
+	idp.mu.Unlock()
+
+	back, _ := url.Parse(q.Get("redirect_uri"))
+	back.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	idp.mu.Lock()
+	auth, found := idp.codes[r.FormValue("code")]
+	delete(idp.codes, r.FormValue("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	switch {
+	case id != clientID || secret != clientSecret:
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	case !found || base64.RawURLEncoding.EncodeToString(sum[:]) != auth.Get("code_challenge"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":                idp.URL,
+		"aud":                clientID,
+		"sub":                auth.Get("sub"),
+		"email":              auth.Get("email"),
+		"preferred_username": auth.Get("preferred_username"),
+		"nonce":              auth.Get("nonce"),
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Hour).Unix(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "acceso",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     oidctest.SignIDToken(idp.key, "k1", oidc.RS256, string(claims)),
+	})
+}
+
+// login sigue la redirección de /auth y devuelve la URL de vuelta.
+func (idp *fakeIdP) login(t *testing.T, authURL string) *url.URL {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(authURL)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	back, err := res.Location()
+	require.NoError(t, err)
+	return back
+}
+
+func (idp *fakeIdP) provider(t *testing.T, autoProvision bool) *sso.Provider {
+	p, err := sso.New(context.Background(), sso.Config{
+		Issuer:        idp.URL,
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   redirectURL,
+		Name:          "Empresa",
+		AutoProvision: autoProvision,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+	return db
+}
+
+func TestExchange(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := idp.provider(t, false)
+	ctx := context.Background()
+
+	req, err := sso.NewRequest()
+	require.NoError(t, err)
+	back := idp.login(t, p.AuthCodeURL(req))
+	assert.Equal(t, req.State, back.Query().Get("state"))
+
+	id, err := p.Exchange(ctx, back.Query().Get("code"), req)
+	require.NoError(t, err)
+	assert.Equal(t, "ext-123", id.Subject)
+	assert.Equal(t, "ana@example.com", id.Email)
+
+	_, err = p.Exchange(ctx, back.Query().Get("code"), req)
+	assert.Error(t, err, "codes can only be used once")
+
+	other, err := sso.NewRequest()
+	require.NoError(t, err)
+	back = idp.login(t, p.AuthCodeURL(req))
+	_, err = p.Exchange(ctx, back.Query().Get("code"), sso.Request{State: req.State, Nonce: req.Nonce, Verifier: other.Verifier})
+	assert.Error(t, err, "wrong PKCE verifier")
+
+	back = idp.login(t, p.AuthCodeURL(req))
+	_, err = p.Exchange(ctx, back.Query().Get("code"), sso.Request{State: req.State, Nonce: other.Nonce, Verifier: req.Verifier})
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestLinkAndProvision(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	_, err := db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x')`)
+	require.NoError(t, err)
+	id := &sso.Identity{Subject: "ext-1", Email: "ana@example.com"}
+
+	_, err = sso.FindUser(ctx, db, "https://idp", id)
+	assert.ErrorIs(t, err, sso.ErrNotLinked)
+
+	_, err = sso.Link(ctx, db, 1, "https://idp", id)
+	require.NoError(t, err)
+	_, err = sso.Link(ctx, db, 1, "https://idp", id)
+	assert.NoError(t, err, "linking twice to the same user is a no-op")
+	_, err = sso.Link(ctx, db, 2, "https://idp", id)
+	assert.ErrorIs(t, err, sso.ErrLinkedElsewhere)
+
+	user, err := sso.FindUser(ctx, db, "https://idp", id)
+	require.NoError(t, err)
+	assert.Equal(t, "ana", user.Username)
+	_, err = sso.FindUser(ctx, db, "https://otro-idp", id)
+	assert.ErrorIs(t, err, sso.ErrNotLinked, "the subject is only unique per issuer")
+
+	// El nombre ana ya existe
+	user, err = sso.Provision(ctx, db, "https://otro-idp", id, credentials.DefaultPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, "ana2", user.Username)
+	assert.Empty(t, user.PasswordHash)
+	found, err := sso.FindUser(ctx, db, "https://otro-idp", id)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	policy := credentials.DefaultPolicy
+	assert.Equal(t, "luis", sso.Username(&sso.Identity{PreferredUsername: " luis ", Email: "l@example.com"}, policy))
+	assert.Equal(t, "usuario", sso.Username(&sso.Identity{Subject: "x"}, policy))
+
+	// El nombre se adapta a la política: solo los caracteres admitidos y sin
+	// pasar de la longitud máxima, también con el número detrás
+	assert.Equal(t, "Ana.María.López.x", sso.Username(&sso.Identity{PreferredUsername: "Ana María López <x>"}, policy))
+	assert.Equal(t, "jose.perez", sso.Username(&sso.Identity{Email: "+jose+perez@example.com"}, policy))
+	assert.Equal(t, "usuario", sso.Username(&sso.Identity{PreferredUsername: "<@>"}, policy))
+	assert.Equal(t, "usuario", sso.Username(&sso.Identity{PreferredUsername: "yo"}, policy), "too short")
+	long := strings.Repeat("a", 40)
+	assert.Equal(t, strings.Repeat("a", 32), sso.Username(&sso.Identity{PreferredUsername: long}, policy))
+	for _, want := range []string{strings.Repeat("a", 32), strings.Repeat("a", 31) + "2"} {
+		user, err := sso.Provision(ctx, db, "https://idp", &sso.Identity{Subject: want, PreferredUsername: long}, policy)
+		require.NoError(t, err)
+		assert.Equal(t, want, user.Username)
+	}
+
+	// Borrar el usuario borra sus vínculos
+	_, err = db.Exec(`DELETE FROM users WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = sso.FindUser(ctx, db, "https://idp", id)
+	assert.ErrorIs(t, err, sso.ErrNotLinked)
+}
+
+// browser guarda las cookies de sesión entre peticiones, como un navegador.
+type browser struct {
+	cookies map[string]*http.Cookie
+}
+
+func (b *browser) do(handler http.HandlerFunc, method, target string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range b.cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	for _, c := range w.Result().Cookies() {
+		b.cookies[c.Name] = c
+	}
+	return w
+}
+
+// ssoLogin hace el login completo con el proveedor y devuelve la respuesta de
+// la vuelta.
+func (b *browser) ssoLogin(t *testing.T, h *handlers.WebHandler, idp *fakeIdP) *httptest.ResponseRecorder {
+	t.Helper()
+	w := b.do(h.SSOLogin, "GET", "/login/oidc", nil)
+	require.Equal(t, http.StatusFound, w.Code)
+	back := idp.login(t, w.Header().Get("Location"))
+	return b.do(h.SSOCallback, "GET", "/login/oidc/callback?"+back.RawQuery, nil)
+}
+
+func TestLoginFlow(t *testing.T) {
+	idp := newFakeIdP(t)
+	templates := template.Must(template.ParseGlob("../../web_templates/*.html"))
+	templates = template.Must(templates.ParseGlob("../../web_templates/fragments/*.html"))
+	h := &handlers.WebHandler{
+		Db:        testDB(t),
+		Templates: templates,
+		Store:     sessions.NewCookieStore([]byte("test-secret")),
+		SSO:       idp.provider(t, false),
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("testpass"), bcrypt.MinCost)
+	_, err := h.Db.Exec("INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', ?)", hash)
+	require.NoError(t, err)
+
+	t.Run("Login Page", func(t *testing.T) {
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.do(h.LoginHandler, "GET", "/login", nil)
+		assert.Contains(t, w.Body.String(), `<a href="/login/oidc" class="sso-button">Entrar con Empresa</a>`)
+	})
+
+	t.Run("Not Linked", func(t *testing.T) {
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.ssoLogin(t, h, idp)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "no está vinculada a ningún usuario")
+	})
+
+	t.Run("Link And Login", func(t *testing.T) {
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.do(h.LoginHandler, "POST", "/login", url.Values{"username": {"ana"}, "password": {"testpass"}})
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		w = b.ssoLogin(t, h, idp)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/settings", w.Header().Get("Location"))
+		w = b.do(h.SettingsPage, "GET", "/settings", nil)
+		assert.Contains(t, w.Body.String(), "ana@example.com")
+
+		b = &browser{cookies: map[string]*http.Cookie{}}
+		w = b.ssoLogin(t, h, idp)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/", w.Header().Get("Location"))
+		w = b.do(h.SettingsPage, "GET", "/settings", nil)
+		assert.Equal(t, http.StatusOK, w.Code, "the session belongs to ana")
+	})
+
+	t.Run("Two Factor", func(t *testing.T) {
+		key, err := totp.Generate(totp.GenerateOpts{Issuer: "todo-list", AccountName: "ana"})
+		require.NoError(t, err)
+		_, err = h.Db.Exec("UPDATE users SET totp_secret = ?, totp_enabled_at = CURRENT_TIMESTAMP WHERE id = 1", key.Secret())
+		require.NoError(t, err)
+		defer h.Db.Exec("UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = 1")
+
+		// El proveedor no se salta el código
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.ssoLogin(t, h, idp)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login/2fa", w.Header().Get("Location"))
+		w = b.do(h.SettingsPage, "GET", "/settings", nil)
+		assert.NotEqual(t, http.StatusOK, w.Code, "the session has no user yet")
+
+		code, err := totp.GenerateCode(key.Secret(), time.Now())
+		require.NoError(t, err)
+		w = b.do(h.LoginTwoFactor, "POST", "/login/2fa", url.Values{"code": {code}})
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/", w.Header().Get("Location"))
+		w = b.do(h.SettingsPage, "GET", "/settings", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid State", func(t *testing.T) {
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.do(h.SSOLogin, "GET", "/login/oidc", nil)
+		back := idp.login(t, w.Header().Get("Location"))
+		q := back.Query()
+		q.Set("state", "otro")
+		w = b.do(h.SSOCallback, "GET", "/login/oidc/callback?"+q.Encode(), nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		// Sin login previo tampoco vale
+		b = &browser{cookies: map[string]*http.Cookie{}}
+		w = b.do(h.SSOCallback, "GET", "/login/oidc/callback?"+back.RawQuery, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = b.do(h.SSOCallback, "GET", "/login/oidc/callback?error=access_denied", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Auto Provision", func(t *testing.T) {
+		h.SSO = idp.provider(t, true)
+		idp.Subject, idp.Email = "ext-456", "luis@example.com"
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.ssoLogin(t, h, idp)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+
+		var username, password string
+		require.NoError(t, h.Db.QueryRow(`SELECT u.username, u.password_hash FROM users u
+			JOIN user_identities i ON i.user_id = u.id WHERE i.subject = 'ext-456'`).Scan(&username, &password))
+		assert.Equal(t, "luis", username)
+		assert.Empty(t, password)
+
+		// Un usuario creado desde el proveedor no puede entrar con contraseña
+		w = b.do(h.LoginHandler, "POST", "/login", url.Values{"username": {"luis"}, "password": {""}})
+		assert.Contains(t, w.Body.String(), "Usuario o contraseña incorrectos")
+	})
+
+	t.Run("Auto Provision Username", func(t *testing.T) {
+		idp.Subject, idp.Email, idp.PreferredUsername = "ext-789", "", "Ana María López <x>"
+		defer func() { idp.PreferredUsername = "" }()
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		w := b.ssoLogin(t, h, idp)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+
+		var username string
+		require.NoError(t, h.Db.QueryRow(`SELECT u.username FROM users u
+			JOIN user_identities i ON i.user_id = u.id WHERE i.subject = 'ext-789'`).Scan(&username))
+		assert.Equal(t, "Ana.María.López.x", username)
+		assert.NoError(t, credentials.DefaultPolicy.CheckUsername(username))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := &handlers.WebHandler{Db: h.Db, Templates: templates, Store: h.Store}
+		b := &browser{cookies: map[string]*http.Cookie{}}
+		assert.Equal(t, http.StatusNotFound, b.do(disabled.SSOLogin, "GET", "/login/oidc", nil).Code)
+		w := b.do(disabled.LoginHandler, "GET", "/login", nil)
+		assert.NotContains(t, w.Body.String(), "sso-button")
+	})
+}
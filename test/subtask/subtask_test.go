@@ -0,0 +1,104 @@
+package subtask_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/subtask"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v8"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x');
+		INSERT INTO projects (id, user_id, name) VALUES (1, 1, 'Casa');
+		INSERT INTO tasks (id, title, done, user_id, project_id) VALUES
+			(1, 'Mudanza', 0, 1, 1), (2, 'Ajena', 0, 2, NULL)`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestProgress(t *testing.T) {
+	p := subtask.Of(models.TaskSlice{
+		{Done: null.BoolFrom(true)},
+		{Done: null.BoolFrom(false)},
+		{Done: null.BoolFrom(true)},
+	})
+	assert.Equal(t, subtask.Progress{Done: 2, Total: 3}, p)
+	assert.Equal(t, "2/3", p.String())
+	assert.False(t, p.Complete())
+	assert.True(t, subtask.Progress{Done: 3, Total: 3}.Complete())
+	assert.False(t, subtask.Of(nil).Complete())
+}
+
+func TestAddAndSuggestComplete(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	_, err := subtask.FindParent(ctx, db, 1, 2)
+	assert.ErrorIs(t, err, subtask.ErrNotFound)
+
+	parent, err := subtask.FindParent(ctx, db, 1, 1)
+	require.NoError(t, err)
+	cajas, err := subtask.Add(ctx, db, parent, "Cajas")
+	require.NoError(t, err)
+	_, err = subtask.Add(ctx, db, parent, "Camión")
+	require.NoError(t, err)
+	assert.Equal(t, parent.ID, cajas.ParentID)
+	assert.Equal(t, int64(1), cajas.ProjectID.Int64)
+
+	_, err = subtask.FindParent(ctx, db, 1, cajas.ID.Int64)
+	assert.ErrorIs(t, err, subtask.ErrNested)
+
+	suggest, err := subtask.SuggestComplete(ctx, db, 1)
+	require.NoError(t, err)
+	assert.False(t, suggest)
+
+	_, err = db.Exec("UPDATE tasks SET done = 1 WHERE parent_id = 1")
+	require.NoError(t, err)
+	suggest, err = subtask.SuggestComplete(ctx, db, 1)
+	require.NoError(t, err)
+	assert.True(t, suggest)
+
+	_, err = db.Exec("UPDATE tasks SET done = 1 WHERE id = 1")
+	require.NoError(t, err)
+	suggest, err = subtask.SuggestComplete(ctx, db, 1)
+	require.NoError(t, err)
+	assert.False(t, suggest)
+}
+
+func TestSyncProjectAndCascade(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	parent, err := subtask.FindParent(ctx, db, 1, 1)
+	require.NoError(t, err)
+	_, err = subtask.Add(ctx, db, parent, "Cajas")
+	require.NoError(t, err)
+
+	parent.ProjectID = null.Int64{}
+	_, err = db.Exec("UPDATE tasks SET project_id = NULL WHERE id = 1")
+	require.NoError(t, err)
+	require.NoError(t, subtask.SyncProject(ctx, db, parent))
+	var projectID sql.NullInt64
+	require.NoError(t, db.QueryRow("SELECT project_id FROM tasks WHERE parent_id = 1").Scan(&projectID))
+	assert.False(t, projectID.Valid)
+
+	_, err = parent.Delete(ctx, db)
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tasks WHERE parent_id = 1").Scan(&count))
+	assert.Zero(t, count)
+}
@@ -0,0 +1,76 @@
+package tag_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestNormalize(t *testing.T) {
+	name, err := tag.Normalize("  Trabajo ")
+	require.NoError(t, err)
+	assert.Equal(t, "trabajo", name)
+
+	for _, in := range []string{"", "  ", "-casa", "dos palabras", "a,b", "demasiado-largo-para-una-etiqueta-de-verdad"} {
+		_, err := tag.Normalize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseList(t *testing.T) {
+	names, err := tag.ParseList("trabajo, Casa,, trabajo ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trabajo", "casa"}, names)
+
+	names, err = tag.ParseList("")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, err = tag.ParseList("bien, -mal")
+	assert.Error(t, err)
+}
+
+func TestEnsureAndReassign(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, migrations.Apply(ctx, db))
+
+	_, err = db.Exec("INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x')")
+	require.NoError(t, err)
+
+	first, err := tag.Ensure(ctx, db, 1, []string{"trabajo", "casa"})
+	require.NoError(t, err)
+	again, err := tag.Ensure(ctx, db, 1, []string{"casa"})
+	require.NoError(t, err)
+	assert.Equal(t, first[1].ID, again[0].ID, "no debe duplicar etiquetas")
+
+	_, err = db.Exec("INSERT INTO tasks (id, title, user_id) VALUES (1, 'Informe', 1)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (1, ?)", first[0].ID)
+	require.NoError(t, err)
+
+	require.NoError(t, tag.Reassign(ctx, db, 1, 2))
+	var owner int64
+	var name string
+	err = db.QueryRow("SELECT g.user_id, g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id = 1").Scan(&owner, &name)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), owner)
+	assert.Equal(t, "trabajo", name)
+
+	// Al borrar el usuario se borran sus etiquetas
+	_, err = db.Exec("DELETE FROM users WHERE id = 1")
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tags WHERE user_id = 1").Scan(&n))
+	assert.Zero(t, n)
+}
@@ -0,0 +1,184 @@
+package taskquery_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/taskquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v8"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+
+	// Las fechas se guardan como lo hace la aplicación, desde time.Time
+	day1 := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x'), (2, 'luis', 'x');
+		INSERT INTO tasks (id, title, done, user_id, priority, due_at, description) VALUES
+			(1, 'Comprar leche', 0, 1, 0, NULL, ''),
+			(2, 'Banco', 1, 1, 3, ?, ''),
+			(3, 'Dentista', 0, 1, 3, NULL, 'Llevar la leche'),
+			(4, 'Alquiler', 0, 1, 0, ?, ''),
+			(5, 'Correo', 1, 1, 2, ?, ''),
+			(6, 'De luis', 0, 2, 3, NULL, '')`, day2, day1, day1)
+	require.NoError(t, err)
+	return db
+}
+
+func taskIDs(tasks models.TaskSlice) []int64 {
+	out := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, task.ID.Int64)
+	}
+	return out
+}
+
+// allPages recorre el listado de página en página siguiendo los cursores.
+func allPages(t *testing.T, db *sql.DB, f taskquery.Filter) []int64 {
+	var out []int64
+	for i := 0; i < 10; i++ {
+		page, err := f.Page(context.Background(), db)
+		require.NoError(t, err)
+		out = append(out, taskIDs(page.Tasks)...)
+		if page.Next == "" {
+			return out
+		}
+		f.After, err = taskquery.ParseCursor(page.Next, f.Sort)
+		require.NoError(t, err)
+	}
+	t.Fatal("demasiadas páginas")
+	return nil
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := taskquery.ParseFilter(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, taskquery.Filter{}, f)
+
+	f, err = taskquery.ParseFilter(url.Values{
+		"done": {"pending"}, "q": {" leche "}, "tag": {"casa"}, "project": {"none"},
+		"sort": {"Title"}, "order": {"desc"}, "limit": {"10"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, null.BoolFrom(false), f.Done)
+	assert.Equal(t, "leche", f.Text)
+	assert.Equal(t, []string{"casa"}, f.Tags.Include)
+	assert.True(t, f.Project.Active)
+	assert.Equal(t, taskquery.Sort{Field: "title", Desc: true}, f.Sort)
+	assert.Equal(t, 10, f.Limit)
+
+	for _, values := range []url.Values{
+		{"done": {"quizá"}},
+		{"q": {`"" *`}},
+		{"sort": {"color"}},
+		{"order": {"arriba"}},
+		{"limit": {"0"}},
+		{"limit": {"201"}},
+		{"limit": {"diez"}},
+		{"cursor": {"no-es-un-cursor"}},
+	} {
+		_, err := taskquery.ParseFilter(values)
+		assert.Error(t, err, values.Encode())
+	}
+}
+
+func TestParseDone(t *testing.T) {
+	for in, want := range map[string]null.Bool{
+		"":        {},
+		"true":    null.BoolFrom(true),
+		"1":       null.BoolFrom(true),
+		"Done":    null.BoolFrom(true),
+		"false":   null.BoolFrom(false),
+		"0":       null.BoolFrom(false),
+		"pending": null.BoolFrom(false),
+	} {
+		got, err := taskquery.ParseDone(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := taskquery.ParseDone("hecha")
+	assert.Error(t, err)
+}
+
+func TestParseCursor(t *testing.T) {
+	sort := taskquery.Sort{Field: "due", Desc: true}
+	task := &models.Task{ID: null.Int64From(4), Title: "Alquiler", Priority: 2}
+	c, err := taskquery.ParseCursor(sort.Cursor(task).String(), sort)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), c.ID)
+	assert.Nil(t, c.DueAt)
+
+	// Un cursor solo vale para el orden con el que se pidió
+	_, err = taskquery.ParseCursor(sort.Cursor(task).String(), taskquery.Sort{Field: "due"})
+	assert.ErrorIs(t, err, taskquery.ErrInvalidCursor)
+	_, err = taskquery.ParseCursor("e30", sort)
+	assert.ErrorIs(t, err, taskquery.ErrInvalidCursor)
+}
+
+func TestFilterPage(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	// Por defecto, por prioridad, después por vencimiento y las que no
+	// vencen al final
+	f := taskquery.Filter{UserID: 1}
+	page, err := f.Page(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3, 5, 4, 1}, taskIDs(page.Tasks))
+	assert.Equal(t, int64(5), page.Total)
+	assert.Empty(t, page.Next)
+
+	tests := []struct {
+		sort taskquery.Sort
+		want []int64
+	}{
+		{taskquery.Sort{}, []int64{2, 3, 5, 4, 1}},
+		{taskquery.Sort{Desc: true}, []int64{1, 4, 5, 3, 2}},
+		{taskquery.Sort{Field: "id", Desc: true}, []int64{5, 4, 3, 2, 1}},
+		{taskquery.Sort{Field: "title"}, []int64{4, 2, 1, 5, 3}},
+		{taskquery.Sort{Field: "status"}, []int64{1, 3, 4, 2, 5}},
+		{taskquery.Sort{Field: "due"}, []int64{4, 5, 2, 1, 3}},
+		{taskquery.Sort{Field: "due", Desc: true}, []int64{3, 1, 2, 5, 4}},
+	}
+	for _, tt := range tests {
+		// De dos en dos, los cursores dan el mismo orden que sin paginar
+		f := taskquery.Filter{UserID: 1, Sort: tt.sort, Limit: 2}
+		assert.Equal(t, tt.want, allPages(t, db, f), "%+v", tt.sort)
+	}
+
+	// Los filtros se combinan y el total cuenta todas las páginas
+	f = taskquery.Filter{UserID: 1, Done: null.BoolFrom(false), Text: "leche", Limit: 1}
+	page, err = f.Page(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, taskIDs(page.Tasks))
+	assert.Equal(t, int64(2), page.Total)
+	assert.NotEmpty(t, page.Next)
+	assert.Equal(t, []int64{3, 1}, allPages(t, db, f))
+
+	// Las tareas que se crean detrás del cursor aparecen en la página siguiente
+	f = taskquery.Filter{UserID: 1, Sort: taskquery.Sort{Field: "id"}, Limit: 3}
+	page, err = f.Page(ctx, db)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM tasks WHERE id = 2; INSERT INTO tasks (id, title, done, user_id) VALUES (7, 'Nueva', 0, 1)`)
+	require.NoError(t, err)
+	f.After, err = taskquery.ParseCursor(page.Next, f.Sort)
+	require.NoError(t, err)
+	page, err = f.Page(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 5, 7}, taskIDs(page.Tasks))
+	assert.Empty(t, page.Next)
+}
@@ -0,0 +1,48 @@
+package taskquery_test
+
+import (
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/taskquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	f, err := taskquery.ParseTagFilter([]string{"Work", "-home", "", "urgente"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work", "urgente"}, f.Include)
+	assert.Equal(t, []string{"home"}, f.Exclude)
+	assert.Equal(t, []string{"work", "urgente", "-home"}, f.Values())
+	assert.Len(t, f.Mods(), 3)
+	assert.False(t, f.Empty())
+
+	f, err = taskquery.ParseTagFilter(nil)
+	require.NoError(t, err)
+	assert.True(t, f.Empty())
+
+	_, err = taskquery.ParseTagFilter([]string{"--doble"})
+	assert.Error(t, err)
+}
+
+func TestParseProjectFilter(t *testing.T) {
+	f, err := taskquery.ParseProjectFilter("")
+	require.NoError(t, err)
+	assert.False(t, f.Active)
+	assert.Empty(t, f.Mods())
+
+	f, err = taskquery.ParseProjectFilter("none")
+	require.NoError(t, err)
+	assert.Equal(t, taskquery.ProjectFilter{Active: true}, f)
+	assert.Equal(t, "none", f.Value())
+
+	f, err = taskquery.ParseProjectFilter("7")
+	require.NoError(t, err)
+	assert.Equal(t, "7", f.Value())
+	assert.Len(t, f.Mods(), 1)
+
+	for _, in := range []string{"casa", "0", "-1"} {
+		_, err := taskquery.ParseProjectFilter(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,29 @@
+package tasktitle_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JorgeePG/todo-list/internal/tasktitle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	title, err := tasktitle.Normalize("  Fregar platos ")
+	require.NoError(t, err)
+	assert.Equal(t, "Fregar platos", title)
+
+	// La longitud se cuenta en caracteres y sin los espacios de alrededor
+	long := strings.Repeat("ñ", tasktitle.MaxLength)
+	title, err = tasktitle.Normalize(" " + long + " ")
+	require.NoError(t, err)
+	assert.Equal(t, long, title)
+
+	_, err = tasktitle.Normalize(long + "ñ")
+	assert.EqualError(t, err, "el título no puede superar los 200 caracteres")
+	for _, in := range []string{"", "   "} {
+		_, err := tasktitle.Normalize(in)
+		assert.ErrorIs(t, err, tasktitle.ErrEmpty, "%q", in)
+	}
+}
@@ -0,0 +1,168 @@
+package twofactor_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/JorgeePG/todo-list/internal/migrations"
+	"github.com/JorgeePG/todo-list/internal/models"
+	"github.com/JorgeePG/todo-list/internal/twofactor"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/null/v8"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrations.Apply(context.Background(), db))
+
+	_, err = db.Exec(`INSERT INTO users (id, username, password_hash) VALUES (1, 'ana', 'x')`)
+	require.NoError(t, err)
+	return db
+}
+
+func findUser(t *testing.T, db *sql.DB) *models.User {
+	user, err := models.FindUser(context.Background(), db, null.Int64From(1))
+	require.NoError(t, err)
+	return user
+}
+
+func code(t *testing.T, secret string, at time.Time) string {
+	c, err := totp.GenerateCode(secret, at)
+	require.NoError(t, err)
+	return c
+}
+
+func TestEnrollAndConfirm(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := findUser(t, db)
+
+	_, err := twofactor.PendingKey(user)
+	assert.ErrorIs(t, err, twofactor.ErrNotEnrolling)
+	_, err = twofactor.Confirm(ctx, db, user, "123456", now)
+	assert.ErrorIs(t, err, twofactor.ErrNotEnrolling)
+
+	key, err := twofactor.Enroll(ctx, db, user)
+	require.NoError(t, err)
+	assert.Equal(t, twofactor.Issuer, key.Issuer())
+	assert.Equal(t, "ana", key.AccountName())
+
+	// La clave pendiente se reconstruye desde la base de datos
+	user = findUser(t, db)
+	assert.False(t, twofactor.Enabled(user))
+	pending, err := twofactor.PendingKey(user)
+	require.NoError(t, err)
+	assert.Equal(t, key.URL(), pending.URL())
+
+	_, err = twofactor.Confirm(ctx, db, user, "000000", now)
+	assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+
+	codes, err := twofactor.Confirm(ctx, db, user, code(t, key.Secret(), now), now)
+	require.NoError(t, err)
+	assert.Len(t, codes, twofactor.RecoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}$`, codes[0])
+
+	user = findUser(t, db)
+	assert.True(t, twofactor.Enabled(user))
+	left, err := twofactor.RemainingRecoveryCodes(ctx, db, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, twofactor.RecoveryCodeCount, left)
+
+	// Solo se guardan los hashes
+	var stored int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM user_recovery_codes WHERE code_hash = ?`, codes[0]).Scan(&stored))
+	assert.Zero(t, stored)
+
+	_, err = twofactor.Enroll(ctx, db, user)
+	assert.ErrorIs(t, err, twofactor.ErrAlreadyEnabled)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := findUser(t, db)
+
+	assert.ErrorIs(t, twofactor.Verify(ctx, db, user, "123456", now), twofactor.ErrNotEnabled)
+
+	key, err := twofactor.Enroll(ctx, db, user)
+	require.NoError(t, err)
+	codes, err := twofactor.Confirm(ctx, db, user, code(t, key.Secret(), now), now)
+	require.NoError(t, err)
+
+	// El código con el que se confirmó el alta ya está usado
+	assert.ErrorIs(t, twofactor.Verify(ctx, db, user, code(t, key.Secret(), now), now), twofactor.ErrInvalidCode)
+
+	// Se acepta un paso de desfase, pero no más, y cada código una sola vez
+	later := now.Add(twofactor.Period * time.Second)
+	assert.NoError(t, twofactor.Verify(ctx, db, user, code(t, key.Secret(), later.Add(twofactor.Period*time.Second)), later))
+	assert.ErrorIs(t, twofactor.Verify(ctx, db, user, code(t, key.Secret(), later), later), twofactor.ErrInvalidCode)
+	farther := later.Add(10 * time.Minute)
+	assert.ErrorIs(t, twofactor.Verify(ctx, db, user, code(t, key.Secret(), farther.Add(2*time.Minute)), farther), twofactor.ErrInvalidCode)
+
+	// Los códigos de recuperación valen una vez, con o sin guion
+	assert.NoError(t, twofactor.Verify(ctx, db, findUser(t, db), codes[0], farther))
+	assert.ErrorIs(t, twofactor.Verify(ctx, db, user, codes[0], farther), twofactor.ErrInvalidCode)
+	assert.NoError(t, twofactor.Verify(ctx, db, user, " "+codes[1][:4]+codes[1][5:]+" ", farther))
+	left, err := twofactor.RemainingRecoveryCodes(ctx, db, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, twofactor.RecoveryCodeCount-2, left)
+}
+
+func TestVerifySameCodeTwice(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := findUser(t, db)
+	key, err := twofactor.Enroll(ctx, db, user)
+	require.NoError(t, err)
+	_, err = twofactor.Confirm(ctx, db, user, code(t, key.Secret(), now), now)
+	require.NoError(t, err)
+
+	// Dos logins a la vez leen el usuario antes de que ninguno guarde el paso
+	later := now.Add(time.Minute)
+	first, second := findUser(t, db), findUser(t, db)
+	assert.NoError(t, twofactor.Verify(ctx, db, first, code(t, key.Secret(), later), later))
+	assert.ErrorIs(t, twofactor.Verify(ctx, db, second, code(t, key.Secret(), later), later), twofactor.ErrInvalidCode)
+}
+
+func TestDisable(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := findUser(t, db)
+
+	key, err := twofactor.Enroll(ctx, db, user)
+	require.NoError(t, err)
+	_, err = twofactor.Confirm(ctx, db, user, code(t, key.Secret(), now), now)
+	require.NoError(t, err)
+
+	require.NoError(t, twofactor.Disable(ctx, db, user))
+	user = findUser(t, db)
+	assert.False(t, twofactor.Enabled(user))
+	assert.False(t, user.TotpSecret.Valid)
+	left, err := twofactor.RemainingRecoveryCodes(ctx, db, 1)
+	require.NoError(t, err)
+	assert.Zero(t, left)
+
+	// Al borrar el usuario se borran sus códigos
+	key, err = twofactor.Enroll(ctx, db, user)
+	require.NoError(t, err)
+	_, err = twofactor.Confirm(ctx, db, user, code(t, key.Secret(), now), now)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM users WHERE id = 1`)
+	require.NoError(t, err)
+	left, err = twofactor.RemainingRecoveryCodes(ctx, db, 1)
+	require.NoError(t, err)
+	assert.Zero(t, left)
+}
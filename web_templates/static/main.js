@@ -6,6 +6,11 @@ document.querySelectorAll('.edit-btn').forEach(function (btn) {
         const editTitle = container.querySelector('.edit-title');
         editTitle.style.display = 'block'; // <-- Cambiado de 'flex' a 'block'
         editTitle.focus();
+        container.querySelector('.edit-due').style.display = 'block';
+        container.querySelector('.edit-priority').style.display = 'block';
+        container.querySelector('.edit-tags').style.display = 'block';
+        container.querySelector('.edit-project').style.display = 'block';
+        container.querySelector('.edit-recurrence').style.display = 'block';
         btn.style.display = 'none';
         container.querySelector('.save-btn').style.display = 'inline-block';
         container.querySelector('.cancel-btn').style.display = 'inline-block';
@@ -18,6 +23,21 @@ document.querySelectorAll('.cancel-btn').forEach(function (btn) {
         const container = btn.closest('.task-info');
         container.querySelector('.task-title').style.display = 'inline';
         container.querySelector('.edit-title').style.display = 'none';
+        const editDue = container.querySelector('.edit-due');
+        editDue.value = container.getAttribute('data-due');
+        editDue.style.display = 'none';
+        const editPriority = container.querySelector('.edit-priority');
+        editPriority.value = container.getAttribute('data-priority');
+        editPriority.style.display = 'none';
+        const editTags = container.querySelector('.edit-tags');
+        editTags.value = container.getAttribute('data-tags');
+        editTags.style.display = 'none';
+        const editProject = container.querySelector('.edit-project');
+        editProject.value = container.getAttribute('data-project');
+        editProject.style.display = 'none';
+        const editRecurrence = container.querySelector('.edit-recurrence');
+        editRecurrence.value = container.getAttribute('data-recurrence');
+        editRecurrence.style.display = 'none';
         container.querySelector('.edit-btn').style.display = 'inline-block';
         container.querySelector('.save-btn').style.display = 'none';
         btn.style.display = 'none';
@@ -31,17 +51,51 @@ document.querySelectorAll('.save-btn').forEach(function (btn) {
         const id = container.getAttribute('data-id');
         const newTitle = container.querySelector('.edit-title').value;
         const done = container.querySelector('.edit-done').checked ? "on" : "";
+        const dueAt = container.querySelector('.edit-due').value;
+        const priority = container.querySelector('.edit-priority').value;
+        const tags = container.querySelector('.edit-tags').value;
+        const projectID = container.querySelector('.edit-project').value;
+        const recurrence = container.querySelector('.edit-recurrence').value;
 
         fetch('/update', {
             method: 'POST',
-            headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
-            body: `id=${encodeURIComponent(id)}&title=${encodeURIComponent(newTitle)}&done=${encodeURIComponent(done)}`
+            // Con el ETag, el servidor rechaza el cambio (412) si la tarea se
+            // ha modificado desde que se cargó la página, p. ej. en otra pestaña
+            headers: {
+                'Content-Type': 'application/x-www-form-urlencoded',
+                'If-Match': container.getAttribute('data-etag')
+            },
+            body: `id=${encodeURIComponent(id)}&title=${encodeURIComponent(newTitle)}&done=${encodeURIComponent(done)}&due_at=${encodeURIComponent(dueAt)}&priority=${encodeURIComponent(priority)}&tags=${encodeURIComponent(tags)}&project_id=${encodeURIComponent(projectID)}&recurrence=${encodeURIComponent(recurrence)}`
         }).then(resp => {
+            if (resp.status === 412) {
+                if (confirm('Esta tarea ha cambiado desde que cargaste la página. ¿Recargar para ver los cambios? Se perderá lo que has editado.')) {
+                    window.location.reload();
+                }
+                return;
+            }
             if (resp.ok) {
+                container.setAttribute('data-etag', resp.headers.get('ETag'));
+                // La etiqueta relativa, el estilo de vencida, las etiquetas, los
+                // totales de los proyectos, las repeticiones y el orden se
+                // calculan en el servidor
+                if (dueAt !== '' || container.getAttribute('data-due') !== '' ||
+                    recurrence !== container.getAttribute('data-recurrence') ||
+                    priority !== container.getAttribute('data-priority') ||
+                    tags !== container.getAttribute('data-tags') ||
+                    projectID !== container.getAttribute('data-project') ||
+                    done !== (container.getAttribute('data-done') === 'true' ? 'on' : '')) {
+                    window.location.reload();
+                    return;
+                }
                 const taskTitle = container.querySelector('.task-title');
                 taskTitle.textContent = newTitle;
                 taskTitle.style.display = 'inline';
                 container.querySelector('.edit-title').style.display = 'none';
+                container.querySelector('.edit-due').style.display = 'none';
+                container.querySelector('.edit-priority').style.display = 'none';
+                container.querySelector('.edit-tags').style.display = 'none';
+                container.querySelector('.edit-project').style.display = 'none';
+                container.querySelector('.edit-recurrence').style.display = 'none';
                 container.querySelector('.edit-btn').style.display = 'inline-block';
                 btn.style.display = 'none';
                 container.querySelector('.cancel-btn').style.display = 'none';
@@ -57,4 +111,123 @@ document.querySelectorAll('.save-btn').forEach(function (btn) {
             }
         });
     });
-});
\ No newline at end of file
+});
+// Marca una subtarea como hecha o pendiente. Si quedan hechas todas, propone
+// completar también la tarea principal.
+document.querySelectorAll('.subtask-done').forEach(function (box) {
+    box.addEventListener('change', function () {
+        const list = box.closest('.subtask-list');
+        const parentID = list.getAttribute('data-parent');
+        const container = document.querySelector(`.task-info[data-id="${parentID}"]`);
+
+        fetch(`/api/tasks/${encodeURIComponent(parentID)}/subtasks/${encodeURIComponent(box.getAttribute('data-id'))}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+            body: `done=${box.checked ? 'true' : 'false'}`
+        }).then(resp => {
+            if (!resp.ok) {
+                box.checked = !box.checked;
+                alert('Error actualizando la subtarea');
+                return;
+            }
+            return resp.json().then(data => {
+                box.closest('li').querySelector('.subtask-title').classList.toggle('completed', box.checked);
+                const progress = container.querySelector('.subtask-progress');
+                progress.textContent = `${data.progress.done}/${data.progress.total}`;
+                progress.classList.toggle('complete', data.progress.done === data.progress.total);
+                refreshETag(container);
+                if (data.suggest_complete_parent &&
+                    confirm('Todas las subtareas están hechas. ¿Marcar también la tarea como hecha?')) {
+                    completeTask(container);
+                }
+            });
+        });
+    });
+});
+
+// refreshETag vuelve a leer el ETag de la tarea de container, que también
+// cambia con sus subtareas.
+function refreshETag(container) {
+    const id = container.getAttribute('data-id');
+    fetch(`/api/tasks/${encodeURIComponent(id)}`).then(resp => {
+        if (resp.ok) {
+            container.setAttribute('data-etag', resp.headers.get('ETag'));
+        }
+    });
+}
+
+// completeTask marca como hecha la tarea de container. /update solo cambia
+// los campos que se envían.
+function completeTask(container) {
+    const id = container.getAttribute('data-id');
+    fetch('/update', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+        body: `id=${encodeURIComponent(id)}&done=on`
+    }).then(resp => {
+        if (resp.ok) {
+            window.location.reload();
+        } else {
+            alert('Error actualizando la tarea');
+        }
+    });
+}
+
+// Selección múltiple: la barra aplica una acción a todas las tareas marcadas
+// con una sola petición a /api/tasks/bulk. Cada operación tiene que llevar el
+// ETag de su tarea: el servidor rechaza las que no lo llevan (428) y las de
+// tareas que han cambiado en otra pestaña (412).
+const bulkBar = document.querySelector('.bulk-bar');
+if (bulkBar) {
+    const boxes = document.querySelectorAll('.bulk-select');
+    const selected = () => Array.from(boxes).filter(box => box.checked).map(box => box.closest('.task-info'));
+
+    const updateBar = () => {
+        const count = selected().length;
+        bulkBar.querySelector('.bulk-count').textContent = `${count} seleccionada${count === 1 ? '' : 's'}`;
+        bulkBar.querySelectorAll('.bulk-action, select').forEach(control => control.disabled = count === 0);
+        bulkBar.querySelector('.bulk-all').checked = count > 0 && count === boxes.length;
+    };
+    boxes.forEach(box => box.addEventListener('change', updateBar));
+    bulkBar.querySelector('.bulk-all').addEventListener('change', function () {
+        boxes.forEach(box => box.checked = this.checked);
+        updateBar();
+    });
+
+    const runBulk = (op, extra) => {
+        const operations = selected().map(container => Object.assign({
+            op: op,
+            id: Number(container.getAttribute('data-id')),
+            if_match: container.getAttribute('data-etag')
+        }, extra));
+        fetch('/api/tasks/bulk', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ operations: operations })
+        }).then(resp => resp.json().then(data => {
+            const failed = (data.results || []).filter(result => result.error);
+            if (!resp.ok || failed.length > 0) {
+                const conflicts = failed.filter(result => result.status === 412).length;
+                alert(`No se han podido cambiar ${failed.length || operations.length} de ${operations.length} tareas` +
+                    (conflicts > 0 ? ': alguna ha cambiado desde que cargaste la página.' : '.'));
+            }
+            window.location.reload();
+        }));
+    };
+
+    bulkBar.querySelectorAll('.bulk-action').forEach(function (btn) {
+        btn.addEventListener('click', function () {
+            const op = btn.getAttribute('data-op');
+            if (op === 'delete' && !confirm(`¿Eliminar ${selected().length} tareas?`)) {
+                return;
+            }
+            runBulk(op);
+        });
+    });
+    bulkBar.querySelector('.bulk-project').addEventListener('change', function () {
+        runBulk('set', { fields: { project_id: this.value === 'none' ? null : Number(this.value) } });
+    });
+    bulkBar.querySelector('.bulk-priority').addEventListener('change', function () {
+        runBulk('set', { fields: { priority: this.value } });
+    });
+}